@@ -32,6 +32,20 @@
 // parameters for non-interactive operations (taken from [2]) are time=1 and to
 // use the maximum available memory.
 //
+//
+// Argon2d
+//
+// Argon2d (implemented by Derive) uses data-dependent memory access only. Derive
+// also accepts the secret key and associated data inputs of RFC 9106, for all
+// three variants.
+//
+//
+// Password hashing
+//
+// GenerateFromPassword and CompareHashAndPassword store password hashes along
+// with their salt and parameters in the PHC string format of the reference
+// implementation, such as "$argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>".
+//
 // [1] https://github.com/P-H-C/phc-winner-argon2/blob/master/argon2-specs.pdf
 // [2] https://tools.ietf.org/html/draft-irtf-cfrg-argon2-03#section-9.3
 package argon2
@@ -52,6 +66,15 @@ const (
 	argon2id
 )
 
+// Mode selects the variant of Argon2 computed by Derive.
+type Mode int
+
+const (
+	Argon2d  Mode = argon2d  // data-dependent memory access
+	Argon2i  Mode = argon2i  // data-independent memory access
+	Argon2id Mode = argon2id // hybrid, as used by IDKey
+)
+
 // Key derives a key from the password, salt, and cost parameters using Argon2i
 // returning a byte slice of length keyLen that can be used as cryptographic
 // key. The CPU cost and parallelism degree must be greater than zero.
@@ -99,6 +122,24 @@ func IDKey(password, salt []byte, time, memory uint32, threads uint8, keyLen uin
 	return deriveKey(argon2id, password, salt, nil, nil, time, memory, threads, keyLen)
 }
 
+// Derive derives a key from the password, salt, and cost parameters using
+// the given Argon2 variant, returning a byte slice of length keyLen. Unlike
+// Key and IDKey, it also accepts the optional inputs of RFC 9106: the secret
+// value K, which turns Argon2 into a keyed hash, for example with a pepper
+// held apart from the stored hashes, and the associated data X. Either may be
+// nil. The CPU cost and parallelism degree must be greater than zero.
+//
+// Argon2d uses data-dependent memory access, which makes it the fastest and
+// most resistant to GPU cracking, but also vulnerable to side-channel
+// attacks. It should only be used where those are not a concern, such as
+// proof-of-work schemes.
+func Derive(mode Mode, password, salt, secret, ad []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	if mode != Argon2d && mode != Argon2i && mode != Argon2id {
+		panic("argon2: invalid mode")
+	}
+	return deriveKey(int(mode), password, salt, secret, ad, time, memory, threads, keyLen)
+}
+
 func deriveKey(mode int, password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
 	if time < 1 {
 		panic("argon2: number of rounds too small")
@@ -77,6 +77,27 @@ func testArgon2id(t *testing.T) {
 	}
 }
 
+func TestDerive(t *testing.T) {
+	// The test vectors of RFC 9106, section 5.
+	for _, v := range []struct {
+		mode Mode
+		tag  string
+	}{
+		{Argon2d, "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb"},
+		{Argon2i, "c814d9d1dc7f37aa13f0d77f2494bda1c8de6b016dd388d29952a4c4672b6ce8"},
+		{Argon2id, "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659"},
+	} {
+		tag := Derive(v.mode, genKatPassword, genKatSalt, genKatSecret, genKatAAD, 3, 32, 4, 32)
+		if got := hex.EncodeToString(tag); got != v.tag {
+			t.Errorf("mode %d: got %s, want %s", v.mode, got, v.tag)
+		}
+	}
+
+	if !bytes.Equal(Derive(Argon2id, []byte("password"), genKatSalt, nil, nil, 1, 64, 1, 32), IDKey([]byte("password"), genKatSalt, 1, 64, 1, 32)) {
+		t.Error("Derive without secret and associated data does not match IDKey")
+	}
+}
+
 func TestVectors(t *testing.T) {
 	password, salt := []byte("password"), []byte("somesalt")
 	for i, v := range testVectors {
@@ -231,3 +252,64 @@ var testVectors = []struct {
 		hash: "1640b932f4b60e272f5d2207b9a9c626ffa1bd88d2349016",
 	},
 }
+
+func TestGenerateFromPassword(t *testing.T) {
+	params := &Params{Time: 1, Memory: 64, Threads: 2, SaltLen: 16, KeyLen: 32}
+	hashed, err := GenerateFromPassword([]byte("password"), params)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword: %v", err)
+	}
+	if !bytes.HasPrefix(hashed, []byte("$argon2id$v=19$m=64,t=1,p=2$")) {
+		t.Errorf("unexpected encoding %q", hashed)
+	}
+	if err := CompareHashAndPassword(hashed, []byte("password")); err != nil {
+		t.Errorf("CompareHashAndPassword: %v", err)
+	}
+	if err := CompareHashAndPassword(hashed, []byte("passwore")); err != ErrMismatchedHashAndPassword {
+		t.Errorf("wrong password: got %v, want %v", err, ErrMismatchedHashAndPassword)
+	}
+	if cost, err := Cost(hashed); err != nil || *cost != *params {
+		t.Errorf("Cost: got %+v, %v, want %+v", cost, err, params)
+	}
+
+	if _, err := GenerateFromPassword(nil, &Params{Time: 1, Memory: 64, Threads: 2, SaltLen: 4, KeyLen: 32}); err == nil {
+		t.Error("short salt accepted")
+	}
+}
+
+func TestCompareHashAndPassword(t *testing.T) {
+	// The Argon2i hash is from the README of the reference implementation:
+	// echo -n password | argon2 somesalt -t 2 -m 16 -p 4 -l 24 -e
+	// and the others use the same parameters with -d and -id.
+	for _, hashed := range []string{
+		"$argon2i$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG",
+		"$argon2d$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$7Kn6V2imUuaFkZmKdZLb3nvg91N5Lt7H",
+		"$argon2id$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$F1jG2CV3/Nr+yRuIsPKw0J9r4s7cJHBU",
+	} {
+		if err := CompareHashAndPassword([]byte(hashed), []byte("password")); err != nil {
+			t.Errorf("%s: %v", hashed, err)
+		}
+	}
+}
+
+func TestInvalidHashErrors(t *testing.T) {
+	check := func(hashed string, want error) {
+		t.Helper()
+		if err := CompareHashAndPassword([]byte(hashed), []byte("password")); err != want {
+			t.Errorf("%s: got %v, want %v", hashed, err, want)
+		}
+	}
+	check("", ErrInvalidHash)
+	check("argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$c29tZWhhc2g", ErrInvalidHash)
+	check("$argon2x$v=19$m=64,t=1,p=1$c29tZXNhbHQ$c29tZWhhc2g", InvalidHashPrefixError("argon2x"))
+	check("$argon2id$v=20$m=64,t=1,p=1$c29tZXNhbHQ$c29tZWhhc2g", HashVersionTooNewError(20))
+	check("$argon2id$m=64,t=1,p=1$c29tZXNhbHQ$c29tZWhhc2g", HashVersionTooOldError(16))
+	check("$argon2id$v=19$t=1,m=64,p=1$c29tZXNhbHQ$c29tZWhhc2g", ErrInvalidHash)
+	check("$argon2id$v=19$m=064,t=1,p=1$c29tZXNhbHQ$c29tZWhhc2g", ErrInvalidHash)
+	check("$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ=$c29tZWhhc2g", ErrInvalidHash)
+	check("$argon2id$v=19$m=64,t=0,p=1$c29tZXNhbHQ$c29tZWhhc2g", InvalidParamsError{"t", 0})
+	check("$argon2id$v=19$m=4294967295,t=1,p=1$c29tZXNhbHQ$c29tZWhhc2g", InvalidParamsError{"m", 4294967295})
+	check("$argon2id$v=19$m=64,t=100,p=1$c29tZXNhbHQ$c29tZWhhc2g", InvalidParamsError{"t", 100})
+	check("$argon2id$v=19$m=1048576,t=32,p=1$c29tZXNhbHQ$c29tZWhhc2g", InvalidParamsError{"t", 32})
+	check("$argon2id$v=19$m=64,t=1,p=1$c29tZQ$c29tZWhhc2g", InvalidParamsError{"salt", 4})
+}
@@ -0,0 +1,285 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package argon2
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/benchlab/bench-crypto/rand"
+	"github.com/benchlab/bench-crypto/subtle"
+)
+
+// Params holds the cost parameters of an encoded Argon2 password hash.
+type Params struct {
+	Time    uint32 // number of passes over the memory
+	Memory  uint32 // size of the memory in KiB
+	Threads uint8  // degree of parallelism
+	SaltLen uint32 // length of the random salt in bytes
+	KeyLen  uint32 // length of the hash in bytes
+}
+
+// DefaultParams are the parameters used by GenerateFromPassword if none are
+// given. They follow the second recommended option of RFC 9106, section 4.
+var DefaultParams = &Params{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 4,
+	SaltLen: 16,
+	KeyLen:  32,
+}
+
+const (
+	minSaltLen = 8
+	minKeyLen  = 4
+	// maxMemory bounds the memory cost, in KiB, of the hashes accepted by
+	// CompareHashAndPassword, so that a malicious hash cannot exhaust the
+	// memory of the verifier.
+	maxMemory = 4 * 1024 * 1024
+	// maxTime and maxWork bound the number of passes, and the number of
+	// passes times the memory cost, of the hashes accepted by
+	// CompareHashAndPassword, so that a malicious hash cannot tie up the
+	// verifier for hours either.
+	maxTime = 64
+	maxWork = 16 * 1024 * 1024
+)
+
+// The error returned from CompareHashAndPassword when a password and hash do
+// not match.
+var ErrMismatchedHashAndPassword = errors.New("argon2: hashedPassword is not the hash of the given password")
+
+// The error returned from CompareHashAndPassword when a hash is not in the
+// PHC string format.
+var ErrInvalidHash = errors.New("argon2: hashedPassword is not in the encoded Argon2 format")
+
+// The error returned from CompareHashAndPassword when a hash does not start
+// with the identifier of an Argon2 variant.
+type InvalidHashPrefixError string
+
+func (ih InvalidHashPrefixError) Error() string {
+	return fmt.Sprintf("argon2: hashes must start with $argon2id$, $argon2i$ or $argon2d$, but hashedPassword started with %q", string(ih))
+}
+
+// The error returned from CompareHashAndPassword when a hash was created with
+// an Argon2 version newer than this implementation.
+type HashVersionTooNewError uint32
+
+func (hv HashVersionTooNewError) Error() string {
+	return fmt.Sprintf("argon2: Argon2 version %d requested is newer than current version %d", uint32(hv), Version)
+}
+
+// The error returned from CompareHashAndPassword when a hash was created with
+// an Argon2 version older than the one implemented by this package.
+type HashVersionTooOldError uint32
+
+func (hv HashVersionTooOldError) Error() string {
+	return fmt.Sprintf("argon2: Argon2 version %d requested is older than current version %d", uint32(hv), Version)
+}
+
+// The error returned when a cost parameter, or the length of the salt or of
+// the hash, is outside the allowed range.
+type InvalidParamsError struct {
+	Param string // "t", "m", "p", "salt" or "key"
+	Value uint64
+}
+
+func (ip InvalidParamsError) Error() string {
+	return fmt.Sprintf("argon2: parameter %s=%d is outside allowed range", ip.Param, ip.Value)
+}
+
+// GenerateFromPassword returns the Argon2id hash of the password with the
+// given parameters, or with DefaultParams if params is nil, encoded in the
+// PHC string format:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+//
+// where the salt and the hash are encoded in unpadded standard base64. The
+// salt is generated randomly. Use CompareHashAndPassword, as defined in this
+// package, to compare the returned hashed password with its cleartext
+// version.
+func GenerateFromPassword(password []byte, params *Params) ([]byte, error) {
+	if params == nil {
+		params = DefaultParams
+	}
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, params.SaltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	p := &hashed{
+		mode:    argon2id,
+		params:  *params,
+		salt:    salt,
+		hash:    IDKey(password, salt, params.Time, params.Memory, params.Threads, params.KeyLen),
+		version: Version,
+	}
+	return p.encode(), nil
+}
+
+// CompareHashAndPassword compares an encoded Argon2 hashed password, of any
+// of the Argon2i, Argon2d and Argon2id variants, with its possible plaintext
+// equivalent. Returns nil on success, or an error on failure.
+func CompareHashAndPassword(hashedPassword, password []byte) error {
+	p, err := decodeHash(hashedPassword)
+	if err != nil {
+		return err
+	}
+	otherHash := deriveKey(p.mode, password, p.salt, nil, nil, p.params.Time, p.params.Memory, p.params.Threads, p.params.KeyLen)
+	if subtle.ConstantTimeCompare(p.hash, otherHash) == 1 {
+		return nil
+	}
+	return ErrMismatchedHashAndPassword
+}
+
+// Cost returns the parameters used to create the given encoded hashed
+// password. When the cost of a password system needs to be increased, this
+// function allows one to establish which passwords need to be updated.
+func Cost(hashedPassword []byte) (*Params, error) {
+	p, err := decodeHash(hashedPassword)
+	if err != nil {
+		return nil, err
+	}
+	return &p.params, nil
+}
+
+func (params *Params) check() error {
+	switch {
+	case params.Time < 1 || params.Time > maxTime:
+		return InvalidParamsError{"t", uint64(params.Time)}
+	case params.Threads < 1:
+		return InvalidParamsError{"p", uint64(params.Threads)}
+	case params.Memory < 8*uint32(params.Threads) || params.Memory > maxMemory:
+		return InvalidParamsError{"m", uint64(params.Memory)}
+	case uint64(params.Time)*uint64(params.Memory) > maxWork:
+		return InvalidParamsError{"t", uint64(params.Time)}
+	case params.SaltLen < minSaltLen:
+		return InvalidParamsError{"salt", uint64(params.SaltLen)}
+	case params.KeyLen < minKeyLen:
+		return InvalidParamsError{"key", uint64(params.KeyLen)}
+	}
+	return nil
+}
+
+var modeNames = [...]string{
+	argon2d:  "argon2d",
+	argon2i:  "argon2i",
+	argon2id: "argon2id",
+}
+
+type hashed struct {
+	mode    int
+	version uint32
+	params  Params
+	salt    []byte
+	hash    []byte
+}
+
+func (p *hashed) encode() []byte {
+	b := make([]byte, 0, 64+base64.RawStdEncoding.EncodedLen(len(p.salt))+base64.RawStdEncoding.EncodedLen(len(p.hash)))
+	b = append(b, '$')
+	b = append(b, modeNames[p.mode]...)
+	b = append(b, "$v="...)
+	b = strconv.AppendUint(b, uint64(p.version), 10)
+	b = append(b, "$m="...)
+	b = strconv.AppendUint(b, uint64(p.params.Memory), 10)
+	b = append(b, ",t="...)
+	b = strconv.AppendUint(b, uint64(p.params.Time), 10)
+	b = append(b, ",p="...)
+	b = strconv.AppendUint(b, uint64(p.params.Threads), 10)
+	b = append(b, '$')
+	b = appendBase64(b, p.salt)
+	b = append(b, '$')
+	b = appendBase64(b, p.hash)
+	return b
+}
+
+func decodeHash(hashedPassword []byte) (*hashed, error) {
+	fields := bytes.Split(hashedPassword, []byte("$"))
+	if len(fields) < 2 || len(fields[0]) != 0 {
+		return nil, ErrInvalidHash
+	}
+	p := new(hashed)
+	p.mode = -1
+	for mode, name := range modeNames {
+		if string(fields[1]) == name {
+			p.mode = mode
+		}
+	}
+	if p.mode < 0 {
+		return nil, InvalidHashPrefixError(fields[1])
+	}
+
+	// Hashes without a version field were created with version 0x10.
+	if len(fields) == 5 {
+		return nil, HashVersionTooOldError(0x10)
+	}
+	if len(fields) != 6 {
+		return nil, ErrInvalidHash
+	}
+	version, ok := parseParam(fields[2], "v", 32)
+	if !ok {
+		return nil, ErrInvalidHash
+	}
+	switch {
+	case version > Version:
+		return nil, HashVersionTooNewError(version)
+	case version < Version:
+		return nil, HashVersionTooOldError(version)
+	}
+	p.version = uint32(version)
+
+	costs := bytes.Split(fields[3], []byte(","))
+	if len(costs) != 3 {
+		return nil, ErrInvalidHash
+	}
+	m, okM := parseParam(costs[0], "m", 32)
+	t, okT := parseParam(costs[1], "t", 32)
+	threads, okP := parseParam(costs[2], "p", 8)
+	if !okM || !okT || !okP {
+		return nil, ErrInvalidHash
+	}
+	p.params.Memory, p.params.Time, p.params.Threads = uint32(m), uint32(t), uint8(threads)
+
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(string(fields[4])); err != nil {
+		return nil, ErrInvalidHash
+	}
+	if p.hash, err = base64.RawStdEncoding.DecodeString(string(fields[5])); err != nil {
+		return nil, ErrInvalidHash
+	}
+	p.params.SaltLen, p.params.KeyLen = uint32(len(p.salt)), uint32(len(p.hash))
+	if err := p.params.check(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// parseParam parses a "name=value" field holding a decimal integer of at
+// most bitSize bits.
+func parseParam(field []byte, name string, bitSize int) (uint64, bool) {
+	if !bytes.HasPrefix(field, []byte(name+"=")) {
+		return 0, false
+	}
+	digits := string(field[len(name)+1:])
+	// Reject the signs and leading zeros accepted by strconv.
+	if len(digits) == 0 || digits[0] < '0' || digits[0] > '9' || (digits[0] == '0' && len(digits) > 1) {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(digits, 10, bitSize)
+	return v, err == nil
+}
+
+func appendBase64(b, src []byte) []byte {
+	n := len(b)
+	b = append(b, make([]byte, base64.RawStdEncoding.EncodedLen(len(src)))...)
+	base64.RawStdEncoding.Encode(b[n:], src)
+	return b
+}
@@ -8,6 +8,7 @@ package bcrypt // import "golang.org/x/github.com/benchlab/bench-crypto/bcrypt"
 
 // The code is a port of Provos and Mazières's C implementation.
 import (
+	"bytes"
 	"github.com/benchlab/bench-crypto/rand"
 	"github.com/benchlab/bench-crypto/subtle"
 	"errors"
@@ -80,6 +81,8 @@ type hashed struct {
 	cost  int // allowed range is MinCost to MaxCost
 	major byte
 	minor byte
+
+	prehashed bool // the password was hashed with HMAC-SHA-384 first
 }
 
 // GenerateFromPassword returns the bcrypt hash of the password at the given
@@ -96,18 +99,30 @@ func GenerateFromPassword(password []byte, cost int) ([]byte, error) {
 
 // CompareHashAndPassword compares a bcrypt hashed password with its possible
 // plaintext equivalent. Returns nil on success, or an error on failure.
+//
+// Hashes of the $2a$, $2b$ and $2y$ versions are computed identically. Hashes
+// of the $2x$ version, produced by PHP and crypt_blowfish for hashes created
+// before the sign extension bug of crypt_blowfish was fixed, are verified
+// with that bug. Pre-hashed hashes, see GenerateFromPasswordWithOptions, are
+// recognized by their prefix.
 func CompareHashAndPassword(hashedPassword, password []byte) error {
 	p, err := newFromHash(hashedPassword)
 	if err != nil {
 		return err
 	}
 
+	if p.prehashed {
+		password = prehash(password, p.salt)
+	}
+	if p.minor == 'x' {
+		password = signExtendedKey(password)
+	}
 	otherHash, err := bcrypt(password, p.cost, p.salt)
 	if err != nil {
 		return err
 	}
 
-	otherP := &hashed{otherHash, p.salt, p.cost, p.major, p.minor}
+	otherP := &hashed{otherHash, p.salt, p.cost, p.major, p.minor, p.prehashed}
 	if subtle.ConstantTimeCompare(p.Hash(), otherP.Hash()) == 1 {
 		return nil
 	}
@@ -128,6 +143,10 @@ func Cost(hashedPassword []byte) (int, error) {
 }
 
 func newFromPassword(password []byte, cost int) (*hashed, error) {
+	return generate(password, cost, false)
+}
+
+func generate(password []byte, cost int, prehashed bool) (*hashed, error) {
 	if cost < MinCost {
 		cost = DefaultCost
 	}
@@ -148,6 +167,10 @@ func newFromPassword(password []byte, cost int) (*hashed, error) {
 	}
 
 	p.salt = base64Encode(unencodedSalt)
+	if prehashed {
+		p.prehashed = true
+		password = prehash(password, p.salt)
+	}
 	hash, err := bcrypt(password, p.cost, p.salt)
 	if err != nil {
 		return nil, err
@@ -157,10 +180,14 @@ func newFromPassword(password []byte, cost int) (*hashed, error) {
 }
 
 func newFromHash(hashedSecret []byte) (*hashed, error) {
+	p := new(hashed)
+	if bytes.HasPrefix(hashedSecret, []byte(prehashPrefix+"$")) {
+		p.prehashed = true
+		hashedSecret = hashedSecret[len(prehashPrefix):]
+	}
 	if len(hashedSecret) < minHashSize {
 		return nil, ErrHashTooShort
 	}
-	p := new(hashed)
 	n, err := p.decodeVersion(hashedSecret)
 	if err != nil {
 		return nil, err
@@ -250,6 +277,9 @@ func (p *hashed) Hash() []byte {
 	n += encodedSaltSize
 	copy(arr[n:], p.hash)
 	n += encodedHashSize
+	if p.prehashed {
+		return append([]byte(prehashPrefix), arr[:n]...)
+	}
 	return arr[:n]
 }
 
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestBcryptingIsEasy(t *testing.T) {
@@ -241,3 +242,87 @@ func TestNoSideEffectsFromCompare(t *testing.T) {
 		t.Errorf("got=%q want=%q", got, want)
 	}
 }
+
+func TestLongPasswordPolicies(t *testing.T) {
+	long := bytes.Repeat([]byte("0123456789"), 8)
+	other := append(long[:MaxPasswordLength:MaxPasswordLength], "different suffix"...)
+
+	if _, err := GenerateFromPasswordWithOptions(long, &Options{Cost: MinCost, LongPasswords: RejectLongPasswords}); err != ErrPasswordTooLong {
+		t.Errorf("RejectLongPasswords: got %v, want %v", err, ErrPasswordTooLong)
+	}
+	if _, err := GenerateFromPasswordWithOptions(long[:MaxPasswordLength], &Options{Cost: MinCost, LongPasswords: RejectLongPasswords}); err != nil {
+		t.Errorf("RejectLongPasswords: %d bytes password rejected: %v", MaxPasswordLength, err)
+	}
+
+	hash, err := GenerateFromPasswordWithOptions(long, &Options{Cost: MinCost})
+	if err != nil {
+		t.Fatalf("TruncateLongPasswords: %v", err)
+	}
+	if err := CompareHashAndPassword(hash, other); err != nil {
+		t.Errorf("TruncateLongPasswords: password with the same prefix should match: %v", err)
+	}
+
+	hash, err = GenerateFromPasswordWithOptions(long, &Options{Cost: MinCost, LongPasswords: PrehashPasswords})
+	if err != nil {
+		t.Fatalf("PrehashPasswords: %v", err)
+	}
+	if !bytes.HasPrefix(hash, []byte("$bcrypt-sha384$2a$04$")) {
+		t.Errorf("PrehashPasswords: unexpected encoding %q", hash)
+	}
+	if err := CompareHashAndPassword(hash, long); err != nil {
+		t.Errorf("PrehashPasswords: %v", err)
+	}
+	if err := CompareHashAndPassword(hash, other); err != ErrMismatchedHashAndPassword {
+		t.Errorf("PrehashPasswords: password with the same prefix: got %v, want %v", err, ErrMismatchedHashAndPassword)
+	}
+	if err := CompareHashAndPassword(hash[len(prehashPrefix):], long); err != ErrMismatchedHashAndPassword {
+		t.Errorf("PrehashPasswords: hash without prefix: got %v, want %v", err, ErrMismatchedHashAndPassword)
+	}
+	if cost, err := Cost(hash); err != nil || cost != MinCost {
+		t.Errorf("PrehashPasswords: Cost returned %d, %v", cost, err)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	// Test vectors of crypt_blowfish.
+	for _, v := range []struct {
+		hash, password string
+	}{
+		{"$2x$05$/OK.fbVrR/bpIqNJ5ianF.CE5elHaaO4EbggVDjb8P19RukzXSM3e", "\xa3"},
+		{"$2x$05$/OK.fbVrR/bpIqNJ5ianF.CE5elHaaO4EbggVDjb8P19RukzXSM3e", "\xff\xff\xa3"},
+		{"$2y$05$/OK.fbVrR/bpIqNJ5ianF.CE5elHaaO4EbggVDjb8P19RukzXSM3e", "\xff\xff\xa3"},
+		{"$2b$05$/OK.fbVrR/bpIqNJ5ianF.CE5elHaaO4EbggVDjb8P19RukzXSM3e", "\xff\xff\xa3"},
+		{"$2y$05$/OK.fbVrR/bpIqNJ5ianF.Sa7shbm4.OzKpvFnX1pQLmQW96oUlCq", "\xa3"},
+		{"$2x$05$/OK.fbVrR/bpIqNJ5ianF.o./n25XVfn6oAPaUvHe.Csk4zRfsYPi", "1\xa3345"},
+		{"$2x$05$/OK.fbVrR/bpIqNJ5ianF.o./n25XVfn6oAPaUvHe.Csk4zRfsYPi", "\xff\xa3345"},
+		{"$2x$05$/OK.fbVrR/bpIqNJ5ianF.o./n25XVfn6oAPaUvHe.Csk4zRfsYPi", "\xff\xa334\xff\xff\xff\xa3345"},
+		{"$2y$05$/OK.fbVrR/bpIqNJ5ianF.o./n25XVfn6oAPaUvHe.Csk4zRfsYPi", "\xff\xa334\xff\xff\xff\xa3345"},
+		{"$2y$05$/OK.fbVrR/bpIqNJ5ianF.nRht2l/HRhr6zmCp9vYUvvsqynflf9e", "\xff\xa3345"},
+		{"$2x$05$/OK.fbVrR/bpIqNJ5ianF.6IflQkJytoRVc1yuaNtHfiuq.FRlSIS", "\xa3ab"},
+		{"$2y$05$/OK.fbVrR/bpIqNJ5ianF.6IflQkJytoRVc1yuaNtHfiuq.FRlSIS", "\xa3ab"},
+		{"$2x$05$6bNw2HLQYeqHYyBfLMsv/OiwqTymGIGzFsA4hOTWebfehXHNprcAS", "\xd1\x91"},
+		{"$2x$05$6bNw2HLQYeqHYyBfLMsv/O9LIGgn8OMzuDoHfof8AQimSGfcSWxnS", "\xd0\xc1\xd2\xcf\xcc\xd8"},
+	} {
+		if err := CompareHashAndPassword([]byte(v.hash), []byte(v.password)); err != nil {
+			t.Errorf("%s with %q: %v", v.hash, v.password, err)
+		}
+	}
+
+	// The sign extension bug of $2x$ changes the hash of non-ASCII
+	// passwords.
+	if err := CompareHashAndPassword([]byte("$2x$05$/OK.fbVrR/bpIqNJ5ianF.Sa7shbm4.OzKpvFnX1pQLmQW96oUlCq"), []byte("\xa3")); err != ErrMismatchedHashAndPassword {
+		t.Errorf("$2x$ hash verified without the bug: %v", err)
+	}
+}
+
+func TestCalibrateCost(t *testing.T) {
+	if cost := CalibrateCost(0); cost != MinCost {
+		t.Errorf("CalibrateCost(0) = %d, want %d", cost, MinCost)
+	}
+	if testing.Short() {
+		t.Skip("skipping calibration in short mode")
+	}
+	if cost := CalibrateCost(50 * time.Millisecond); cost < MinCost || cost > MaxCost {
+		t.Errorf("CalibrateCost(50ms) = %d", cost)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bcrypt
+
+import (
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/benchlab/bench-crypto/hmac"
+	"github.com/benchlab/bench-crypto/sha512"
+)
+
+// MaxPasswordLength is the number of password bytes used by bcrypt. Any
+// further bytes are ignored, unless the password is pre-hashed.
+const MaxPasswordLength = 72
+
+// The error returned from GenerateFromPasswordWithOptions when a password is
+// longer than MaxPasswordLength and the policy is RejectLongPasswords.
+var ErrPasswordTooLong = errors.New("github.com/benchlab/bench-crypto/bcrypt: password length exceeds 72 bytes")
+
+// LongPasswordPolicy decides how GenerateFromPasswordWithOptions handles
+// passwords longer than MaxPasswordLength.
+type LongPasswordPolicy int
+
+const (
+	// TruncateLongPasswords ignores the bytes after MaxPasswordLength, as
+	// GenerateFromPassword does. Passwords which only differ after that
+	// length have the same hash.
+	TruncateLongPasswords LongPasswordPolicy = iota
+	// RejectLongPasswords returns ErrPasswordTooLong.
+	RejectLongPasswords
+	// PrehashPasswords hashes every password, whatever its length, with
+	// HMAC-SHA-384 keyed by the salt, and passes the base64 encoding of the
+	// result to bcrypt. The resulting hashes start with "$bcrypt-sha384$"
+	// instead of "$", and are only understood by CompareHashAndPassword and
+	// implementations of the same scheme.
+	PrehashPasswords
+)
+
+// prehashPrefix precedes the usual encoding of pre-hashed hashes, such as
+// "$bcrypt-sha384$2a$10$...".
+const prehashPrefix = "$bcrypt-sha384"
+
+// Options holds the parameters of GenerateFromPasswordWithOptions.
+type Options struct {
+	// Cost is the hashing cost. If it is less than MinCost, DefaultCost is
+	// used instead.
+	Cost int
+	// LongPasswords is the handling of passwords longer than
+	// MaxPasswordLength.
+	LongPasswords LongPasswordPolicy
+}
+
+// GenerateFromPasswordWithOptions returns the bcrypt hash of the password
+// with the given options. Use CompareHashAndPassword, as defined in this
+// package, to compare the returned hashed password with its cleartext
+// version.
+func GenerateFromPasswordWithOptions(password []byte, opts *Options) ([]byte, error) {
+	switch opts.LongPasswords {
+	case TruncateLongPasswords, PrehashPasswords:
+	case RejectLongPasswords:
+		if len(password) > MaxPasswordLength {
+			return nil, ErrPasswordTooLong
+		}
+	default:
+		return nil, errors.New("github.com/benchlab/bench-crypto/bcrypt: invalid long password policy")
+	}
+	p, err := generate(password, opts.Cost, opts.LongPasswords == PrehashPasswords)
+	if err != nil {
+		return nil, err
+	}
+	return p.Hash(), nil
+}
+
+// prehash returns the base64-encoded HMAC-SHA-384 of the password keyed by
+// the encoded salt. It is 64 bytes long and free of NUL bytes, so bcrypt
+// uses all of it.
+func prehash(password, salt []byte) []byte {
+	mac := hmac.New(sha512.New384, salt)
+	mac.Write(password)
+	sum := mac.Sum(nil)
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(sum)))
+	base64.StdEncoding.Encode(encoded, sum)
+	return encoded
+}
+
+// signExtendedKey returns the key bytes that the correct Blowfish key
+// schedule reads from the returned slice in the same order as the key
+// schedule of $2x$ hashes reads the words of the key. Those hashes were
+// created by crypt_blowfish before version 1.1, which sign extended the
+// bytes of the key while shifting them into 32-bit words, so that every
+// byte with its high bit set overwrote the preceding bytes of its word with
+// 0xff.
+func signExtendedKey(password []byte) []byte {
+	// The key schedule cycles through the password and a trailing NUL
+	// byte, reading 18 words.
+	key := append(password[:len(password):len(password)], 0)
+	out := make([]byte, 18*4)
+	for i := range out {
+		out[i] = key[i%len(key)]
+	}
+	for w := 0; w < len(out); w += 4 {
+		for j := 1; j < 4; j++ {
+			if out[w+j]&0x80 != 0 {
+				for k := 0; k < j; k++ {
+					out[w+k] = 0xff
+				}
+			}
+		}
+	}
+	return out
+}
+
+// CalibrateCost returns the highest cost at which hashing a password on this
+// machine takes at most target, or MinCost if even that takes longer. Since
+// every cost increment doubles the hashing time, it runs for up to about twice
+// the target duration.
+func CalibrateCost(target time.Duration) int {
+	password := []byte("bcrypt cost calibration")
+	salt := base64Encode(make([]byte, maxSaltSize))
+	cost := MinCost
+	for cost < MaxCost {
+		start := time.Now()
+		if _, err := bcrypt(password, cost+1, salt); err != nil {
+			break
+		}
+		if time.Since(start) > target {
+			break
+		}
+		cost++
+	}
+	return cost
+}
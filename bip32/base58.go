@@ -0,0 +1,96 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bip32
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/benchlab/bench-crypto/sha256"
+)
+
+// base58Alphabet is the Bitcoin Base58 alphabet, which omits 0, O, I and l.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58CheckEncode returns the Base58 encoding of b followed by the first
+// four bytes of its double SHA-256 hash.
+func base58CheckEncode(b []byte) string {
+	sum := checksum(b)
+	b = append(append([]byte{}, b...), sum[:]...)
+
+	// Repeatedly divide the big-endian number in b by 58. Leading zero
+	// bytes are encoded as leading '1' characters.
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+	var digits []byte
+	for start := zeros; start < len(b); {
+		rem := 0
+		for i := start; i < len(b); i++ {
+			acc := rem<<8 | int(b[i])
+			b[i] = byte(acc / 58)
+			rem = acc % 58
+		}
+		digits = append(digits, base58Alphabet[rem])
+		for start < len(b) && b[start] == 0 {
+			start++
+		}
+	}
+
+	out := make([]byte, 0, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		out = append(out, '1')
+	}
+	for i := len(digits) - 1; i >= 0; i-- {
+		out = append(out, digits[i])
+	}
+	return string(out)
+}
+
+// base58CheckDecode decodes s and verifies and strips its four-byte checksum.
+func base58CheckDecode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	// b holds the big-endian value of the remaining digits.
+	var b []byte
+	for i := zeros; i < len(s); i++ {
+		d := strings.IndexByte(base58Alphabet, s[i])
+		if d < 0 {
+			return nil, errors.New("bip32: invalid Base58 character")
+		}
+		carry := d
+		for j := len(b) - 1; j >= 0; j-- {
+			carry += int(b[j]) * 58
+			b[j] = byte(carry)
+			carry >>= 8
+		}
+		for ; carry > 0; carry >>= 8 {
+			b = append([]byte{byte(carry)}, b...)
+		}
+	}
+	b = append(make([]byte, zeros), b...)
+
+	if len(b) < 4 {
+		return nil, errors.New("bip32: Base58 string too short")
+	}
+	payload, sum := b[:len(b)-4], b[len(b)-4:]
+	want := checksum(payload)
+	if !bytes.Equal(sum, want[:]) {
+		return nil, errors.New("bip32: invalid Base58 checksum")
+	}
+	return payload, nil
+}
+
+func checksum(b []byte) [4]byte {
+	h := sha256.Sum256(b)
+	h = sha256.Sum256(h[:])
+	var sum [4]byte
+	copy(sum[:], h[:4])
+	return sum
+}
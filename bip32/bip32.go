@@ -0,0 +1,275 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bip32 implements hierarchical deterministic key derivation, as
+// specified by Bitcoin's BIP-32 for secp256k1 and by SLIP-10 for Ed25519.
+//
+// A master key is derived from a seed, usually produced by the bip39 package,
+// and child keys are derived from it along a path such as m/44'/0'/0'/0/0.
+// Extended secp256k1 keys can be serialized in the xprv and xpub formats.
+package bip32 // import "golang.org/x/github.com/benchlab/bench-crypto/bip32"
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/benchlab/bench-crypto/hmac"
+	"github.com/benchlab/bench-crypto/sha256"
+	"github.com/benchlab/bench-crypto/sha512"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/ripemd160"
+	"golang.org/x/github.com/benchlab/bench-crypto/secp256k1"
+)
+
+const (
+	// HardenedOffset is added to a child index to select hardened
+	// derivation, written with a ' suffix in paths.
+	HardenedOffset uint32 = 0x80000000
+
+	// MinSeedSize and MaxSeedSize are the bounds, in bytes, on the length
+	// of master key seeds.
+	MinSeedSize = 16
+	MaxSeedSize = 64
+
+	// serializedKeySize is the length of an extended key before Base58Check
+	// encoding.
+	serializedKeySize = 78
+)
+
+// ErrInvalidChild is returned when a derived key is invalid, which happens
+// with probability lower than 2^-127. BIP-32 specifies that the next index
+// should be used instead.
+var ErrInvalidChild = errors.New("bip32: derived key is invalid")
+
+// Version bytes of serialized extended keys.
+var (
+	mainnetPrivate = [4]byte{0x04, 0x88, 0xad, 0xe4} // xprv
+	mainnetPublic  = [4]byte{0x04, 0x88, 0xb2, 0x1e} // xpub
+	testnetPrivate = [4]byte{0x04, 0x35, 0x83, 0x94} // tprv
+	testnetPublic  = [4]byte{0x04, 0x35, 0x87, 0xcf} // tpub
+)
+
+// ExtendedKey is a BIP-32 extended secp256k1 key, either private or public.
+type ExtendedKey struct {
+	version           [4]byte
+	depth             byte
+	parentFingerprint [4]byte
+	childNumber       uint32
+	chainCode         [32]byte
+
+	priv *secp256k1.PrivateKey // nil for public keys
+	pub  *secp256k1.PublicKey
+}
+
+// NewMasterKey returns the master private key derived from seed, which must
+// be between MinSeedSize and MaxSeedSize bytes long. Serializing the key
+// produces the mainnet xprv format.
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < MinSeedSize || len(seed) > MaxSeedSize {
+		return nil, errors.New("bip32: bad seed length")
+	}
+	il, ir := hmacSHA512([]byte("Bitcoin seed"), seed)
+	priv, err := secp256k1.NewPrivateKey(il)
+	if err != nil {
+		return nil, errors.New("bip32: seed results in an invalid master key")
+	}
+	k := &ExtendedKey{version: mainnetPrivate, priv: priv, pub: priv.PublicKey()}
+	copy(k.chainCode[:], ir)
+	return k, nil
+}
+
+// hmacSHA512 returns the two halves of HMAC-SHA512(key, data...).
+func hmacSHA512(key []byte, data ...[]byte) (il, ir []byte) {
+	m := hmac.New(sha512.New, key)
+	for _, d := range data {
+		m.Write(d)
+	}
+	sum := m.Sum(nil)
+	return sum[:32], sum[32:]
+}
+
+// hash160 returns RIPEMD-160(SHA-256(b)).
+func hash160(b []byte) []byte {
+	h := sha256.Sum256(b)
+	r := ripemd160.New()
+	r.Write(h[:])
+	return r.Sum(nil)
+}
+
+// Child returns the child key with index i, which is hardened if i is at
+// least HardenedOffset. Public keys only have non-hardened children. If the
+// derived key is invalid, Child returns ErrInvalidChild.
+func (k *ExtendedKey) Child(i uint32) (*ExtendedKey, error) {
+	if k.depth == 255 {
+		return nil, errors.New("bip32: maximum depth exceeded")
+	}
+
+	var index [4]byte
+	binary.BigEndian.PutUint32(index[:], i)
+	var il, ir []byte
+	switch {
+	case i >= HardenedOffset && k.priv == nil:
+		return nil, errors.New("bip32: cannot derive a hardened child of a public key")
+	case i >= HardenedOffset:
+		il, ir = hmacSHA512(k.chainCode[:], []byte{0}, k.priv.Bytes(), index[:])
+	default:
+		il, ir = hmacSHA512(k.chainCode[:], k.pub.SerializeCompressed(), index[:])
+	}
+
+	child := &ExtendedKey{
+		version:     k.version,
+		depth:       k.depth + 1,
+		childNumber: i,
+	}
+	copy(child.parentFingerprint[:], k.Fingerprint())
+	copy(child.chainCode[:], ir)
+
+	var err error
+	if k.priv != nil {
+		child.priv, err = k.priv.TweakAdd(il)
+		if err == nil {
+			child.pub = child.priv.PublicKey()
+		}
+	} else {
+		child.pub, err = k.pub.TweakAdd(il)
+	}
+	if err != nil {
+		return nil, ErrInvalidChild
+	}
+	return child, nil
+}
+
+// DerivePath returns the descendant of k at path, a sequence of child
+// indexes relative to k in the format accepted by ParsePath.
+func (k *ExtendedKey) DerivePath(path string) (*ExtendedKey, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indexes {
+		if k, err = k.Child(i); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// Public returns the extended public key corresponding to k, which can
+// derive the public keys of all non-hardened descendants of k.
+func (k *ExtendedKey) Public() *ExtendedKey {
+	pub := *k
+	pub.priv = nil
+	switch k.version {
+	case mainnetPrivate:
+		pub.version = mainnetPublic
+	case testnetPrivate:
+		pub.version = testnetPublic
+	}
+	return &pub
+}
+
+// IsPrivate reports whether k is an extended private key.
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.priv != nil
+}
+
+// PrivateKey returns the private key of k, or nil if k is a public key.
+func (k *ExtendedKey) PrivateKey() *secp256k1.PrivateKey {
+	return k.priv
+}
+
+// PublicKey returns the public key of k.
+func (k *ExtendedKey) PublicKey() *secp256k1.PublicKey {
+	return k.pub
+}
+
+// ChainCode returns the 32-byte chain code of k.
+func (k *ExtendedKey) ChainCode() []byte {
+	return append([]byte{}, k.chainCode[:]...)
+}
+
+// Depth returns the number of derivation steps from the master key to k.
+func (k *ExtendedKey) Depth() int {
+	return int(k.depth)
+}
+
+// ChildNumber returns the index k was derived with, or zero for a master key.
+func (k *ExtendedKey) ChildNumber() uint32 {
+	return k.childNumber
+}
+
+// Fingerprint returns the 4-byte identifier of k, the first bytes of the
+// HASH160 of its compressed public key.
+func (k *ExtendedKey) Fingerprint() []byte {
+	return hash160(k.pub.SerializeCompressed())[:4]
+}
+
+// ParentFingerprint returns the fingerprint of the parent of k, or zero for a
+// master key.
+func (k *ExtendedKey) ParentFingerprint() []byte {
+	return append([]byte{}, k.parentFingerprint[:]...)
+}
+
+// String returns the Base58Check serialization of k, starting with "xprv"
+// or "xpub" for mainnet keys and "tprv" or "tpub" for testnet keys.
+func (k *ExtendedKey) String() string {
+	b := make([]byte, 0, serializedKeySize)
+	b = append(b, k.version[:]...)
+	b = append(b, k.depth)
+	b = append(b, k.parentFingerprint[:]...)
+	b = append(b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(b)-4:], k.childNumber)
+	b = append(b, k.chainCode[:]...)
+	if k.priv != nil {
+		b = append(b, 0)
+		b = append(b, k.priv.Bytes()...)
+	} else {
+		b = append(b, k.pub.SerializeCompressed()...)
+	}
+	return base58CheckEncode(b)
+}
+
+// ParseExtendedKey parses a mainnet or testnet extended key serialized with
+// String. It returns an error if the checksum is wrong or the key is invalid.
+func ParseExtendedKey(s string) (*ExtendedKey, error) {
+	b, err := base58CheckDecode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != serializedKeySize {
+		return nil, errors.New("bip32: bad extended key length")
+	}
+
+	k := new(ExtendedKey)
+	copy(k.version[:], b[0:4])
+	k.depth = b[4]
+	copy(k.parentFingerprint[:], b[5:9])
+	k.childNumber = binary.BigEndian.Uint32(b[9:13])
+	copy(k.chainCode[:], b[13:45])
+	if k.depth == 0 && (k.parentFingerprint != [4]byte{} || k.childNumber != 0) {
+		return nil, errors.New("bip32: invalid master key")
+	}
+
+	key := b[45:]
+	switch k.version {
+	case mainnetPrivate, testnetPrivate:
+		if key[0] != 0 {
+			return nil, errors.New("bip32: invalid private key")
+		}
+		if k.priv, err = secp256k1.NewPrivateKey(key[1:]); err != nil {
+			return nil, err
+		}
+		k.pub = k.priv.PublicKey()
+	case mainnetPublic, testnetPublic:
+		if key[0] != 2 && key[0] != 3 {
+			return nil, errors.New("bip32: invalid public key")
+		}
+		if k.pub, err = secp256k1.ParsePublicKey(key); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("bip32: unknown extended key version")
+	}
+	return k, nil
+}
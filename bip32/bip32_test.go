@@ -0,0 +1,285 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bip32
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+type bip32TestKey struct {
+	path, xprv, xpub string
+}
+
+// Test vectors 1 to 3 from BIP-32.
+var bip32Tests = []struct {
+	seed string
+	keys []bip32TestKey
+}{
+	{
+		seed: "000102030405060708090a0b0c0d0e0f",
+		keys: []bip32TestKey{
+			{
+				path: "m",
+				xprv: "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+				xpub: "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+			},
+			{
+				path: "m/0'",
+				xprv: "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+				xpub: "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+			},
+			{
+				path: "m/0'/1",
+				xprv: "xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs",
+				xpub: "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+			},
+			{
+				path: "m/0'/1/2'",
+				xprv: "xprv9z4pot5VBttmtdRTWfWQmoH1taj2axGVzFqSb8C9xaxKymcFzXBDptWmT7FwuEzG3ryjH4ktypQSAewRiNMjANTtpgP4mLTj34bhnZX7UiM",
+				xpub: "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+			},
+			{
+				path: "m/0'/1/2'/2",
+				xprv: "xprvA2JDeKCSNNZky6uBCviVfJSKyQ1mDYahRjijr5idH2WwLsEd4Hsb2Tyh8RfQMuPh7f7RtyzTtdrbdqqsunu5Mm3wDvUAKRHSC34sJ7in334",
+				xpub: "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+			},
+			{
+				path: "m/0'/1/2'/2/1000000000",
+				xprv: "xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76",
+				xpub: "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+			},
+		},
+	},
+	{
+		seed: "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		keys: []bip32TestKey{
+			{
+				path: "m",
+				xprv: "xprv9s21ZrQH143K31xYSDQpPDxsXRTUcvj2iNHm5NUtrGiGG5e2DtALGdso3pGz6ssrdK4PFmM8NSpSBHNqPqm55Qn3LqFtT2emdEXVYsCzC2U",
+				xpub: "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB",
+			},
+			{
+				path: "m/0",
+				xprv: "xprv9vHkqa6EV4sPZHYqZznhT2NPtPCjKuDKGY38FBWLvgaDx45zo9WQRUT3dKYnjwih2yJD9mkrocEZXo1ex8G81dwSM1fwqWpWkeS3v86pgKt",
+				xpub: "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH",
+			},
+			{
+				path: "m/0/2147483647'",
+				xprv: "xprv9wSp6B7kry3Vj9m1zSnLvN3xH8RdsPP1Mh7fAaR7aRLcQMKTR2vidYEeEg2mUCTAwCd6vnxVrcjfy2kRgVsFawNzmjuHc2YmYRmagcEPdU9",
+				xpub: "xpub6ASAVgeehLbnwdqV6UKMHVzgqAG8Gr6riv3Fxxpj8ksbH9ebxaEyBLZ85ySDhKiLDBrQSARLq1uNRts8RuJiHjaDMBU4Zn9h8LZNnBC5y4a",
+			},
+			{
+				path: "m/0/2147483647'/1",
+				xprv: "xprv9zFnWC6h2cLgpmSA46vutJzBcfJ8yaJGg8cX1e5StJh45BBciYTRXSd25UEPVuesF9yog62tGAQtHjXajPPdbRCHuWS6T8XA2ECKADdw4Ef",
+				xpub: "xpub6DF8uhdarytz3FWdA8TvFSvvAh8dP3283MY7p2V4SeE2wyWmG5mg5EwVvmdMVCQcoNJxGoWaU9DCWh89LojfZ537wTfunKau47EL2dhHKon",
+			},
+			{
+				path: "m/0/2147483647'/1/2147483646'",
+				xprv: "xprvA1RpRA33e1JQ7ifknakTFpgNXPmW2YvmhqLQYMmrj4xJXXWYpDPS3xz7iAxn8L39njGVyuoseXzU6rcxFLJ8HFsTjSyQbLYnMpCqE2VbFWc",
+				xpub: "xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL",
+			},
+			{
+				path: "m/0/2147483647'/1/2147483646'/2",
+				xprv: "xprvA2nrNbFZABcdryreWet9Ea4LvTJcGsqrMzxHx98MMrotbir7yrKCEXw7nadnHM8Dq38EGfSh6dqA9QWTyefMLEcBYJUuekgW4BYPJcr9E7j",
+				xpub: "xpub6FnCn6nSzZAw5Tw7cgR9bi15UV96gLZhjDstkXXxvCLsUXBGXPdSnLFbdpq8p9HmGsApME5hQTZ3emM2rnY5agb9rXpVGyy3bdW6EEgAtqt",
+			},
+		},
+	},
+	{
+		seed: "4b381541583be4423346c643850da4b320e46a87ae3d2a4e6da11eba819cd4acba45d239319ac14f863b8d5ab5a0d0c64d2e8a1e7d1457df2e5a3c51c73235be",
+		keys: []bip32TestKey{
+			{
+				path: "m",
+				xprv: "xprv9s21ZrQH143K25QhxbucbDDuQ4naNntJRi4KUfWT7xo4EKsHt2QJDu7KXp1A3u7Bi1j8ph3EGsZ9Xvz9dGuVrtHHs7pXeTzjuxBrCmmhgC6",
+				xpub: "xpub661MyMwAqRbcEZVB4dScxMAdx6d4nFc9nvyvH3v4gJL378CSRZiYmhRoP7mBy6gSPSCYk6SzXPTf3ND1cZAceL7SfJ1Z3GC8vBgp2epUt13",
+			},
+			{
+				path: "m/0'",
+				xprv: "xprv9uPDJpEQgRQfDcW7BkF7eTya6RPxXeJCqCJGHuCJ4GiRVLzkTXBAJMu2qaMWPrS7AANYqdq6vcBcBUdJCVVFceUvJFjaPdGZ2y9WACViL4L",
+				xpub: "xpub68NZiKmJWnxxS6aaHmn81bvJeTESw724CRDs6HbuccFQN9Ku14VQrADWgqbhhTHBaohPX4CjNLf9fq9MYo6oDaPPLPxSb7gwQN3ih19Zm4Y",
+			},
+		},
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for i, test := range bip32Tests {
+		seed, _ := hex.DecodeString(test.seed)
+		master, err := NewMasterKey(seed)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		for _, want := range test.keys {
+			k, err := master.DerivePath(want.path)
+			if err != nil {
+				t.Fatalf("#%d: %s: %v", i, want.path, err)
+			}
+			if got := k.String(); got != want.xprv {
+				t.Errorf("#%d: %s: got %s, want %s", i, want.path, got, want.xprv)
+			}
+			if got := k.Public().String(); got != want.xpub {
+				t.Errorf("#%d: %s: got %s, want %s", i, want.path, got, want.xpub)
+			}
+
+			for _, s := range []string{want.xprv, want.xpub} {
+				parsed, err := ParseExtendedKey(s)
+				if err != nil {
+					t.Errorf("#%d: ParseExtendedKey(%s): %v", i, s, err)
+					continue
+				}
+				if got := parsed.String(); got != s {
+					t.Errorf("#%d: ParseExtendedKey(%s) round trips to %s", i, s, got)
+				}
+			}
+		}
+	}
+}
+
+func TestPublicDerivation(t *testing.T) {
+	seed, _ := hex.DecodeString(bip32Tests[0].seed)
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := master.DerivePath("m/44'/0'/0'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []uint32{0, 1, 2, 1000, HardenedOffset - 1} {
+		priv, err := parent.Child(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err := parent.Public().Child(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pub.IsPrivate() || pub.PrivateKey() != nil {
+			t.Errorf("public derivation returned a private key")
+		}
+		if !reflect.DeepEqual(priv.Public(), pub) {
+			t.Errorf("child %d: public derivation returned %s, want %s", i, pub, priv.Public())
+		}
+		if !bytes.Equal(pub.ParentFingerprint(), parent.Fingerprint()) {
+			t.Errorf("child %d: wrong parent fingerprint", i)
+		}
+	}
+
+	if _, err := parent.Public().Child(HardenedOffset); err == nil {
+		t.Errorf("derived a hardened child from a public key")
+	}
+	if _, err := parent.Public().DerivePath("m/0/1'"); err == nil {
+		t.Errorf("derived a hardened path from a public key")
+	}
+}
+
+func TestPublicPublicDerivation(t *testing.T) {
+	// From the test suite of github.com/tyler-smith/go-bip32, for the
+	// m/44'/60'/0'/0 key of the root
+	// xprv9s21ZrQH143K2Cfj4mDZBcEecBmJmawReGwwoAou2zZzG45bM6cFPJSvobVTCB55L6Ld2y8RzC61CpvadeAnhws3CHsMFhNjozBKGNgucYm.
+	parent, err := ParseExtendedKey("xpub6DxSCdWu6jKqr4isjo7bsPeDD6s3J4YVQV1JSHZg12Eagdqnf7XX4fxqyW2sLhUoFWutL7tAELU2LiGZrEXtjVbvYptvTX5Eoa4Mamdjm9u")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent.IsPrivate() || parent.Depth() != 4 || parent.ChildNumber() != 0 {
+		t.Errorf("parsed key has wrong metadata")
+	}
+	for i, want := range []string{
+		"0243187e1a2ba9ba824f5f81090650c8f4faa82b7baf93060d10b81f4b705afd46",
+		"023790d11eb715c4320d8e31fba3a09b700051dc2cdbcce03f44b11c274d1e220b",
+		"0302c5749c3c75cea234878ae3f4d8f65b75d584bcd7ed0943b016d6f6b59a2bad",
+		"03f0440c94e5b14ea5b15875934597afff541bec287c6e65dc1102cafc07f69699",
+		"026419d0d8996707605508ac44c5871edc7fe206a79ef615b74f2eea09c5852e2b",
+	} {
+		child, err := parent.Child(uint32(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(child.PublicKey().SerializeCompressed()); got != want {
+			t.Errorf("child %d: got %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestParseInvalidExtendedKeys(t *testing.T) {
+	for _, s := range []string{
+		"",
+		// Truncated.
+		"xprv9s21ZrQH143K4YUcKrp6cVxQaX59ZFkN6MFdeZjt8CHVYNs55xxQSvZpHWfojWMv6zgjmzopCyWPSFAnV4RU33J4pwCcnhsB4R4mPEnTsM",
+		// Bad checksum.
+		"xprv9s21ZrQH143K3YSbAXLMPCzJso5QAarQksAGc5rQCyZCBfw4Rj2PqVLFNgezSBhktYkiL3Ta2stLPDF9yZtLMaxk6Spiqh3DNFG8p8MVeEc",
+		// Not Base58.
+		"xprv9s21ZrQH143K3YSbAXLMPCzJso5QAarQksAGc5rQCyZCBfw4Rj2PqVLFNgezSBhktYkiL3Ta2stLPDF9yZtLMaxk6Spiqh3DNFG8p8MVeE0",
+		// From the invalid extended keys of BIP-32 test vector 5.
+		"xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6LBpB85b3D2yc8sfvZU521AAwdZafEz7mnzBBsz4wKY5fTtTQBm",
+		"xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzFGTQQD3dC4H2D5GBj7vWvSQaaBv5cxi9gafk7NF3pnBju6dwKvH",
+		"xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6Txnt3siSujt9RCVYsx4qHZGc62TG4McvMGcAUjeuwZdduYEvFn",
+		"xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzFGpWnsj83BHtEy5Zt8CcDr1UiRXuWCmTQLxEK9vbz5gPstX92JQ",
+		"xprv9s2SPatNQ9Vc6GTbVMFPFo7jsaZySyzk7L8n2uqKXJen3KUmvQNTuLh3fhZMBoG3G4ZW1N2kZuHEPY53qmbZzCHshoQnNf4GvELZfqTUrcv",
+		"xpub661no6RGEX3uJkY4bNnPcw4URcQTrSibUZ4NqJEw5eBkv7ovTwgiT91XX27VbEXGENhYRCf7hyEbWrR3FewATdCEebj6znwMfQkhRYHRLpJ",
+		"xprv9s21ZrQH4r4TsiLvyLXqM9P7k1K3EYhA1kkD6xuquB5i39AU8KF42acDyL3qsDbU9NmZn6MsGSUYZEsuoePmjzsB3eFKSUEh3Gu1N3cqVUN",
+		"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHL",
+		"xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzF93Y5wvzdUayhgkkFoicQZcP3y52uPPxFnfoLZB21Teqt1VvEHx",
+		"xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzFAzHGBP2UuGCqWLTAPLcMtD9y5gkZ6Eq3Rjuahrv17fEQ3Qen6J",
+		"xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6Q5JXayek4PRsn35jii4veMimro1xefsM58PgBMrvdYre8QyULY",
+	} {
+		if _, err := ParseExtendedKey(s); err == nil {
+			t.Errorf("ParseExtendedKey accepted %s", s)
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	for _, test := range []struct {
+		path string
+		want []uint32
+	}{
+		{"m", []uint32{}},
+		{"m/0", []uint32{0}},
+		{"m/44'/0'/0'/0/0", []uint32{HardenedOffset + 44, HardenedOffset, HardenedOffset, 0, 0}},
+		{"m/44h/60H/2147483647'", []uint32{HardenedOffset + 44, HardenedOffset + 60, 1<<32 - 1}},
+	} {
+		got, err := ParsePath(test.path)
+		if err != nil {
+			t.Errorf("ParsePath(%q): %v", test.path, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParsePath(%q) = %v, want %v", test.path, got, test.want)
+		}
+	}
+
+	for _, path := range []string{
+		"", "M", "/0", "0/1", "m/", "m//0", "m/-1", "m/+1", "m/0''", "m/'", "m/a",
+		"m/2147483648", "m/4294967296'", "m/0 ",
+	} {
+		if _, err := ParsePath(path); err == nil {
+			t.Errorf("ParsePath accepted %q", path)
+		}
+	}
+}
+
+func TestSeedLength(t *testing.T) {
+	for _, n := range []int{0, MinSeedSize - 1, MaxSeedSize + 1} {
+		if _, err := NewMasterKey(make([]byte, n)); err == nil {
+			t.Errorf("NewMasterKey accepted a %d-byte seed", n)
+		}
+		if _, err := NewEd25519MasterKey(make([]byte, n)); err == nil {
+			t.Errorf("NewEd25519MasterKey accepted a %d-byte seed", n)
+		}
+	}
+}
+
+func TestBase58(t *testing.T) {
+	for _, b := range [][]byte{{}, {0}, {0, 0, 1}, {0xff, 0}, bytes.Repeat([]byte{0xa5}, 80)} {
+		got, err := base58CheckDecode(base58CheckEncode(b))
+		if err != nil {
+			t.Errorf("%x: %v", b, err)
+		} else if !bytes.Equal(got, b) {
+			t.Errorf("%x: round trip returned %x", b, got)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bip32
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ParsePath parses a derivation path such as "m/44'/0'/0'/0/0" into child
+// indexes. The path starts with "m", and each component is a decimal index
+// below HardenedOffset, followed by ', h or H for hardened derivation. The
+// path "m" alone has no components.
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, errors.New("bip32: path must start with m: " + path)
+	}
+
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		var offset uint32
+		if n := len(part); n > 0 && (part[n-1] == '\'' || part[n-1] == 'h' || part[n-1] == 'H') {
+			part = part[:n-1]
+			offset = HardenedOffset
+		}
+		i, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(i) >= HardenedOffset {
+			return nil, errors.New("bip32: invalid path component in " + path)
+		}
+		indexes = append(indexes, uint32(i)+offset)
+	}
+	return indexes, nil
+}
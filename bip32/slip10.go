@@ -0,0 +1,118 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bip32
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/ed25519"
+)
+
+// Ed25519Key is a SLIP-10 extended Ed25519 private key. Ed25519 only supports
+// hardened derivation, so there are no extended public keys.
+type Ed25519Key struct {
+	depth             byte
+	parentFingerprint [4]byte
+	childNumber       uint32
+	chainCode         [32]byte
+
+	seed [32]byte // the RFC 8032 private key
+}
+
+// NewEd25519MasterKey returns the SLIP-10 master Ed25519 key derived from
+// seed, which must be between MinSeedSize and MaxSeedSize bytes long.
+func NewEd25519MasterKey(seed []byte) (*Ed25519Key, error) {
+	if len(seed) < MinSeedSize || len(seed) > MaxSeedSize {
+		return nil, errors.New("bip32: bad seed length")
+	}
+	il, ir := hmacSHA512([]byte("ed25519 seed"), seed)
+	k := new(Ed25519Key)
+	copy(k.seed[:], il)
+	copy(k.chainCode[:], ir)
+	return k, nil
+}
+
+// Child returns the hardened child key with index i, which must be at least
+// HardenedOffset.
+func (k *Ed25519Key) Child(i uint32) (*Ed25519Key, error) {
+	if i < HardenedOffset {
+		return nil, errors.New("bip32: Ed25519 keys only have hardened children")
+	}
+	if k.depth == 255 {
+		return nil, errors.New("bip32: maximum depth exceeded")
+	}
+
+	var index [4]byte
+	binary.BigEndian.PutUint32(index[:], i)
+	il, ir := hmacSHA512(k.chainCode[:], []byte{0}, k.seed[:], index[:])
+
+	child := &Ed25519Key{
+		depth:       k.depth + 1,
+		childNumber: i,
+	}
+	copy(child.parentFingerprint[:], k.Fingerprint())
+	copy(child.chainCode[:], ir)
+	copy(child.seed[:], il)
+	return child, nil
+}
+
+// DerivePath returns the descendant of k at path, a sequence of child
+// indexes relative to k in the format accepted by ParsePath. Every component
+// must be hardened.
+func (k *Ed25519Key) DerivePath(path string) (*Ed25519Key, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indexes {
+		if k, err = k.Child(i); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// PrivateKey returns the Ed25519 private key of k.
+func (k *Ed25519Key) PrivateKey() ed25519.PrivateKey {
+	_, priv, err := ed25519.GenerateKey(bytes.NewReader(k.seed[:]))
+	if err != nil {
+		panic("bip32: internal error: " + err.Error())
+	}
+	return priv
+}
+
+// PublicKey returns the Ed25519 public key of k.
+func (k *Ed25519Key) PublicKey() ed25519.PublicKey {
+	return k.PrivateKey().Public().(ed25519.PublicKey)
+}
+
+// ChainCode returns the 32-byte chain code of k.
+func (k *Ed25519Key) ChainCode() []byte {
+	return append([]byte{}, k.chainCode[:]...)
+}
+
+// Depth returns the number of derivation steps from the master key to k.
+func (k *Ed25519Key) Depth() int {
+	return int(k.depth)
+}
+
+// ChildNumber returns the index k was derived with, or zero for a master key.
+func (k *Ed25519Key) ChildNumber() uint32 {
+	return k.childNumber
+}
+
+// Fingerprint returns the 4-byte identifier of k, the first bytes of the
+// HASH160 of its public key prefixed with a zero byte, as in SLIP-10.
+func (k *Ed25519Key) Fingerprint() []byte {
+	return hash160(append([]byte{0}, k.PublicKey()...))[:4]
+}
+
+// ParentFingerprint returns the fingerprint of the parent of k, or zero for a
+// master key.
+func (k *Ed25519Key) ParentFingerprint() []byte {
+	return append([]byte{}, k.parentFingerprint[:]...)
+}
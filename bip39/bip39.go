@@ -0,0 +1,155 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bip39 implements the mnemonic sentences of Bitcoin's BIP-39, which
+// encode wallet entropy as a sequence of words from the English wordlist, and
+// the derivation of binary seeds from them.
+//
+// BIP-39 requires mnemonics and passphrases to be in Unicode NFKD form before
+// seed derivation. This package does not normalize its inputs: English
+// mnemonics are always ASCII, but callers accepting non-ASCII passphrases must
+// normalize them first.
+package bip39 // import "golang.org/x/github.com/benchlab/bench-crypto/bip39"
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	cryptorand "github.com/benchlab/bench-crypto/rand"
+	"github.com/benchlab/bench-crypto/sha256"
+	"github.com/benchlab/bench-crypto/sha512"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/pbkdf2"
+)
+
+const (
+	// SeedSize is the size, in bytes, of seeds returned by Seed.
+	SeedSize = 64
+
+	// seedIterations is the PBKDF2 iteration count fixed by BIP-39.
+	seedIterations = 2048
+)
+
+// wordIndex maps each word of the wordlist to its 11-bit value.
+var wordIndex = make(map[string]int, len(english))
+
+func init() {
+	for i, w := range english {
+		wordIndex[w] = i
+	}
+}
+
+// IsWord reports whether word is in the BIP-39 English wordlist.
+func IsWord(word string) bool {
+	_, ok := wordIndex[word]
+	return ok
+}
+
+// NewMnemonic returns a mnemonic encoding bits of entropy read from rand.
+// bits must be a multiple of 32 between 128 and 256, resulting in 12 to 24
+// words. If rand is nil, github.com/benchlab/bench-crypto/rand.Reader will be
+// used.
+func NewMnemonic(rand io.Reader, bits int) (string, error) {
+	if err := checkEntropySize(bits); err != nil {
+		return "", err
+	}
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := io.ReadFull(rand, entropy); err != nil {
+		return "", err
+	}
+	return EntropyToMnemonic(entropy)
+}
+
+func checkEntropySize(bits int) error {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return errors.New("bip39: entropy must be 128, 160, 192, 224 or 256 bits")
+	}
+	return nil
+}
+
+// EntropyToMnemonic returns the mnemonic encoding entropy, which must be 16,
+// 20, 24, 28 or 32 bytes long.
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	if err := checkEntropySize(len(entropy) * 8); err != nil {
+		return "", err
+	}
+
+	// The entropy is followed by the first len(entropy)/4 bits of its
+	// SHA-256 hash, and the result is split into 11-bit words.
+	h := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), h[0])
+	words := make([]string, (len(entropy)*8+len(entropy)/4)/11)
+	for i := range words {
+		words[i] = english[bitsAt(data, i*11)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// bitsAt returns the big-endian 11-bit value starting at bit offset off.
+func bitsAt(data []byte, off int) int {
+	v := 0
+	for i := off; i < off+11; i++ {
+		v = v<<1 | int(data[i/8]>>(7-uint(i%8))&1)
+	}
+	return v
+}
+
+// MnemonicToEntropy returns the entropy encoded by mnemonic. It returns an
+// error if mnemonic has the wrong number of words, contains words that are not
+// in the wordlist, or has an invalid checksum.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words)%3 != 0 || len(words) < 12 || len(words) > 24 {
+		return nil, errors.New("bip39: mnemonic must have 12, 15, 18, 21 or 24 words")
+	}
+
+	// Each group of three words holds 32 bits of entropy and 1 bit of
+	// checksum.
+	entropyBytes := len(words) / 3 * 4
+	data := make([]byte, entropyBytes+1)
+	for i, w := range words {
+		v, ok := wordIndex[w]
+		if !ok {
+			return nil, errors.New("bip39: invalid word in mnemonic: " + w)
+		}
+		for j := 0; j < 11; j++ {
+			if v>>(10-uint(j))&1 == 1 {
+				off := i*11 + j
+				data[off/8] |= 1 << (7 - uint(off%8))
+			}
+		}
+	}
+
+	entropy := data[:entropyBytes]
+	h := sha256.Sum256(entropy)
+	checksumBits := uint(entropyBytes / 4)
+	mask := byte(0xff) << (8 - checksumBits)
+	if data[entropyBytes] != h[0]&mask {
+		return nil, errors.New("bip39: invalid mnemonic checksum")
+	}
+	return entropy, nil
+}
+
+// IsValid reports whether mnemonic is a valid BIP-39 mnemonic, with words
+// from the English wordlist and a correct checksum.
+func IsValid(mnemonic string) bool {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err == nil
+}
+
+// Seed returns the 64-byte seed derived from mnemonic and passphrase with
+// PBKDF2-HMAC-SHA512, as the input to BIP-32 key derivation. The passphrase
+// may be empty.
+//
+// Following BIP-39, Seed does not check that mnemonic is valid; use IsValid
+// or MnemonicToEntropy to do so. Words are joined with single spaces, so
+// surrounding and repeated whitespace is ignored.
+func Seed(mnemonic, passphrase string) []byte {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"+passphrase), seedIterations, SeedSize, sha512.New)
+}
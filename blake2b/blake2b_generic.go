@@ -25,6 +25,13 @@ var precomputed = [12][16]byte{
 }
 
 func hashChunksGeneric(h *[8]uint64, c *[2]uint64, flag uint64, chunks []byte) {
+	hashChunksGenericNode(h, c, flag, 0, chunks)
+}
+
+// hashChunksGenericNode is like hashChunksGeneric, but also takes the
+// last-node flag of tree hashing, which the assembly implementations do not
+// support. It is only used for the final chunk of a last node.
+func hashChunksGenericNode(h *[8]uint64, c *[2]uint64, flag, lastNode uint64, chunks []byte) {
 	var m [16]uint64
 	c0, c1 := c[0], c[1]
 
@@ -39,6 +46,7 @@ func hashChunksGeneric(h *[8]uint64, c *[2]uint64, flag uint64, chunks []byte) {
 		v12 ^= c0
 		v13 ^= c1
 		v14 ^= flag
+		v15 ^= lastNode
 
 		for j := range m {
 			m[j] = binary.LittleEndian.Uint64(chunks[i:])
@@ -302,6 +302,220 @@ func TestSelfTest(t *testing.T) {
 
 // Benchmarks
 
+func TestParams(t *testing.T) {
+	input := make([]byte, 100)
+	for i := range input {
+		input[i] = byte(i)
+	}
+
+	for i, v := range paramsVectors {
+		h, err := NewWithParams(&v.params)
+		if err != nil {
+			t.Fatalf("#%d: error from NewWithParams: %v", i, err)
+		}
+		h.Write(input)
+		if got := fmt.Sprintf("%x", h.Sum(nil)); got != v.sum {
+			t.Errorf("#%d: got %s, wanted %s", i, got, v.sum)
+		}
+	}
+
+	h, _ := NewWithParams(&Params{Size: Size256})
+	h.Write(input)
+	if got, want := h.Sum(nil), Sum256(input); !bytes.Equal(got, want[:]) {
+		t.Errorf("default parameters: got %x, wanted %x", got, want)
+	}
+
+	for i, p := range []Params{
+		{Size: 0},
+		{Size: Size + 1},
+		{Size: Size, Key: make([]byte, Size+1)},
+		{Size: Size, Salt: make([]byte, SaltSize+1)},
+		{Size: Size, Personal: make([]byte, PersonalSize+1)},
+		{Size: Size, Fanout: 2},
+		{Size: Size, Depth: 2, InnerLength: Size + 1},
+	} {
+		if _, err := NewWithParams(&p); err == nil {
+			t.Errorf("#%d: invalid parameters accepted", i)
+		}
+	}
+}
+
+func TestMarshalParams(t *testing.T) {
+	input := make([]byte, 300)
+	for i := range input {
+		input[i] = byte(i)
+	}
+
+	p := Params{
+		Size:        Size,
+		Salt:        []byte("salt"),
+		Personal:    []byte("personal"),
+		Fanout:      2,
+		Depth:       3,
+		LeafLength:  4096,
+		NodeOffset:  7,
+		NodeDepth:   1,
+		InnerLength: Size,
+		LastNode:    true,
+	}
+	h, err := NewWithParams(&p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Write(input[:100])
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	}
+
+	// Restore into a digest with the default parameters, which must take
+	// the parameter block and the last node flag from the state.
+	h2, _ := New(Size, nil)
+	if err := h2.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		t.Fatalf("could not unmarshal: %v", err)
+	}
+	h.Write(input[100:])
+	h2.Write(input[100:])
+	if sum, sum2 := h.Sum(nil), h2.Sum(nil); !bytes.Equal(sum, sum2) {
+		t.Errorf("results do not match; sum = %x, sum2 = %x", sum, sum2)
+	}
+
+	h.Reset()
+	h2.Reset()
+	h.Write(input)
+	h2.Write(input)
+	if sum, sum2 := h.Sum(nil), h2.Sum(nil); !bytes.Equal(sum, sum2) {
+		t.Errorf("results do not match after Reset; sum = %x, sum2 = %x", sum, sum2)
+	}
+
+	// A default digest restored from a parameterized state must be reset
+	// to the default parameters again.
+	h3, _ := New(Size, nil)
+	state, _ = h3.(encoding.BinaryMarshaler).MarshalBinary()
+	if err := h2.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		t.Fatalf("could not unmarshal: %v", err)
+	}
+	h2.Write(input)
+	h3.Write(input)
+	if sum, sum2 := h3.Sum(nil), h2.Sum(nil); !bytes.Equal(sum, sum2) {
+		t.Errorf("default state: results do not match; sum = %x, sum2 = %x", sum, sum2)
+	}
+}
+
+var paramsVectors = []struct {
+	params Params
+	sum    string
+}{
+	{
+		Params{
+			Size:        32,
+			Key:         []byte("key"),
+			Salt:        []byte("salt"),
+			Personal:    []byte("personal"),
+			Fanout:      2,
+			Depth:       3,
+			LeafLength:  4096,
+			NodeOffset:  7,
+			NodeDepth:   1,
+			InnerLength: 64,
+			LastNode:    true,
+		},
+		"4cc86a2a1a27a49d06b72190ed919f19f701ee813becce93ba227012fc98aa51",
+	},
+	{
+		Params{
+			Size:     64,
+			Salt:     []byte("0123456789abcdef"),
+			Personal: []byte("ZcashPoW"),
+		},
+		"5d3028f1ccef25ffe475beb0910140785f76256af3b76da97c6964b2ba0bf5d209f70e2867a19e04f27ff3ffef0782c13128f61cf2e64d7b32158597700de3fb",
+	},
+}
+
+func TestBP(t *testing.T) {
+	defer func(sse4, avx, avx2 bool) {
+		useSSE4, useAVX, useAVX2 = sse4, avx, avx2
+	}(useSSE4, useAVX, useAVX2)
+
+	if useAVX2 {
+		t.Log("AVX2 version")
+		testBP(t)
+		useAVX2 = false
+	}
+	if useAVX {
+		t.Log("AVX version")
+		testBP(t)
+		useAVX = false
+	}
+	if useSSE4 {
+		t.Log("SSE4 version")
+		testBP(t)
+		useSSE4 = false
+	}
+	t.Log("generic version")
+	testBP(t)
+}
+
+func testBP(t *testing.T) {
+	input := make([]byte, 1<<20)
+	for i := range input {
+		input[i] = byte(i)
+	}
+
+	for _, v := range bpVectors {
+		if got := fmt.Sprintf("%x", SumBP512(input[:v.length])); got != v.sum {
+			t.Fatalf("length %d: got %s, wanted %s", v.length, got, v.sum)
+		}
+
+		h, _ := NewBP(Size, nil)
+		for p := input[:v.length]; len(p) > 0; {
+			n := 1 + len(p)%300
+			if n > len(p) {
+				n = len(p)
+			}
+			h.Write(p[:n])
+			p = p[n:]
+		}
+		if got := fmt.Sprintf("%x", h.Sum(nil)); got != v.sum {
+			t.Fatalf("length %d (split writes): got %s, wanted %s", v.length, got, v.sum)
+		}
+	}
+
+	// The first keyed BLAKE2bp test vector of the reference implementation.
+	key := fromHex("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f")
+	h, err := NewBP(Size, key)
+	if err != nil {
+		t.Fatalf("error from NewBP: %v", err)
+	}
+	want := "9d9461073e4eb640a255357b839f394b838c6ff57c9b686a3f76107c1066728f3c9956bd785cbc3bf79dc2ab578c5a0c063b9d9c405848de1dbe821cd05c940a"
+	if got := fmt.Sprintf("%x", h.Sum(nil)); got != want {
+		t.Fatalf("keyed: got %s, wanted %s", got, want)
+	}
+}
+
+var bpVectors = []struct {
+	length int
+	sum    string
+}{
+	{0, "b5ef811a8038f70b628fa8b294daae7492b1ebe343a80eaabbf1f6ae664dd67b9d90b0120791eab81dc96985f28849f6a305186a85501b405114bfa678df9380"},
+	{1, "a139280e72757b723e6473d5be59f36e9d50fc5cd7d4585cbc09804895a36c521242fb2789f85cb9e35491f31d4a6952f9d8e097aef94fa1ca0b12525721f03d"},
+	{63, "0425caaa923b47b35045eb50829c048bc890444afeefc0afc9d1877b821e043c9c7b9d6dc33fbbdfa537c1ece311965b2fee8982bc46a2a750bfc71d79dbea04"},
+	{64, "6b9d86f15c090a00fc3d907f906c5eb79265e58b88eb64294b4cc4e2b89b1a7c5ee3127ed21b456862de6b2abda59eaacf2dcbe922ca755e40735be81d9c88a5"},
+	{65, "146a187a99e8a2d233e0eb373d437b02bfa8d6515b3ca1de48a6b6acf7437eb7e7ac3f2d19ef3bb9b833cc5761dba22d1ad060be76cdcb812d64d578e989a5a4"},
+	{127, "ea64b003a135766121cfbccbdc08dca2402926be78cea3d0a7253d9ec9e63b8acdd994559917e0e03b5e155f944d7198d99245a794ce19c9b4df4da4a3399334"},
+	{128, "05ad0f271faf7e361320518452813ff9fb9976ac378050b6eefb05f7867b577b8f14475794cff61b2bc062d346a7c65c6e0067c60a374af7940f10aa449d5fb9"},
+	{129, "b545880294afa153f8b9f49c73d952b5d1228f1a1ab5ebcb05ff79e560c030f7500fe256a40b6a0e6cb3d42acd4b98595c5b51eaec5ad69cd40f1fc16d2d5f50"},
+	{255, "3f35c45d24fcfb4acca651076c08000e279ebbff37a1333ce19fd577202dbd24b58c514e36dd9ba64af4d78eea4e2dd13bc18d798887dd971376bcae0087e17e"},
+	{256, "ef1132d866055876c15959557d79cff0539b93b26f47bf4183748921df72c3ed94b0a5e95e17a4bbc59437f34564e60d20923dd643420f5ca25b2ca7ec1ceda4"},
+	{511, "fa14897433dd69321b1933a1fe101fdd463dc15fffe3f572c0b489bb607edff8b6dd04a23871be993d64af5aaa9b76af482a2363a36c1e6daaef21d3e3ac29c6"},
+	{512, "5b3a0e990c4e8c6e5463e763a6686551a129a81ab48c49cd8dc10519dfe2d02d2a451cbba6511775b6a9cb26db88363cdd067ffb7183efe19826678b2fc9f349"},
+	{513, "cd79fbbded91823272abb7a97a5530608f0583bd5405c7765156c4d8754ddf435d6d71b84f83c6381078935e378d4bf0f752b309d1398af578e103e443b8ac55"},
+	{1000, "1ce5b8d6f6fcc89fcb6ed29f12796cc210a03f4763e528cb2c0e1b4b1255d6ae86c79332529f6368d0bcfe9d316a5f999a53af47a8f0ec4412ce19156bbafd04"},
+	{2048, "6390c1edda24c198efc734c68dafde65e6db2fd01ec6faa4bd4c142ea6e29ec10a1c8cfe0308ee6d4509d773f0a35a4665facf7cf90911978e92391a3cf1e98e"},
+	{4097, "65e1ddc72b56acc924ce51985a0a355b361e874f3328d71aa40d6fd6e22ba7a3bd93c2344bc77acea4cf4afc58dd691f500e45983eb98f716de5fd1753d90fe3"},
+	{1048576, "3b5cb9af5c942d94de7a10d6cc03f245fcdf89fa80427c04311c79282231c05d97c65f7d818ef3245256e12846b94160b0f25082ae72b3119256d8e584304d13"},
+}
+
 func benchmarkSum(b *testing.B, size int) {
 	data := make([]byte, size)
 	b.SetBytes(int64(size))
@@ -0,0 +1,160 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package blake2b
+
+import (
+	"hash"
+	"sync"
+)
+
+const (
+	// bpLeaves is the degree of parallelism of BLAKE2bp.
+	bpLeaves = 4
+	// bpStripe is the number of bytes distributed over the leaves at once.
+	bpStripe = bpLeaves * ChunkSize
+	// bpParallelThreshold is the size of writes from which the leaves are
+	// hashed on separate goroutines.
+	bpParallelThreshold = 16 * 1024
+)
+
+// SumBP512 returns the BLAKE2bp-512 checksum of the data.
+func SumBP512(data []byte) [Size]byte {
+	var sum [Size]byte
+	d, _ := newBPDigest(Size, nil)
+	d.Write(data)
+	d.Sum(sum[:0])
+	return sum
+}
+
+// NewBP returns a new hash.Hash computing the BLAKE2bp checksum with a custom
+// length. BLAKE2bp splits the input over four BLAKE2b instances, which are
+// hashed in parallel, and produces different checksums than BLAKE2b. A
+// non-nil key turns the hash into a MAC. The key must between zero and 64
+// bytes long. The hash size can be a value between 1 and 64.
+func NewBP(size int, key []byte) (hash.Hash, error) { return newBPDigest(size, key) }
+
+type bpDigest struct {
+	leaves [bpLeaves]digest
+	root   digest
+	stripe [bpStripe]byte
+	offset int
+}
+
+func newBPDigest(hashSize int, key []byte) (*bpDigest, error) {
+	p := Params{
+		Size:        hashSize,
+		Key:         key,
+		Fanout:      bpLeaves,
+		Depth:       2,
+		InnerLength: Size,
+	}
+	d := new(bpDigest)
+	for i := range d.leaves {
+		p.NodeOffset = uint64(i)
+		p.LastNode = i == bpLeaves-1
+		param, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		// The leaves produce inner hashes, but their parameter block
+		// holds the size of the final checksum.
+		d.leaves[i] = digest{
+			size:     Size,
+			keyLen:   len(key),
+			param:    param,
+			lastNode: p.LastNode,
+		}
+		copy(d.leaves[i].key[:], key)
+	}
+
+	// The root node is not keyed, but its parameter block holds the key
+	// length.
+	p.Key, p.NodeOffset, p.NodeDepth, p.LastNode = nil, 0, 1, true
+	param, _ := p.block()
+	param[1] = byte(len(key))
+	d.root = digest{size: hashSize, param: param, lastNode: true}
+
+	d.Reset()
+	return d, nil
+}
+
+func (d *bpDigest) ChunkSize() int { return ChunkSize }
+
+func (d *bpDigest) Size() int { return d.root.size }
+
+func (d *bpDigest) Reset() {
+	for i := range d.leaves {
+		d.leaves[i].Reset()
+	}
+	d.root.Reset()
+	d.offset = 0
+}
+
+func (d *bpDigest) Write(p []byte) (n int, err error) {
+	n = len(p)
+
+	if d.offset > 0 {
+		remaining := bpStripe - d.offset
+		if n < remaining {
+			d.offset += copy(d.stripe[d.offset:], p)
+			return
+		}
+		copy(d.stripe[d.offset:], p[:remaining])
+		d.hashStripes(d.stripe[:])
+		d.offset = 0
+		p = p[remaining:]
+	}
+
+	if nn := len(p) &^ (bpStripe - 1); nn > 0 {
+		d.hashStripes(p[:nn])
+		p = p[nn:]
+	}
+
+	d.offset += copy(d.stripe[:], p)
+	return
+}
+
+// hashStripes writes the i-th chunk of every stripe of p to the i-th leaf.
+func (d *bpDigest) hashStripes(p []byte) {
+	hashLeaf := func(i int) {
+		for off := i * ChunkSize; off < len(p); off += bpStripe {
+			d.leaves[i].Write(p[off : off+ChunkSize])
+		}
+	}
+	if len(p) < bpParallelThreshold {
+		for i := range d.leaves {
+			hashLeaf(i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 1; i < bpLeaves; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			hashLeaf(i)
+		}(i)
+	}
+	hashLeaf(0)
+	wg.Wait()
+}
+
+func (d *bpDigest) Sum(sum []byte) []byte {
+	leaves, root := d.leaves, d.root
+	for i := range leaves {
+		if lo := i * ChunkSize; lo < d.offset {
+			hi := lo + ChunkSize
+			if hi > d.offset {
+				hi = d.offset
+			}
+			leaves[i].Write(d.stripe[lo:hi])
+		}
+		var hash [Size]byte
+		leaves[i].finalize(&hash)
+		root.Write(hash[:])
+	}
+	return root.Sum(sum)
+}
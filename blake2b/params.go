@@ -0,0 +1,110 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package blake2b
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+const (
+	// The maximum salt size of BLAKE2b in bytes.
+	SaltSize = 16
+	// The maximum personalization size of BLAKE2b in bytes.
+	PersonalSize = 16
+)
+
+// Params holds the parameters of a BLAKE2b hash, as encoded in the
+// parameter block defined in section 2.8 of the BLAKE2 specification.
+// The zero value, except for Size, describes plain sequential hashing.
+type Params struct {
+	// Size is the hash size in bytes, between 1 and 64.
+	Size int
+	// Key turns the hash into a MAC if non-empty. It must be at most
+	// 64 bytes long.
+	Key []byte
+	// Salt and Personal are at most 16 bytes long each, and are padded
+	// with zeros if shorter.
+	Salt     []byte
+	Personal []byte
+
+	// Fanout and Depth are the maximal fanout (0 for unlimited) and depth
+	// (1 to 255) of a tree. If both are zero, they default to 1, the
+	// values of sequential hashing.
+	Fanout uint8
+	Depth  uint8
+	// LeafLength is the maximal byte length of leaves, 0 for unlimited.
+	LeafLength uint32
+	// NodeOffset and NodeDepth are the position of the node in the tree,
+	// counting depth from zero at the leaves.
+	NodeOffset uint64
+	NodeDepth  uint8
+	// InnerLength is the hash size in bytes of the inner nodes of the
+	// tree, 0 for sequential hashing.
+	InnerLength uint8
+	// LastNode marks the node as the last one of its layer of the tree.
+	LastNode bool
+}
+
+// NewWithParams returns a new hash.Hash computing the BLAKE2b checksum with
+// the given parameters, for use by protocols which need a salt, a
+// personalization string or the parameters of tree hashing. The returned
+// hash.Hash implements BinaryMarshaler and BinaryUnmarshaler unless a key is
+// set.
+func NewWithParams(p *Params) (hash.Hash, error) {
+	param, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	d := &digest{
+		size:     p.Size,
+		keyLen:   len(p.Key),
+		param:    param,
+		lastNode: p.LastNode,
+	}
+	copy(d.key[:], p.Key)
+	d.Reset()
+	return d, nil
+}
+
+// block returns the parameter block encoding p.
+func (p *Params) block() (*[Size]byte, error) {
+	if p.Size < 1 || p.Size > Size {
+		return nil, errHashSize
+	}
+	if len(p.Key) > Size {
+		return nil, errKeySize
+	}
+	if len(p.Salt) > SaltSize {
+		return nil, errors.New("blake2b: invalid salt size")
+	}
+	if len(p.Personal) > PersonalSize {
+		return nil, errors.New("blake2b: invalid personalization size")
+	}
+	if p.InnerLength > Size {
+		return nil, errors.New("blake2b: invalid inner hash size")
+	}
+	fanout, depth := p.Fanout, p.Depth
+	if fanout == 0 && depth == 0 {
+		fanout, depth = 1, 1
+	}
+	if depth == 0 {
+		return nil, errors.New("blake2b: invalid tree depth")
+	}
+
+	var b [Size]byte
+	b[0] = byte(p.Size)
+	b[1] = byte(len(p.Key))
+	b[2] = fanout
+	b[3] = depth
+	binary.LittleEndian.PutUint32(b[4:], p.LeafLength)
+	binary.LittleEndian.PutUint64(b[8:], p.NodeOffset)
+	b[16] = p.NodeDepth
+	b[17] = p.InnerLength
+	copy(b[32:], p.Salt)
+	copy(b[48:], p.Personal)
+	return &b, nil
+}
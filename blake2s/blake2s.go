@@ -14,6 +14,11 @@
 //
 // BLAKE2X is a construction to compute hash values larger than 32 bytes. It
 // can produce hash values between 0 and 65535 bytes.
+//
+// Protocols which need a salt, a personalization string or the parameters of
+// tree hashing can set them with NewWithParams. BLAKE2sp, the parallel mode of
+// BLAKE2s which hashes the input over eight BLAKE2s instances, is implemented by
+// NewSP.
 package blake2s // import "golang.org/x/github.com/benchlab/bench-crypto/blake2s"
 
 import (
@@ -120,19 +125,33 @@ type digest struct {
 
 	key    [ChunkSize]byte
 	keyLen int
+
+	// param is the parameter block of a digest created from Params, and
+	// nil for the default parameters of sequential hashing.
+	param    *[Size]byte
+	lastNode bool
 }
 
 const (
 	magic         = "b2s"
 	marshaledSize = len(magic) + 8*4 + 2*4 + 1 + ChunkSize + 1
+
+	// magicParams identifies the state of a digest created from Params,
+	// which is followed by its parameter block and last node flag.
+	magicParams         = "b2S"
+	marshaledParamsSize = marshaledSize + Size + 1
 )
 
 func (d *digest) MarshalBinary() ([]byte, error) {
 	if d.keyLen != 0 {
 		return nil, errors.New("github.com/benchlab/bench-crypto/blake2s: cannot marshal MACs")
 	}
-	b := make([]byte, 0, marshaledSize)
-	b = append(b, magic...)
+	b := make([]byte, 0, marshaledParamsSize)
+	if d.param != nil {
+		b = append(b, magicParams...)
+	} else {
+		b = append(b, magic...)
+	}
 	for i := 0; i < 8; i++ {
 		b = appendUint32(b, d.h[i])
 	}
@@ -142,14 +161,23 @@ func (d *digest) MarshalBinary() ([]byte, error) {
 	b = append(b, byte(d.size))
 	b = append(b, d.chunk[:]...)
 	b = append(b, byte(d.offset))
+	if d.param != nil {
+		b = append(b, d.param[:]...)
+		if d.lastNode {
+			b = append(b, 1)
+		} else {
+			b = append(b, 0)
+		}
+	}
 	return b, nil
 }
 
 func (d *digest) UnmarshalBinary(b []byte) error {
-	if len(b) < len(magic) || string(b[:len(magic)]) != magic {
+	hasParams := len(b) >= len(magicParams) && string(b[:len(magicParams)]) == magicParams
+	if !hasParams && (len(b) < len(magic) || string(b[:len(magic)]) != magic) {
 		return errors.New("github.com/benchlab/bench-crypto/blake2s: invalid hash state identifier")
 	}
-	if len(b) != marshaledSize {
+	if hasParams && len(b) != marshaledParamsSize || !hasParams && len(b) != marshaledSize {
 		return errors.New("github.com/benchlab/bench-crypto/blake2s: invalid hash state size")
 	}
 	b = b[len(magic):]
@@ -163,6 +191,13 @@ func (d *digest) UnmarshalBinary(b []byte) error {
 	copy(d.chunk[:], b[:ChunkSize])
 	b = b[ChunkSize:]
 	d.offset = int(b[0])
+	b = b[1:]
+	d.param, d.lastNode = nil, false
+	if hasParams {
+		d.param = new([Size]byte)
+		copy(d.param[:], b[:Size])
+		d.lastNode = b[Size] != 0
+	}
 	return nil
 }
 
@@ -171,9 +206,13 @@ func (d *digest) ChunkSize() int { return ChunkSize }
 func (d *digest) Size() int { return d.size }
 
 func (d *digest) Reset() {
-	d.h = iv
-	d.h[0] ^= uint32(d.size) | (uint32(d.keyLen) << 8) | (1 << 16) | (1 << 24)
-	d.offset, d.c[0], d.c[1] = 0, 0, 0
+	if d.param != nil {
+		d.initConfig(d.param)
+	} else {
+		d.h = iv
+		d.h[0] ^= uint32(d.size) | (uint32(d.keyLen) << 8) | (1 << 16) | (1 << 24)
+		d.offset, d.c[0], d.c[1] = 0, 0, 0
+	}
 	if d.keyLen > 0 {
 		d.chunk = d.key
 		d.offset = ChunkSize
@@ -226,7 +265,11 @@ func (d *digest) finalize(hash *[Size]byte) {
 	}
 	c[0] -= remaining
 
-	hashChunks(&h, &c, 0xFFFFFFFF, chunk[:])
+	if d.lastNode {
+		hashChunksGenericNode(&h, &c, 0xFFFFFFFF, 0xFFFFFFFF, chunk[:])
+	} else {
+		hashChunks(&h, &c, 0xFFFFFFFF, chunk[:])
+	}
 	for i, v := range h {
 		binary.LittleEndian.PutUint32(hash[4*i:], v)
 	}
@@ -21,6 +21,13 @@ var precomputed = [10][16]byte{
 }
 
 func hashChunksGeneric(h *[8]uint32, c *[2]uint32, flag uint32, chunks []byte) {
+	hashChunksGenericNode(h, c, flag, 0, chunks)
+}
+
+// hashChunksGenericNode is like hashChunksGeneric, but also takes the
+// last-node flag of tree hashing, which the assembly implementations do not
+// support. It is only used for the final chunk of a last node.
+func hashChunksGenericNode(h *[8]uint32, c *[2]uint32, flag, lastNode uint32, chunks []byte) {
 	var m [16]uint32
 	c0, c1 := c[0], c[1]
 
@@ -35,6 +42,7 @@ func hashChunksGeneric(h *[8]uint32, c *[2]uint32, flag uint32, chunks []byte) {
 		v12 ^= c0
 		v13 ^= c1
 		v14 ^= flag
+		v15 ^= lastNode
 
 		for j := range m {
 			m[j] = uint32(chunks[i]) | uint32(chunks[i+1])<<8 | uint32(chunks[i+2])<<16 | uint32(chunks[i+3])<<24
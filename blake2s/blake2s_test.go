@@ -244,6 +244,221 @@ func testHashes2X(t *testing.T) {
 
 // Benchmarks
 
+func TestParams(t *testing.T) {
+	input := make([]byte, 100)
+	for i := range input {
+		input[i] = byte(i)
+	}
+
+	for i, v := range paramsVectors {
+		h, err := NewWithParams(&v.params)
+		if err != nil {
+			t.Fatalf("#%d: error from NewWithParams: %v", i, err)
+		}
+		h.Write(input)
+		if got := fmt.Sprintf("%x", h.Sum(nil)); got != v.sum {
+			t.Errorf("#%d: got %s, wanted %s", i, got, v.sum)
+		}
+	}
+
+	h, _ := NewWithParams(&Params{Size: Size})
+	h.Write(input)
+	if got, want := h.Sum(nil), Sum256(input); !bytes.Equal(got, want[:]) {
+		t.Errorf("default parameters: got %x, wanted %x", got, want)
+	}
+
+	for i, p := range []Params{
+		{Size: 0},
+		{Size: Size + 1},
+		{Size: Size, Key: make([]byte, Size+1)},
+		{Size: Size, Salt: make([]byte, SaltSize+1)},
+		{Size: Size, Personal: make([]byte, PersonalSize+1)},
+		{Size: Size, Fanout: 2},
+		{Size: Size, Depth: 2, InnerLength: Size + 1},
+		{Size: Size, Depth: 2, NodeOffset: 1 << 48},
+	} {
+		if _, err := NewWithParams(&p); err == nil {
+			t.Errorf("#%d: invalid parameters accepted", i)
+		}
+	}
+}
+
+func TestMarshalParams(t *testing.T) {
+	input := make([]byte, 300)
+	for i := range input {
+		input[i] = byte(i)
+	}
+
+	p := Params{
+		Size:        Size,
+		Salt:        []byte("salt"),
+		Personal:    []byte("personal"),
+		Fanout:      2,
+		Depth:       3,
+		LeafLength:  4096,
+		NodeOffset:  7,
+		NodeDepth:   1,
+		InnerLength: Size,
+		LastNode:    true,
+	}
+	h, err := NewWithParams(&p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Write(input[:100])
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	}
+
+	// Restore into a digest with the default parameters, which must take
+	// the parameter block and the last node flag from the state.
+	h2, _ := New256(nil)
+	if err := h2.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		t.Fatalf("could not unmarshal: %v", err)
+	}
+	h.Write(input[100:])
+	h2.Write(input[100:])
+	if sum, sum2 := h.Sum(nil), h2.Sum(nil); !bytes.Equal(sum, sum2) {
+		t.Errorf("results do not match; sum = %x, sum2 = %x", sum, sum2)
+	}
+
+	h.Reset()
+	h2.Reset()
+	h.Write(input)
+	h2.Write(input)
+	if sum, sum2 := h.Sum(nil), h2.Sum(nil); !bytes.Equal(sum, sum2) {
+		t.Errorf("results do not match after Reset; sum = %x, sum2 = %x", sum, sum2)
+	}
+
+	// A default digest restored from a parameterized state must be reset
+	// to the default parameters again.
+	h3, _ := New256(nil)
+	state, _ = h3.(encoding.BinaryMarshaler).MarshalBinary()
+	if err := h2.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		t.Fatalf("could not unmarshal: %v", err)
+	}
+	h2.Write(input)
+	h3.Write(input)
+	if sum, sum2 := h3.Sum(nil), h2.Sum(nil); !bytes.Equal(sum, sum2) {
+		t.Errorf("default state: results do not match; sum = %x, sum2 = %x", sum, sum2)
+	}
+}
+
+var paramsVectors = []struct {
+	params Params
+	sum    string
+}{
+	{
+		Params{
+			Size:        32,
+			Key:         []byte("key"),
+			Salt:        []byte("salt"),
+			Personal:    []byte("personal"),
+			Fanout:      2,
+			Depth:       3,
+			LeafLength:  4096,
+			NodeOffset:  1<<40 + 7,
+			NodeDepth:   1,
+			InnerLength: 32,
+			LastNode:    true,
+		},
+		"4c9607e48bce2e98d9fee38d35360d6881f6b111c75129351a4e9c14ffd5a10c",
+	},
+	{
+		Params{
+			Size:     20,
+			Salt:     []byte("01234567"),
+			Personal: []byte("ZcashPoW"),
+		},
+		"356acc4b9ce5afdb26abe2c92deb7ae7b62b0bab",
+	},
+}
+
+func TestSP(t *testing.T) {
+	defer func(sse2, ssse3, sse4 bool) {
+		useSSE2, useSSSE3, useSSE4 = sse2, ssse3, sse4
+	}(useSSE2, useSSSE3, useSSE4)
+
+	if useSSE4 {
+		t.Log("SSE4 version")
+		testSP(t)
+		useSSE4 = false
+	}
+	if useSSSE3 {
+		t.Log("SSSE3 version")
+		testSP(t)
+		useSSSE3 = false
+	}
+	if useSSE2 {
+		t.Log("SSE2 version")
+		testSP(t)
+		useSSE2 = false
+	}
+	t.Log("generic version")
+	testSP(t)
+}
+
+func testSP(t *testing.T) {
+	input := make([]byte, 1<<20)
+	for i := range input {
+		input[i] = byte(i)
+	}
+
+	for _, v := range spVectors {
+		if got := fmt.Sprintf("%x", SumSP256(input[:v.length])); got != v.sum {
+			t.Fatalf("length %d: got %s, wanted %s", v.length, got, v.sum)
+		}
+
+		h, _ := NewSP(Size, nil)
+		for p := input[:v.length]; len(p) > 0; {
+			n := 1 + len(p)%300
+			if n > len(p) {
+				n = len(p)
+			}
+			h.Write(p[:n])
+			p = p[n:]
+		}
+		if got := fmt.Sprintf("%x", h.Sum(nil)); got != v.sum {
+			t.Fatalf("length %d (split writes): got %s, wanted %s", v.length, got, v.sum)
+		}
+	}
+
+	// The first keyed BLAKE2sp test vector of the reference implementation.
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	h, err := NewSP(Size, key)
+	if err != nil {
+		t.Fatalf("error from NewSP: %v", err)
+	}
+	want := "715cb13895aeb678f6124160bff21465b30f4f6874193fc851b4621043f09cc6"
+	if got := fmt.Sprintf("%x", h.Sum(nil)); got != want {
+		t.Fatalf("keyed: got %s, wanted %s", got, want)
+	}
+}
+
+var spVectors = []struct {
+	length int
+	sum    string
+}{
+	{0, "dd0e891776933f43c7d032b08a917e25741f8aa9a12c12e1cac8801500f2ca4f"},
+	{1, "a6b9eecc25227ad788c99d3f236debc8da408849e9a5178978727a81457f7239"},
+	{63, "1024c940be7341449b5010522b509f65bbdc1287b455c2bb7f72b2c92fd0d189"},
+	{64, "52603b6cbfad4966cb044cb267568385cf35f21e6c45cf30aed19832cb51e9f5"},
+	{65, "fff24d3cc729d395daf978b0157306cb495797e6c8dca1731d2f6f81b849baae"},
+	{127, "a626543c271fccc3e4450b48d66bc9cbdeb25e5d077a6213cd90cbbd0fd22076"},
+	{128, "05cf3a90049116dc60efc31536aaa3d167762994892876dcb7ef3fbecd7449c0"},
+	{129, "ccd61c926cc1e5e9128c021c0c6e92aefc4ffbde394dd6f3b7d87a8ced896014"},
+	{255, "25059f10605e67adfe681350666e15ae976a5a571c13cf5bc8053f430e120a52"},
+	{256, "5140cfbe0c4ec095dd01713dc470e0ca049e5ba8671984cd28ab510dffee97cd"},
+	{511, "50285271956932d39b0967202b56006cbb6d738ee29e5a867edf72c8c4386f1b"},
+	{512, "322ce06cc141a0b3d89bcdcfcb385975dbca56e5719a78c34000fcec2e15b55d"},
+	{513, "1336628c7f1541c7815fc0ff1fb5dfb07a85cf5a17a2872a3ce4b322d4a03d0b"},
+	{1000, "7e2830f74fc7c4d224a201b46f95e37ebbfb56dddc492f8227e4d905201734b8"},
+	{2048, "2f2446d594620c85b60de83a27275f6145fc45cbfc2f4483a8592fdcc66111c3"},
+	{4097, "ff56cf04d1724a53f37ffea682e83bbcd0033f6fc0dfef6a21dbf621a79eb667"},
+	{1048576, "1c80342a8b03e44a4f0d72290144f21305d2c8599039dc7209ff9b00b29fff67"},
+}
+
 func benchmarkSum(b *testing.B, size int) {
 	data := make([]byte, size)
 	b.SetBytes(int64(size))
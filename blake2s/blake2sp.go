@@ -0,0 +1,160 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package blake2s
+
+import (
+	"hash"
+	"sync"
+)
+
+const (
+	// spLeaves is the degree of parallelism of BLAKE2sp.
+	spLeaves = 8
+	// spStripe is the number of bytes distributed over the leaves at once.
+	spStripe = spLeaves * ChunkSize
+	// spParallelThreshold is the size of writes from which the leaves are
+	// hashed on separate goroutines.
+	spParallelThreshold = 16 * 1024
+)
+
+// SumSP256 returns the BLAKE2sp-256 checksum of the data.
+func SumSP256(data []byte) [Size]byte {
+	var sum [Size]byte
+	d, _ := newSPDigest(Size, nil)
+	d.Write(data)
+	d.Sum(sum[:0])
+	return sum
+}
+
+// NewSP returns a new hash.Hash computing the BLAKE2sp checksum with a custom
+// length. BLAKE2sp splits the input over eight BLAKE2s instances, which are
+// hashed in parallel, and produces different checksums than BLAKE2s. A
+// non-nil key turns the hash into a MAC. The key must between zero and 32
+// bytes long. The hash size can be a value between 1 and 32.
+func NewSP(size int, key []byte) (hash.Hash, error) { return newSPDigest(size, key) }
+
+type spDigest struct {
+	leaves [spLeaves]digest
+	root   digest
+	stripe [spStripe]byte
+	offset int
+}
+
+func newSPDigest(hashSize int, key []byte) (*spDigest, error) {
+	p := Params{
+		Size:        hashSize,
+		Key:         key,
+		Fanout:      spLeaves,
+		Depth:       2,
+		InnerLength: Size,
+	}
+	d := new(spDigest)
+	for i := range d.leaves {
+		p.NodeOffset = uint64(i)
+		p.LastNode = i == spLeaves-1
+		param, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		// The leaves produce inner hashes, but their parameter block
+		// holds the size of the final checksum.
+		d.leaves[i] = digest{
+			size:     Size,
+			keyLen:   len(key),
+			param:    param,
+			lastNode: p.LastNode,
+		}
+		copy(d.leaves[i].key[:], key)
+	}
+
+	// The root node is not keyed, but its parameter block holds the key
+	// length.
+	p.Key, p.NodeOffset, p.NodeDepth, p.LastNode = nil, 0, 1, true
+	param, _ := p.block()
+	param[1] = byte(len(key))
+	d.root = digest{size: hashSize, param: param, lastNode: true}
+
+	d.Reset()
+	return d, nil
+}
+
+func (d *spDigest) ChunkSize() int { return ChunkSize }
+
+func (d *spDigest) Size() int { return d.root.size }
+
+func (d *spDigest) Reset() {
+	for i := range d.leaves {
+		d.leaves[i].Reset()
+	}
+	d.root.Reset()
+	d.offset = 0
+}
+
+func (d *spDigest) Write(p []byte) (n int, err error) {
+	n = len(p)
+
+	if d.offset > 0 {
+		remaining := spStripe - d.offset
+		if n < remaining {
+			d.offset += copy(d.stripe[d.offset:], p)
+			return
+		}
+		copy(d.stripe[d.offset:], p[:remaining])
+		d.hashStripes(d.stripe[:])
+		d.offset = 0
+		p = p[remaining:]
+	}
+
+	if nn := len(p) &^ (spStripe - 1); nn > 0 {
+		d.hashStripes(p[:nn])
+		p = p[nn:]
+	}
+
+	d.offset += copy(d.stripe[:], p)
+	return
+}
+
+// hashStripes writes the i-th chunk of every stripe of p to the i-th leaf.
+func (d *spDigest) hashStripes(p []byte) {
+	hashLeaf := func(i int) {
+		for off := i * ChunkSize; off < len(p); off += spStripe {
+			d.leaves[i].Write(p[off : off+ChunkSize])
+		}
+	}
+	if len(p) < spParallelThreshold {
+		for i := range d.leaves {
+			hashLeaf(i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 1; i < spLeaves; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			hashLeaf(i)
+		}(i)
+	}
+	hashLeaf(0)
+	wg.Wait()
+}
+
+func (d *spDigest) Sum(sum []byte) []byte {
+	leaves, root := d.leaves, d.root
+	for i := range leaves {
+		if lo := i * ChunkSize; lo < d.offset {
+			hi := lo + ChunkSize
+			if hi > d.offset {
+				hi = d.offset
+			}
+			leaves[i].Write(d.stripe[lo:hi])
+		}
+		var hash [Size]byte
+		leaves[i].finalize(&hash)
+		root.Write(hash[:])
+	}
+	return root.Sum(sum)
+}
@@ -0,0 +1,360 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package blake3 implements the BLAKE3 hash function, its keyed hash and
+// key derivation modes, and its extendable output function (XOF).
+//
+// For a detailed specification of BLAKE3 see
+// https://github.com/BLAKE3-team/BLAKE3-specs/blob/master/blake3.pdf
+//
+// BLAKE3 splits its input into chunks of 1024 bytes, which are hashed
+// independently and combined in a binary tree. Large inputs written at once
+// are hashed on several goroutines, and the tree allows verifying parts of
+// some content independently, see EncodeOutboard and VerifyLeaf. On amd64,
+// the chunks of such inputs are hashed four at a time with SSE2 instructions,
+// one chunk in each lane; elsewhere the compression function is portable Go.
+//
+// The exported names follow the conventions of this module rather than the
+// specification: ChunkSize is the size of the input block of the hash, as
+// for every hash.Hash here, which the specification calls BLOCK_LEN, and a
+// leaf, of LeafSize bytes, is what the specification calls a chunk, of
+// CHUNK_LEN bytes. The implementation uses the specification's terms.
+//
+// If you aren't sure which function you need, use BLAKE3 (Sum256 or New256).
+// If you need a secret-key MAC (message authentication code), use the New256
+// function with a non-nil key. If you need to derive keys from a master key,
+// use DeriveKey with a hardcoded, globally unique context string.
+package blake3 // import "golang.org/x/github.com/benchlab/bench-crypto/blake3"
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"math/bits"
+)
+
+const (
+	// The hash size of BLAKE3 in bytes.
+	Size = 32
+	// The chunksize of BLAKE3 in bytes, the size of the input block of its
+	// compression function (BLOCK_LEN in the specification).
+	ChunkSize = 64
+	// The key size of keyed BLAKE3 in bytes.
+	KeySize = 32
+	// The size of a BLAKE3 leaf in bytes, the unit of the tree and of
+	// VerifyLeaf (a chunk of CHUNK_LEN bytes in the specification).
+	LeafSize = 1024
+)
+
+// The specification's names for ChunkSize and LeafSize, used internally.
+const (
+	blockLen = ChunkSize
+	chunkLen = LeafSize
+)
+
+const (
+	flagChunkStart = 1 << iota
+	flagChunkEnd
+	flagParent
+	flagRoot
+	flagKeyedHash
+	flagDeriveKeyContext
+	flagDeriveKeyMaterial
+)
+
+// maxDepth is the maximal height of the tree of a 2^64 byte input.
+const maxDepth = 54
+
+var (
+	errKeySize  = errors.New("blake3: invalid key size")
+	errHashSize = errors.New("blake3: invalid hash size")
+)
+
+var iv = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a,
+	0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+// Sum256 returns the BLAKE3 checksum of the data.
+func Sum256(data []byte) [Size]byte {
+	var sum [Size]byte
+	d := newDigest(Size, iv, 0)
+	d.Write(data)
+	d.Sum(sum[:0])
+	return sum
+}
+
+// New256 returns a new hash.Hash computing the BLAKE3 checksum. A non-nil
+// key turns the hash into a MAC. The key must be nil or 32 bytes long.
+func New256(key []byte) (hash.Hash, error) { return New(Size, key) }
+
+// New returns a new hash.Hash computing the BLAKE3 checksum with a custom
+// length. A non-nil key turns the hash into a MAC. The key must be nil or 32
+// bytes long. The hash size can be any positive value, but sizes below 32
+// reduce the security of BLAKE3. Shorter outputs are prefixes of longer ones.
+func New(size int, key []byte) (hash.Hash, error) {
+	if size < 1 {
+		return nil, errHashSize
+	}
+	k, flags, err := keyWords(key)
+	if err != nil {
+		return nil, err
+	}
+	return newDigest(size, k, flags), nil
+}
+
+// DeriveKey fills key with key material derived from the secret material
+// using BLAKE3 in key derivation mode. The context string should be
+// hardcoded, globally unique and application specific, for example
+// "example.com 2019-12-25 16:18:03 session tokens v1".
+func DeriveKey(key []byte, context string, material []byte) {
+	x := NewDeriveKey(context)
+	x.Write(material)
+	x.Read(key)
+}
+
+// NewDeriveKey returns a new XOF in key derivation mode for the given
+// context string. The secret key material is written to the XOF and derived
+// keys are read from it.
+func NewDeriveKey(context string) XOF {
+	c := newDigest(KeySize, iv, flagDeriveKeyContext)
+	io.WriteString(c, context)
+	var contextKey [KeySize]byte
+	c.Sum(contextKey[:0])
+
+	k, _, _ := keyWords(contextKey[:])
+	return &xof{d: *newDigest(Size, k, flagDeriveKeyMaterial)}
+}
+
+func keyWords(key []byte) (k [8]uint32, flags uint32, err error) {
+	if key == nil {
+		return iv, 0, nil
+	}
+	if len(key) != KeySize {
+		return k, 0, errKeySize
+	}
+	for i := range k {
+		k[i] = binary.LittleEndian.Uint32(key[4*i:])
+	}
+	return k, flagKeyedHash, nil
+}
+
+// output holds the inputs of a compression that has not been performed yet,
+// because its flags depend on whether the node turns out to be the root.
+type output struct {
+	cv       [8]uint32
+	block    [blockLen]byte
+	counter  uint64
+	blockLen uint32
+	flags    uint32
+}
+
+func (o *output) chainingValue() (cv [8]uint32) {
+	v := compress(&o.cv, &o.block, o.counter, o.blockLen, o.flags)
+	copy(cv[:], v[:8])
+	return
+}
+
+// rootBlock returns the output block at index i of the root node o.
+func (o *output) rootBlock(i uint64) (out [blockLen]byte) {
+	v := compress(&o.cv, &o.block, i, o.blockLen, o.flags|flagRoot)
+	for j, w := range v {
+		binary.LittleEndian.PutUint32(out[4*j:], w)
+	}
+	return
+}
+
+func parentOutput(left, right *[8]uint32, key *[8]uint32, flags uint32) output {
+	o := output{cv: *key, blockLen: blockLen, flags: flags | flagParent}
+	for i := 0; i < 8; i++ {
+		binary.LittleEndian.PutUint32(o.block[4*i:], left[i])
+		binary.LittleEndian.PutUint32(o.block[32+4*i:], right[i])
+	}
+	return o
+}
+
+// chunkState hashes the blocks of a single chunk.
+type chunkState struct {
+	cv               [8]uint32
+	counter          uint64
+	block            [blockLen]byte
+	blockLen         int
+	blocksCompressed int
+	flags            uint32
+}
+
+func newChunkState(key *[8]uint32, counter uint64, flags uint32) chunkState {
+	return chunkState{cv: *key, counter: counter, flags: flags}
+}
+
+func (c *chunkState) len() int { return blockLen*c.blocksCompressed + c.blockLen }
+
+func (c *chunkState) startFlag() uint32 {
+	if c.blocksCompressed == 0 {
+		return flagChunkStart
+	}
+	return 0
+}
+
+func (c *chunkState) update(p []byte) {
+	for len(p) > 0 {
+		if c.blockLen == blockLen {
+			v := compress(&c.cv, &c.block, c.counter, blockLen, c.flags|c.startFlag())
+			copy(c.cv[:], v[:8])
+			c.blocksCompressed++
+			c.block = [blockLen]byte{}
+			c.blockLen = 0
+		}
+		n := copy(c.block[c.blockLen:], p)
+		c.blockLen += n
+		p = p[n:]
+	}
+}
+
+func (c *chunkState) output() output {
+	return output{
+		cv:       c.cv,
+		block:    c.block,
+		counter:  c.counter,
+		blockLen: uint32(c.blockLen),
+		flags:    c.flags | c.startFlag() | flagChunkEnd,
+	}
+}
+
+// chunkCV returns the chaining value of a chunk that is not the root.
+func chunkCV(p []byte, counter uint64, key *[8]uint32, flags uint32) [8]uint32 {
+	c := newChunkState(key, counter, flags)
+	c.update(p)
+	o := c.output()
+	return o.chainingValue()
+}
+
+type digest struct {
+	key   [8]uint32
+	flags uint32
+	size  int
+
+	chunk chunkState
+	// stack holds the chaining values of complete subtrees, which are
+	// merged lazily so that the last one is never mistaken for a non-root
+	// node.
+	stack    [maxDepth + 1][8]uint32
+	stackLen int
+}
+
+func newDigest(size int, key [8]uint32, flags uint32) *digest {
+	d := &digest{key: key, flags: flags, size: size}
+	d.Reset()
+	return d
+}
+
+func (d *digest) ChunkSize() int { return ChunkSize }
+
+func (d *digest) Size() int { return d.size }
+
+func (d *digest) Reset() {
+	d.chunk = newChunkState(&d.key, 0, d.flags)
+	d.stackLen = 0
+}
+
+func (d *digest) Write(p []byte) (n int, err error) {
+	n = len(p)
+
+	if d.chunk.len() > 0 {
+		todo := chunkLen - d.chunk.len()
+		if todo > len(p) {
+			todo = len(p)
+		}
+		d.chunk.update(p[:todo])
+		p = p[todo:]
+		if len(p) == 0 {
+			return
+		}
+		o := d.chunk.output()
+		d.pushCV(o.chainingValue(), d.chunk.counter)
+		d.chunk = newChunkState(&d.key, d.chunk.counter+1, d.flags)
+	}
+
+	// Hash the largest complete subtrees that are aligned with the number
+	// of chunks hashed so far, keeping at least one byte for the final
+	// chunk state.
+	for len(p) > chunkLen {
+		subtree := uint64(1) << uint(bits.Len64(uint64(len(p)))-1)
+		for (subtree-1)&(d.chunk.counter*chunkLen) != 0 {
+			subtree >>= 1
+		}
+		chunks := subtree / chunkLen
+		counter := d.chunk.counter
+		if chunks == 1 {
+			d.pushCV(chunkCV(p[:subtree], counter, &d.key, d.flags), counter)
+		} else {
+			left, right := subtreeChildren(p[:subtree], counter, &d.key, d.flags)
+			d.pushCV(left, counter)
+			d.pushCV(right, counter+chunks/2)
+		}
+		d.chunk.counter += chunks
+		p = p[subtree:]
+	}
+
+	if len(p) > 0 {
+		d.chunk.update(p)
+		// Now that the chunk state holds input, none of the subtrees on
+		// the stack is the root.
+		d.mergeStack(d.chunk.counter)
+	}
+	return
+}
+
+// pushCV pushes cv, the chaining value of the subtree starting at chunk
+// counter, after merging the complete subtrees that precede it.
+func (d *digest) pushCV(cv [8]uint32, counter uint64) {
+	d.mergeStack(counter)
+	d.stack[d.stackLen] = cv
+	d.stackLen++
+}
+
+// mergeStack merges the subtrees on the stack until it holds one subtree for
+// every bit set in the number of chunks they cover.
+func (d *digest) mergeStack(chunks uint64) {
+	for d.stackLen > bits.OnesCount64(chunks) {
+		d.stackLen--
+		o := parentOutput(&d.stack[d.stackLen-1], &d.stack[d.stackLen], &d.key, d.flags)
+		d.stack[d.stackLen-1] = o.chainingValue()
+	}
+}
+
+// rootOutput returns the root node of the tree of the input written so far.
+func (d *digest) rootOutput() output {
+	if d.stackLen == 0 {
+		return d.chunk.output()
+	}
+	var o output
+	i := d.stackLen
+	if d.chunk.len() > 0 {
+		o = d.chunk.output()
+	} else {
+		i -= 2
+		o = parentOutput(&d.stack[i], &d.stack[i+1], &d.key, d.flags)
+	}
+	for i > 0 {
+		i--
+		cv := o.chainingValue()
+		o = parentOutput(&d.stack[i], &cv, &d.key, d.flags)
+	}
+	return o
+}
+
+func (d *digest) Sum(sum []byte) []byte {
+	o := d.rootOutput()
+	for i := uint64(0); d.size > int(i)*blockLen; i++ {
+		out := o.rootBlock(i)
+		n := d.size - int(i)*blockLen
+		if n > blockLen {
+			n = blockLen
+		}
+		sum = append(sum, out[:n]...)
+	}
+	return sum
+}
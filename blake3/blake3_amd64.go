@@ -0,0 +1,66 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build amd64,!gccgo,!appengine
+
+package blake3
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/sys/cpu"
+)
+
+var useSSE2 = cpu.X86.HasSSE2
+
+// compress4SSE2 applies the compression function to four blocks at once. v
+// and m hold the transposed initial states and message blocks, with word i
+// of block j in v[i][j] and m[i][j]. The first eight words of v are replaced
+// with the new chaining values.
+//
+//go:noescape
+func compress4SSE2(v, m *[16][4]uint32)
+
+func hashChunks(cvs *[multiChunks][8]uint32, p []byte, counter uint64, key *[8]uint32, flags uint32) {
+	if useSSE2 {
+		hashChunksSSE2(cvs, p, counter, key, flags)
+	} else {
+		hashChunksGeneric(cvs, p, counter, key, flags)
+	}
+}
+
+func hashChunksSSE2(cvs *[multiChunks][8]uint32, p []byte, counter uint64, key *[8]uint32, flags uint32) {
+	var v, m [16][4]uint32
+	for i := 0; i < 8; i++ {
+		v[i] = [4]uint32{key[i], key[i], key[i], key[i]}
+	}
+	for b := 0; b < chunkLen/blockLen; b++ {
+		blockFlags := flags
+		if b == 0 {
+			blockFlags |= flagChunkStart
+		}
+		if b == chunkLen/blockLen-1 {
+			blockFlags |= flagChunkEnd
+		}
+		for j := 0; j < multiChunks; j++ {
+			block := p[j*chunkLen+b*blockLen:]
+			for i := range m {
+				m[i][j] = binary.LittleEndian.Uint32(block[4*i:])
+			}
+			c := counter + uint64(j)
+			v[12][j], v[13][j] = uint32(c), uint32(c>>32)
+		}
+		for i := 0; i < 4; i++ {
+			v[8+i] = [4]uint32{iv[i], iv[i], iv[i], iv[i]}
+		}
+		v[14] = [4]uint32{blockLen, blockLen, blockLen, blockLen}
+		v[15] = [4]uint32{blockFlags, blockFlags, blockFlags, blockFlags}
+		compress4SSE2(&v, &m)
+	}
+	for j := range cvs {
+		for i := range cvs[j] {
+			cvs[j][i] = v[i][j]
+		}
+	}
+}
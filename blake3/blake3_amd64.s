@@ -0,0 +1,142 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build amd64,!gccgo,!appengine
+
+#include "textflag.h"
+
+// The state and message words are stored transposed: the 16 bytes at offset
+// 16*i hold word i for each of the four chunks being hashed, so that every
+// instruction below operates on the four chunks at once.
+
+#define ROTR_SSE2(n, t, v) \
+	MOVO  v, t;       \
+	PSRLL $n, v;      \
+	PSLLL $(32-n), t; \
+	PXOR  t, v
+
+#define ROTR16_SSE2(v) \
+	PSHUFLW $0xB1, v, v; \
+	PSHUFHW $0xB1, v, v
+
+// G applies the G function to the state words at offsets va, vb, vc and vd
+// from DI with the message words at offsets mx and my from SI.
+#define G(va, vb, vc, vd, mx, my) \
+	MOVOU va(DI), X0;        \
+	MOVOU vb(DI), X1;        \
+	MOVOU vc(DI), X2;        \
+	MOVOU vd(DI), X3;        \
+	MOVOU mx(SI), X4;        \
+	MOVOU my(SI), X5;        \
+	PADDL X1, X0;            \
+	PADDL X4, X0;            \
+	PXOR  X0, X3;            \
+	ROTR16_SSE2(X3);         \
+	PADDL X3, X2;            \
+	PXOR  X2, X1;            \
+	ROTR_SSE2(12, X6, X1);   \
+	PADDL X1, X0;            \
+	PADDL X5, X0;            \
+	PXOR  X0, X3;            \
+	ROTR_SSE2(8, X6, X3);    \
+	PADDL X3, X2;            \
+	PXOR  X2, X1;            \
+	ROTR_SSE2(7, X6, X1);    \
+	MOVOU X0, va(DI);        \
+	MOVOU X1, vb(DI);        \
+	MOVOU X2, vc(DI);        \
+	MOVOU X3, vd(DI)
+
+// FINALIZE sets the state word at offset va from DI to its XOR with the word
+// at offset vb, which is the new chaining value.
+#define FINALIZE(va, vb) \
+	MOVOU va(DI), X0; \
+	MOVOU vb(DI), X1; \
+	PXOR  X1, X0;     \
+	MOVOU X0, va(DI)
+
+// func compress4SSE2(v, m *[16][4]uint32)
+TEXT ·compress4SSE2(SB), NOSPLIT, $0-16
+	MOVQ v+0(FP), DI
+	MOVQ m+8(FP), SI
+
+	// Round 1.
+	G(0, 64, 128, 192, 0, 16)
+	G(16, 80, 144, 208, 32, 48)
+	G(32, 96, 160, 224, 64, 80)
+	G(48, 112, 176, 240, 96, 112)
+	G(0, 80, 160, 240, 128, 144)
+	G(16, 96, 176, 192, 160, 176)
+	G(32, 112, 128, 208, 192, 208)
+	G(48, 64, 144, 224, 224, 240)
+
+	// Round 2.
+	G(0, 64, 128, 192, 32, 96)
+	G(16, 80, 144, 208, 48, 160)
+	G(32, 96, 160, 224, 112, 0)
+	G(48, 112, 176, 240, 64, 208)
+	G(0, 80, 160, 240, 16, 176)
+	G(16, 96, 176, 192, 192, 80)
+	G(32, 112, 128, 208, 144, 224)
+	G(48, 64, 144, 224, 240, 128)
+
+	// Round 3.
+	G(0, 64, 128, 192, 48, 64)
+	G(16, 80, 144, 208, 160, 192)
+	G(32, 96, 160, 224, 208, 32)
+	G(48, 112, 176, 240, 112, 224)
+	G(0, 80, 160, 240, 96, 80)
+	G(16, 96, 176, 192, 144, 0)
+	G(32, 112, 128, 208, 176, 240)
+	G(48, 64, 144, 224, 128, 16)
+
+	// Round 4.
+	G(0, 64, 128, 192, 160, 112)
+	G(16, 80, 144, 208, 192, 144)
+	G(32, 96, 160, 224, 224, 48)
+	G(48, 112, 176, 240, 208, 240)
+	G(0, 80, 160, 240, 64, 0)
+	G(16, 96, 176, 192, 176, 32)
+	G(32, 112, 128, 208, 80, 128)
+	G(48, 64, 144, 224, 16, 96)
+
+	// Round 5.
+	G(0, 64, 128, 192, 192, 208)
+	G(16, 80, 144, 208, 144, 176)
+	G(32, 96, 160, 224, 240, 160)
+	G(48, 112, 176, 240, 224, 128)
+	G(0, 80, 160, 240, 112, 32)
+	G(16, 96, 176, 192, 80, 48)
+	G(32, 112, 128, 208, 0, 16)
+	G(48, 64, 144, 224, 96, 64)
+
+	// Round 6.
+	G(0, 64, 128, 192, 144, 224)
+	G(16, 80, 144, 208, 176, 80)
+	G(32, 96, 160, 224, 128, 192)
+	G(48, 112, 176, 240, 240, 16)
+	G(0, 80, 160, 240, 208, 48)
+	G(16, 96, 176, 192, 0, 160)
+	G(32, 112, 128, 208, 32, 96)
+	G(48, 64, 144, 224, 64, 112)
+
+	// Round 7.
+	G(0, 64, 128, 192, 176, 240)
+	G(16, 80, 144, 208, 80, 0)
+	G(32, 96, 160, 224, 16, 144)
+	G(48, 112, 176, 240, 128, 96)
+	G(0, 80, 160, 240, 224, 160)
+	G(16, 96, 176, 192, 32, 192)
+	G(32, 112, 128, 208, 48, 64)
+	G(48, 64, 144, 224, 112, 208)
+
+	FINALIZE(0, 128)
+	FINALIZE(16, 144)
+	FINALIZE(32, 160)
+	FINALIZE(48, 176)
+	FINALIZE(64, 192)
+	FINALIZE(80, 208)
+	FINALIZE(96, 224)
+	FINALIZE(112, 240)
+	RET
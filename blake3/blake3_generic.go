@@ -0,0 +1,76 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package blake3
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// msgSchedule holds the message word order of each of the seven rounds, which
+// results from applying the BLAKE3 message permutation repeatedly.
+var msgSchedule = [7][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8},
+	{3, 4, 10, 12, 13, 2, 7, 14, 6, 5, 9, 0, 11, 15, 8, 1},
+	{10, 7, 12, 9, 14, 3, 13, 15, 4, 0, 11, 2, 5, 8, 1, 6},
+	{12, 13, 9, 11, 15, 10, 14, 8, 7, 2, 5, 3, 0, 1, 6, 4},
+	{9, 14, 11, 5, 8, 12, 15, 1, 13, 3, 0, 10, 2, 6, 4, 7},
+	{11, 15, 5, 0, 1, 9, 8, 6, 14, 10, 2, 12, 3, 4, 7, 13},
+}
+
+func g(v *[16]uint32, a, b, c, d int, mx, my uint32) {
+	v[a] += v[b] + mx
+	v[d] = bits.RotateLeft32(v[d]^v[a], -16)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft32(v[b]^v[c], -12)
+	v[a] += v[b] + my
+	v[d] = bits.RotateLeft32(v[d]^v[a], -8)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft32(v[b]^v[c], -7)
+}
+
+// compress applies the BLAKE3 compression function to the 64-byte block m,
+// of which the first n bytes are input, with the chaining value cv and
+// returns the full 16-word output state. The first eight words are the new
+// chaining value; all sixteen are used for root output.
+func compress(cv *[8]uint32, m *[blockLen]byte, counter uint64, n uint32, flags uint32) [16]uint32 {
+	var w [16]uint32
+	for i := range w {
+		w[i] = binary.LittleEndian.Uint32(m[4*i:])
+	}
+	v := [16]uint32{
+		cv[0], cv[1], cv[2], cv[3], cv[4], cv[5], cv[6], cv[7],
+		iv[0], iv[1], iv[2], iv[3],
+		uint32(counter), uint32(counter >> 32), n, flags,
+	}
+	for _, s := range msgSchedule {
+		g(&v, 0, 4, 8, 12, w[s[0]], w[s[1]])
+		g(&v, 1, 5, 9, 13, w[s[2]], w[s[3]])
+		g(&v, 2, 6, 10, 14, w[s[4]], w[s[5]])
+		g(&v, 3, 7, 11, 15, w[s[6]], w[s[7]])
+		g(&v, 0, 5, 10, 15, w[s[8]], w[s[9]])
+		g(&v, 1, 6, 11, 12, w[s[10]], w[s[11]])
+		g(&v, 2, 7, 8, 13, w[s[12]], w[s[13]])
+		g(&v, 3, 4, 9, 14, w[s[14]], w[s[15]])
+	}
+	for i := 0; i < 8; i++ {
+		v[i] ^= v[i+8]
+		v[i+8] ^= cv[i]
+	}
+	return v
+}
+
+// multiChunks is the number of chunks that hashChunks hashes at once.
+const multiChunks = 4
+
+// hashChunksGeneric sets cvs to the chaining values of the multiChunks
+// complete chunks held in p, the first of which is at chunk counter. None of
+// them may be the root.
+func hashChunksGeneric(cvs *[multiChunks][8]uint32, p []byte, counter uint64, key *[8]uint32, flags uint32) {
+	for i := range cvs {
+		cvs[i] = chunkCV(p[i*chunkLen:(i+1)*chunkLen], counter+uint64(i), key, flags)
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !amd64 gccgo appengine
+
+package blake3
+
+var useSSE2 = false
+
+func hashChunks(cvs *[multiChunks][8]uint32, p []byte, counter uint64, key *[8]uint32, flags uint32) {
+	hashChunksGeneric(cvs, p, counter, key, flags)
+}
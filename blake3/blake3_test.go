@@ -0,0 +1,313 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package blake3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+const (
+	testVectorKey     = "whats the Elvish word for friend"
+	testVectorContext = "BLAKE3 2019-12-27 16:29:52 test vectors context"
+)
+
+// Test vectors from the official BLAKE3 repository, test_vectors.json. The
+// input of length n is the sequence 0, 1, ..., 250, 0, 1, ... of n bytes.
+var vectors = []struct {
+	inputLen  int
+	hash      string
+	keyedHash string
+	deriveKey string
+}{
+	{
+		0,
+		"af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262e00f03e7b69af26b7faaf09fcd333050338ddfe085b8cc869ca98b206c08243a26f5487789e8f660afe6c99ef9e0c52b92e7393024a80459cf91f476f9ffdbda7001c22e159b402631f277ca96f2defdf1078282314e763699a31c5363165421cce14d",
+		"92b2b75604ed3c761f9d6f62392c8a9227ad0ea3f09573e783f1498a4ed60d26b18171a2f22a4b94822c701f107153dba24918c4bae4d2945c20ece13387627d3b73cbf97b797d5e59948c7ef788f54372df45e45e4293c7dc18c1d41144a9758be58960856be1eabbe22c2653190de560ca3b2ac4aa692a9210694254c371e851bc8f",
+		"2cc39783c223154fea8dfb7c1b1660f2ac2dcbd1c1de8277b0b0dd39b7e50d7d905630c8be290dfcf3e6842f13bddd573c098c3f17361f1f206b8cad9d088aa4a3f746752c6b0ce6a83b0da81d59649257cdf8eb3e9f7d4998e41021fac119deefb896224ac99f860011f73609e6e0e4540f93b273e56547dfd3aa1a035ba6689d89a0",
+	},
+	{
+		1,
+		"2d3adedff11b61f14c886e35afa036736dcd87a74d27b5c1510225d0f592e213c3a6cb8bf623e20cdb535f8d1a5ffb86342d9c0b64aca3bce1d31f60adfa137b358ad4d79f97b47c3d5e79f179df87a3b9776ef8325f8329886ba42f07fb138bb502f4081cbcec3195c5871e6c23e2cc97d3c69a613eba131e5f1351f3f1da786545e5",
+		"6d7878dfff2f485635d39013278ae14f1454b8c0a3a2d34bc1ab38228a80c95b6568c0490609413006fbd428eb3fd14e7756d90f73a4725fad147f7bf70fd61c4e0cf7074885e92b0e3f125978b4154986d4fb202a3f331a3fb6cf349a3a70e49990f98fe4289761c8602c4e6ab1138d31d3b62218078b2f3ba9a88e1d08d0dd4cea11",
+		"b3e2e340a117a499c6cf2398a19ee0d29cca2bb7404c73063382693bf66cb06c5827b91bf889b6b97c5477f535361caefca0b5d8c4746441c57617111933158950670f9aa8a05d791daae10ac683cbef8faf897c84e6114a59d2173c3f417023a35d6983f2c7dfa57e7fc559ad751dbfb9ffab39c2ef8c4aafebc9ae973a64f0c76551",
+	},
+	{
+		1023,
+		"10108970eeda3eb932baac1428c7a2163b0e924c9a9e25b35bba72b28f70bd11a182d27a591b05592b15607500e1e8dd56bc6c7fc063715b7a1d737df5bad3339c56778957d870eb9717b57ea3d9fb68d1b55127bba6a906a4a24bbd5acb2d123a37b28f9e9a81bbaae360d58f85e5fc9d75f7c370a0cc09b6522d9c8d822f2f28f485",
+		"c951ecdf03288d0fcc96ee3413563d8a6d3589547f2c2fb36d9786470f1b9d6e890316d2e6d8b8c25b0a5b2180f94fb1a158ef508c3cde45e2966bd796a696d3e13efd86259d756387d9becf5c8bf1ce2192b87025152907b6d8cc33d17826d8b7b9bc97e38c3c85108ef09f013e01c229c20a83d9e8efac5b37470da28575fd755a10",
+		"74a16c1c3d44368a86e1ca6df64be6a2f64cce8f09220787450722d85725dea59c413264404661e9e4d955409dfe4ad3aa487871bcd454ed12abfe2c2b1eb7757588cf6cb18d2eccad49e018c0d0fec323bec82bf1644c6325717d13ea712e6840d3e6e730d35553f59eff5377a9c350bcc1556694b924b858f329c44ee64b884ef00d",
+	},
+	{
+		1024,
+		"42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af71cf8107265ecdaf8505b95d8fcec83a98a6a96ea5109d2c179c47a387ffbb404756f6eeae7883b446b70ebb144527c2075ab8ab204c0086bb22b7c93d465efc57f8d917f0b385c6df265e77003b85102967486ed57db5c5ca170ba441427ed9afa684e",
+		"75c46f6f3d9eb4f55ecaaee480db732e6c2105546f1e675003687c31719c7ba4a78bc838c72852d4f49c864acb7adafe2478e824afe51c8919d06168414c265f298a8094b1ad813a9b8614acabac321f24ce61c5a5346eb519520d38ecc43e89b5000236df0597243e4d2493fd626730e2ba17ac4d8824d09d1a4a8f57b8227778e2de",
+		"7356cd7720d5b66b6d0697eb3177d9f8d73a4a5c5e968896eb6a6896843027066c23b601d3ddfb391e90d5c8eccdef4ae2a264bce9e612ba15e2bc9d654af1481b2e75dbabe615974f1070bba84d56853265a34330b4766f8e75edd1f4a1650476c10802f22b64bd3919d246ba20a17558bc51c199efdec67e80a227251808d8ce5bad",
+	},
+	{
+		1025,
+		"d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444f4c4a22b4b399155358a994e52bf255de60035742ec71bd08ac275a1b51cc6bfe332b0ef84b409108cda080e6269ed4b3e2c3f7d722aa4cdc98d16deb554e5627be8f955c98e1d5f9565a9194cad0c4285f93700062d9595adb992ae68ff12800ab67a",
+		"357dc55de0c7e382c900fd6e320acc04146be01db6a8ce7210b7189bd664ea69362396b77fdc0d2634a552970843722066c3c15902ae5097e00ff53f1e116f1cd5352720113a837ab2452cafbde4d54085d9cf5d21ca613071551b25d52e69d6c81123872b6f19cd3bc1333edf0c52b94de23ba772cf82636cff4542540a7738d5b930",
+		"effaa245f065fbf82ac186839a249707c3bddf6d3fdda22d1b95a3c970379bcb5d31013a167509e9066273ab6e2123bc835b408b067d88f96addb550d96b6852dad38e320b9d940f86db74d398c770f462118b35d2724efa13da97194491d96dd37c3c09cbef665953f2ee85ec83d88b88d11547a6f911c8217cca46defa2751e7f3ad",
+	},
+	{
+		2048,
+		"e776b6028c7cd22a4d0ba182a8bf62205d2ef576467e838ed6f2529b85fba24a9a60bf80001410ec9eea6698cd537939fad4749edd484cb541aced55cd9bf54764d063f23f6f1e32e12958ba5cfeb1bf618ad094266d4fc3c968c2088f677454c288c67ba0dba337b9d91c7e1ba586dc9a5bc2d5e90c14f53a8863ac75655461cea8f9",
+		"879cf1fa2ea0e79126cb1063617a05b6ad9d0b696d0d757cf053439f60a99dd10173b961cd574288194b23ece278c330fbb8585485e74967f31352a8183aa782b2b22f26cdcadb61eed1a5bc144b8198fbb0c13abbf8e3192c145d0a5c21633b0ef86054f42809df823389ee40811a5910dcbd1018af31c3b43aa55201ed4edaac74fe",
+		"7b2945cb4fef70885cc5d78a87bf6f6207dd901ff239201351ffac04e1088a23e2c11a1ebffcea4d80447867b61badb1383d842d4e79645d48dd82ccba290769caa7af8eaa1bd78a2a5e6e94fbdab78d9c7b74e894879f6a515257ccf6f95056f4e25390f24f6b35ffbb74b766202569b1d797f2d4bd9d17524c720107f985f4ddc583",
+	},
+	{
+		2049,
+		"5f4d72f40d7a5f82b15ca2b2e44b1de3c2ef86c426c95c1af0b687952256303096de31d71d74103403822a2e0bc1eb193e7aecc9643a76b7bbc0c9f9c52e8783aae98764ca468962b5c2ec92f0c74eb5448d519713e09413719431c802f948dd5d90425a4ecdadece9eb178d80f26efccae630734dff63340285adec2aed3b51073ad3",
+		"9f29700902f7c86e514ddc4df1e3049f258b2472b6dd5267f61bf13983b78dd5f9a88abfefdfa1e00b418971f2b39c64ca621e8eb37fceac57fd0c8fc8e117d43b81447be22d5d8186f8f5919ba6bcc6846bd7d50726c06d245672c2ad4f61702c646499ee1173daa061ffe15bf45a631e2946d616a4c345822f1151284712f76b2b0e",
+		"2ea477c5515cc3dd606512ee72bb3e0e758cfae7232826f35fb98ca1bcbdf27316d8e9e79081a80b046b60f6a263616f33ca464bd78d79fa18200d06c7fc9bffd808cc4755277a7d5e09da0f29ed150f6537ea9bed946227ff184cc66a72a5f8c1e4bd8b04e81cf40fe6dc4427ad5678311a61f4ffc39d195589bdbc670f63ae70f4b6",
+	},
+	{
+		3072,
+		"b98cb0ff3623be03326b373de6b9095218513e64f1ee2edd2525c7ad1e5cffd29a3f6b0b978d6608335c09dc94ccf682f9951cdfc501bfe47b9c9189a6fc7b404d120258506341a6d802857322fbd20d3e5dae05b95c88793fa83db1cb08e7d8008d1599b6209d78336e24839724c191b2a52a80448306e0daa84a3fdb566661a37e11",
+		"044a0e7b172a312dc02a4c9a818c036ffa2776368d7f528268d2e6b5df19177022f302d0529e4174cc507c463671217975e81dab02b8fdeb0d7ccc7568dd22574c783a76be215441b32e91b9a904be8ea81f7a0afd14bad8ee7c8efc305ace5d3dd61b996febe8da4f56ca0919359a7533216e2999fc87ff7d8f176fbecb3d6f34278b",
+		"050df97f8c2ead654d9bb3ab8c9178edcd902a32f8495949feadcc1e0480c46b3604131bbd6e3ba573b6dd682fa0a63e5b165d39fc43a625d00207607a2bfeb65ff1d29292152e26b298868e3b87be95d6458f6f2ce6118437b632415abe6ad522874bcd79e4030a5e7bad2efa90a7a7c67e93f0a18fb28369d0a9329ab5c24134ccb0",
+	},
+	{
+		4097,
+		"9b4052b38f1c5fc8b1f9ff7ac7b27cd242487b3d890d15c96a1c25b8aa0fb99505f91b0b5600a11251652eacfa9497b31cd3c409ce2e45cfe6c0a016967316c426bd26f619eab5d70af9a418b845c608840390f361630bd497b1ab44019316357c61dbe091ce72fc16dc340ac3d6e009e050b3adac4b5b2c92e722cffdc46501531956",
+		"00df940cd36bb9fa7cbbc3556744e0dbc8191401afe70520ba292ee3ca80abbc606db4976cfdd266ae0abf667d9481831ff12e0caa268e7d3e57260c0824115a54ce595ccc897786d9dcbf495599cfd90157186a46ec800a6763f1c59e36197e9939e900809f7077c102f888caaf864b253bc41eea812656d46742e4ea42769f89b83f",
+		"aca51029626b55fda7117b42a7c211f8c6e9ba4fe5b7a8ca922f34299500ead8a897f66a400fed9198fd61dd2d58d382458e64e100128075fc54b860934e8de2e84170734b06e1d212a117100820dbc48292d148afa50567b8b84b1ec336ae10d40c8c975a624996e12de31abbe135d9d159375739c333798a80c64ae895e51e22f3ad",
+	},
+	{
+		8193,
+		"bab6c09cb8ce8cf459261398d2e7aef35700bf488116ceb94a36d0f5f1b7bc3bb2282aa69be089359ea1154b9a9286c4a56af4de975a9aa4a5c497654914d279bea60bb6d2cf7225a2fa0ff5ef56bbe4b149f3ed15860f78b4e2ad04e158e375c1e0c0b551cd7dfc82f1b155c11b6b3ed51ec9edb30d133653bb5709d1dbd55f4e1ff6",
+		"954a2a75420c8d6547e3ba5b98d963e6fa6491addc8c023189cc519821b4a1f5f03228648fd983aef045c2fa8290934b0866b615f585149587dda2299039965328835a2b18f1d63b7e300fc76ff260b571839fe44876a4eae66cbac8c67694411ed7e09df51068a22c6e67d6d3dd2cca8ff12e3275384006c80f4db68023f24eebba57",
+		"af1e0346e389b17c23200270a64aa4e1ead98c61695d917de7d5b00491c9b0f12f20a01d6d622edf3de026a4db4e4526225debb93c1237934d71c7340bb5916158cbdafe9ac3225476b6ab57a12357db3abbad7a26c6e66290e44034fb08a20a8d0ec264f309994d2810c49cfba6989d7abb095897459f5425adb48aba07c5fb3c83c0",
+	},
+	{
+		16384,
+		"f875d6646de28985646f34ee13be9a576fd515f76b5b0a26bb324735041ddde49d764c270176e53e97bdffa58d549073f2c660be0e81293767ed4e4929f9ad34bbb39a529334c57c4a381ffd2a6d4bfdbf1482651b172aa883cc13408fa67758a3e47503f93f87720a3177325f7823251b85275f64636a8f1d599c2e49722f42e93893",
+		"9e9fc4eb7cf081ea7c47d1807790ed211bfec56aa25bb7037784c13c4b707b0df9e601b101e4cf63a404dfe50f2e1865bb12edc8fca166579ce0c70dba5a5c0fc960ad6f3772183416a00bd29d4c6e651ea7620bb100c9449858bf14e1ddc9ecd35725581ca5b9160de04060045993d972571c3e8f71e9d0496bfa744656861b169d65",
+		"160e18b5878cd0df1c3af85eb25a0db5344d43a6fbd7a8ef4ed98d0714c3f7e160dc0b1f09caa35f2f417b9ef309dfe5ebd67f4c9507995a531374d099cf8ae317542e885ec6f589378864d3ea98716b3bbb65ef4ab5e0ab5bb298a501f19a41ec19af84a5e6b428ecd813b1a47ed91c9657c3fba11c406bc316768b58f6802c9e9b57",
+	},
+	{
+		31744,
+		"62b6960e1a44bcc1eb1a611a8d6235b6b4b78f32e7abc4fb4c6cdcce94895c47860cc51f2b0c28a7b77304bd55fe73af663c02d3f52ea053ba43431ca5bab7bfea2f5e9d7121770d88f70ae9649ea713087d1914f7f312147e247f87eb2d4ffef0ac978bf7b6579d57d533355aa20b8b77b13fd09748728a5cc327a8ec470f4013226f",
+		"efa53b389ab67c593dba624d898d0f7353ab99e4ac9d42302ee64cbf9939a4193a7258db2d9cd32a7a3ecfce46144114b15c2fcb68a618a976bd74515d47be08b628be420b5e830fade7c080e351a076fbc38641ad80c736c8a18fe3c66ce12f95c61c2462a9770d60d0f77115bbcd3782b593016a4e728d4c06cee4505cb0c08a42ec",
+		"39772aef80e0ebe60596361e45b061e8f417429d529171b6764468c22928e28e9759adeb797a3fbf771b1bcea30150a020e317982bf0d6e7d14dd9f064bc11025c25f31e81bd78a921db0174f03dd481d30e93fd8e90f8b2fee209f849f2d2a52f31719a490fb0ba7aea1e09814ee912eba111a9fde9d5c274185f7bae8ba85d300a2b",
+	},
+}
+
+func testInput(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		input := testInput(v.inputLen)
+		want := v.hash
+
+		sum := Sum256(input)
+		if got := hex.EncodeToString(sum[:]); got != want[:2*Size] {
+			t.Errorf("Sum256, len=%d: got %s, want %s", v.inputLen, got, want[:2*Size])
+		}
+
+		h, _ := New(len(want)/2, nil)
+		h.Write(input)
+		if got := hex.EncodeToString(h.Sum(nil)); got != want {
+			t.Errorf("New, len=%d: got %s, want %s", v.inputLen, got, want)
+		}
+
+		x, _ := NewXOF(nil)
+		x.Write(input)
+		out := make([]byte, len(want)/2)
+		x.Read(out)
+		if got := hex.EncodeToString(out); got != want {
+			t.Errorf("XOF, len=%d: got %s, want %s", v.inputLen, got, want)
+		}
+
+		k, err := New(len(v.keyedHash)/2, []byte(testVectorKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+		k.Write(input)
+		if got := hex.EncodeToString(k.Sum(nil)); got != v.keyedHash {
+			t.Errorf("keyed, len=%d: got %s, want %s", v.inputLen, got, v.keyedHash)
+		}
+
+		derived := make([]byte, len(v.deriveKey)/2)
+		DeriveKey(derived, testVectorContext, input)
+		if got := hex.EncodeToString(derived); got != v.deriveKey {
+			t.Errorf("DeriveKey, len=%d: got %s, want %s", v.inputLen, got, v.deriveKey)
+		}
+	}
+}
+
+// TestWriteSplits checks that the checksum does not depend on how the input
+// is split into writes, including writes large enough to be hashed
+// concurrently.
+func TestWriteSplits(t *testing.T) {
+	input := testInput(300*LeafSize + 17)
+	h, _ := New256(nil)
+	h.Write(input)
+	want := h.Sum(nil)
+
+	for _, step := range []int{1, 63, 64, 65, 1023, 1024, 1025, 4096, 5000, 70000, 100 * LeafSize} {
+		h.Reset()
+		for i := 0; i < len(input); i += step {
+			end := i + step
+			if end > len(input) {
+				end = len(input)
+			}
+			h.Write(input[i:end])
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("step=%d: got %x, want %x", step, got, want)
+		}
+	}
+
+	// Start with a partial leaf so that the large write is not aligned.
+	h.Reset()
+	h.Write(input[:3])
+	h.Write(input[3:])
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("unaligned write: got %x, want %x", got, want)
+	}
+}
+
+// TestHashChunks checks that the multi-chunk path agrees with hashing each
+// chunk on its own, including when the counter crosses 2^32.
+func TestHashChunks(t *testing.T) {
+	input := testInput(multiChunks * chunkLen)
+	k, _, _ := keyWords([]byte(testVectorKey))
+	for _, key := range []*[8]uint32{&iv, &k} {
+		for _, flags := range []uint32{0, flagKeyedHash, flagDeriveKeyMaterial} {
+			for _, counter := range []uint64{0, 4, 1<<32 - 2, 1<<40 + 8} {
+				var got, want [multiChunks][8]uint32
+				hashChunks(&got, input, counter, key, flags)
+				hashChunksGeneric(&want, input, counter, key, flags)
+				if got != want {
+					t.Errorf("flags=%d, counter=%d: got %x, want %x", flags, counter, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestKeySize(t *testing.T) {
+	if _, err := New256(make([]byte, 16)); err == nil {
+		t.Error("New256 accepted a 16-byte key")
+	}
+	if _, err := NewXOF(make([]byte, 33)); err == nil {
+		t.Error("NewXOF accepted a 33-byte key")
+	}
+	if _, err := New(0, nil); err == nil {
+		t.Error("New accepted a hash size of zero")
+	}
+}
+
+func TestXOFSeek(t *testing.T) {
+	x, _ := NewXOF(nil)
+	x.Write(testInput(2049))
+	want := make([]byte, 1000)
+	x.Clone().Read(want)
+
+	for _, pos := range []int64{0, 1, 63, 64, 65, 500, 999} {
+		if _, err := x.Seek(pos, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		got := make([]byte, len(want)-int(pos))
+		x.Read(got)
+		if !bytes.Equal(got, want[pos:]) {
+			t.Errorf("Seek(%d, io.SeekStart): output does not match", pos)
+		}
+	}
+
+	x.Seek(10, io.SeekStart)
+	x.Seek(-5, io.SeekCurrent)
+	got := make([]byte, 5)
+	x.Read(got)
+	if !bytes.Equal(got, want[5:10]) {
+		t.Errorf("Seek(-5, io.SeekCurrent): output does not match")
+	}
+	if _, err := x.Seek(0, io.SeekEnd); err == nil {
+		t.Error("Seek relative to the end succeeded")
+	}
+	if _, err := x.Seek(-1, io.SeekStart); err == nil {
+		t.Error("Seek to a negative position succeeded")
+	}
+}
+
+func TestXOFWriteAfterRead(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Write after Read did not panic")
+		}
+	}()
+	x, _ := NewXOF(nil)
+	x.Read(make([]byte, 1))
+	x.Write([]byte{0})
+}
+
+func TestOutboard(t *testing.T) {
+	for _, n := range []int{0, 1, 1024, 1025, 2048, 3 * LeafSize, 5*LeafSize + 7, 31744, 102400} {
+		content := testInput(n)
+		outboard, sum := EncodeOutboard(content)
+		if sum != Sum256(content) {
+			t.Errorf("len=%d: EncodeOutboard checksum %x, want %x", n, sum, Sum256(content))
+		}
+		leaves := chunkCount(uint64(n))
+		if len(outboard) != outboardHeaderSize+int(leaves-1)*2*Size {
+			t.Errorf("len=%d: outboard has %d bytes", n, len(outboard))
+		}
+
+		for i := uint64(0); i < leaves; i++ {
+			end := (i + 1) * LeafSize
+			if end > uint64(n) {
+				end = uint64(n)
+			}
+			leaf := content[i*LeafSize : end]
+			if err := VerifyLeaf(sum, outboard, i, leaf); err != nil {
+				t.Fatalf("len=%d: leaf %d rejected: %v", n, i, err)
+			}
+
+			bad := append([]byte{}, leaf...)
+			if len(bad) > 0 {
+				bad[len(bad)/2] ^= 1
+				if VerifyLeaf(sum, outboard, i, bad) == nil {
+					t.Fatalf("len=%d: corrupted leaf %d accepted", n, i)
+				}
+			}
+			if leaves > 1 {
+				corrupted := append([]byte{}, outboard...)
+				// The root node is on the path to every leaf.
+				corrupted[outboardHeaderSize+Size-1] ^= 1
+				if VerifyLeaf(sum, corrupted, i, leaf) == nil {
+					t.Fatalf("len=%d: corrupted outboard accepted", n)
+				}
+				if VerifyLeaf(sum, outboard, (i+1)%leaves, leaf) == nil && len(leaf) == LeafSize {
+					t.Fatalf("len=%d: leaf %d accepted at index %d", n, i, (i+1)%leaves)
+				}
+			}
+		}
+	}
+}
+
+func benchmarkSum(b *testing.B, size int) {
+	data := make([]byte, size)
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Sum256(data)
+	}
+}
+
+func BenchmarkSum256_64(b *testing.B)  { benchmarkSum(b, 64) }
+func BenchmarkSum256_1K(b *testing.B)  { benchmarkSum(b, 1024) }
+func BenchmarkSum256_8K(b *testing.B)  { benchmarkSum(b, 8*1024) }
+func BenchmarkSum256_1M(b *testing.B)  { benchmarkSum(b, 1024*1024) }
+func BenchmarkSum256_16M(b *testing.B) { benchmarkSum(b, 16*1024*1024) }
@@ -0,0 +1,207 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package blake3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"sync"
+)
+
+// parallelThreshold is the size of the subtrees from which the two halves
+// are hashed on separate goroutines.
+const parallelThreshold = 64 * chunkLen
+
+// subtreeCV returns the chaining value of the non-root subtree holding p,
+// whose length is a power-of-two multiple of chunkLen, starting at chunk
+// counter.
+func subtreeCV(p []byte, counter uint64, key *[8]uint32, flags uint32) [8]uint32 {
+	if len(p) <= chunkLen {
+		return chunkCV(p, counter, key, flags)
+	}
+	left, right := subtreeChildren(p, counter, key, flags)
+	o := parentOutput(&left, &right, key, flags)
+	return o.chainingValue()
+}
+
+// subtreeChildren returns the chaining values of the two halves of the
+// subtree holding p, which are hashed concurrently for large subtrees. The
+// chunks of subtrees of multiChunks chunks are hashed with a single call to
+// hashChunks.
+func subtreeChildren(p []byte, counter uint64, key *[8]uint32, flags uint32) (left, right [8]uint32) {
+	if len(p) == multiChunks*chunkLen {
+		var cvs [multiChunks][8]uint32
+		hashChunks(&cvs, p, counter, key, flags)
+		l := parentOutput(&cvs[0], &cvs[1], key, flags)
+		r := parentOutput(&cvs[2], &cvs[3], key, flags)
+		return l.chainingValue(), r.chainingValue()
+	}
+
+	half := len(p) / 2
+	rightCounter := counter + uint64(half/chunkLen)
+	if len(p) < parallelThreshold {
+		return subtreeCV(p[:half], counter, key, flags), subtreeCV(p[half:], rightCounter, key, flags)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		left = subtreeCV(p[:half], counter, key, flags)
+	}()
+	right = subtreeCV(p[half:], rightCounter, key, flags)
+	wg.Wait()
+	return
+}
+
+// ErrInvalidLeaf is returned by VerifyLeaf if a leaf does not match the
+// outboard tree and the expected checksum.
+var ErrInvalidLeaf = errors.New("blake3: leaf does not match the checksum")
+
+// outboardHeaderSize is the size of the little-endian content length that
+// starts an outboard encoding.
+const outboardHeaderSize = 8
+
+// EncodeOutboard returns the BLAKE3 checksum of content along with its
+// outboard tree, as defined by the Bao format: the content length as an
+// 8-byte little-endian integer followed by the chaining values of the
+// children of every parent node of the tree, in pre-order. The outboard tree
+// is about 1/16 of the size of the content, and allows verifying any leaf of
+// the content independently with VerifyLeaf.
+func EncodeOutboard(content []byte) (outboard []byte, sum [Size]byte) {
+	chunks := chunkCount(uint64(len(content)))
+	outboard = make([]byte, outboardHeaderSize, outboardHeaderSize+(chunks-1)*2*Size)
+	binary.LittleEndian.PutUint64(outboard, uint64(len(content)))
+	outboard, root := appendParents(outboard, content, 0)
+	out := root.rootBlock(0)
+	copy(sum[:], out[:Size])
+	return outboard, sum
+}
+
+// appendParents appends the parent nodes of the subtree holding p, which
+// starts at chunk counter, to outboard in pre-order, and returns the pending
+// output of the root of the subtree.
+func appendParents(outboard, p []byte, counter uint64) ([]byte, output) {
+	if len(p) <= chunkLen {
+		c := newChunkState(&iv, counter, 0)
+		c.update(p)
+		return outboard, c.output()
+	}
+
+	// Reserve room for this node, which precedes its descendants.
+	at := len(outboard)
+	outboard = append(outboard, make([]byte, 2*Size)...)
+	split := leftSubtreeSize(uint64(len(p)))
+	outboard, lo := appendParents(outboard, p[:split], counter)
+	outboard, ro := appendParents(outboard, p[split:], counter+split/chunkLen)
+
+	left, right := lo.chainingValue(), ro.chainingValue()
+	putCV(outboard[at:], &left)
+	putCV(outboard[at+Size:], &right)
+	return outboard, parentOutput(&left, &right, &iv, 0)
+}
+
+// VerifyLeaf checks that leaf is the leaf at index of the content with the
+// given BLAKE3 checksum and outboard tree, as returned by EncodeOutboard.
+// Leaves are the chunks of the BLAKE3 tree: every leaf is LeafSize bytes
+// long, except for the last one, which may be shorter. Only the outboard nodes
+// on the path to the leaf are read, so a large content can be verified leaf
+// by leaf as it is received.
+func VerifyLeaf(sum [Size]byte, outboard []byte, index uint64, leaf []byte) error {
+	if len(outboard) < outboardHeaderSize {
+		return ErrInvalidLeaf
+	}
+	length := binary.LittleEndian.Uint64(outboard)
+	chunks := chunkCount(length)
+	if index >= chunks || uint64(len(outboard)) != outboardHeaderSize+(chunks-1)*2*Size {
+		return ErrInvalidLeaf
+	}
+	if want := chunkLength(length, index); uint64(len(leaf)) != want {
+		return ErrInvalidLeaf
+	}
+	if chunks == 1 {
+		if Sum256(leaf) != sum {
+			return ErrInvalidLeaf
+		}
+		return nil
+	}
+
+	// Walk down the tree from the root, checking every parent node on the
+	// path against the chaining value its parent committed to.
+	nodes := outboard[outboardHeaderSize:]
+	var want [8]uint32
+	lo, size := uint64(0), length
+	for root := true; ; root = false {
+		if len(nodes) < 2*Size {
+			return ErrInvalidLeaf
+		}
+		var left, right [8]uint32
+		for i := range left {
+			left[i] = binary.LittleEndian.Uint32(nodes[4*i:])
+			right[i] = binary.LittleEndian.Uint32(nodes[Size+4*i:])
+		}
+		o := parentOutput(&left, &right, &iv, 0)
+		if root {
+			out := o.rootBlock(0)
+			if !bytes.Equal(out[:Size], sum[:]) {
+				return ErrInvalidLeaf
+			}
+		} else if o.chainingValue() != want {
+			return ErrInvalidLeaf
+		}
+
+		split := leftSubtreeSize(size)
+		if index < lo+split/chunkLen {
+			want, size = left, split
+			nodes = nodes[2*Size:]
+		} else {
+			// Skip the parent nodes of the left subtree.
+			want, size = right, size-split
+			nodes = nodes[2*Size+(split/chunkLen-1)*2*Size:]
+			lo += split / chunkLen
+		}
+		if size <= chunkLen {
+			break
+		}
+	}
+	if chunkCV(leaf, index, &iv, 0) != want {
+		return ErrInvalidLeaf
+	}
+	return nil
+}
+
+// chunkCount returns the number of chunks of a content of the given length.
+// The empty content has a single, empty chunk.
+func chunkCount(length uint64) uint64 {
+	if length == 0 {
+		return 1
+	}
+	return (length + chunkLen - 1) / chunkLen
+}
+
+// chunkLength returns the length of the chunk at index of a content of the
+// given length.
+func chunkLength(length, index uint64) uint64 {
+	if rest := length - index*chunkLen; rest < chunkLen {
+		return rest
+	}
+	return chunkLen
+}
+
+// leftSubtreeSize returns the size of the left subtree of a tree holding
+// more than chunkLen bytes: the largest power-of-two number of chunks that
+// leaves at least one byte for the right subtree.
+func leftSubtreeSize(length uint64) uint64 {
+	fullChunks := (length - 1) / chunkLen
+	return chunkLen << uint(bits.Len64(fullChunks)-1)
+}
+
+func putCV(b []byte, cv *[8]uint32) {
+	for i, w := range cv {
+		binary.LittleEndian.PutUint32(b[4*i:], w)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package blake3
+
+import (
+	"errors"
+	"io"
+)
+
+// XOF defines the interface to hash functions that
+// support arbitrary-length output.
+type XOF interface {
+	// Write absorbs more data into the hash's state. It panics if called
+	// after Read or Seek.
+	io.Writer
+
+	// Read reads more output from the hash. It never returns an error.
+	io.Reader
+
+	// Seek sets the position of the next Read in the output stream.
+	// Seeking relative to the end is not supported, as the output is
+	// unbounded.
+	io.Seeker
+
+	// Clone returns a copy of the XOF in its current state.
+	Clone() XOF
+
+	// Reset resets the XOF to its initial state.
+	Reset()
+}
+
+// NewXOF creates a new variable-output-length hash. A non-nil key turns the
+// hash into a MAC. The key must be nil or 32 bytes long.
+func NewXOF(key []byte) (XOF, error) {
+	k, flags, err := keyWords(key)
+	if err != nil {
+		return nil, err
+	}
+	return &xof{d: *newDigest(Size, k, flags)}, nil
+}
+
+type xof struct {
+	d    digest
+	root *output
+	pos  uint64
+}
+
+func (x *xof) Write(p []byte) (n int, err error) {
+	if x.root != nil {
+		panic("blake3: write to XOF after read")
+	}
+	return x.d.Write(p)
+}
+
+func (x *xof) finalize() {
+	if x.root == nil {
+		o := x.d.rootOutput()
+		x.root = &o
+	}
+}
+
+func (x *xof) Read(p []byte) (n int, err error) {
+	x.finalize()
+	n = len(p)
+	for len(p) > 0 {
+		out := x.root.rootBlock(x.pos / blockLen)
+		c := copy(p, out[x.pos%blockLen:])
+		x.pos += uint64(c)
+		p = p[c:]
+	}
+	return
+}
+
+func (x *xof) Seek(offset int64, whence int) (int64, error) {
+	x.finalize()
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += int64(x.pos)
+	default:
+		return int64(x.pos), errors.New("blake3: invalid whence")
+	}
+	if offset < 0 {
+		return int64(x.pos), errors.New("blake3: negative position")
+	}
+	x.pos = uint64(offset)
+	return offset, nil
+}
+
+func (x *xof) Clone() XOF {
+	c := *x
+	if x.root != nil {
+		root := *x.root
+		c.root = &root
+	}
+	return &c
+}
+
+func (x *xof) Reset() {
+	x.d.Reset()
+	x.root = nil
+	x.pos = 0
+}
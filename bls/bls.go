@@ -0,0 +1,323 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bls implements BLS signatures over the bn256 bilinear group, with
+// signatures in G₁ and public keys in G₂, following the proof of possession
+// scheme of draft-irtf-cfrg-bls-signature-05.
+//
+// Signatures on any messages can be aggregated into a single signature, and
+// verified with AggregateVerify. Signatures on the same message can also be
+// checked against an aggregate of the public keys, with FastAggregateVerify,
+// which is much faster but only secure if every public key was checked with
+// VerifyPossession first. Otherwise, a rogue key chosen as a function of the
+// other public keys can forge an aggregate signature.
+//
+// Messages are hashed to G₁ with HashToG1, which runs in constant time. The
+// bn256 arithmetic is not constant time, so signing with a private key is not
+// either. See the bn256 package for its security level.
+package bls // import "golang.org/x/github.com/benchlab/bench-crypto/bls"
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+
+	cryptorand "github.com/benchlab/bench-crypto/rand"
+	"github.com/benchlab/bench-crypto/sha256"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/bn256"
+	"golang.org/x/github.com/benchlab/bench-crypto/hkdf"
+)
+
+const (
+	// PrivateKeySize is the size, in bytes, of encoded private keys.
+	PrivateKeySize = 32
+	// PublicKeySize is the size, in bytes, of encoded public keys, as
+	// produced by bn256.G2.Marshal.
+	PublicKeySize = 128
+	// SignatureSize is the size, in bytes, of encoded signatures and proofs
+	// of possession, as produced by bn256.G1.Marshal.
+	SignatureSize = 64
+	// MinSeedSize is the minimum size, in bytes, of seeds passed to
+	// NewKeyFromSeed.
+	MinSeedSize = 32
+)
+
+// Domain separation tags of the ciphersuite, named after the conventions of
+// draft-irtf-cfrg-bls-signature-05 for the hash to G₁ of HashToG1.
+const (
+	signatureDST  = "BLS_SIG_BN256G1_XMD:SHA-256_SVDW_RO_POP_"
+	possessionDST = "BLS_POP_BN256G1_XMD:SHA-256_SVDW_RO_POP_"
+)
+
+// g2Generator is the generator of G₂, against which signatures are paired.
+var g2Generator = new(bn256.G2).ScalarBaseMult(big.NewInt(1))
+
+// PublicKey is a BLS public key, an element of G₂ other than the identity.
+type PublicKey struct {
+	p       *bn256.G2
+	encoded []byte
+}
+
+// PrivateKey is a BLS private key.
+type PrivateKey struct {
+	x   *big.Int
+	pub *PublicKey
+}
+
+// Signature is a BLS signature, an aggregate of signatures, or a proof of
+// possession, all of which are elements of G₁.
+type Signature struct {
+	p       *bn256.G1
+	encoded []byte
+}
+
+// GenerateKey generates a private key using entropy from rand. If rand is
+// nil, github.com/benchlab/bench-crypto/rand.Reader will be used.
+func GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	x, p, err := bn256.RandomG2(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{x: x, pub: newPublicKey(p)}, nil
+}
+
+// NewKeyFromSeed derives a private key from seed, which must be at least
+// MinSeedSize bytes of secret, uniformly random key material, with the
+// HKDF-SHA256 based KeyGen procedure of draft-irtf-cfrg-bls-signature-05,
+// Section 2.3, and an empty key_info.
+func NewKeyFromSeed(seed []byte) (*PrivateKey, error) {
+	if len(seed) < MinSeedSize {
+		return nil, errors.New("bls: seed too short")
+	}
+	// L = ceil(3 * ceil(log2(r)) / 16) bytes, which makes the bias of the
+	// reduction negligible.
+	const L = 48
+	ikm := append(append([]byte{}, seed...), 0)
+	salt := []byte("BLS-SIG-KEYGEN-SALT-")
+	okm := make([]byte, L)
+	for {
+		h := sha256.Sum256(salt)
+		salt = h[:]
+		prk := hkdf.Extract(sha256.New, ikm, salt)
+		if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, []byte{0, L}), okm); err != nil {
+			panic("bls: internal error: " + err.Error())
+		}
+		x := new(big.Int).SetBytes(okm)
+		x.Mod(x, bn256.Order)
+		if x.Sign() != 0 {
+			return newPrivateKey(x), nil
+		}
+	}
+}
+
+// NewPrivateKey returns the private key with the big-endian 32-byte encoding
+// key. It returns an error if key is zero or not less than the group order.
+func NewPrivateKey(key []byte) (*PrivateKey, error) {
+	if len(key) != PrivateKeySize {
+		return nil, errors.New("bls: bad private key length")
+	}
+	x := new(big.Int).SetBytes(key)
+	if x.Sign() == 0 || x.Cmp(bn256.Order) >= 0 {
+		return nil, errors.New("bls: invalid private key")
+	}
+	return newPrivateKey(x), nil
+}
+
+func newPrivateKey(x *big.Int) *PrivateKey {
+	return &PrivateKey{x: x, pub: newPublicKey(new(bn256.G2).ScalarBaseMult(x))}
+}
+
+// Bytes returns the big-endian 32-byte encoding of priv.
+func (priv *PrivateKey) Bytes() []byte {
+	b := make([]byte, PrivateKeySize)
+	xBytes := priv.x.Bytes()
+	copy(b[PrivateKeySize-len(xBytes):], xBytes)
+	return b
+}
+
+// PublicKey returns the public key corresponding to priv.
+func (priv *PrivateKey) PublicKey() *PublicKey {
+	return priv.pub
+}
+
+// Sign returns the signature of msg by priv.
+func (priv *PrivateKey) Sign(msg []byte) *Signature {
+	return newSignature(new(bn256.G1).ScalarMult(HashToG1(msg, []byte(signatureDST)), priv.x))
+}
+
+// ProvePossession returns a proof of possession of priv, a signature of its
+// public key under a separate domain, to be checked with VerifyPossession
+// before the public key is aggregated.
+func (priv *PrivateKey) ProvePossession() *Signature {
+	h := HashToG1(priv.pub.encoded, []byte(possessionDST))
+	return newSignature(new(bn256.G1).ScalarMult(h, priv.x))
+}
+
+// newPublicKey returns the public key p. Marshal converts p to affine
+// coordinates, after which the bn256 operations do not modify it.
+func newPublicKey(p *bn256.G2) *PublicKey {
+	return &PublicKey{p: p, encoded: p.Marshal()}
+}
+
+// ParsePublicKey parses a public key encoded with PublicKey.Bytes. It returns
+// an error if b is not the canonical encoding of an element of G₂ other than
+// the identity.
+func ParsePublicKey(b []byte) (*PublicKey, error) {
+	p, ok := new(bn256.G2).Unmarshal(b)
+	if !ok {
+		return nil, errors.New("bls: invalid public key encoding")
+	}
+	pub := newPublicKey(p)
+	if !bytes.Equal(pub.encoded, b) {
+		return nil, errors.New("bls: non-canonical public key encoding")
+	}
+	if pub.isIdentity() {
+		return nil, errors.New("bls: public key is the identity")
+	}
+	// Unlike G₁, G₂ is a subgroup of the points on its curve.
+	if !isIdentity(new(bn256.G2).ScalarMult(p, bn256.Order).Marshal()) {
+		return nil, errors.New("bls: public key is not in G2")
+	}
+	return pub, nil
+}
+
+// Bytes returns the PublicKeySize-byte encoding of pub.
+func (pub *PublicKey) Bytes() []byte {
+	return append([]byte{}, pub.encoded...)
+}
+
+func (pub *PublicKey) isIdentity() bool {
+	return isIdentity(pub.encoded)
+}
+
+// isIdentity reports whether b is the bn256 encoding of the identity, which
+// is all zeroes.
+func isIdentity(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func newSignature(p *bn256.G1) *Signature {
+	return &Signature{p: p, encoded: p.Marshal()}
+}
+
+// ParseSignature parses a signature or proof of possession encoded with
+// Signature.Bytes. It returns an error if b is not the canonical encoding of
+// an element of G₁.
+func ParseSignature(b []byte) (*Signature, error) {
+	p, ok := new(bn256.G1).Unmarshal(b)
+	if !ok {
+		return nil, errors.New("bls: invalid signature encoding")
+	}
+	sig := newSignature(p)
+	if !bytes.Equal(sig.encoded, b) {
+		return nil, errors.New("bls: non-canonical signature encoding")
+	}
+	return sig, nil
+}
+
+// Bytes returns the SignatureSize-byte encoding of sig.
+func (sig *Signature) Bytes() []byte {
+	return append([]byte{}, sig.encoded...)
+}
+
+// Verify reports whether sig is a valid signature of msg by pub.
+func Verify(pub *PublicKey, msg []byte, sig *Signature) bool {
+	return AggregateVerify([]*PublicKey{pub}, [][]byte{msg}, sig)
+}
+
+// VerifyPossession reports whether proof is a valid proof of possession of
+// the private key of pub, as returned by PrivateKey.ProvePossession.
+func VerifyPossession(pub *PublicKey, proof *Signature) bool {
+	if pub.isIdentity() {
+		return false
+	}
+	h := HashToG1(pub.encoded, []byte(possessionDST))
+	return pairingCheck(proof, []*bn256.G1{h}, []*PublicKey{pub})
+}
+
+// AggregateSignatures returns the aggregate of sigs, which must not be empty.
+// The aggregate is verified with AggregateVerify, or FastAggregateVerify if
+// all signatures are of the same message.
+func AggregateSignatures(sigs []*Signature) (*Signature, error) {
+	if len(sigs) == 0 {
+		return nil, errors.New("bls: no signatures to aggregate")
+	}
+	sum := sigs[0].p
+	for _, sig := range sigs[1:] {
+		sum = new(bn256.G1).Add(sum, sig.p)
+	}
+	return newSignature(sum), nil
+}
+
+// AggregatePublicKeys returns the aggregate of pubs, which must not be empty,
+// against which FastAggregateVerify checks signatures. It returns an error if
+// the aggregate is the identity, which is impossible for public keys whose
+// proofs of possession are valid.
+func AggregatePublicKeys(pubs []*PublicKey) (*PublicKey, error) {
+	if len(pubs) == 0 {
+		return nil, errors.New("bls: no public keys to aggregate")
+	}
+	sum := pubs[0].p
+	for _, pub := range pubs[1:] {
+		sum = new(bn256.G2).Add(sum, pub.p)
+	}
+	agg := newPublicKey(sum)
+	if agg.isIdentity() {
+		return nil, errors.New("bls: aggregate public key is the identity")
+	}
+	return agg, nil
+}
+
+// FastAggregateVerify reports whether sig is an aggregate of signatures of
+// msg by each of pubs. Each public key must have been checked with
+// VerifyPossession, or this is vulnerable to rogue key attacks.
+func FastAggregateVerify(pubs []*PublicKey, msg []byte, sig *Signature) bool {
+	agg, err := AggregatePublicKeys(pubs)
+	if err != nil {
+		return false
+	}
+	return Verify(agg, msg, sig)
+}
+
+// AggregateVerify reports whether sig is an aggregate of signatures of each
+// of msgs by the public key with the same index in pubs. Messages need not be
+// distinct, as in the proof of possession scheme.
+func AggregateVerify(pubs []*PublicKey, msgs [][]byte, sig *Signature) bool {
+	if len(pubs) == 0 || len(pubs) != len(msgs) {
+		return false
+	}
+	hs := make([]*bn256.G1, len(msgs))
+	for i, msg := range msgs {
+		if pubs[i].isIdentity() {
+			return false
+		}
+		hs[i] = HashToG1(msg, []byte(signatureDST))
+	}
+	return pairingCheck(sig, hs, pubs)
+}
+
+// pairingCheck reports whether e(sig, g₂) is the product of e(hs[i], pubs[i]).
+func pairingCheck(sig *Signature, hs []*bn256.G1, pubs []*PublicKey) bool {
+	var rhs *bn256.GT
+	for i, h := range hs {
+		e := bn256.Pair(h, pubs[i].p)
+		if rhs == nil {
+			rhs = e
+		} else {
+			rhs.Add(rhs, e)
+		}
+	}
+	lhs := bn256.Pair(sig.p, g2Generator)
+	return bytes.Equal(lhs.Marshal(), rhs.Marshal())
+}
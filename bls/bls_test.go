@@ -0,0 +1,329 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bls
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/benchlab/bench-crypto/rand"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/bn256"
+)
+
+// putBig writes x as a big-endian integer filling b.
+func putBig(b []byte, x *big.Int) {
+	xBytes := x.Bytes()
+	copy(b[len(b)-len(xBytes):], xBytes)
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func generateKeys(t *testing.T, n int) []*PrivateKey {
+	t.Helper()
+	keys := make([]*PrivateKey, n)
+	for i := range keys {
+		var err error
+		if keys[i], err = GenerateKey(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return keys
+}
+
+func TestVectors(t *testing.T) {
+	seed := decodeHex(t, "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	priv, err := NewKeyFromSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(priv.Bytes()), "6c79f6dc25b724e59cd7579af62446038ec1be99b63522380e31c07eb392ab64"; got != want {
+		t.Fatalf("NewKeyFromSeed = %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		msg, sig string
+	}{
+		{"", "71ad4ed93cd8638eedda4b271fccc7b3cf812c9e032f18d90db95065edf3669d7713cce9a6863bf06dc7eeecea558f1dfc6c8443445e9125a53a68e025a9dd86"},
+		{"hello world", "413550c5c91478988c0402464af4c1b094742c2bf8e110935da3827e834374e76fa657afb0eb8e1391283fd33b2df85f374b6949fc64dc10603cf34a535d7406"},
+	}
+	pub, err := ParsePublicKey(priv.PublicKey().Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		sig := priv.Sign([]byte(tt.msg))
+		if got := hex.EncodeToString(sig.Bytes()); got != tt.sig {
+			t.Errorf("Sign(%q) = %s, want %s", tt.msg, got, tt.sig)
+		}
+		parsed, err := ParseSignature(decodeHex(t, tt.sig))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(pub, []byte(tt.msg), parsed) {
+			t.Errorf("Verify(%q) failed", tt.msg)
+		}
+		if Verify(pub, []byte(tt.msg+"x"), parsed) {
+			t.Errorf("Verify(%q) accepted the signature of a different message", tt.msg)
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	keys := generateKeys(t, 2)
+	msg := []byte("test message")
+	sig := keys[0].Sign(msg)
+	if !Verify(keys[0].PublicKey(), msg, sig) {
+		t.Error("valid signature rejected")
+	}
+	if Verify(keys[1].PublicKey(), msg, sig) {
+		t.Error("signature accepted under the wrong key")
+	}
+	if Verify(keys[0].PublicKey(), msg, keys[0].ProvePossession()) {
+		t.Error("proof of possession accepted as a signature")
+	}
+
+	priv, err := NewPrivateKey(keys[0].Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv.Sign(msg).Bytes(), sig.Bytes()) {
+		t.Error("signature changed after round-tripping the private key")
+	}
+}
+
+func TestNewKeyFromSeed(t *testing.T) {
+	if _, err := NewKeyFromSeed(make([]byte, MinSeedSize-1)); err == nil {
+		t.Error("short seed accepted")
+	}
+	seed := decodeHex(t, "c00f12b0e0f6a4aaf7c1c8f4bc4b8e2e5d9c0f7a2b4c6d8e0f1a3b5c7d9e1f3a")
+	priv, err := NewKeyFromSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(priv.Bytes()), "85c5c95cba8de9e04f0d5eb6cc4a9289d3d1454f72290f0ac9d54652b1bcf07d"; got != want {
+		t.Errorf("NewKeyFromSeed = %s, want %s", got, want)
+	}
+}
+
+func TestInvalidPrivateKeys(t *testing.T) {
+	order := make([]byte, PrivateKeySize)
+	putBig(order, bn256.Order)
+	for _, key := range [][]byte{
+		make([]byte, PrivateKeySize),
+		order,
+		bytes.Repeat([]byte{0xff}, PrivateKeySize),
+		make([]byte, PrivateKeySize-1),
+	} {
+		if _, err := NewPrivateKey(key); err == nil {
+			t.Errorf("NewPrivateKey(%x) succeeded", key)
+		}
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub := generateKeys(t, 1)[0].PublicKey().Bytes()
+	if _, err := ParsePublicKey(pub); err != nil {
+		t.Fatal(err)
+	}
+
+	// A point on the twist that is not in G₂.
+	notInG2 := decodeHex(t, "00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000005411a13e9a6cff0d495c65f2a5abff3c932124c456fabf273cb6a69ee115a10db2a4b207aedfde4226b4c8e89436baed7e637d2bb51a7cfb0a721bdddd89da10f")
+	if _, ok := new(bn256.G2).Unmarshal(notInG2); !ok {
+		t.Fatal("test point is not on the twist")
+	}
+
+	// The generator of G₂ with p added to its last coordinate, which bn256
+	// reduces.
+	nonCanonical := g2Generator.Marshal()
+	y := new(big.Int).SetBytes(nonCanonical[96:])
+	putBig(nonCanonical[96:], y.Add(y, p))
+
+	offCurve := append([]byte{}, pub...)
+	offCurve[PublicKeySize-1] ^= 1
+
+	for name, b := range map[string][]byte{
+		"identity":      make([]byte, PublicKeySize),
+		"not in G2":     notInG2,
+		"off curve":     offCurve,
+		"short":         pub[:PublicKeySize-1],
+		"non-canonical": nonCanonical,
+	} {
+		if _, err := ParsePublicKey(b); err == nil {
+			t.Errorf("%s public key accepted", name)
+		}
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	sig := generateKeys(t, 1)[0].Sign([]byte("message")).Bytes()
+	if _, err := ParseSignature(sig); err != nil {
+		t.Fatal(err)
+	}
+
+	// (1, 2) + (0, p) encodes the generator of G₁ non-canonically.
+	nonCanonical := make([]byte, SignatureSize)
+	nonCanonical[31] = 1
+	putBig(nonCanonical[32:], new(big.Int).Add(p, big.NewInt(2)))
+	offCurve := append([]byte{}, sig...)
+	offCurve[SignatureSize-1] ^= 1
+	for name, b := range map[string][]byte{
+		"non-canonical": nonCanonical,
+		"off curve":     offCurve,
+		"long":          append(sig, 0),
+	} {
+		if _, err := ParseSignature(b); err == nil {
+			t.Errorf("%s signature accepted", name)
+		}
+	}
+}
+
+func TestFastAggregateVerify(t *testing.T) {
+	keys := generateKeys(t, 4)
+	msg := []byte("same message")
+	var pubs []*PublicKey
+	var sigs []*Signature
+	for _, k := range keys {
+		if !VerifyPossession(k.PublicKey(), k.ProvePossession()) {
+			t.Fatal("valid proof of possession rejected")
+		}
+		pubs = append(pubs, k.PublicKey())
+		sigs = append(sigs, k.Sign(msg))
+	}
+	agg, err := AggregateSignatures(sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !FastAggregateVerify(pubs, msg, agg) {
+		t.Error("valid aggregate signature rejected")
+	}
+	if FastAggregateVerify(pubs[:3], msg, agg) {
+		t.Error("aggregate signature accepted with a missing public key")
+	}
+	if FastAggregateVerify(pubs, []byte("other message"), agg) {
+		t.Error("aggregate signature accepted for the wrong message")
+	}
+	if FastAggregateVerify(nil, msg, agg) {
+		t.Error("aggregate signature accepted with no public keys")
+	}
+
+	// Aggregating the same signature twice doubles it.
+	double, err := AggregateSignatures([]*Signature{sigs[0], sigs[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !FastAggregateVerify([]*PublicKey{pubs[0], pubs[0]}, msg, double) {
+		t.Error("doubled signature rejected")
+	}
+
+	if _, err := AggregateSignatures(nil); err == nil {
+		t.Error("empty aggregate of signatures succeeded")
+	}
+	if _, err := AggregatePublicKeys(nil); err == nil {
+		t.Error("empty aggregate of public keys succeeded")
+	}
+}
+
+func TestAggregateVerify(t *testing.T) {
+	keys := generateKeys(t, 3)
+	msgs := [][]byte{[]byte("first"), []byte("second"), []byte("first")}
+	var pubs []*PublicKey
+	var sigs []*Signature
+	for i, k := range keys {
+		pubs = append(pubs, k.PublicKey())
+		sigs = append(sigs, k.Sign(msgs[i]))
+	}
+	agg, err := AggregateSignatures(sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !AggregateVerify(pubs, msgs, agg) {
+		t.Error("valid aggregate signature rejected")
+	}
+	swapped := [][]byte{msgs[1], msgs[0], msgs[2]}
+	if AggregateVerify(pubs, swapped, agg) {
+		t.Error("aggregate signature accepted with swapped messages")
+	}
+	if AggregateVerify(pubs[:2], msgs, agg) {
+		t.Error("aggregate signature accepted with mismatched lengths")
+	}
+}
+
+func TestRogueKey(t *testing.T) {
+	victim := generateKeys(t, 1)[0].PublicKey()
+
+	// The attacker picks a key β and publishes β·g₂ - victim, so that the
+	// aggregate with the victim's key is β·g₂.
+	beta, betaG2, err := bn256.RandomG2(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minusVictim := new(bn256.G2).ScalarMult(victim.p, new(big.Int).Sub(bn256.Order, big.NewInt(1)))
+	rogue, err := ParsePublicKey(new(bn256.G2).Add(betaG2, minusVictim).Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("the victim agrees")
+	forged := newSignature(new(bn256.G1).ScalarMult(HashToG1(msg, []byte(signatureDST)), beta))
+	if !FastAggregateVerify([]*PublicKey{victim, rogue}, msg, forged) {
+		t.Fatal("forgery does not pass FastAggregateVerify")
+	}
+
+	// Without the private key of the rogue key, the attacker cannot prove
+	// possession of it; the closest it can do is sign with β.
+	h := HashToG1(rogue.encoded, []byte(possessionDST))
+	proof := newSignature(new(bn256.G1).ScalarMult(h, beta))
+	if VerifyPossession(rogue, proof) {
+		t.Error("proof of possession of a rogue key accepted")
+	}
+}
+
+func TestVerifyIdentity(t *testing.T) {
+	identity, _ := new(bn256.G2).Unmarshal(make([]byte, PublicKeySize))
+	pub := newPublicKey(identity)
+	sig, err := ParseSignature(make([]byte, SignatureSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Verify(pub, []byte("message"), sig) {
+		t.Error("identity signature accepted under the identity public key")
+	}
+	if VerifyPossession(pub, sig) {
+		t.Error("identity proof of possession accepted")
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	priv, err := GenerateKey(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := []byte("hello world")
+	for i := 0; i < b.N; i++ {
+		priv.Sign(msg)
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	priv, err := GenerateKey(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := []byte("hello world")
+	sig := priv.Sign(msg)
+	for i := 0; i < b.N; i++ {
+		Verify(priv.PublicKey(), msg, sig)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bls
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// fieldElement is an element of GF(p), the field G₁ is defined over, as four
+// little-endian 64-bit limbs in Montgomery form, that is x·2²⁵⁶ mod p. Unlike
+// the math/big arithmetic of bn256, all operations on it run in constant
+// time, so that hashing to G₁ does not leak the message.
+type fieldElement [4]uint64
+
+func bigFromBase10(s string) *big.Int {
+	n, _ := new(big.Int).SetString(s, 10)
+	return n
+}
+
+// p is the bn256 base field prime. Note that p > 2²⁵⁵.
+var p = bigFromBase10("65000549695646603732796438742359905742825358107623003571877145026864184071783")
+
+var (
+	pLimbs = limbsFromBig(p)
+	// pInv is -p⁻¹ mod 2⁶⁴.
+	pInv = func() uint64 {
+		// Newton's iteration doubles the number of correct bits each step.
+		inv := uint64(1)
+		for i := 0; i < 6; i++ {
+			inv *= 2 - pLimbs[0]*inv
+		}
+		return -inv
+	}()
+
+	// rSquared and rCubed are 2⁵¹² mod p and 2⁷⁶⁸ mod p, which take
+	// integers to and beyond Montgomery form.
+	rSquared = limbsFromBig(new(big.Int).Exp(big.NewInt(2), big.NewInt(512), p))
+	rCubed   = limbsFromBig(new(big.Int).Exp(big.NewInt(2), big.NewInt(768), p))
+
+	feOne = limbsFromBig(new(big.Int).Exp(big.NewInt(2), big.NewInt(256), p))
+
+	pMinus2       = new(big.Int).Sub(p, big.NewInt(2))
+	pMinus1Over2  = new(big.Int).Rsh(p, 1)
+	pPlus1Over4   = new(big.Int).Rsh(new(big.Int).Add(p, big.NewInt(1)), 2)
+	curveBElement = feFromBig(big.NewInt(3))
+)
+
+// limbsFromBig returns x, which must be smaller than 2²⁵⁶, as limbs without
+// converting it to Montgomery form.
+func limbsFromBig(x *big.Int) fieldElement {
+	var b [32]byte
+	xBytes := x.Bytes()
+	copy(b[32-len(xBytes):], xBytes)
+	return limbsFromBytes(b[:])
+}
+
+// limbsFromBytes returns the 32-byte big-endian integer b as limbs without
+// reducing it or converting it to Montgomery form.
+func limbsFromBytes(b []byte) fieldElement {
+	var v fieldElement
+	for i := range v {
+		for j := 0; j < 8; j++ {
+			v[i] |= uint64(b[31-8*i-j]) << uint(8*j)
+		}
+	}
+	return v
+}
+
+// feFromBig returns x mod p as a field element.
+func feFromBig(x *big.Int) fieldElement {
+	l := limbsFromBig(new(big.Int).Mod(x, p))
+	var v fieldElement
+	feMul(&v, &l, &rSquared)
+	return v
+}
+
+// feFromWideBytes sets v to the 48-byte big-endian integer b modulo p, as
+// hash_to_field does.
+func feFromWideBytes(v *fieldElement, b []byte) {
+	// b = hi·2²⁵⁶ + lo, and Montgomery multiplication by 2⁷⁶⁸ and 2⁵¹²
+	// takes hi·2²⁵⁶ and lo to Montgomery form, even though lo may exceed p.
+	var wide [32]byte
+	copy(wide[16:], b[:16])
+	hi := limbsFromBytes(wide[:])
+	lo := limbsFromBytes(b[16:])
+	var t fieldElement
+	feMul(&t, &hi, &rCubed)
+	feMul(v, &lo, &rSquared)
+	feAdd(v, v, &t)
+}
+
+// bytes returns the canonical 32-byte big-endian encoding of v.
+func (v *fieldElement) bytes() []byte {
+	one := fieldElement{1}
+	var t fieldElement
+	feMul(&t, v, &one)
+	out := make([]byte, 32)
+	for i := range t {
+		for j := 0; j < 8; j++ {
+			out[31-8*i-j] = byte(t[i] >> uint(8*j))
+		}
+	}
+	return out
+}
+
+// feReduce sets v to the 257-bit integer carry·2²⁵⁶ + t modulo p, given that
+// it is smaller than 2p.
+func feReduce(v, t *fieldElement, carry uint64) {
+	var d fieldElement
+	var b uint64
+	d[0], b = bits.Sub64(t[0], pLimbs[0], 0)
+	d[1], b = bits.Sub64(t[1], pLimbs[1], b)
+	d[2], b = bits.Sub64(t[2], pLimbs[2], b)
+	d[3], b = bits.Sub64(t[3], pLimbs[3], b)
+	_, b = bits.Sub64(carry, 0, b)
+	// b is 1 if the subtraction underflowed, that is if t was already
+	// smaller than p.
+	feSelect(v, t, &d, int(b))
+}
+
+// feAdd sets v = x + y.
+func feAdd(v, x, y *fieldElement) {
+	var t fieldElement
+	var c uint64
+	t[0], c = bits.Add64(x[0], y[0], 0)
+	t[1], c = bits.Add64(x[1], y[1], c)
+	t[2], c = bits.Add64(x[2], y[2], c)
+	t[3], c = bits.Add64(x[3], y[3], c)
+	feReduce(v, &t, c)
+}
+
+// feSub sets v = x - y.
+func feSub(v, x, y *fieldElement) {
+	var t fieldElement
+	var b uint64
+	t[0], b = bits.Sub64(x[0], y[0], 0)
+	t[1], b = bits.Sub64(x[1], y[1], b)
+	t[2], b = bits.Sub64(x[2], y[2], b)
+	t[3], b = bits.Sub64(x[3], y[3], b)
+	// Add p back if the subtraction underflowed.
+	mask := -b
+	var c uint64
+	v[0], c = bits.Add64(t[0], pLimbs[0]&mask, 0)
+	v[1], c = bits.Add64(t[1], pLimbs[1]&mask, c)
+	v[2], c = bits.Add64(t[2], pLimbs[2]&mask, c)
+	v[3], _ = bits.Add64(t[3], pLimbs[3]&mask, c)
+}
+
+// feNeg sets v = -x.
+func feNeg(v, x *fieldElement) {
+	feSub(v, &fieldElement{}, x)
+}
+
+// feMul sets v = x * y, using the CIOS method of Montgomery multiplication.
+// The product of the integer values of x and y must be smaller than p·2²⁵⁶,
+// which holds if either is reduced.
+func feMul(v, x, y *fieldElement) {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		// t += x * y[i]
+		var c uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(x[j], y[i])
+			var cc uint64
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j], c = lo, hi
+		}
+		t[4], t[5] = bits.Add64(t[4], c, 0)
+
+		// t = (t + m * p) / 2⁶⁴, where m makes the division exact.
+		m := t[0] * pInv
+		hi, lo := bits.Mul64(m, pLimbs[0])
+		_, cc := bits.Add64(lo, t[0], 0)
+		c = hi + cc
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(m, pLimbs[j])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j-1], c = lo, hi
+		}
+		t[3], cc = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + cc
+	}
+	feReduce(v, &fieldElement{t[0], t[1], t[2], t[3]}, t[4])
+}
+
+// feSquare sets v = x².
+func feSquare(v, x *fieldElement) {
+	feMul(v, x, x)
+}
+
+// feExp sets v = x^e. It runs in constant time with respect to x, but not to
+// e, which is always a public constant.
+func feExp(v, x *fieldElement, e *big.Int) {
+	z := feOne
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		feSquare(&z, &z)
+		if e.Bit(i) == 1 {
+			feMul(&z, &z, x)
+		}
+	}
+	*v = z
+}
+
+// feInvert sets v = 1/x, or zero if x is zero.
+func feInvert(v, x *fieldElement) {
+	feExp(v, x, pMinus2)
+}
+
+// feSqrt sets v to a square root of x, if x is square.
+func feSqrt(v, x *fieldElement) {
+	// p = 3 mod 4, so x^((p+1)/4) is a square root of x if there is one.
+	feExp(v, x, pPlus1Over4)
+}
+
+// feIsSquare returns 1 if x is a square, including zero, and 0 otherwise.
+func feIsSquare(x *fieldElement) int {
+	var t fieldElement
+	feExp(&t, x, pMinus1Over2)
+	var zero fieldElement
+	return feEqual(&t, &feOne) | feEqual(&t, &zero)
+}
+
+// feIsOdd returns sgn0(x) as defined in RFC 9380, Section 4.1: the least
+// significant bit of the canonical value of x.
+func feIsOdd(x *fieldElement) int {
+	one := fieldElement{1}
+	var t fieldElement
+	feMul(&t, x, &one)
+	return int(t[0] & 1)
+}
+
+// feEqual returns 1 if x == y, and 0 otherwise.
+func feEqual(x, y *fieldElement) int {
+	d := (x[0] ^ y[0]) | (x[1] ^ y[1]) | (x[2] ^ y[2]) | (x[3] ^ y[3])
+	return int(((d | -d) >> 63) ^ 1)
+}
+
+// feSelect sets v to a if cond is 1 and to b if cond is 0.
+func feSelect(v, a, b *fieldElement, cond int) {
+	mask := -uint64(cond)
+	for i := range v {
+		v[i] = b[i] ^ (mask & (a[i] ^ b[i]))
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bls
+
+import (
+	"github.com/benchlab/bench-crypto/sha256"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/bn256"
+)
+
+// Constants of the Shallue-van de Woestijne map for y² = x³ + 3 with Z = 1,
+// the Z chosen by the find_z_svdw procedure of RFC 9380, Appendix H.1.
+var (
+	// svdwC1 is g(Z) = 4.
+	svdwC1 = feFromBig(bigFromBase10("4"))
+	// svdwC2 is -Z / 2.
+	svdwC2 = feFromBig(bigFromBase10("32500274847823301866398219371179952871412679053811501785938572513432092035891"))
+	// svdwC3 is sqrt(-g(Z) * 3 * Z²) = sqrt(-12), with sgn0 equal to 0.
+	svdwC3 = feFromBig(bigFromBase10("19943133337236537044590945618699067654969446694243210536122"))
+	// svdwC4 is -4 * g(Z) / (3 * Z²) = -16 / 3.
+	svdwC4 = feFromBig(bigFromBase10("21666849898548867910932146247453301914275119369207667857292381675621394690589"))
+)
+
+// HashToG1 hashes msg to a point of G₁ with the domain separation tag dst,
+// which must be at most 255 bytes long.
+//
+// It implements the hash_to_curve function of RFC 9380, Section 3, with
+// expand_message_xmd and SHA-256, and the Shallue-van de Woestijne map of
+// Section 6.6.1, as the suite BN256G1_XMD:SHA-256_SVDW_RO_ for the bn256
+// curve. G₁ is the whole curve, so there is no cofactor to clear. Unlike
+// hashing with try-and-increment, it runs in constant time with respect to
+// msg.
+func HashToG1(msg, dst []byte) *bn256.G1 {
+	if len(dst) > 255 {
+		panic("bls: domain separation tag too long")
+	}
+	// Each of the two field elements takes 48 bytes, which gives 128 bits
+	// of margin over the 256-bit p.
+	uniform := expandMessageXMD(msg, dst, 2*48)
+
+	var q0, q1, sum projectivePoint
+	var u fieldElement
+	feFromWideBytes(&u, uniform[:48])
+	q0.mapToCurve(&u)
+	feFromWideBytes(&u, uniform[48:])
+	q1.mapToCurve(&u)
+	sum.add(&q0, &q1)
+
+	out, ok := new(bn256.G1).Unmarshal(sum.bytes())
+	if !ok {
+		panic("bls: internal error: hash to G1 produced an invalid point")
+	}
+	return out
+}
+
+// expandMessageXMD implements expand_message_xmd of RFC 9380, Section
+// 5.3.1, with SHA-256. n must be at most 255 * 32, and dst at most 255 bytes.
+func expandMessageXMD(msg, dst []byte, n int) []byte {
+	ell := (n + sha256.Size - 1) / sha256.Size
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h := sha256.New()
+	h.Write(make([]byte, h.ChunkSize())) // Z_pad
+	h.Write(msg)
+	h.Write([]byte{byte(n >> 8), byte(n), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, ell*sha256.Size)
+	bi := make([]byte, sha256.Size)
+	for i := 1; i <= ell; i++ {
+		// b_i = H(strxor(b_0, b_(i-1)) || I2OSP(i, 1) || DST_prime),
+		// where b_0 takes the place of the strxor for b_1.
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(bi[:0])
+		out = append(out, bi...)
+	}
+	return out[:n]
+}
+
+// projectivePoint is a point on y² = x³ + 3 in homogeneous projective
+// coordinates (X : Y : Z), with the identity at (0 : 1 : 0).
+type projectivePoint struct {
+	x, y, z fieldElement
+}
+
+// mapToCurve sets v to the image of u under the Shallue-van de Woestijne map
+// of RFC 9380, Section 6.6.1, in constant time.
+func (v *projectivePoint) mapToCurve(u *fieldElement) {
+	// tv3 = 1 / ((1 - c1 * u²) * (1 + c1 * u²)), or zero if the
+	// denominator is zero.
+	var tv1, tv2, tv3, tv4 fieldElement
+	feSquare(&tv1, u)
+	feMul(&tv1, &tv1, &svdwC1)
+	feAdd(&tv2, &feOne, &tv1)
+	feSub(&tv1, &feOne, &tv1)
+	feMul(&tv3, &tv1, &tv2)
+	feInvert(&tv3, &tv3)
+
+	// tv4 = c3 * u * (1 - c1 * u²) * tv3
+	feMul(&tv4, u, &tv1)
+	feMul(&tv4, &tv4, &tv3)
+	feMul(&tv4, &tv4, &svdwC3)
+
+	// x1 = c2 - tv4, x2 = c2 + tv4 and x3 = Z + c4 * ((1 + c1 * u²)² * tv3)².
+	// At least one of g(x1), g(x2) and g(x3) is square, and the first such
+	// is chosen.
+	var x1, x2, x3, gx fieldElement
+	feSub(&x1, &svdwC2, &tv4)
+	curveRHS(&gx, &x1)
+	e1 := feIsSquare(&gx)
+	feAdd(&x2, &svdwC2, &tv4)
+	curveRHS(&gx, &x2)
+	e2 := feIsSquare(&gx) &^ e1
+	feSquare(&x3, &tv2)
+	feMul(&x3, &x3, &tv3)
+	feSquare(&x3, &x3)
+	feMul(&x3, &x3, &svdwC4)
+	feAdd(&x3, &x3, &feOne)
+	feSelect(&v.x, &x1, &x3, e1)
+	feSelect(&v.x, &x2, &v.x, e2)
+
+	// y = sqrt(g(x)), with the same sgn0 as u.
+	var minusY fieldElement
+	curveRHS(&gx, &v.x)
+	feSqrt(&v.y, &gx)
+	feNeg(&minusY, &v.y)
+	feSelect(&v.y, &minusY, &v.y, feIsOdd(u)^feIsOdd(&v.y))
+	v.z = feOne
+}
+
+// curveRHS sets v = x³ + 3, the right-hand side of the curve equation.
+func curveRHS(v, x *fieldElement) {
+	var t fieldElement
+	feSquare(&t, x)
+	feMul(&t, &t, x)
+	feAdd(v, &t, &curveBElement)
+}
+
+// add sets v = a + b with the complete addition formula for short Weierstrass
+// curves without an x term of Renes, Costello and Batina, "Complete addition
+// formulas for prime order elliptic curves", Algorithm 7, which runs in
+// constant time and has no exceptional cases.
+func (v *projectivePoint) add(a, b *projectivePoint) {
+	var b3 fieldElement
+	feAdd(&b3, &curveBElement, &curveBElement)
+	feAdd(&b3, &b3, &curveBElement)
+
+	var t0, t1, t2, t3, t4, x3, y3, z3 fieldElement
+	feMul(&t0, &a.x, &b.x)
+	feMul(&t1, &a.y, &b.y)
+	feMul(&t2, &a.z, &b.z)
+	feAdd(&t3, &a.x, &a.y)
+	feAdd(&t4, &b.x, &b.y)
+	feMul(&t3, &t3, &t4)
+	feAdd(&t4, &t0, &t1)
+	feSub(&t3, &t3, &t4)
+	feAdd(&t4, &a.y, &a.z)
+	feAdd(&x3, &b.y, &b.z)
+	feMul(&t4, &t4, &x3)
+	feAdd(&x3, &t1, &t2)
+	feSub(&t4, &t4, &x3)
+	feAdd(&x3, &a.x, &a.z)
+	feAdd(&y3, &b.x, &b.z)
+	feMul(&x3, &x3, &y3)
+	feAdd(&y3, &t0, &t2)
+	feSub(&y3, &x3, &y3)
+	feAdd(&x3, &t0, &t0)
+	feAdd(&t0, &x3, &t0)
+	feMul(&t2, &b3, &t2)
+	feAdd(&z3, &t1, &t2)
+	feSub(&t1, &t1, &t2)
+	feMul(&y3, &b3, &y3)
+	feMul(&x3, &t4, &y3)
+	feMul(&t2, &t3, &t1)
+	feSub(&x3, &t2, &x3)
+	feMul(&y3, &y3, &t0)
+	feMul(&t1, &t1, &z3)
+	feAdd(&y3, &t1, &y3)
+	feMul(&t0, &t0, &t3)
+	feMul(&z3, &z3, &t4)
+	feAdd(&z3, &z3, &t0)
+	v.x, v.y, v.z = x3, y3, z3
+}
+
+// bytes returns the encoding of v used by bn256.G1.Marshal: the affine x and
+// y coordinates, or 64 zero bytes for the identity, whose Z is zero and so
+// has a zero inverse.
+func (v *projectivePoint) bytes() []byte {
+	var zInv, x, y fieldElement
+	feInvert(&zInv, &v.z)
+	feMul(&x, &v.x, &zInv)
+	feMul(&y, &v.y, &zInv)
+	return append(x.bytes(), y.bytes()...)
+}
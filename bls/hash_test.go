@@ -0,0 +1,177 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bls
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/benchlab/bench-crypto/rand"
+)
+
+func TestFieldArithmetic(t *testing.T) {
+	// Include values near 2²⁵⁶ and p, where the carries are exercised.
+	values := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		new(big.Int).Sub(p, big.NewInt(1)),
+		new(big.Int).Sub(p, big.NewInt(2)),
+		new(big.Int).Rsh(p, 1),
+	}
+	for i := 0; i < 50; i++ {
+		v, err := rand.Int(rand.Reader, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, v)
+	}
+	check := func(op string, got *fieldElement, want *big.Int) {
+		t.Helper()
+		want = new(big.Int).Mod(want, p)
+		if g := new(big.Int).SetBytes(got.bytes()); g.Cmp(want) != 0 {
+			t.Errorf("%s = %v, want %v", op, g, want)
+		}
+	}
+	for _, a := range values {
+		for _, b := range values[:10] {
+			x, y := feFromBig(a), feFromBig(b)
+			var v fieldElement
+			feAdd(&v, &x, &y)
+			check("add", &v, new(big.Int).Add(a, b))
+			feSub(&v, &x, &y)
+			check("sub", &v, new(big.Int).Sub(a, b))
+			feMul(&v, &x, &y)
+			check("mul", &v, new(big.Int).Mul(a, b))
+		}
+		x := feFromBig(a)
+		var v fieldElement
+		feInvert(&v, &x)
+		if a.Sign() == 0 {
+			check("invert", &v, big.NewInt(0))
+		} else {
+			check("invert", &v, new(big.Int).ModInverse(a, p))
+		}
+		isSquare := big.Jacobi(a, p) >= 0
+		if got := feIsSquare(&x) == 1; got != isSquare {
+			t.Errorf("isSquare(%v) = %v, want %v", a, got, isSquare)
+		}
+		if isSquare {
+			feSqrt(&v, &x)
+			feSquare(&v, &v)
+			check("sqrt²", &v, a)
+		}
+	}
+}
+
+func TestFeFromWideBytes(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		b := make([]byte, 48)
+		if _, err := rand.Read(b); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			for j := range b {
+				b[j] = 0xff
+			}
+		}
+		var v fieldElement
+		feFromWideBytes(&v, b)
+		want := new(big.Int).Mod(new(big.Int).SetBytes(b), p)
+		if got := new(big.Int).SetBytes(v.bytes()); got.Cmp(want) != 0 {
+			t.Errorf("feFromWideBytes(%x) = %v, want %v", b, got, want)
+		}
+	}
+}
+
+func TestSVDWConstants(t *testing.T) {
+	var v, w fieldElement
+	feSquare(&v, &svdwC3)
+	w = feFromBig(big.NewInt(-12))
+	if feEqual(&v, &w) != 1 {
+		t.Error("c3² != -12")
+	}
+	if feIsOdd(&svdwC3) != 0 {
+		t.Error("sgn0(c3) != 0")
+	}
+	w = feFromBig(big.NewInt(-1))
+	feAdd(&v, &svdwC2, &svdwC2)
+	if feEqual(&v, &w) != 1 {
+		t.Error("2 * c2 != -1")
+	}
+	w = feFromBig(big.NewInt(-16))
+	feAdd(&v, &svdwC4, &svdwC4)
+	feAdd(&v, &v, &svdwC4)
+	if feEqual(&v, &w) != 1 {
+		t.Error("3 * c4 != -16")
+	}
+}
+
+func TestMapToCurve(t *testing.T) {
+	half := new(big.Int).ModInverse(big.NewInt(2), p)
+	tests := []struct {
+		u     *big.Int
+		point string
+	}{
+		{big.NewInt(0), "2fe700a118e12d5338cff992cb2c4960a4c92d9b0ae73c8a081ee4241f58321e5fce014231c25aae902b933d1420e5915d41f8817ad1ce5061195aecae764220"},
+		{big.NewInt(1), "2b1cb3c42ffddbfd76dcc59e1a1711435b1d4c988f439bc7a5b606dbfd33a5c65dcc2f5d96831f0756ab9890d9ac2c320669b5c7c1369341529388c287251f7f"},
+		// The exceptional cases, where 1 - 4u² is zero, map to (Z, ±2).
+		{half, "00000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000002"},
+		{new(big.Int).Sub(p, half), "00000000000000000000000000000000000000000000000000000000000000018fb501e34aa387f9aa6fecb86184dc21ee5b88d120b5b59e185cac6c5e089665"},
+	}
+	for _, tt := range tests {
+		u := feFromBig(tt.u)
+		var q projectivePoint
+		q.mapToCurve(&u)
+		if got := hex.EncodeToString(q.bytes()); got != tt.point {
+			t.Errorf("mapToCurve(%v) = %s, want %s", tt.u, got, tt.point)
+		}
+	}
+}
+
+func TestHashToG1(t *testing.T) {
+	const dst = "QUUX-V01-CS02-with-BN256G1_XMD:SHA-256_SVDW_RO_"
+	tests := []struct {
+		msg, point string
+	}{
+		{"", "24806e759b4a774899c983aad9032f5bf7570d2320896c99a181e2fdeb12bb3376b08d168cf7755a0a094881a48f5a19f32d7146bb66d5914b7488422291150d"},
+		{"abc", "64ae303357450c22fee03159020f3d847de6d27a19d58da9cf4f2688ce42e31e5e5afd6b978975f0d2644ff3f3e611580f442b1aaa09faf74fc6ad6762b5ec55"},
+		{"abcdef0123456789", "13dd8022b75d2f8b2305255257fb2b5fdc283ca9e08a68aaebfa074a3e22fb245424bfa2f8b514bb406b846d1da502eaef57e720622fed8fe79c005e20d803ce"},
+		{"q128_" + string(bytes.Repeat([]byte("q"), 128)), "4ca146c352e451fd9e7dec0120a4c21ed0f1e379d80df4add98c2725e555b20c1f1c574398b9bcc9221b630e04616d8b9ac6b02dd641d39ec2ec047a2c42bef7"},
+		{"a512_" + string(bytes.Repeat([]byte("a"), 512)), "3d7af00e53a54e34f6cff2201ea0f42f27ac836fa1ab84623cb578250397218728a06a90e87e54eed0d94e3cd162f1f974357e792722efbeca264b958c89b21e"},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(HashToG1([]byte(tt.msg), []byte(dst)).Marshal()); got != tt.point {
+			t.Errorf("HashToG1(%.10q) = %s, want %s", tt.msg, got, tt.point)
+		}
+	}
+}
+
+// Test vectors from RFC 9380, Appendix K.1.
+func TestExpandMessageXMD(t *testing.T) {
+	const dst = "QUUX-V01-CS02-with-expander-SHA256-128"
+	tests := []struct {
+		msg string
+		n   int
+		out string
+	}{
+		{"", 0x20, "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235"},
+		{"abc", 0x20, "d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615"},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(expandMessageXMD([]byte(tt.msg), []byte(dst), tt.n)); got != tt.out {
+			t.Errorf("expandMessageXMD(%q, %d) = %s, want %s", tt.msg, tt.n, got, tt.out)
+		}
+	}
+}
+
+func BenchmarkHashToG1(b *testing.B) {
+	msg := []byte("hello world")
+	dst := []byte(signatureDST)
+	for i := 0; i < b.N; i++ {
+		HashToG1(msg, dst)
+	}
+}
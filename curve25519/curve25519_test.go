@@ -5,6 +5,8 @@
 package curve25519
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
 	"testing"
 )
@@ -28,6 +30,96 @@ func TestBaseScalarMult(t *testing.T) {
 	}
 }
 
+// The test vectors of RFC 7748, Section 5.2 and Section 6.1.
+var x25519Tests = []struct {
+	scalar, point, out string
+}{
+	{
+		"a546e36bf0527c9d3b16154b82465edd62144c0ac1fc5a18506a2244ba449ac4",
+		"e6db6867583030db3594c1a424b15f7c726624ec26b3353b10a903a6d0ab1c4c",
+		"c3da55379de9c6908e94ea4df28d084f32eccf03491c71f754b4075577a28552",
+	},
+	{
+		"4b66e9d4d1b4673c5ad22691957d6af5c11b6421e0ea01d42ca4169e7918ba0d",
+		"e5210f12786811d3f4b7959d0538ae2c31dbe7106fc03c3efc4cd549c715a493",
+		"95cbde9476e8907d7aade45cb4b873f88b595a68799fa152e6f8f7647aac7957",
+	},
+	{
+		"77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a",
+		"0900000000000000000000000000000000000000000000000000000000000000",
+		"8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a",
+	},
+	{
+		"5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb",
+		"0900000000000000000000000000000000000000000000000000000000000000",
+		"de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f",
+	},
+	{
+		"77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a",
+		"de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f",
+		"4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742",
+	},
+	{
+		"5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb",
+		"8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a",
+		"4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742",
+	},
+}
+
+func TestX25519(t *testing.T) {
+	for i, test := range x25519Tests {
+		scalar, _ := hex.DecodeString(test.scalar)
+		point, _ := hex.DecodeString(test.point)
+		out, err := X25519(scalar, point)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if got := hex.EncodeToString(out); got != test.out {
+			t.Errorf("#%d: got %s, want %s", i, got, test.out)
+		}
+	}
+}
+
+func TestX25519LowOrderPoints(t *testing.T) {
+	scalar, _ := hex.DecodeString(x25519Tests[0].scalar)
+	// u = 0, u = 1, a point of order 8, u = p - 1, and the non-canonical
+	// encodings u = p and u = p + 1.
+	for _, p := range []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		"e0eb7a7c3b41b8ae1656e3faf19fc46ada098deb9c32b1fd866205165f49b800",
+		"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	} {
+		point, _ := hex.DecodeString(p)
+		if out, err := X25519(scalar, point); err == nil {
+			t.Errorf("X25519 accepted low order point %s, output %x", p, out)
+		}
+	}
+
+	if _, err := X25519(scalar[:31], Basepoint); err == nil {
+		t.Errorf("X25519 accepted a short scalar")
+	}
+	if _, err := X25519(scalar, Basepoint[:31]); err == nil {
+		t.Errorf("X25519 accepted a short point")
+	}
+}
+
+func TestX25519ScalarMult(t *testing.T) {
+	var scalar, out [32]byte
+	scalar[0] = 1
+	ScalarBaseMult(&out, &scalar)
+	got, err := X25519(scalar[:], Basepoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, out[:]) {
+		t.Errorf("X25519 = %x, ScalarBaseMult = %x", got, out)
+	}
+}
+
 func BenchmarkScalarBaseMult(b *testing.B) {
 	var in, out [32]byte
 	in[0] = 1
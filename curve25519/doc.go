@@ -6,6 +6,12 @@
 // the elliptic curve known as curve25519. See https://cr.yp.to/ecdh.html
 package curve25519 // import "golang.org/x/github.com/benchlab/bench-crypto/curve25519"
 
+import (
+	"errors"
+
+	"github.com/benchlab/bench-crypto/subtle"
+)
+
 // basePoint is the x coordinate of the generator of the curve.
 var basePoint = [32]byte{9, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
 
@@ -21,3 +27,40 @@ func ScalarMult(dst, in, base *[32]byte) {
 func ScalarBaseMult(dst, in *[32]byte) {
 	ScalarMult(dst, in, &basePoint)
 }
+
+const (
+	// ScalarSize is the size of the scalar input to X25519.
+	ScalarSize = 32
+	// PointSize is the size of the point input to X25519.
+	PointSize = 32
+)
+
+// Basepoint is the canonical Curve25519 generator.
+var Basepoint = basePoint[:]
+
+// X25519 returns the result of the scalar multiplication (scalar * point),
+// according to RFC 7748, Section 5. scalar, point and the return value are
+// slices of 32 bytes.
+//
+// scalar can be generated at random, for example with crypto/rand. point should
+// be either Basepoint or the output of another X25519 call.
+//
+// Unlike ScalarMult, X25519 returns an error if point is a low-order point,
+// for which the result would be all zeroes regardless of scalar, as described
+// in RFC 7748, Section 6.1.
+func X25519(scalar, point []byte) ([]byte, error) {
+	if len(scalar) != ScalarSize {
+		return nil, errors.New("curve25519: bad scalar length")
+	}
+	if len(point) != PointSize {
+		return nil, errors.New("curve25519: bad point length")
+	}
+	var dst, in, base, zero [32]byte
+	copy(in[:], scalar)
+	copy(base[:], point)
+	ScalarMult(&dst, &in, &base)
+	if subtle.ConstantTimeCompare(dst[:], zero[:]) == 1 {
+		return nil, errors.New("curve25519: bad input point: low order point")
+	}
+	return dst[:], nil
+}
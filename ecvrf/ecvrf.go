@@ -0,0 +1,246 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ecvrf implements the elliptic curve verifiable random functions of
+// RFC 9381 over edwards25519, ECVRF-EDWARDS25519-SHA512-TAI and
+// ECVRF-EDWARDS25519-SHA512-ELL2.
+//
+// A VRF maps an input to an output that only the holder of a private key can
+// compute, but that anyone can check against the public key with a proof.
+// Keys are Ed25519 keys from the ed25519 package.
+//
+// Proving runs in constant time with respect to the private key. With
+// Edwards25519ELL2 it also runs in constant time with respect to the input,
+// which Edwards25519TAI, whose hash to the curve loops a number of times that
+// depends on the input, does not.
+package ecvrf // import "golang.org/x/github.com/benchlab/bench-crypto/ecvrf"
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/benchlab/bench-crypto/sha512"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/ed25519"
+	ref10 "golang.org/x/github.com/benchlab/bench-crypto/int/edwards25519"
+)
+
+const (
+	// ProofSize is the size, in bytes, of proofs: a point, a 16-byte
+	// challenge and a scalar.
+	ProofSize = 80
+	// OutputSize is the size, in bytes, of VRF outputs.
+	OutputSize = sha512.Size
+
+	challengeSize = 16
+
+	suiteTAI  = 0x03
+	suiteELL2 = 0x04
+)
+
+// Suite is an ECVRF cipher suite.
+type Suite struct {
+	id byte
+
+	// encodeToCurve sets h to the hash of alpha, with the public key as
+	// salt, to the prime-order subgroup.
+	encodeToCurve func(h *ref10.ExtendedGroupElement, salt, alpha []byte)
+}
+
+var (
+	// Edwards25519TAI is ECVRF-EDWARDS25519-SHA512-TAI, which hashes to the
+	// curve with the try-and-increment method.
+	Edwards25519TAI = &Suite{id: suiteTAI, encodeToCurve: encodeToCurveTAI}
+
+	// Edwards25519ELL2 is ECVRF-EDWARDS25519-SHA512-ELL2, which hashes to the
+	// curve with the Elligator 2 encoding of RFC 9380.
+	Edwards25519ELL2 = &Suite{id: suiteELL2, encodeToCurve: encodeToCurveELL2}
+)
+
+// Prove returns the proof for input alpha under privateKey. The VRF output
+// can be computed from it with ProofToHash. It will panic if len(privateKey)
+// is not ed25519.PrivateKeySize.
+func (s *Suite) Prove(privateKey ed25519.PrivateKey, alpha []byte) []byte {
+	if l := len(privateKey); l != ed25519.PrivateKeySize {
+		panic("ecvrf: bad private key length: " + strconv.Itoa(l))
+	}
+
+	// The secret scalar and the nonce key are derived as in Ed25519.
+	digest := sha512.Sum512(privateKey[:32])
+	var x [32]byte
+	copy(x[:], digest[:32])
+	x[0] &= 248
+	x[31] &= 127
+	x[31] |= 64
+	publicKey := privateKey[32:]
+
+	var H, gamma, U, V ref10.ExtendedGroupElement
+	s.encodeToCurve(&H, publicKey, alpha)
+	var hString [32]byte
+	H.ToBytes(&hString)
+	ref10.GeScalarMult(&gamma, &x, &H)
+
+	// k = SHA-512(nonce key || H) mod l
+	var kDigest [64]byte
+	h := sha512.New()
+	h.Write(digest[32:])
+	h.Write(hString[:])
+	h.Sum(kDigest[:0])
+	var k [32]byte
+	ref10.ScReduce(&k, &kDigest)
+	ref10.GeScalarMultBase(&U, &k)
+	ref10.GeScalarMult(&V, &k, &H)
+
+	var yString [32]byte
+	copy(yString[:], publicKey)
+	c := s.challenge(&yString, &hString, &gamma, &U, &V)
+
+	// s = k + c * x mod l
+	var sc [32]byte
+	ref10.ScMulAdd(&sc, &c, &x, &k)
+
+	pi := make([]byte, 0, ProofSize)
+	var gammaString [32]byte
+	gamma.ToBytes(&gammaString)
+	pi = append(pi, gammaString[:]...)
+	pi = append(pi, c[:challengeSize]...)
+	return append(pi, sc[:]...)
+}
+
+// challenge returns the challenge of RFC 9381, Section 5.4.3, for the
+// public key and H, given as encodings, and the points Gamma, U and V, as a
+// little-endian scalar.
+func (s *Suite) challenge(y, h *[32]byte, gamma, U, V *ref10.ExtendedGroupElement) [32]byte {
+	d := sha512.New()
+	d.Write([]byte{s.id, 0x02})
+	d.Write(y[:])
+	d.Write(h[:])
+	for _, p := range []*ref10.ExtendedGroupElement{gamma, U, V} {
+		var b [32]byte
+		p.ToBytes(&b)
+		d.Write(b[:])
+	}
+	d.Write([]byte{0x00})
+
+	var c [32]byte
+	copy(c[:], d.Sum(nil)[:challengeSize])
+	return c
+}
+
+// decodeProof decodes pi into the point Gamma, the challenge c and the
+// scalar s, rejecting non-canonical encodings.
+func decodeProof(gamma *ref10.ExtendedGroupElement, c, s *[32]byte, pi []byte) bool {
+	if len(pi) != ProofSize || !decodeCanonical(gamma, pi[:32]) {
+		return false
+	}
+	*c = [32]byte{}
+	copy(c[:], pi[32:32+challengeSize])
+	copy(s[:], pi[32+challengeSize:])
+	return ref10.ScMinimal(s)
+}
+
+// ProofToHash returns the VRF output of the proof pi, without verifying it.
+// It must only be used on proofs returned by Prove, or that Verify accepted,
+// which returns the same output.
+func (s *Suite) ProofToHash(pi []byte) ([]byte, error) {
+	var gamma ref10.ExtendedGroupElement
+	var c, sc [32]byte
+	if !decodeProof(&gamma, &c, &sc, pi) {
+		return nil, errors.New("ecvrf: invalid proof")
+	}
+	return s.proofToHash(&gamma), nil
+}
+
+func (s *Suite) proofToHash(gamma *ref10.ExtendedGroupElement) []byte {
+	var p ref10.ExtendedGroupElement
+	mulByCofactor(&p, gamma)
+	var b [32]byte
+	p.ToBytes(&b)
+
+	h := sha512.New()
+	h.Write([]byte{s.id, 0x03})
+	h.Write(b[:])
+	h.Write([]byte{0x00})
+	return h.Sum(nil)
+}
+
+// Verify checks that pi is a valid proof for input alpha under publicKey, and
+// if so returns the VRF output and true. Public keys of small order are
+// rejected.
+func (s *Suite) Verify(publicKey ed25519.PublicKey, alpha, pi []byte) (beta []byte, ok bool) {
+	var Y ref10.ExtendedGroupElement
+	if len(publicKey) != ed25519.PublicKeySize || !decodeCanonical(&Y, publicKey) || hasSmallOrder(&Y) {
+		return nil, false
+	}
+	var gamma ref10.ExtendedGroupElement
+	var c, sc [32]byte
+	if !decodeProof(&gamma, &c, &sc, pi) {
+		return nil, false
+	}
+
+	var H ref10.ExtendedGroupElement
+	s.encodeToCurve(&H, publicKey, alpha)
+
+	// U = s * B - c * Y
+	// V = s * H - c * Gamma
+	var minusY, minusGamma ref10.ExtendedGroupElement
+	negate(&minusY, &Y)
+	negate(&minusGamma, &gamma)
+	var u, v ref10.ProjectiveGroupElement
+	ref10.GeDoubleScalarMultVartime(&u, &c, &minusY, &sc)
+	var zero [32]byte
+	ref10.GeMultiScalarMultVartime(&v, [][32]byte{sc, c}, []ref10.ExtendedGroupElement{H, minusGamma}, &zero)
+	var U, V ref10.ExtendedGroupElement
+	u.ToExtended(&U)
+	v.ToExtended(&V)
+
+	var yString, hString [32]byte
+	copy(yString[:], publicKey)
+	H.ToBytes(&hString)
+	if s.challenge(&yString, &hString, &gamma, &U, &V) != c {
+		return nil, false
+	}
+	return s.proofToHash(&gamma), true
+}
+
+// decodeCanonical decodes s into p, and reports whether s is the canonical
+// encoding of a valid point.
+func decodeCanonical(p *ref10.ExtendedGroupElement, s []byte) bool {
+	var in, out [32]byte
+	copy(in[:], s)
+	if !p.FromBytes(&in) {
+		return false
+	}
+	p.ToBytes(&out)
+	return out == in
+}
+
+// mulByCofactor sets v = 8 * p.
+func mulByCofactor(v, p *ref10.ExtendedGroupElement) {
+	var c ref10.CompletedGroupElement
+	var r ref10.ProjectiveGroupElement
+	p.Double(&c)
+	c.ToProjective(&r)
+	r.Double(&c)
+	c.ToProjective(&r)
+	r.Double(&c)
+	c.ToExtended(v)
+}
+
+// hasSmallOrder reports whether p is in the torsion subgroup of order 8.
+func hasSmallOrder(p *ref10.ExtendedGroupElement) bool {
+	var q ref10.ExtendedGroupElement
+	mulByCofactor(&q, p)
+	var encoded [32]byte
+	q.ToBytes(&encoded)
+	return encoded == [32]byte{1}
+}
+
+// negate sets v = -p.
+func negate(v, p *ref10.ExtendedGroupElement) {
+	ref10.FeNeg(&v.X, &p.X)
+	ref10.FeCopy(&v.Y, &p.Y)
+	ref10.FeCopy(&v.Z, &p.Z)
+	ref10.FeNeg(&v.T, &p.T)
+}
@@ -0,0 +1,214 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecvrf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/ed25519"
+)
+
+type vrfTest struct {
+	seed, pub, alpha, pi, beta string
+}
+
+// Examples 16 to 18 of RFC 9381, Appendix B.3.
+var taiTests = []vrfTest{
+	{
+		seed:  "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+		pub:   "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+		alpha: "",
+		pi:    "8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f26f8a57ccaed74ee1b190bed1f479d9727d2d0f9b005a6e456a35d4fb0daab1268a1b0db10836d9826a528ca76567805",
+		beta:  "90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae",
+	},
+	{
+		seed:  "4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+		pub:   "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+		alpha: "72",
+		pi:    "f3141cd382dc42909d19ec5110469e4feae18300e94f304590abdced48aed5933bf0864a62558b3ed7f2fea45c92a465301b3bbf5e3e54ddf2d935be3b67926da3ef39226bbc355bdc9850112c8f4b02",
+		beta:  "eb4440665d3891d668e7e0fcaf587f1b4bd7fbfe99d0eb2211ccec90496310eb5e33821bc613efb94db5e5b54c70a848a0bef4553a41befc57663b56373a5031",
+	},
+	{
+		seed:  "c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7",
+		pub:   "fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025",
+		alpha: "af82",
+		pi:    "9bc0f79119cc5604bf02d23b4caede71393cedfbb191434dd016d30177ccbf8096bb474e53895c362d8628ee9f9ea3c0e52c7a5c691b6c18c9979866568add7a2d41b00b05081ed0f58ee5e31b3a970e",
+		beta:  "645427e5d00c62a23fb703732fa5d892940935942101e456ecca7bb217c61c452118fec1219202a0edcf038bb6373241578be7217ba85a2687f7a0310b2df19f",
+	},
+}
+
+// Examples 19 to 21 of RFC 9381, Appendix B.4.
+var ell2Tests = []vrfTest{
+	{
+		seed:  "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+		pub:   "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+		alpha: "",
+		pi:    "7d9c633ffeee27349264cf5c667579fc583b4bda63ab71d001f89c10003ab46f14adf9a3cd8b8412d9038531e865c341cafa73589b023d14311c331a9ad15ff2fb37831e00f0acaa6d73bc9997b06501",
+		beta:  "9d574bf9b8302ec0fc1e21c3ec5368269527b87b462ce36dab2d14ccf80c53cccf6758f058c5b1c856b116388152bbe509ee3b9ecfe63d93c3b4346c1fbc6c54",
+	},
+	{
+		seed:  "4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+		pub:   "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+		alpha: "72",
+		pi:    "47b327393ff2dd81336f8a2ef10339112401253b3c714eeda879f12c509072ef055b48372bb82efbdce8e10c8cb9a2f9d60e93908f93df1623ad78a86a028d6bc064dbfc75a6a57379ef855dc6733801",
+		beta:  "38561d6b77b71d30eb97a062168ae12b667ce5c28caccdf76bc88e093e4635987cd96814ce55b4689b3dd2947f80e59aac7b7675f8083865b46c89b2ce9cc735",
+	},
+	{
+		seed:  "c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7",
+		pub:   "fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025",
+		alpha: "af82",
+		pi:    "926e895d308f5e328e7aa159c06eddbe56d06846abf5d98c2512235eaa57fdce35b46edfc655bc828d44ad09d1150f31374e7ef73027e14760d42e77341fe05467bb286cc2c9d7fde29120a0b2320d04",
+		beta:  "121b7f9b9aaaa29099fc04a94ba52784d44eac976dd1a3cca458733be5cd090a7b5fbd148444f17f8daf1fb55cb04b1ae85a626e30a54b4b0f8abf4a43314a58",
+	},
+}
+
+func testVectors(t *testing.T, suite *Suite, tests []vrfTest) {
+	for i, test := range tests {
+		seed, _ := hex.DecodeString(test.seed)
+		alpha, _ := hex.DecodeString(test.alpha)
+		pub, priv, _ := ed25519.GenerateKey(bytes.NewReader(seed))
+		if got := hex.EncodeToString(pub); got != test.pub {
+			t.Errorf("#%d: public key %s, want %s", i, got, test.pub)
+		}
+
+		pi := suite.Prove(priv, alpha)
+		if got := hex.EncodeToString(pi); got != test.pi {
+			t.Errorf("#%d: proof %s, want %s", i, got, test.pi)
+		}
+		beta, err := suite.ProofToHash(pi)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if got := hex.EncodeToString(beta); got != test.beta {
+			t.Errorf("#%d: output %s, want %s", i, got, test.beta)
+		}
+
+		verified, ok := suite.Verify(pub, alpha, pi)
+		if !ok {
+			t.Errorf("#%d: Verify failed", i)
+		} else if !bytes.Equal(verified, beta) {
+			t.Errorf("#%d: Verify returned %x, want %x", i, verified, beta)
+		}
+	}
+}
+
+func TestTAIVectors(t *testing.T)  { testVectors(t, Edwards25519TAI, taiTests) }
+func TestELL2Vectors(t *testing.T) { testVectors(t, Edwards25519ELL2, ell2Tests) }
+
+func TestVerifyRejects(t *testing.T) {
+	for _, suite := range []*Suite{Edwards25519TAI, Edwards25519ELL2} {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		otherPub, _, _ := ed25519.GenerateKey(nil)
+		alpha := []byte("leader election round 1")
+		pi := suite.Prove(priv, alpha)
+		if _, ok := suite.Verify(pub, alpha, pi); !ok {
+			t.Fatalf("suite %d: valid proof rejected", suite.id)
+		}
+
+		if _, ok := suite.Verify(pub, []byte("leader election round 2"), pi); ok {
+			t.Errorf("suite %d: proof accepted for a different input", suite.id)
+		}
+		if _, ok := suite.Verify(otherPub, alpha, pi); ok {
+			t.Errorf("suite %d: proof accepted for a different key", suite.id)
+		}
+		for _, i := range []int{0, 32, 48, 79} {
+			bad := append([]byte{}, pi...)
+			bad[i] ^= 0x01
+			if _, ok := suite.Verify(pub, alpha, bad); ok {
+				t.Errorf("suite %d: proof with byte %d modified accepted", suite.id, i)
+			}
+		}
+		if _, ok := suite.Verify(pub, alpha, pi[:ProofSize-1]); ok {
+			t.Errorf("suite %d: truncated proof accepted", suite.id)
+		}
+
+		// s + l is a non-canonical encoding of the same scalar.
+		bad := append([]byte{}, pi...)
+		var carry uint16
+		for i, b := range order {
+			carry += uint16(bad[48+i]) + uint16(b)
+			bad[48+i] = byte(carry)
+			carry >>= 8
+		}
+		if _, ok := suite.Verify(pub, alpha, bad); ok {
+			t.Errorf("suite %d: proof with non-canonical s accepted", suite.id)
+		}
+		if _, err := suite.ProofToHash(bad); err == nil {
+			t.Errorf("suite %d: ProofToHash accepted non-canonical s", suite.id)
+		}
+
+		// The identity is a public key of small order.
+		identity := make([]byte, ed25519.PublicKeySize)
+		identity[0] = 1
+		if _, ok := suite.Verify(identity, alpha, pi); ok {
+			t.Errorf("suite %d: small order public key accepted", suite.id)
+		}
+	}
+}
+
+// order is the order l of the prime subgroup, little-endian.
+var order = [32]byte{
+	0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+}
+
+func TestExpandMessageXMD(t *testing.T) {
+	// From RFC 9380, Appendix K.3.
+	const dst = "QUUX-V01-CS02-with-expander-SHA512-256"
+	for _, test := range []struct {
+		msg  string
+		n    int
+		want string
+	}{
+		{"", 0x20, "6b9a7312411d92f921c6f68ca0b6380730a1a4d982c507211a90964c394179ba"},
+		{"abc", 0x20, "0da749f12fbe5483eb066a5f595055679b976e93abe9be6f0f6318bce7aca8dc"},
+		{"abc", 0x80, "7f1dddd13c08b543f2e2037b14cefb255b44c83cc397c1786d975653e36a6b11bdd7732d8b38adb4a0edc26a0cef4bb45217135456e58fbca1703cd6032cb1347ee720b87972d63fbf232587043ed2901bce7f22610c0419751c065922b488431851041310ad659e4b23520e1772ab29dcdeb2002222a363f0c2b1c972b3efe1"},
+	} {
+		got := hex.EncodeToString(expandMessageXMD([]byte(test.msg), []byte(dst), test.n))
+		if got != test.want {
+			t.Errorf("expandMessageXMD(%q, %d) = %s, want %s", test.msg, test.n, got, test.want)
+		}
+	}
+}
+
+var suiteBenchmarks = []struct {
+	name  string
+	suite *Suite
+}{
+	{"TAI", Edwards25519TAI},
+	{"ELL2", Edwards25519ELL2},
+}
+
+func BenchmarkProve(b *testing.B) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	alpha := []byte("Hello, world!")
+	for _, bb := range suiteBenchmarks {
+		suite := bb.suite
+		b.Run(bb.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				suite.Prove(priv, alpha)
+			}
+		})
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	alpha := []byte("Hello, world!")
+	for _, bb := range suiteBenchmarks {
+		suite := bb.suite
+		pi := suite.Prove(priv, alpha)
+		b.Run(bb.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				suite.Verify(pub, alpha, pi)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecvrf
+
+import (
+	"github.com/benchlab/bench-crypto/sha512"
+
+	ref10 "golang.org/x/github.com/benchlab/bench-crypto/int/edwards25519"
+)
+
+// encodeToCurveTAI implements ECVRF_encode_to_curve_try_and_increment of
+// RFC 9381, Section 5.4.1.1. It runs in variable time with respect to alpha.
+func encodeToCurveTAI(h *ref10.ExtendedGroupElement, salt, alpha []byte) {
+	for ctr := 0; ctr < 256; ctr++ {
+		d := sha512.New()
+		d.Write([]byte{suiteTAI, 0x01})
+		d.Write(salt)
+		d.Write(alpha)
+		d.Write([]byte{byte(ctr), 0x00})
+		var p ref10.ExtendedGroupElement
+		if decodeCanonical(&p, d.Sum(nil)[:32]) {
+			mulByCofactor(h, &p)
+			return
+		}
+	}
+	// Each attempt fails with probability about 1/2.
+	panic("ecvrf: try-and-increment failed")
+}
+
+// ell2DST is the domain separation tag of RFC 9381, Section 5.4.1.2, for
+// ECVRF-EDWARDS25519-SHA512-ELL2.
+const ell2DST = "ECVRF_edwards25519_XMD:SHA-512_ELL2_NU_\x04"
+
+// encodeToCurveELL2 implements the edwards25519_XMD:SHA-512_ELL2_NU_
+// encode_to_curve function of RFC 9380, as used by RFC 9381, Section
+// 5.4.1.2. It runs in constant time.
+func encodeToCurveELL2(h *ref10.ExtendedGroupElement, salt, alpha []byte) {
+	msg := make([]byte, 0, len(salt)+len(alpha))
+	msg = append(msg, salt...)
+	msg = append(msg, alpha...)
+	uniform := expandMessageXMD(msg, []byte(ell2DST), 48)
+
+	var u ref10.FieldElement
+	feFromWideBytes(&u, uniform)
+	var p ref10.ExtendedGroupElement
+	mapToCurveElligator2(&p, &u)
+	mulByCofactor(h, &p)
+}
+
+// expandMessageXMD implements expand_message_xmd of RFC 9380, Section
+// 5.3.1, with SHA-512. n must be at most 255 * 64, and dst at most 255 bytes.
+func expandMessageXMD(msg, dst []byte, n int) []byte {
+	ell := (n + sha512.Size - 1) / sha512.Size
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h := sha512.New()
+	h.Write(make([]byte, h.ChunkSize())) // Z_pad
+	h.Write(msg)
+	h.Write([]byte{byte(n >> 8), byte(n), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, ell*sha512.Size)
+	bi := make([]byte, sha512.Size)
+	for i := 1; i <= ell; i++ {
+		// b_i = H(strxor(b_0, b_(i-1)) || I2OSP(i, 1) || DST_prime),
+		// where b_0 takes the place of the strxor for b_1.
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(bi[:0])
+		out = append(out, bi...)
+	}
+	return out[:n]
+}
+
+var (
+	// fe2Pow128 is 2^128.
+	fe2Pow128 = feFromBytes([32]byte{16: 1})
+	// feMinusA is -486662, the negated Montgomery coefficient of Curve25519.
+	feMinusA = func() ref10.FieldElement {
+		var v ref10.FieldElement
+		ref10.FeNeg(&v, &ref10.A)
+		return v
+	}()
+	// sqrtMinusAMinus2 is sqrt(-486664), the constant of the rational map
+	// between Curve25519 and edwards25519, with sgn0 equal to 0.
+	sqrtMinusAMinus2 = feFromBytes([32]byte{
+		0x06, 0x7e, 0x45, 0xff, 0xaa, 0x04, 0x6e, 0xcc, 0x82, 0x1a, 0x7d, 0x4b, 0xd1, 0xd3, 0xa1, 0xc5,
+		0x7e, 0x4f, 0xfc, 0x03, 0xdc, 0x08, 0x7b, 0xd2, 0xbb, 0x06, 0xa0, 0x60, 0xf4, 0xed, 0x26, 0x0f,
+	})
+)
+
+func feFromBytes(b [32]byte) ref10.FieldElement {
+	var fe ref10.FieldElement
+	ref10.FeFromBytes(&fe, &b)
+	return fe
+}
+
+// feFromWideBytes sets v to the 48-byte big-endian integer b modulo p, as
+// hash_to_field does.
+func feFromWideBytes(v *ref10.FieldElement, b []byte) {
+	// Split b into three 128-bit limbs, which FeFromBytes takes whole.
+	var limbs [3]ref10.FieldElement
+	for i := range limbs {
+		var le [32]byte
+		for j := 0; j < 16; j++ {
+			le[j] = b[i*16+15-j]
+		}
+		ref10.FeFromBytes(&limbs[i], &le)
+	}
+	// v = (limbs[0] * 2^128 + limbs[1]) * 2^128 + limbs[2]
+	ref10.FeMul(v, &limbs[0], &fe2Pow128)
+	ref10.FeAdd(v, v, &limbs[1])
+	ref10.FeMul(v, v, &fe2Pow128)
+	ref10.FeAdd(v, v, &limbs[2])
+}
+
+// mapToCurveElligator2 sets p to the image of u under the Elligator 2 map
+// to Curve25519 of RFC 9380, Section 6.7.1, followed by the rational map to
+// edwards25519 of Appendix D.1, in constant time.
+func mapToCurveElligator2(p *ref10.ExtendedGroupElement, u *ref10.FieldElement) {
+	var one, t ref10.FieldElement
+	ref10.FeOne(&one)
+
+	// x1 = -A / (1 + 2 * u^2), where the denominator is never zero as -1/2
+	// is not a square.
+	var x1 ref10.FieldElement
+	ref10.FeSquare2(&t, u)
+	ref10.FeAdd(&t, &t, &one)
+	ref10.FeInvert(&t, &t)
+	ref10.FeMul(&x1, &feMinusA, &t)
+
+	// x2 = -x1 - A
+	var x2 ref10.FieldElement
+	ref10.FeNeg(&x2, &x1)
+	ref10.FeSub(&x2, &x2, &ref10.A)
+
+	// If gx1 is square, (x, y) = (x1, sqrt(gx1)) with sgn0(y) == 1,
+	// otherwise (x2, sqrt(gx2)) with sgn0(y) == 0. feSqrt returns the
+	// root with sgn0 0.
+	var gx1, gx2, y1, y2 ref10.FieldElement
+	curveRHS(&gx1, &x1)
+	curveRHS(&gx2, &x2)
+	isSquare := feSqrt(&y1, &gx1)
+	feSqrt(&y2, &gx2)
+	ref10.FeNeg(&y1, &y1)
+	s, y := x2, y2
+	ref10.FeCMove(&s, &x1, isSquare)
+	ref10.FeCMove(&y, &y1, isSquare)
+
+	// (v, w) = (sqrt(-486664) * s / y, (s - 1) / (s + 1)), or the identity
+	// if y or s + 1 is zero, computed with a single inversion.
+	var sMinusOne, sPlusOne, inv, v, w ref10.FieldElement
+	ref10.FeSub(&sMinusOne, &s, &one)
+	ref10.FeAdd(&sPlusOne, &s, &one)
+	ref10.FeMul(&inv, &y, &sPlusOne)
+	ref10.FeInvert(&inv, &inv)
+	ref10.FeMul(&v, &sqrtMinusAMinus2, &s)
+	ref10.FeMul(&v, &v, &sPlusOne)
+	ref10.FeMul(&v, &v, &inv)
+	ref10.FeMul(&w, &sMinusOne, &y)
+	ref10.FeMul(&w, &w, &inv)
+	ref10.FeCMove(&w, &one, 1^ref10.FeIsNonZero(&inv))
+
+	ref10.FeCopy(&p.X, &v)
+	ref10.FeCopy(&p.Y, &w)
+	ref10.FeOne(&p.Z)
+	ref10.FeMul(&p.T, &v, &w)
+}
+
+// curveRHS sets v = x^3 + A * x^2 + x, the right-hand side of the Curve25519
+// equation.
+func curveRHS(v, x *ref10.FieldElement) {
+	var t ref10.FieldElement
+	ref10.FeAdd(&t, x, &ref10.A)
+	ref10.FeMul(&t, &t, x)
+	ref10.FeOne(v)
+	ref10.FeAdd(&t, &t, v)
+	ref10.FeMul(v, &t, x)
+}
+
+// feSqrt sets r to the square root of x with sgn0 0 and returns 1 if x is
+// square. Otherwise, it returns 0 and the value of r is unspecified.
+func feSqrt(r, x *ref10.FieldElement) int32 {
+	// r = x^((p+3)/8) = x * (x^((p-5)/8)), and multiplying by sqrt(-1)
+	// fixes it up if r^2 = -x.
+	var rI, check, minusX ref10.FieldElement
+	ref10.FePow22523(r, x)
+	ref10.FeMul(r, r, x)
+	ref10.FeSquare(&check, r)
+	ref10.FeNeg(&minusX, x)
+	ref10.FeMul(&rI, r, &ref10.SqrtM1)
+	flipped := feEqual(&check, &minusX)
+	ref10.FeCMove(r, &rI, flipped)
+
+	var minusR ref10.FieldElement
+	ref10.FeNeg(&minusR, r)
+	ref10.FeCMove(r, &minusR, int32(ref10.FeIsNegative(r)))
+	return feEqual(&check, x) | flipped
+}
+
+// feEqual returns 1 if f == g, and 0 otherwise.
+func feEqual(f, g *ref10.FieldElement) int32 {
+	var t ref10.FieldElement
+	ref10.FeSub(&t, f, g)
+	return 1 ^ ref10.FeIsNonZero(&t)
+}
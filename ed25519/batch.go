@@ -0,0 +1,157 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"io"
+	"strconv"
+
+	cryptorand "github.com/benchlab/bench-crypto/rand"
+	"github.com/benchlab/bench-crypto/sha512"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/int/edwards25519"
+)
+
+// scalarOne is the coefficient of a signature verified on its own.
+var scalarOne = [32]byte{1}
+
+// BatchVerifier verifies many signatures at once, faster than verifying
+// them one by one, under the same rules as Verify. The zero value is an
+// empty batch ready to use.
+type BatchVerifier struct {
+	entries []batchEntry
+}
+
+// batchEntry is a signature decoded for verification.
+type batchEntry struct {
+	ok   bool // false if the signature can be rejected without computation
+	negA edwards25519.ExtendedGroupElement
+	negR edwards25519.ExtendedGroupElement
+	k    [32]byte // SHA-512(R || A || M) reduced modulo the order
+	s    [32]byte
+}
+
+// Add adds a signature to the batch. It will panic if len(publicKey) is not
+// PublicKeySize.
+func (v *BatchVerifier) Add(publicKey PublicKey, message, sig []byte) {
+	var e batchEntry
+	e.ok = e.init(publicKey, message, sig, domPrefixPure, "")
+	v.entries = append(v.entries, e)
+}
+
+// Len returns the number of signatures in the batch.
+func (v *BatchVerifier) Len() int {
+	return len(v.entries)
+}
+
+// Verify reports whether all the signatures in the batch are valid, and, in
+// the order in which they were added, whether each of them is valid. When
+// the whole batch is valid, no signature is verified on its own; otherwise
+// every signature is then checked as by the package-level Verify to find the
+// invalid ones.
+//
+// The batch equation is weighted with 128-bit coefficients drawn from rand,
+// so that invalid signatures cannot cancel each other out. If rand is nil,
+// github.com/benchlab/bench-crypto/rand.Reader will be used. Verify returns
+// an error for any error returned by rand.Read.
+func (v *BatchVerifier) Verify(rand io.Reader) (allValid bool, valid []bool, err error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	valid = make([]bool, len(v.entries))
+	if len(v.entries) == 0 {
+		return true, valid, nil
+	}
+
+	allValid = true
+	for _, e := range v.entries {
+		allValid = allValid && e.ok
+	}
+	if allValid {
+		z := make([][32]byte, len(v.entries))
+		for i := range z {
+			if _, err := io.ReadFull(rand, z[i][:16]); err != nil {
+				return false, nil, err
+			}
+		}
+		allValid = verifyBatch(v.entries, z)
+	}
+
+	for i, e := range v.entries {
+		valid[i] = allValid || e.ok && verifyBatch(v.entries[i:i+1], [][32]byte{scalarOne})
+	}
+	return allValid, valid, nil
+}
+
+// init decodes the signature into e, with the domain separation of the
+// variant given by domPrefix and context, and reports whether it can be
+// valid.
+func (e *batchEntry) init(publicKey PublicKey, message, sig []byte, domPrefix, context string) bool {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	if len(sig) != SignatureSize {
+		return false
+	}
+
+	var publicKeyBytes, rBytes [32]byte
+	copy(publicKeyBytes[:], publicKey)
+	copy(rBytes[:], sig[:32])
+	copy(e.s[:], sig[32:])
+	if !edwards25519.ScMinimal(&e.s) {
+		return false
+	}
+	if !e.negA.FromBytes(&publicKeyBytes) || !e.negR.FromBytes(&rBytes) {
+		return false
+	}
+	edwards25519.FeNeg(&e.negA.X, &e.negA.X)
+	edwards25519.FeNeg(&e.negA.T, &e.negA.T)
+	edwards25519.FeNeg(&e.negR.X, &e.negR.X)
+	edwards25519.FeNeg(&e.negR.T, &e.negR.T)
+
+	h := sha512.New()
+	writeDom(h, domPrefix, context)
+	h.Write(sig[:32])
+	h.Write(publicKey)
+	h.Write(message)
+	var digest [64]byte
+	h.Sum(digest[:0])
+	edwards25519.ScReduce(&e.k, &digest)
+	return true
+}
+
+// verifyBatch reports whether
+//
+//	[8]( [Σ z_i s_i]B - Σ [z_i]R_i - Σ [z_i k_i]A_i )
+//
+// is the identity, with the variable-time multiscalar multiplication of the
+// internal edwards25519 package.
+func verifyBatch(entries []batchEntry, z [][32]byte) bool {
+	var zero, bScalar [32]byte
+	scalars := make([][32]byte, 0, 2*len(entries))
+	points := make([]edwards25519.ExtendedGroupElement, 0, 2*len(entries))
+	for i := range entries {
+		e := &entries[i]
+		var sum, zk [32]byte
+		edwards25519.ScMulAdd(&sum, &z[i], &e.s, &bScalar)
+		bScalar = sum
+		edwards25519.ScMulAdd(&zk, &z[i], &e.k, &zero)
+		scalars = append(scalars, z[i], zk)
+		points = append(points, e.negR, e.negA)
+	}
+
+	var r edwards25519.ProjectiveGroupElement
+	edwards25519.GeMultiScalarMultVartime(&r, scalars, points, &bScalar)
+
+	// Multiply by the cofactor to discard any component of small order.
+	var t edwards25519.CompletedGroupElement
+	for i := 0; i < 3; i++ {
+		r.Double(&t)
+		t.ToProjective(&r)
+	}
+	var encoded [32]byte
+	r.ToBytes(&encoded)
+	return encoded == [32]byte{1}
+}
@@ -0,0 +1,162 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/benchlab/bench-crypto/rand"
+	"github.com/benchlab/bench-crypto/sha512"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/int/edwards25519"
+)
+
+func TestBatchVerifier(t *testing.T) {
+	var v BatchVerifier
+	if ok, valid, err := v.Verify(nil); !ok || len(valid) != 0 || err != nil {
+		t.Errorf("empty batch: Verify() = %v, %v, %v", ok, valid, err)
+	}
+
+	var messages [][]byte
+	for i := 0; i < 40; i++ {
+		public, private, _ := GenerateKey(rand.Reader)
+		message := []byte("message " + strconv.Itoa(i))
+		v.Add(public, message, Sign(private, message))
+		messages = append(messages, message)
+	}
+	if v.Len() != 40 {
+		t.Fatalf("Len() = %d, want 40", v.Len())
+	}
+	ok, valid, err := v.Verify(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("valid batch rejected")
+	}
+	for i, ok := range valid {
+		if !ok {
+			t.Errorf("valid signature %d reported as invalid", i)
+		}
+	}
+
+	// Corrupt a few entries: a signature of another message, a
+	// non-canonical s and a truncated signature.
+	public, private, _ := GenerateKey(rand.Reader)
+	v.entries[3].k = v.entries[4].k
+	sig := Sign(private, messages[0])
+	sig[63] |= 0xf0
+	v.Add(public, messages[0], sig)
+	v.Add(public, messages[0], Sign(private, messages[0])[:63])
+
+	ok, valid, err = v.Verify(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("invalid batch accepted")
+	}
+	for i, ok := range valid {
+		if want := i != 3 && i < 40; ok != want {
+			t.Errorf("signature %d: valid = %v, want %v", i, ok, want)
+		}
+	}
+}
+
+func TestBatchVerifierReadError(t *testing.T) {
+	var v BatchVerifier
+	public, private, _ := GenerateKey(rand.Reader)
+	v.Add(public, nil, Sign(private, nil))
+	errRead := errors.New("read error")
+	if _, _, err := v.Verify(errReader{errRead}); err != errRead {
+		t.Errorf("Verify() error = %v, want %v", err, errRead)
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestVerifyZIP215(t *testing.T) {
+	var zero zeroReader
+	public, private, _ := GenerateKey(zero)
+	message := []byte("test message")
+
+	// A non-canonical encoding of the identity as R, y = p + 1, with s = ka.
+	// The cofactorless rules of RFC 8032 would re-encode R and compare it,
+	// while ZIP 215 accepts any encoding.
+	rBytes, _ := hex.DecodeString("eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	digest := sha512.Sum512(private[:32])
+	var a [32]byte
+	copy(a[:], digest[:])
+	a[0] &= 248
+	a[31] &= 63
+	a[31] |= 64
+	h := sha512.New()
+	h.Write(rBytes)
+	h.Write(public)
+	h.Write(message)
+	var hram [64]byte
+	h.Sum(hram[:0])
+	var k, s, z [32]byte
+	edwards25519.ScReduce(&k, &hram)
+	edwards25519.ScMulAdd(&s, &k, &a, &z)
+	sig := append(rBytes, s[:]...)
+	checkZIP215(t, public, message, sig, true)
+	checkZIP215(t, public, []byte("wrong message"), sig, false)
+
+	// A public key of order 4, (sqrt(-1), 0), with R the identity and s = 0.
+	// The cofactored equation holds for every message, the cofactorless one
+	// only if k is a multiple of 4.
+	smallOrder := make(PublicKey, PublicKeySize)
+	identity := make([]byte, SignatureSize)
+	identity[0] = 1
+	for i := 0; i < 16; i++ {
+		checkZIP215(t, smallOrder, []byte{byte(i)}, identity, true)
+	}
+}
+
+// checkZIP215 checks that Verify, VerifyWithOptions and a batch agree that
+// sig is valid if want is true, and invalid otherwise.
+func checkZIP215(t *testing.T, public PublicKey, message, sig []byte, want bool) {
+	t.Helper()
+	if got := Verify(public, message, sig); got != want {
+		t.Errorf("Verify(%x, %x, %x) = %v, want %v", public, message, sig, got, want)
+	}
+	if err := VerifyWithOptions(public, message, sig, &Options{}); (err == nil) != want {
+		t.Errorf("VerifyWithOptions(%x, %x, %x) = %v, want valid = %v", public, message, sig, err, want)
+	}
+	var v BatchVerifier
+	v.Add(public, message, sig)
+	for i := 0; i < 3; i++ {
+		public, private, _ := GenerateKey(rand.Reader)
+		v.Add(public, message, Sign(private, message))
+	}
+	ok, valid, err := v.Verify(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok != want || valid[0] != want {
+		t.Errorf("batch with %x, %x, %x: Verify() = %v, %v, want %v", public, message, sig, ok, valid[0], want)
+	}
+}
+
+func BenchmarkBatchVerification(b *testing.B) {
+	var v BatchVerifier
+	message := []byte("Hello, world!")
+	for i := 0; i < 64; i++ {
+		public, private, _ := GenerateKey(rand.Reader)
+		v.Add(public, message, Sign(private, message))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ok, _, _ := v.Verify(nil); !ok {
+			b.Fatal("valid batch rejected")
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+
+	crypto "github.com/benchlab/bench-crypto"
+	"github.com/benchlab/bench-crypto/sha512"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/curve25519"
+	"golang.org/x/github.com/benchlab/bench-crypto/int/edwards25519"
+)
+
+// The Edwards curve of Ed25519 is birationally equivalent to Curve25519, the
+// Montgomery curve of X25519, through the map
+//
+//	u = (1 + y) / (1 - y)
+//	y = (u - 1) / (u + 1)
+//
+// which lets an Ed25519 key pair double as an X25519 key pair, for example for
+// use with golang.org/x/github.com/benchlab/bench-crypto/nacl/box. Keys that
+// are used for both signing and key exchange should be reserved for protocols
+// designed to tolerate that reuse.
+
+// PublicKeyToCurve25519 returns the Montgomery u-coordinate, as used by X25519,
+// of the point encoded by publicKey. It returns an error if publicKey is not a
+// canonical encoding of a valid point, or if the point is not in the
+// prime-order subgroup generated by the base point. Points of small order
+// would make X25519 shared secrets independent of the private key, and no
+// Ed25519 private key has a public key with a small-order component.
+//
+// It is equivalent to libsodium's crypto_sign_ed25519_pk_to_curve25519.
+func PublicKeyToCurve25519(publicKey PublicKey) ([]byte, error) {
+	if l := len(publicKey); l != PublicKeySize {
+		return nil, errors.New("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	var A edwards25519.ExtendedGroupElement
+	if !decodeCanonical(&A, publicKey) || hasSmallOrder(&A) || !isTorsionFree(&A) {
+		return nil, errors.New("ed25519: invalid public key")
+	}
+
+	// u = (Z + Y) / (Z - Y)
+	var n, d, u edwards25519.FieldElement
+	edwards25519.FeAdd(&n, &A.Z, &A.Y)
+	edwards25519.FeSub(&d, &A.Z, &A.Y)
+	edwards25519.FeInvert(&d, &d)
+	edwards25519.FeMul(&u, &n, &d)
+
+	out := make([]byte, 32)
+	var uBytes [32]byte
+	edwards25519.FeToBytes(&uBytes, &u)
+	copy(out, uBytes[:])
+	return out, nil
+}
+
+// PrivateKeyToCurve25519 returns the X25519 scalar corresponding to
+// privateKey, such that the X25519 public key derived from it matches the
+// output of PublicKeyToCurve25519 for privateKey's public key. It will panic
+// if len(privateKey) is not PrivateKeySize.
+//
+// It is equivalent to libsodium's crypto_sign_ed25519_sk_to_curve25519.
+func PrivateKeyToCurve25519(privateKey PrivateKey) []byte {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	digest := sha512.Sum512(privateKey[:32])
+	digest[0] &= 248
+	digest[31] &= 127
+	digest[31] |= 64
+
+	out := make([]byte, 32)
+	copy(out, digest[:32])
+	return out
+}
+
+// Curve25519ToPublicKey returns the Ed25519 public key of the point with
+// Montgomery u-coordinate u. Since u does not determine the sign of the
+// Edwards x-coordinate, the non-negative one is chosen, as in XEdDSA. It
+// returns an error if u is not a canonical 32-byte little-endian encoding, or
+// if it is not the coordinate of a point of large order on Curve25519.
+func Curve25519ToPublicKey(u []byte) (PublicKey, error) {
+	if l := len(u); l != 32 {
+		return nil, errors.New("ed25519: bad Curve25519 point length: " + strconv.Itoa(l))
+	}
+	var uBytes, check [32]byte
+	copy(uBytes[:], u)
+	var uFe, one, n, d, y edwards25519.FieldElement
+	edwards25519.FeFromBytes(&uFe, &uBytes)
+	edwards25519.FeToBytes(&check, &uFe)
+	if check != uBytes {
+		return nil, errors.New("ed25519: non-canonical Curve25519 point")
+	}
+
+	// y = (u - 1) / (u + 1), where u = -1 maps to no point.
+	edwards25519.FeOne(&one)
+	edwards25519.FeSub(&n, &uFe, &one)
+	edwards25519.FeAdd(&d, &uFe, &one)
+	if edwards25519.FeIsNonZero(&d) == 0 {
+		return nil, errors.New("ed25519: invalid Curve25519 point")
+	}
+	edwards25519.FeInvert(&d, &d)
+	edwards25519.FeMul(&y, &n, &d)
+
+	publicKey := make([]byte, PublicKeySize)
+	var yBytes [32]byte
+	edwards25519.FeToBytes(&yBytes, &y)
+	copy(publicKey, yBytes[:])
+
+	// A u-coordinate on the quadratic twist maps to a y for which there is no
+	// x, and is rejected here along with the points of small order.
+	var A edwards25519.ExtendedGroupElement
+	if !decodeCanonical(&A, publicKey) || hasSmallOrder(&A) {
+		return nil, errors.New("ed25519: invalid Curve25519 point")
+	}
+	return publicKey, nil
+}
+
+// Curve25519Key is a key pair that both signs with Ed25519 and agrees on
+// shared secrets with X25519, using the conversions above. It implements
+// crypto.Signer, with the semantics of PrivateKey.
+type Curve25519Key struct {
+	private PrivateKey
+	scalar  []byte
+	public  *Curve25519PublicKey
+}
+
+// Curve25519PublicKey is the public key of a Curve25519Key, which verifies
+// its Ed25519 signatures and is the peer of its X25519 key agreements.
+type Curve25519PublicKey struct {
+	public PublicKey
+	u      []byte
+}
+
+// GenerateCurve25519Key generates a Curve25519Key using entropy from rand. If
+// rand is nil, github.com/benchlab/bench-crypto/rand.Reader will be used.
+func GenerateCurve25519Key(rand io.Reader) (*Curve25519Key, error) {
+	_, privateKey, err := GenerateKey(rand)
+	if err != nil {
+		return nil, err
+	}
+	return NewCurve25519Key(privateKey)
+}
+
+// NewCurve25519Key returns the Curve25519Key of an Ed25519 private key. It
+// returns an error if len(privateKey) is not PrivateKeySize, or if its
+// public key is not valid, as checked by PublicKeyToCurve25519.
+func NewCurve25519Key(privateKey PrivateKey) (*Curve25519Key, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		return nil, errors.New("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	public, err := NewCurve25519PublicKey(PublicKey(privateKey[32:]))
+	if err != nil {
+		return nil, err
+	}
+	return &Curve25519Key{
+		private: append(PrivateKey{}, privateKey...),
+		scalar:  PrivateKeyToCurve25519(privateKey),
+		public:  public,
+	}, nil
+}
+
+// NewCurve25519PublicKey returns the Curve25519PublicKey of an Ed25519
+// public key. It returns an error in the cases of PublicKeyToCurve25519.
+func NewCurve25519PublicKey(publicKey PublicKey) (*Curve25519PublicKey, error) {
+	u, err := PublicKeyToCurve25519(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Curve25519PublicKey{public: append(PublicKey{}, publicKey...), u: u}, nil
+}
+
+// PublicKey returns the public key of k.
+func (k *Curve25519Key) PublicKey() *Curve25519PublicKey {
+	return k.public
+}
+
+// Ed25519 returns a copy of the Ed25519 private key of k.
+func (k *Curve25519Key) Ed25519() PrivateKey {
+	return append(PrivateKey{}, k.private...)
+}
+
+// X25519 returns a copy of the X25519 scalar of k.
+func (k *Curve25519Key) X25519() []byte {
+	return append([]byte{}, k.scalar...)
+}
+
+// Public returns the Ed25519 PublicKey of k, as PrivateKey.Public does, so
+// that signatures made through crypto.Signer verify as usual.
+func (k *Curve25519Key) Public() crypto.PublicKey {
+	return k.public.Ed25519()
+}
+
+// Sign signs message with the Ed25519 key of k, as PrivateKey.Sign does.
+func (k *Curve25519Key) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	return k.private.Sign(rand, message, opts)
+}
+
+// SharedSecret returns the X25519 shared secret of k and peer. It returns an
+// error if the result is all zeroes, as curve25519.X25519 does, which cannot
+// happen for a peer returned by NewCurve25519PublicKey.
+func (k *Curve25519Key) SharedSecret(peer *Curve25519PublicKey) ([]byte, error) {
+	return curve25519.X25519(k.scalar, peer.u)
+}
+
+// Ed25519 returns a copy of the Ed25519 public key of k.
+func (k *Curve25519PublicKey) Ed25519() PublicKey {
+	return append(PublicKey{}, k.public...)
+}
+
+// X25519 returns a copy of the X25519 public key, a Montgomery
+// u-coordinate, of k.
+func (k *Curve25519PublicKey) X25519() []byte {
+	return append([]byte{}, k.u...)
+}
+
+// Verify reports whether sig is a valid Ed25519 signature of message by k.
+func (k *Curve25519PublicKey) Verify(message, sig []byte) bool {
+	return Verify(k.public, message, sig)
+}
+
+// Equal reports whether k and x are the same public key.
+func (k *Curve25519PublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(*Curve25519PublicKey)
+	return ok && bytes.Equal(k.public, xx.public)
+}
+
+// decodeCanonical decodes s into p, and reports whether s is the canonical
+// encoding of a valid point.
+func decodeCanonical(p *edwards25519.ExtendedGroupElement, s []byte) bool {
+	var in, out [32]byte
+	copy(in[:], s)
+	if !p.FromBytes(&in) {
+		return false
+	}
+	p.ToBytes(&out)
+	return out == in
+}
+
+// hasSmallOrder reports whether p is in the torsion subgroup of order 8.
+func hasSmallOrder(p *edwards25519.ExtendedGroupElement) bool {
+	var c edwards25519.CompletedGroupElement
+	var r edwards25519.ProjectiveGroupElement
+	p.Double(&c)
+	c.ToProjective(&r)
+	r.Double(&c)
+	c.ToProjective(&r)
+	r.Double(&c)
+	c.ToProjective(&r)
+
+	var encoded [32]byte
+	r.ToBytes(&encoded)
+	return encoded == [32]byte{1}
+}
+
+// groupOrder is l = 2^252 + 27742317777372353535851937790883648493, the
+// order of the base point, in little-endian order.
+var groupOrder = [32]byte{
+	0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10,
+}
+
+// isTorsionFree reports whether p is in the prime-order subgroup, that is
+// whether [l]p is the identity.
+func isTorsionFree(p *edwards25519.ExtendedGroupElement) bool {
+	var r edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMult(&r, &groupOrder, p)
+
+	var encoded [32]byte
+	r.ToBytes(&encoded)
+	return encoded == [32]byte{1}
+}
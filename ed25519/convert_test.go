@@ -0,0 +1,176 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	crypto "github.com/benchlab/bench-crypto"
+	"github.com/benchlab/bench-crypto/rand"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/curve25519"
+	"golang.org/x/github.com/benchlab/bench-crypto/int/edwards25519"
+)
+
+func TestCurve25519Conversion(t *testing.T) {
+	// Test vector from libsodium's test/default/ed25519_convert.c.
+	seed, _ := hex.DecodeString("421151a459faeade3d247115f94aedae42318124095afabe4d1451a559faedee")
+	pub, _, _ := GenerateKey(bytes.NewReader(seed))
+	priv := append(append(PrivateKey{}, seed...), pub...)
+
+	u, err := PublicKeyToCurve25519(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(u), "f1814f0e8ff1043d8a44d25babff3cedcae6c22c3edaa48f857ae70de2baae50"; got != want {
+		t.Errorf("PublicKeyToCurve25519 = %s, want %s", got, want)
+	}
+	scalar := PrivateKeyToCurve25519(priv)
+	if got, want := hex.EncodeToString(scalar), "8052030376d47112be7f73ed7a019293dd12ad910b654455798b4667d73de166"; got != want {
+		t.Errorf("PrivateKeyToCurve25519 = %s, want %s", got, want)
+	}
+
+	for i := 0; i < 16; i++ {
+		pub, priv, _ := GenerateKey(rand.Reader)
+		u, err := PublicKeyToCurve25519(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		derived, err := curve25519.X25519(PrivateKeyToCurve25519(priv), curve25519.Basepoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(derived, u) {
+			t.Errorf("X25519 public key %x does not match converted public key %x", derived, u)
+		}
+
+		back, err := Curve25519ToPublicKey(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := append([]byte{}, pub...)
+		want[31] &= 0x7f
+		if !bytes.Equal(back, want) {
+			t.Errorf("Curve25519ToPublicKey = %x, want %x", back, want)
+		}
+	}
+}
+
+func TestCurve25519ConversionInvalid(t *testing.T) {
+	// An all-zero encoding is y = 0, a point of order 4.
+	if _, err := PublicKeyToCurve25519(make(PublicKey, PublicKeySize)); err == nil {
+		t.Errorf("PublicKeyToCurve25519 accepted a point of small order")
+	}
+	// The identity, y = 1, would map to u = 0.
+	identity := make(PublicKey, PublicKeySize)
+	identity[0] = 1
+	if _, err := PublicKeyToCurve25519(identity); err == nil {
+		t.Errorf("PublicKeyToCurve25519 accepted the identity")
+	}
+	// y = p + 1 is a non-canonical encoding of the identity.
+	nonCanonical, _ := hex.DecodeString("eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	if _, err := PublicKeyToCurve25519(nonCanonical); err == nil {
+		t.Errorf("PublicKeyToCurve25519 accepted a non-canonical encoding")
+	}
+	if _, err := PublicKeyToCurve25519(make(PublicKey, 31)); err == nil {
+		t.Errorf("PublicKeyToCurve25519 accepted a short key")
+	}
+
+	// A valid public key plus the point of order 4 at y = 0 has large order,
+	// but is outside the prime-order subgroup.
+	pub, _, _ := GenerateKey(rand.Reader)
+	var A, T, mixed edwards25519.ExtendedGroupElement
+	var pubBytes, torsion, mixedBytes [32]byte
+	copy(pubBytes[:], pub)
+	if !A.FromBytes(&pubBytes) || !T.FromBytes(&torsion) {
+		t.Fatal("could not decode points")
+	}
+	edwards25519.GeAdd(&mixed, &A, &T)
+	mixed.ToBytes(&mixedBytes)
+	if _, err := PublicKeyToCurve25519(mixedBytes[:]); err == nil {
+		t.Errorf("PublicKeyToCurve25519 accepted a point of mixed order")
+	}
+
+	// u = 0 and u = 1 have small order, u = 2 is on the twist, and u = p - 1
+	// has no corresponding Edwards point.
+	for _, u := range []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		"0200000000000000000000000000000000000000000000000000000000000000",
+		"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	} {
+		b, _ := hex.DecodeString(u)
+		if _, err := Curve25519ToPublicKey(b); err == nil {
+			t.Errorf("Curve25519ToPublicKey accepted %s", u)
+		}
+	}
+}
+
+func TestCurve25519Key(t *testing.T) {
+	alice, err := GenerateCurve25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := GenerateCurve25519Key(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s1, err := alice.SharedSecret(bob.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := bob.SharedSecret(alice.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s1, s2) {
+		t.Errorf("shared secrets differ: %x and %x", s1, s2)
+	}
+
+	// The X25519 halves must match the X25519 of the curve25519 package.
+	u, err := curve25519.X25519(alice.X25519(), curve25519.Basepoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(u, alice.PublicKey().X25519()) {
+		t.Errorf("X25519 public key %x does not match scalar", alice.PublicKey().X25519())
+	}
+
+	message := []byte("test message")
+	sig, err := alice.Sign(nil, message, crypto.Hash(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !alice.PublicKey().Verify(message, sig) {
+		t.Error("signature rejected by the Curve25519PublicKey")
+	}
+	if !Verify(alice.Public().(PublicKey), message, sig) {
+		t.Error("signature rejected by the Ed25519 public key")
+	}
+	if bob.PublicKey().Verify(message, sig) {
+		t.Error("signature accepted by another key")
+	}
+
+	again, err := NewCurve25519Key(alice.Ed25519())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.PublicKey().Equal(alice.PublicKey()) || again.PublicKey().Equal(bob.PublicKey()) {
+		t.Error("Equal does not compare the public keys")
+	}
+
+	// A public key of small order is rejected, so that shared secrets
+	// always depend on the private key.
+	if _, err := NewCurve25519PublicKey(make(PublicKey, PublicKeySize)); err == nil {
+		t.Error("NewCurve25519PublicKey accepted a point of small order")
+	}
+	if _, err := NewCurve25519Key(alice.Ed25519()[:32]); err == nil {
+		t.Error("NewCurve25519Key accepted a short private key")
+	}
+}
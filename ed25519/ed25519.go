@@ -6,14 +6,21 @@
 // https://ed25519.cr.yp.to/.
 //
 // These functions are also compatible with the “Ed25519” function defined in
-// RFC 8032.
+// RFC 8032. The Ed25519ph and Ed25519ctx variants of RFC 8032 are available
+// through PrivateKey.Sign and VerifyWithOptions with an Options value.
+//
+// Signatures are verified under the rules of ZIP 215
+// (https://zips.z.cash/zip-0215), which RFC 8032 permits: A and R may be any
+// encoding of a curve point, including non-canonical ones, s must be reduced
+// modulo the group order, and the cofactored equation [8][s]B = [8]R +
+// [8][k]A is checked. Unlike the cofactorless equation, it can be checked in
+// a batch, so Verify and BatchVerifier agree on every signature.
 package ed25519
 
 // This code is a port of the public domain, “ref10” implementation of ed25519
 // from SUPERCOP.
 
 import (
-	"bytes"
 	"errors"
 	"io"
 	"strconv"
@@ -23,7 +30,7 @@ import (
 	cryptorand "github.com/benchlab/bench-crypto/rand"
 	"github.com/benchlab/bench-crypto/sha512"
 
-	"golang.org/x/github.com/benchlab/bench-crypto/ed25519/internal/edwards25519"
+	"golang.org/x/github.com/benchlab/bench-crypto/int/edwards25519"
 )
 
 const (
@@ -48,19 +55,56 @@ func (priv PrivateKey) Public() crypto.PublicKey {
 	return PublicKey(publicKey)
 }
 
-// Sign signs the given message with priv.
-// Ed25519 performs two passes over messages to be signed and therefore cannot
-// handle pre-hashed messages. Thus opts.HashFunc() must return zero to
-// indicate the message hasn't been hashed. This can be achieved by passing
-// crypto.Hash(0) as the value for opts.
+// Sign signs the given message with priv. rand is ignored.
+//
+// If opts.HashFunc() is crypto.SHA512, the pre-hashed variant Ed25519ph is
+// used and message is expected to be a SHA-512 hash, otherwise opts.HashFunc()
+// must be crypto.Hash(0) and the message must not be hashed, as Ed25519
+// performs two passes over messages to be signed.
+//
+// A value of type *Options can be used as opts, or crypto.Hash(0) or
+// crypto.SHA512 directly to select plain Ed25519 or Ed25519ph, respectively.
 func (priv PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) (signature []byte, err error) {
-	if opts.HashFunc() != crypto.Hash(0) {
-		return nil, errors.New("ed25519: cannot sign hashed message")
+	hash := opts.HashFunc()
+	context := ""
+	if opts, ok := opts.(*Options); ok {
+		context = opts.Context
 	}
+	switch {
+	case hash == crypto.SHA512: // Ed25519ph
+		if l := len(message); l != sha512.Size {
+			return nil, errors.New("ed25519: bad Ed25519ph message hash length: " + strconv.Itoa(l))
+		}
+		if l := len(context); l > 255 {
+			return nil, errors.New("ed25519: bad Ed25519ph context length: " + strconv.Itoa(l))
+		}
+		return sign(priv, message, domPrefixPh, context), nil
+	case hash == crypto.Hash(0) && context != "": // Ed25519ctx
+		if l := len(context); l > 255 {
+			return nil, errors.New("ed25519: bad Ed25519ctx context length: " + strconv.Itoa(l))
+		}
+		return sign(priv, message, domPrefixCtx, context), nil
+	case hash == crypto.Hash(0): // Ed25519
+		return Sign(priv, message), nil
+	default:
+		return nil, errors.New("ed25519: expected opts.HashFunc() zero (unhashed message, for standard Ed25519) or SHA-512 (for Ed25519ph)")
+	}
+}
 
-	return Sign(priv, message), nil
+// Options can be used with PrivateKey.Sign or VerifyWithOptions
+// to select Ed25519 variants.
+type Options struct {
+	// Hash can be zero for regular Ed25519, or crypto.SHA512 for Ed25519ph.
+	Hash crypto.Hash
+
+	// Context, if not empty, selects Ed25519ctx or provides the context string
+	// for Ed25519ph. It can be at most 255 bytes in length.
+	Context string
 }
 
+// HashFunc returns o.Hash.
+func (o *Options) HashFunc() crypto.Hash { return o.Hash }
+
 // GenerateKey generates a public/private key pair using entropy from rand.
 // If rand is nil, github.com/benchlab/bench-crypto/rand.Reader will be used.
 func GenerateKey(rand io.Reader) (publicKey PublicKey, privateKey PrivateKey, err error) {
@@ -96,6 +140,33 @@ func GenerateKey(rand io.Reader) (publicKey PublicKey, privateKey PrivateKey, er
 // Sign signs the message with privateKey and returns a signature. It will
 // panic if len(privateKey) is not PrivateKeySize.
 func Sign(privateKey PrivateKey, message []byte) []byte {
+	return sign(privateKey, message, domPrefixPure, "")
+}
+
+// Domain separation prefixes used to disambiguate Ed25519/Ed25519ph/Ed25519ctx.
+// See RFC 8032, Section 2 and Section 5.1.
+const (
+	// domPrefixPure is empty for pure Ed25519.
+	domPrefixPure = ""
+	// domPrefixPh is dom2(phflag=1) for Ed25519ph. It must be followed by the
+	// uint8-length prefixed context.
+	domPrefixPh = "SigEd25519 no Ed25519 collisions\x01"
+	// domPrefixCtx is dom2(phflag=0) for Ed25519ctx. It must be followed by the
+	// uint8-length prefixed context.
+	domPrefixCtx = "SigEd25519 no Ed25519 collisions\x00"
+)
+
+// writeDom writes dom2(x, context) to h, or nothing for pure Ed25519.
+func writeDom(h io.Writer, domPrefix, context string) {
+	if domPrefix == domPrefixPure {
+		return
+	}
+	io.WriteString(h, domPrefix)
+	h.Write([]byte{byte(len(context))})
+	io.WriteString(h, context)
+}
+
+func sign(privateKey PrivateKey, message []byte, domPrefix, context string) []byte {
 	if l := len(privateKey); l != PrivateKeySize {
 		panic("ed25519: bad private key length: " + strconv.Itoa(l))
 	}
@@ -112,6 +183,7 @@ func Sign(privateKey PrivateKey, message []byte) []byte {
 	expandedSecretKey[31] |= 64
 
 	h.Reset()
+	writeDom(h, domPrefix, context)
 	h.Write(digest1[32:])
 	h.Write(message)
 	h.Sum(messageDigest[:0])
@@ -125,6 +197,7 @@ func Sign(privateKey PrivateKey, message []byte) []byte {
 	R.ToBytes(&encodedR)
 
 	h.Reset()
+	writeDom(h, domPrefix, context)
 	h.Write(encodedR[:])
 	h.Write(privateKey[32:])
 	h.Write(message)
@@ -142,49 +215,56 @@ func Sign(privateKey PrivateKey, message []byte) []byte {
 	return signature
 }
 
-// Verify reports whether sig is a valid signature of message by publicKey. It
-// will panic if len(publicKey) is not PublicKeySize.
+// Verify reports whether sig is a valid signature of message by publicKey,
+// under the ZIP 215 rules described in the package documentation. It will
+// panic if len(publicKey) is not PublicKeySize.
 func Verify(publicKey PublicKey, message, sig []byte) bool {
-	if l := len(publicKey); l != PublicKeySize {
-		panic("ed25519: bad public key length: " + strconv.Itoa(l))
-	}
-
-	if len(sig) != SignatureSize || sig[63]&224 != 0 {
-		return false
-	}
+	return verify(publicKey, message, sig, domPrefixPure, "")
+}
 
-	var A edwards25519.ExtendedGroupElement
-	var publicKeyBytes [32]byte
-	copy(publicKeyBytes[:], publicKey)
-	if !A.FromBytes(&publicKeyBytes) {
-		return false
+// VerifyWithOptions reports whether sig is a valid signature of message by
+// publicKey. A valid signature is indicated by returning a nil error. It will
+// panic if len(publicKey) is not PublicKeySize.
+//
+// If opts.Hash is crypto.SHA512, the pre-hashed variant Ed25519ph is used and
+// message is expected to be a SHA-512 hash, otherwise opts.Hash must be
+// crypto.Hash(0) and the message must not be hashed, as Ed25519 performs two
+// passes over messages to be signed.
+func VerifyWithOptions(publicKey PublicKey, message, sig []byte, opts *Options) error {
+	switch {
+	case opts.Hash == crypto.SHA512: // Ed25519ph
+		if l := len(message); l != sha512.Size {
+			return errors.New("ed25519: bad Ed25519ph message hash length: " + strconv.Itoa(l))
+		}
+		if l := len(opts.Context); l > 255 {
+			return errors.New("ed25519: bad Ed25519ph context length: " + strconv.Itoa(l))
+		}
+		if !verify(publicKey, message, sig, domPrefixPh, opts.Context) {
+			return errors.New("ed25519: invalid signature")
+		}
+		return nil
+	case opts.Hash == crypto.Hash(0) && opts.Context != "": // Ed25519ctx
+		if l := len(opts.Context); l > 255 {
+			return errors.New("ed25519: bad Ed25519ctx context length: " + strconv.Itoa(l))
+		}
+		if !verify(publicKey, message, sig, domPrefixCtx, opts.Context) {
+			return errors.New("ed25519: invalid signature")
+		}
+		return nil
+	case opts.Hash == crypto.Hash(0): // Ed25519
+		if !verify(publicKey, message, sig, domPrefixPure, "") {
+			return errors.New("ed25519: invalid signature")
+		}
+		return nil
+	default:
+		return errors.New("ed25519: expected opts.Hash zero (unhashed message, for standard Ed25519) or SHA-512 (for Ed25519ph)")
 	}
-	edwards25519.FeNeg(&A.X, &A.X)
-	edwards25519.FeNeg(&A.T, &A.T)
-
-	h := sha512.New()
-	h.Write(sig[:32])
-	h.Write(publicKey[:])
-	h.Write(message)
-	var digest [64]byte
-	h.Sum(digest[:0])
-
-	var hReduced [32]byte
-	edwards25519.ScReduce(&hReduced, &digest)
-
-	var R edwards25519.ProjectiveGroupElement
-	var s [32]byte
-	copy(s[:], sig[32:])
+}
 
-	// https://tools.ietf.org/html/rfc8032#section-5.1.7 requires that s be in
-	// the range [0, order) in order to prevent signature malleability.
-	if !edwards25519.ScMinimal(&s) {
+func verify(publicKey PublicKey, message, sig []byte, domPrefix, context string) bool {
+	var e batchEntry
+	if !e.init(publicKey, message, sig, domPrefix, context) {
 		return false
 	}
-
-	edwards25519.GeDoubleScalarMultVartime(&R, &hReduced, &A, &s)
-
-	var checkR [32]byte
-	R.ToBytes(&checkR)
-	return bytes.Equal(sig[:32], checkR[:])
+	return verifyBatch([]batchEntry{e}, [][32]byte{scalarOne})
 }
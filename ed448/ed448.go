@@ -0,0 +1,275 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ed448 implements the Ed448 signature algorithm of RFC 8032, over
+// the edwards448 curve. It offers a security level of about 224 bits.
+//
+// The Ed448ph variant and context strings are available through
+// PrivateKey.Sign and VerifyWithOptions with an Options value. Keys and
+// signatures are 57, 114 and 114 bytes long, and the API mirrors that of
+// golang.org/x/github.com/benchlab/bench-crypto/ed25519.
+package ed448 // import "golang.org/x/github.com/benchlab/bench-crypto/ed448"
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+
+	crypto "github.com/benchlab/bench-crypto"
+
+	cryptorand "github.com/benchlab/bench-crypto/rand"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/sha3"
+)
+
+const (
+	// PublicKeySize is the size, in bytes, of public keys as used in this package.
+	PublicKeySize = 57
+	// PrivateKeySize is the size, in bytes, of private keys as used in this package.
+	PrivateKeySize = 114
+	// SignatureSize is the size, in bytes, of signatures generated and verified by this package.
+	SignatureSize = 114
+	// SeedSize is the size, in bytes, of private key seeds. These are the
+	// private key representations used by RFC 8032.
+	SeedSize = 57
+	// PrehashSize is the size, in bytes, of the SHAKE256 message hash signed
+	// by Ed448ph.
+	PrehashSize = 64
+)
+
+// PublicKey is the type of Ed448 public keys.
+type PublicKey []byte
+
+// PrivateKey is the type of Ed448 private keys, the seed followed by the
+// public key. It implements crypto.Signer.
+type PrivateKey []byte
+
+// Public returns the PublicKey corresponding to priv.
+func (priv PrivateKey) Public() crypto.PublicKey {
+	publicKey := make([]byte, PublicKeySize)
+	copy(publicKey, priv[SeedSize:])
+	return PublicKey(publicKey)
+}
+
+// Seed returns the private key seed corresponding to priv.
+func (priv PrivateKey) Seed() []byte {
+	seed := make([]byte, SeedSize)
+	copy(seed, priv[:SeedSize])
+	return seed
+}
+
+// Sign signs the given message with priv. rand is ignored.
+//
+// opts must be crypto.Hash(0) for plain Ed448, or an *Options value to select
+// Ed448ph or a context string. For Ed448ph, message is expected to be the
+// PrehashSize-byte SHAKE256 hash of the message, as computed by Prehash.
+func (priv PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	var o Options
+	switch opts := opts.(type) {
+	case *Options:
+		o = *opts
+	default:
+		if opts.HashFunc() != crypto.Hash(0) {
+			return nil, errors.New("ed448: cannot sign hashed message")
+		}
+	}
+	if l := len(o.Context); l > 255 {
+		return nil, errors.New("ed448: bad context length: " + strconv.Itoa(l))
+	}
+	if l := len(message); o.Prehashed && l != PrehashSize {
+		return nil, errors.New("ed448: bad Ed448ph message hash length: " + strconv.Itoa(l))
+	}
+	return sign(priv, message, o.Prehashed, o.Context), nil
+}
+
+// Options can be used with PrivateKey.Sign or VerifyWithOptions
+// to select Ed448 variants.
+type Options struct {
+	// Prehashed selects Ed448ph, for which the message must be hashed with
+	// Prehash. There is no crypto.Hash value for the SHAKE256 prehash of
+	// Ed448ph, so HashFunc returns zero regardless.
+	Prehashed bool
+
+	// Context is the context string of Ed448 or Ed448ph. It can be at most
+	// 255 bytes in length.
+	Context string
+}
+
+// HashFunc returns crypto.Hash(0).
+func (o *Options) HashFunc() crypto.Hash { return crypto.Hash(0) }
+
+// Prehash returns the PrehashSize-byte SHAKE256 hash of message, to be signed
+// and verified with Ed448ph.
+func Prehash(message []byte) []byte {
+	out := make([]byte, PrehashSize)
+	sha3.ShakeSum256(out, message)
+	return out
+}
+
+// GenerateKey generates a public/private key pair using entropy from rand.
+// If rand is nil, github.com/benchlab/bench-crypto/rand.Reader will be used.
+func GenerateKey(rand io.Reader) (PublicKey, PrivateKey, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, nil, err
+	}
+
+	privateKey := NewKeyFromSeed(seed)
+	publicKey := make([]byte, PublicKeySize)
+	copy(publicKey, privateKey[SeedSize:])
+
+	return publicKey, privateKey, nil
+}
+
+// NewKeyFromSeed calculates a private key from a seed. It will panic if
+// len(seed) is not SeedSize.
+func NewKeyFromSeed(seed []byte) PrivateKey {
+	if l := len(seed); l != SeedSize {
+		panic("ed448: bad seed length: " + strconv.Itoa(l))
+	}
+
+	s, _ := expandSeed(seed)
+	var A point
+	A.scalarMult(s[:], &basePoint)
+
+	privateKey := make([]byte, PrivateKeySize)
+	copy(privateKey, seed)
+	copy(privateKey[SeedSize:], A.toBytes())
+	return privateKey
+}
+
+// expandSeed returns the clamped secret scalar and the prefix derived from
+// seed, following RFC 8032, Section 5.2.5.
+func expandSeed(seed []byte) (s, prefix [57]byte) {
+	var h [114]byte
+	sha3.ShakeSum256(h[:], seed)
+	copy(s[:], h[:57])
+	copy(prefix[:], h[57:])
+	s[0] &= 0xfc
+	s[55] |= 0x80
+	s[56] = 0
+	return s, prefix
+}
+
+// Sign signs the message with privateKey and returns a signature. It will
+// panic if len(privateKey) is not PrivateKeySize.
+func Sign(privateKey PrivateKey, message []byte) []byte {
+	return sign(privateKey, message, false, "")
+}
+
+// newHash returns a SHAKE256 instance initialized with dom4(phflag, context)
+// of RFC 8032, Section 5.2. Unlike Ed25519, Ed448 always includes it.
+func newHash(prehashed bool, context string) sha3.ShakeHash {
+	h := sha3.NewShake256()
+	flag := byte(0)
+	if prehashed {
+		flag = 1
+	}
+	io.WriteString(h, "SigEd448")
+	h.Write([]byte{flag, byte(len(context))})
+	io.WriteString(h, context)
+	return h
+}
+
+// hashToScalar returns the 114-byte output of h reduced modulo L.
+func hashToScalar(h sha3.ShakeHash) scalar {
+	var digest [114]byte
+	h.Read(digest[:])
+	return scReduce(digest[:])
+}
+
+func sign(privateKey PrivateKey, message []byte, prehashed bool, context string) []byte {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed448: bad private key length: " + strconv.Itoa(l))
+	}
+	s, prefix := expandSeed(privateKey[:SeedSize])
+
+	h := newHash(prehashed, context)
+	h.Write(prefix[:])
+	h.Write(message)
+	r := hashToScalar(h)
+
+	var R point
+	rBytes := r.bytes()
+	R.scalarMult(rBytes, &basePoint)
+	encodedR := R.toBytes()
+
+	h = newHash(prehashed, context)
+	h.Write(encodedR)
+	h.Write(privateKey[SeedSize:])
+	h.Write(message)
+	k := hashToScalar(h)
+
+	sReduced := scReduce(s[:])
+	S := scMulAdd(&k, &sReduced, &r)
+
+	signature := make([]byte, SignatureSize)
+	copy(signature, encodedR)
+	copy(signature[57:], S.bytes())
+	return signature
+}
+
+// Verify reports whether sig is a valid signature of message by publicKey. It
+// will panic if len(publicKey) is not PublicKeySize.
+func Verify(publicKey PublicKey, message, sig []byte) bool {
+	return verify(publicKey, message, sig, false, "")
+}
+
+// VerifyWithOptions reports whether sig is a valid signature of message by
+// publicKey. A valid signature is indicated by returning a nil error. It will
+// panic if len(publicKey) is not PublicKeySize.
+//
+// If opts.Prehashed is set, the Ed448ph variant is used and message is
+// expected to be the output of Prehash.
+func VerifyWithOptions(publicKey PublicKey, message, sig []byte, opts *Options) error {
+	if l := len(opts.Context); l > 255 {
+		return errors.New("ed448: bad context length: " + strconv.Itoa(l))
+	}
+	if l := len(message); opts.Prehashed && l != PrehashSize {
+		return errors.New("ed448: bad Ed448ph message hash length: " + strconv.Itoa(l))
+	}
+	if !verify(publicKey, message, sig, opts.Prehashed, opts.Context) {
+		return errors.New("ed448: invalid signature")
+	}
+	return nil
+}
+
+func verify(publicKey PublicKey, message, sig []byte, prehashed bool, context string) bool {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed448: bad public key length: " + strconv.Itoa(l))
+	}
+	if len(sig) != SignatureSize {
+		return false
+	}
+
+	var A, R point
+	if !A.fromBytes(publicKey) || !R.fromBytes(sig[:57]) {
+		return false
+	}
+	// Reject S >= L.
+	S := scReduce(sig[57:])
+	if !bytes.Equal(S.bytes(), sig[57:]) {
+		return false
+	}
+
+	h := newHash(prehashed, context)
+	h.Write(sig[:57])
+	h.Write(publicKey)
+	h.Write(message)
+	k := hashToScalar(h)
+
+	// Check [4][S]B = [4]R + [4][k]A.
+	var lhs, rhs point
+	lhs.scalarMult(S.bytes(), &basePoint)
+	lhs.mulByCofactor(&lhs)
+	rhs.scalarMult(k.bytes(), &A)
+	rhs.add(&rhs, &R)
+	rhs.mulByCofactor(&rhs)
+	return lhs.equal(&rhs)
+}
@@ -0,0 +1,253 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed448
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	crypto "github.com/benchlab/bench-crypto"
+
+	"github.com/benchlab/bench-crypto/rand"
+)
+
+// The test vectors of RFC 8032, Section 7.4 and Section 7.5, except for the
+// 256 and 1023 octet messages.
+var rfc8032Tests = []struct {
+	seed, pub, msg, sig string
+	ctx                 string
+	ph                  bool
+}{
+	{
+		// Blank
+		seed: "6c82a562cb808d10d632be89c8513ebf6c929f34ddfa8c9f63c9960ef6e348a3528c8a3fcc2f044e39a3fc5b94492f8f032e7549a20098f95b",
+		pub:  "5fd7449b59b461fd2ce787ec616ad46a1da1342485a70e1f8a0ea75d80e96778edf124769b46c7061bd6783df1e50f6cd1fa1abeafe8256180",
+		msg:  "",
+		sig: "533a37f6bbe457251f023c0d88f976ae2dfb504a843e34d2074fd823d41a591f2b233f034f628281f2fd7a22ddd47d7828c59bd0a21bfd3980" +
+			"ff0d2028d4b18a9df63e006c5d1c2d345b925d8dc00b4104852db99ac5c7cdda8530a113a0f4dbb61149f05a7363268c71d95808ff2e652600",
+	},
+	{
+		// 1 octet
+		seed: "c4eab05d357007c632f3dbb48489924d552b08fe0c353a0d4a1f00acda2c463afbea67c5e8d2877c5e3bc397a659949ef8021e954e0a12274e",
+		pub:  "43ba28f430cdff456ae531545f7ecd0ac834a55d9358c0372bfa0c6c6798c0866aea01eb00742802b8438ea4cb82169c235160627b4c3a9480",
+		msg:  "03",
+		sig: "26b8f91727bd62897af15e41eb43c377efb9c610d48f2335cb0bd0087810f4352541b143c4b981b7e18f62de8ccdf633fc1bf037ab7cd77980" +
+			"5e0dbcc0aae1cbcee1afb2e027df36bc04dcecbf154336c19f0af7e0a6472905e799f1953d2a0ff3348ab21aa4adafd1d234441cf807c03a00",
+	},
+	{
+		// 1 octet (with context)
+		seed: "c4eab05d357007c632f3dbb48489924d552b08fe0c353a0d4a1f00acda2c463afbea67c5e8d2877c5e3bc397a659949ef8021e954e0a12274e",
+		pub:  "43ba28f430cdff456ae531545f7ecd0ac834a55d9358c0372bfa0c6c6798c0866aea01eb00742802b8438ea4cb82169c235160627b4c3a9480",
+		msg:  "03",
+		ctx:  "foo",
+		sig: "d4f8f6131770dd46f40867d6fd5d5055de43541f8c5e35abbcd001b32a89f7d2151f7647f11d8ca2ae279fb842d607217fce6e042f6815ea00" +
+			"0c85741de5c8da1144a6a1aba7f96de42505d7a7298524fda538fccbbb754f578c1cad10d54d0d5428407e85dcbc98a49155c13764e66c3c00",
+	},
+	{
+		// 11 octets
+		seed: "cd23d24f714274e744343237b93290f511f6425f98e64459ff203e8985083ffdf60500553abc0e05cd02184bdb89c4ccd67e187951267eb328",
+		pub:  "dcea9e78f35a1bf3499a831b10b86c90aac01cd84b67a0109b55a36e9328b1e365fce161d71ce7131a543ea4cb5f7e9f1d8b00696447001400",
+		msg:  "0c3e544074ec63b0265e0c",
+		sig: "1f0a8888ce25e8d458a21130879b840a9089d999aaba039eaf3e3afa090a09d389dba82c4ff2ae8ac5cdfb7c55e94d5d961a29fe0109941e00" +
+			"b8dbdeea6d3b051068df7254c0cdc129cbe62db2dc957dbb47b51fd3f213fb8698f064774250a5028961c9bf8ffd973fe5d5c206492b140e00",
+	},
+	{
+		// 12 octets
+		seed: "258cdd4ada32ed9c9ff54e63756ae582fb8fab2ac721f2c8e676a72768513d939f63dddb55609133f29adf86ec9929dccb52c1c5fd2ff7e21b",
+		pub:  "3ba16da0c6f2cc1f30187740756f5e798d6bc5fc015d7c63cc9510ee3fd44adc24d8e968b6e46e6f94d19b945361726bd75e149ef09817f580",
+		msg:  "64a65f3cdedcdd66811e2915",
+		sig: "7eeeab7c4e50fb799b418ee5e3197ff6bf15d43a14c34389b59dd1a7b1b85b4ae90438aca634bea45e3a2695f1270f07fdcdf7c62b8efeaf00" +
+			"b45c2c96ba457eb1a8bf075a3db28e5c24f6b923ed4ad747c3c9e03c7079efb87cb110d3a99861e72003cbae6d6b8b827e4e6c143064ff3c00",
+	},
+	{
+		// 13 octets
+		seed: "7ef4e84544236752fbb56b8f31a23a10e42814f5f55ca037cdcc11c64c9a3b2949c1bb60700314611732a6c2fea98eebc0266a11a93970100e",
+		pub:  "b3da079b0aa493a5772029f0467baebee5a8112d9d3a22532361da294f7bb3815c5dc59e176b4d9f381ca0938e13c6c07b174be65dfa578e80",
+		msg:  "64a65f3cdedcdd66811e2915e7",
+		sig: "6a12066f55331b6c22acd5d5bfc5d71228fbda80ae8dec26bdd306743c5027cb4890810c162c027468675ecf645a83176c0d7323a2ccde2d80" +
+			"efe5a1268e8aca1d6fbc194d3f77c44986eb4ab4177919ad8bec33eb47bbb5fc6e28196fd1caf56b4e7e0ba5519234d047155ac727a1053100",
+	},
+	{
+		// 64 octets
+		seed: "d65df341ad13e008567688baedda8e9dcdc17dc024974ea5b4227b6530e339bff21f99e68ca6968f3cca6dfe0fb9f4fab4fa135d5542ea3f01",
+		pub:  "df9705f58edbab802c7f8363cfe5560ab1c6132c20a9f1dd163483a26f8ac53a39d6808bf4a1dfbd261b099bb03b3fb50906cb28bd8a081f00",
+		msg:  "bd0f6a3747cd561bdddf4640a332461a4a30a12a434cd0bf40d766d9c6d458e5512204a30c17d1f50b5079631f64eb3112182da3005835461113718d1a5ef944",
+		sig: "554bc2480860b49eab8532d2a533b7d578ef473eeb58c98bb2d0e1ce488a98b18dfde9b9b90775e67f47d4a1c3482058efc9f40d2ca033a080" +
+			"1b63d45b3b722ef552bad3b4ccb667da350192b61c508cf7b6b5adadc2c8d9a446ef003fb05cba5f30e88e36ec2703b349ca229c2670833900",
+	},
+	{
+		// TEST abc
+		seed: "833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42ef7822e0d5104127dc05d6dbefde69e3ab2cec7c867c6e2c49",
+		pub:  "259b71c19f83ef77a7abd26524cbdb3161b590a48f7d17de3ee0ba9c52beb743c09428a131d6b1b57303d90d8132c276d5ed3d5d01c0f53880",
+		msg:  "616263",
+		ph:   true,
+		sig: "822f6901f7480f3d5f562c592994d9693602875614483256505600bbc281ae381f54d6bce2ea911574932f52a4e6cadd78769375ec3ffd1b80" +
+			"1a0d9b3f4030cd433964b6457ea39476511214f97469b57dd32dbc560a9a94d00bff07620464a3ad203df7dc7ce360c3cd3696d9d9fab90f00",
+	},
+	{
+		// TEST abc (with context)
+		seed: "833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42ef7822e0d5104127dc05d6dbefde69e3ab2cec7c867c6e2c49",
+		pub:  "259b71c19f83ef77a7abd26524cbdb3161b590a48f7d17de3ee0ba9c52beb743c09428a131d6b1b57303d90d8132c276d5ed3d5d01c0f53880",
+		msg:  "616263",
+		ctx:  "foo",
+		ph:   true,
+		sig: "c32299d46ec8ff02b54540982814dce9a05812f81962b649d528095916a2aa481065b1580423ef927ecf0af5888f90da0f6a9a85ad5dc3f280" +
+			"d91224ba9911a3653d00e484e2ce232521481c8658df304bb7745a73514cdb9bf3e15784ab71284f8d0704a608c54a6b62d97beb511d132100",
+	},
+}
+
+func TestRFC8032(t *testing.T) {
+	for i, test := range rfc8032Tests {
+		seed, _ := hex.DecodeString(test.seed)
+		msg, _ := hex.DecodeString(test.msg)
+		if test.ph {
+			msg = Prehash(msg)
+		}
+		opts := &Options{Prehashed: test.ph, Context: test.ctx}
+
+		priv := NewKeyFromSeed(seed)
+		if got := hex.EncodeToString(priv[SeedSize:]); got != test.pub {
+			t.Errorf("#%d: public key %s, want %s", i, got, test.pub)
+			continue
+		}
+		sig, err := priv.Sign(nil, msg, opts)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if got := hex.EncodeToString(sig); got != test.sig {
+			t.Errorf("#%d: signature %s, want %s", i, got, test.sig)
+		}
+		pub := priv.Public().(PublicKey)
+		if err := VerifyWithOptions(pub, msg, sig, opts); err != nil {
+			t.Errorf("#%d: %v", i, err)
+		}
+		if !test.ph && test.ctx == "" && !Verify(pub, msg, sig) {
+			t.Errorf("#%d: Verify failed", i)
+		}
+
+		// Signatures are bound to the variant and the context.
+		other := &Options{Prehashed: test.ph, Context: test.ctx + "x"}
+		if VerifyWithOptions(pub, msg, sig, other) == nil {
+			t.Errorf("#%d: signature valid with a different context", i)
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	public, private, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("test message")
+	sig := Sign(private, message)
+	if !Verify(public, message, sig) {
+		t.Errorf("valid signature rejected")
+	}
+
+	wrongMessage := []byte("wrong message")
+	if Verify(public, wrongMessage, sig) {
+		t.Errorf("signature of different message accepted")
+	}
+
+	for _, i := range []int{0, 56, 57, 113} {
+		bad := append([]byte{}, sig...)
+		bad[i] ^= 0x01
+		if Verify(public, message, bad) {
+			t.Errorf("signature with byte %d flipped accepted", i)
+		}
+	}
+	// S + L is a non-canonical encoding of S.
+	bad := append([]byte{}, sig...)
+	S := scReduce(sig[57:])
+	var carry uint32
+	for i := range S {
+		v := S[i] + scL[i] + carry
+		carry = v >> scLimbBits
+		S[i] = v & scLimbMask
+	}
+	copy(bad[57:], S.bytes())
+	if Verify(public, message, bad) {
+		t.Errorf("signature with S + L accepted")
+	}
+
+	if VerifyWithOptions(public, message, sig, &Options{Context: "ctx"}) == nil {
+		t.Errorf("Ed448 signature accepted as Ed448 with context")
+	}
+	if VerifyWithOptions(public, Prehash(message), sig, &Options{Prehashed: true}) == nil {
+		t.Errorf("Ed448 signature accepted as Ed448ph")
+	}
+}
+
+func TestCryptoSigner(t *testing.T) {
+	var zero zeroReader
+	public, private, _ := GenerateKey(zero)
+
+	signer := crypto.Signer(private)
+
+	publicInterface := signer.Public()
+	public2, ok := publicInterface.(PublicKey)
+	if !ok {
+		t.Fatalf("expected PublicKey from Public() but got %T", publicInterface)
+	}
+
+	if !bytes.Equal(public, public2) {
+		t.Errorf("public keys do not match: original:%x vs Public():%x", public, public2)
+	}
+
+	message := []byte("message")
+	var noHash crypto.Hash
+	signature, err := signer.Sign(zero, message, noHash)
+	if err != nil {
+		t.Fatalf("error from Sign(): %s", err)
+	}
+
+	if !Verify(public, message, signature) {
+		t.Errorf("Verify failed on signature from Sign()")
+	}
+
+	if _, err := signer.Sign(zero, message, crypto.SHA512); err == nil {
+		t.Errorf("Sign accepted a hashed message")
+	}
+	if _, err := signer.Sign(zero, message, &Options{Prehashed: true}); err == nil {
+		t.Errorf("Sign accepted a message of the wrong length for Ed448ph")
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(buf []byte) (int, error) {
+	for i := range buf {
+		buf[i] = 0
+	}
+	return len(buf), nil
+}
+
+func BenchmarkSigning(b *testing.B) {
+	_, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		b.Fatal(err)
+	}
+	message := []byte("Hello, world!")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Sign(priv, message)
+	}
+}
+
+func BenchmarkVerification(b *testing.B) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		b.Fatal(err)
+	}
+	message := []byte("Hello, world!")
+	signature := Sign(priv, message)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Verify(pub, message, signature)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed448
+
+import "golang.org/x/github.com/benchlab/bench-crypto/int/curve448"
+
+// point is a point on edwards448, x^2 + y^2 = 1 + d*x^2*y^2 with d = -39081,
+// in projective coordinates (X : Y : Z), where x = X/Z and y = Y/Z.
+//
+// The addition formulas of RFC 8032, Section 5.2.4 are complete on this
+// curve, so they are used for every point, including the identity.
+type point struct {
+	X, Y, Z curve448.FieldElement
+}
+
+// dAbs is -d, the absolute value of the curve constant.
+const dAbs = 39081
+
+// basePoint is the generator B of RFC 8032, Section 5.2, in its encoded form.
+var basePoint = mustDecodePoint([PublicKeySize]byte{
+	0x14, 0xfa, 0x30, 0xf2, 0x5b, 0x79, 0x08, 0x98, 0xad, 0xc8, 0xd7, 0x4e, 0x2c, 0x13, 0xbd, 0xfd,
+	0xc4, 0x39, 0x7c, 0xe6, 0x1c, 0xff, 0xd3, 0x3a, 0xd7, 0xc2, 0xa0, 0x05, 0x1e, 0x9c, 0x78, 0x87,
+	0x40, 0x98, 0xa3, 0x6c, 0x73, 0x73, 0xea, 0x4b, 0x62, 0xc7, 0xc9, 0x56, 0x37, 0x20, 0x76, 0x88,
+	0x24, 0xbc, 0xb6, 0x6e, 0x71, 0x46, 0x3f, 0x69, 0x00,
+})
+
+func mustDecodePoint(s [PublicKeySize]byte) point {
+	var p point
+	if !p.fromBytes(s[:]) {
+		panic("ed448: invalid point encoding")
+	}
+	return p
+}
+
+func (p *point) zero() {
+	curve448.FeZero(&p.X)
+	curve448.FeOne(&p.Y)
+	curve448.FeOne(&p.Z)
+}
+
+// add sets r = p + q.
+func (r *point) add(p, q *point) {
+	var a, b, c, d, e, f, g, h, t curve448.FieldElement
+
+	curve448.FeMul(&a, &p.Z, &q.Z)
+	curve448.FeSquare(&b, &a)
+	curve448.FeMul(&c, &p.X, &q.X)
+	curve448.FeMul(&d, &p.Y, &q.Y)
+	curve448.FeMul(&e, &c, &d)
+	curve448.FeMulSmall(&e, &e, dAbs) // e = -(d * C * D)
+	curve448.FeAdd(&f, &b, &e)
+	curve448.FeSub(&g, &b, &e)
+	curve448.FeAdd(&h, &p.X, &p.Y)
+	curve448.FeAdd(&t, &q.X, &q.Y)
+	curve448.FeMul(&h, &h, &t)
+
+	curve448.FeSub(&h, &h, &c)
+	curve448.FeSub(&h, &h, &d)
+	curve448.FeMul(&h, &h, &f)
+	curve448.FeMul(&r.X, &h, &a)
+	curve448.FeSub(&t, &d, &c)
+	curve448.FeMul(&t, &t, &g)
+	curve448.FeMul(&r.Y, &t, &a)
+	curve448.FeMul(&r.Z, &f, &g)
+}
+
+// double sets r = 2 * p.
+func (r *point) double(p *point) {
+	var b, c, d, e, h, j curve448.FieldElement
+
+	curve448.FeAdd(&b, &p.X, &p.Y)
+	curve448.FeSquare(&b, &b)
+	curve448.FeSquare(&c, &p.X)
+	curve448.FeSquare(&d, &p.Y)
+	curve448.FeAdd(&e, &c, &d)
+	curve448.FeSquare(&h, &p.Z)
+	curve448.FeAdd(&h, &h, &h)
+	curve448.FeSub(&j, &e, &h)
+
+	curve448.FeSub(&b, &b, &e)
+	curve448.FeMul(&r.X, &b, &j)
+	curve448.FeSub(&c, &c, &d)
+	curve448.FeMul(&r.Y, &e, &c)
+	curve448.FeMul(&r.Z, &e, &j)
+}
+
+// cmove sets r = p if b == 1, and leaves it unchanged if b == 0.
+func (r *point) cmove(p *point, b int32) {
+	curve448.FeCMove(&r.X, &p.X, b)
+	curve448.FeCMove(&r.Y, &p.Y, b)
+	curve448.FeCMove(&r.Z, &p.Z, b)
+}
+
+// scalarMult sets r = a * p, where a is a little-endian integer, in constant
+// time with respect to a.
+func (r *point) scalarMult(a []byte, p *point) {
+	// table[i] = i * p
+	var table [16]point
+	table[0].zero()
+	table[1] = *p
+	for i := 2; i < 16; i += 2 {
+		table[i].double(&table[i/2])
+		table[i+1].add(&table[i], p)
+	}
+
+	var q, t point
+	q.zero()
+	for i := len(a) - 1; i >= 0; i-- {
+		for _, nibble := range [2]byte{a[i] >> 4, a[i] & 15} {
+			q.double(&q)
+			q.double(&q)
+			q.double(&q)
+			q.double(&q)
+			t.zero()
+			for j := range table {
+				t.cmove(&table[j], equal(int32(nibble), int32(j)))
+			}
+			q.add(&q, &t)
+		}
+	}
+	*r = q
+}
+
+// equal returns 1 if b == c, and 0 otherwise.
+func equal(b, c int32) int32 {
+	x := uint32(b ^ c)
+	x--
+	return int32(x >> 31)
+}
+
+// mulByCofactor sets r = 4 * p.
+func (r *point) mulByCofactor(p *point) {
+	r.double(p)
+	r.double(r)
+}
+
+// equal reports whether p and q are the same point.
+func (p *point) equal(q *point) bool {
+	var s, t curve448.FieldElement
+	curve448.FeMul(&s, &p.X, &q.Z)
+	curve448.FeMul(&t, &q.X, &p.Z)
+	if curve448.FeEqual(&s, &t) != 1 {
+		return false
+	}
+	curve448.FeMul(&s, &p.Y, &q.Z)
+	curve448.FeMul(&t, &q.Y, &p.Z)
+	return curve448.FeEqual(&s, &t) == 1
+}
+
+// toBytes returns the encoding of p of RFC 8032, Section 5.2.2.
+func (p *point) toBytes() []byte {
+	var recip, x, y curve448.FieldElement
+	curve448.FeInvert(&recip, &p.Z)
+	curve448.FeMul(&x, &p.X, &recip)
+	curve448.FeMul(&y, &p.Y, &recip)
+
+	var yBytes [56]byte
+	curve448.FeToBytes(&yBytes, &y)
+	s := make([]byte, PublicKeySize)
+	copy(s, yBytes[:])
+	s[56] = curve448.FeIsNegative(&x) << 7
+	return s
+}
+
+// fromBytes sets p to the point encoded by s, following RFC 8032,
+// Section 5.2.3, and reports whether s is a canonical encoding of a point.
+func (p *point) fromBytes(s []byte) bool {
+	if len(s) != PublicKeySize || s[56]&0x7f != 0 {
+		return false
+	}
+	var yBytes, check [56]byte
+	copy(yBytes[:], s)
+	curve448.FeFromBytes(&p.Y, &yBytes)
+	curve448.FeToBytes(&check, &p.Y)
+	if check != yBytes {
+		return false
+	}
+	curve448.FeOne(&p.Z)
+
+	// x^2 = (y^2 - 1) / (d y^2 - 1) = u / v
+	var u, v, u3v, u5v3, t curve448.FieldElement
+	curve448.FeSquare(&u, &p.Y)
+	curve448.FeMulSmall(&v, &u, dAbs)
+	curve448.FeNeg(&v, &v)
+	curve448.FeSub(&v, &v, &p.Z)
+	curve448.FeSub(&u, &u, &p.Z)
+
+	// x = u^3 v (u^5 v^3)^((p-3)/4)
+	curve448.FeSquare(&t, &u)
+	curve448.FeMul(&u3v, &t, &u)
+	curve448.FeMul(&u3v, &u3v, &v)
+	curve448.FeMul(&u5v3, &u3v, &t)
+	curve448.FeSquare(&t, &v)
+	curve448.FeMul(&u5v3, &u5v3, &t)
+	curve448.FePowPMinus3Over4(&p.X, &u5v3)
+	curve448.FeMul(&p.X, &p.X, &u3v)
+
+	// Check v x^2 = u, for which there is no solution if u/v is not square.
+	curve448.FeSquare(&t, &p.X)
+	curve448.FeMul(&t, &t, &v)
+	if curve448.FeEqual(&t, &u) != 1 {
+		return false
+	}
+
+	sign := s[56] >> 7
+	if curve448.FeIsNonZero(&p.X) == 0 && sign == 1 {
+		return false
+	}
+	if curve448.FeIsNegative(&p.X) != sign {
+		curve448.FeNeg(&p.X, &p.X)
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed448
+
+// scalar is an integer modulo the group order
+//
+//	L = 2^446 - 13818066809895115352007386748515426880336692474882178609894547503885
+//
+// as sixteen little-endian 28-bit limbs. All operations are constant time.
+type scalar [16]uint32
+
+const (
+	scLimbBits = 28
+	scLimbMask = 1<<scLimbBits - 1
+)
+
+var scL = scalar{
+	0xb5844f3, 0x78c292a, 0x58f5523, 0xc2728dc, 0x690216c, 0x49aed63, 0x9c44edb, 0x7cca23e,
+	0xfffffff, 0xfffffff, 0xfffffff, 0xfffffff, 0xfffffff, 0xfffffff, 0xfffffff, 0x3ffffff,
+}
+
+// scReduce returns the little-endian integer b modulo L.
+func scReduce(b []byte) scalar {
+	var s scalar
+	for i := len(b)*8 - 1; i >= 0; i-- {
+		s.shiftIn(uint32(b[i/8]>>uint(i%8)) & 1)
+	}
+	return s
+}
+
+// shiftIn sets s = 2s + bit mod L.
+func (s *scalar) shiftIn(bit uint32) {
+	// Since s < L < 2^446, 2s + 1 fits in the 448 bits of the limbs, and it
+	// is less than 2L, so a single conditional subtraction reduces it.
+	carry := bit
+	for i := range s {
+		v := s[i]<<1 | carry
+		carry = v >> scLimbBits
+		s[i] = v & scLimbMask
+	}
+
+	var t scalar
+	var borrow uint32
+	for i := range s {
+		v := s[i] - scL[i] - borrow
+		borrow = v >> 31
+		t[i] = v & scLimbMask
+	}
+	mask := borrow - 1 // all ones if s >= L
+	for i := range s {
+		s[i] ^= (s[i] ^ t[i]) & mask
+	}
+}
+
+// scMulAdd returns a * b + c mod L.
+func scMulAdd(a, b, c *scalar) scalar {
+	var p [32]uint64
+	for i := 0; i < 16; i++ {
+		for j := 0; j < 16; j++ {
+			p[i+j] += uint64(a[i]) * uint64(b[j])
+		}
+		p[i] += uint64(c[i])
+	}
+	for i := 0; i < 31; i++ {
+		p[i+1] += p[i] >> scLimbBits
+		p[i] &= scLimbMask
+	}
+
+	var wide [112]byte
+	for i := 0; i < 16; i++ {
+		v := p[2*i] | p[2*i+1]<<scLimbBits
+		for j := 0; j < 7; j++ {
+			wide[7*i+j] = byte(v >> uint(8*j))
+		}
+	}
+	return scReduce(wide[:])
+}
+
+// bytes returns the 57-byte little-endian encoding of s.
+func (s *scalar) bytes() []byte {
+	out := make([]byte, SignatureSize/2)
+	for i := 0; i < 8; i++ {
+		v := uint64(s[2*i]) | uint64(s[2*i+1])<<scLimbBits
+		for j := 0; j < 7; j++ {
+			out[7*i+j] = byte(v >> uint(8*j))
+		}
+	}
+	return out
+}
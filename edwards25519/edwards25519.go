@@ -0,0 +1,235 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package edwards25519 implements group logic for the twisted Edwards curve
+//
+//	-x^2 + y^2 = 1 + -(121665/121666)*x^2*y^2
+//
+// This is better known as the Edwards curve equivalent to Curve25519, and is
+// the curve used by the Ed25519 signature scheme.
+//
+// Most users don't need this package, and should instead use
+// golang.org/x/github.com/benchlab/bench-crypto/ed25519 for signatures or
+// golang.org/x/github.com/benchlab/bench-crypto/curve25519 for Diffie-Hellman.
+// It is meant for building other protocols on the group, such as multisignatures,
+// VRFs and Pedersen commitments.
+//
+// The documentation of each method states whether it runs in constant time
+// with respect to its inputs, and so can be used with secret points and
+// scalars. SetBytes, SetCanonicalBytes and the methods prefixed with VarTime
+// do not, and must only be used with public inputs. Constructors and Set,
+// which only copy their input, run in constant time.
+//
+// The group of points has order 8*l, where l is the order of the prime-order
+// subgroup generated by the generator. This package does not check that
+// points belong to that subgroup.
+package edwards25519 // import "golang.org/x/github.com/benchlab/bench-crypto/edwards25519"
+
+import (
+	"errors"
+
+	ref10 "golang.org/x/github.com/benchlab/bench-crypto/int/edwards25519"
+)
+
+// Point represents a point on the edwards25519 curve.
+//
+// This type works similarly to math/big.Int, and all arguments and receivers
+// are allowed to alias.
+//
+// The zero value is NOT valid, and may be used only as a receiver.
+type Point struct {
+	p ref10.ExtendedGroupElement
+
+	// Make the type not comparable with ==, as equal points can have
+	// different representations.
+	_ [0]func()
+}
+
+// generatorBytes is the canonical encoding of the generator, (x, 4/5) with x
+// positive.
+var generatorBytes = [32]byte{
+	0x58, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+	0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+	0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+	0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+}
+
+// NewIdentityPoint returns a new Point set to the identity.
+func NewIdentityPoint() *Point {
+	v := new(Point)
+	v.p.Zero()
+	return v
+}
+
+// NewGeneratorPoint returns a new Point set to the canonical generator.
+func NewGeneratorPoint() *Point {
+	v := new(Point)
+	b := generatorBytes
+	v.p.FromBytes(&b)
+	return v
+}
+
+// Set sets v = u, and returns v.
+func (v *Point) Set(u *Point) *Point {
+	*v = *u
+	return v
+}
+
+// Bytes returns the canonical 32-byte encoding of v, according to RFC 8032,
+// Section 5.1.2.
+//
+// Bytes runs in constant time.
+func (v *Point) Bytes() []byte {
+	var out [32]byte
+	v.p.ToBytes(&out)
+	return out[:]
+}
+
+// SetBytes sets v = x, where x is a 32-byte encoding of v. If x does not
+// represent a valid point on the curve, SetBytes returns nil and an error and
+// the receiver is unchanged. Otherwise, SetBytes returns v.
+//
+// Note that SetBytes accepts all non-canonical encodings of valid points,
+// that is encodings of y not reduced modulo 2^255 - 19 and the encodings of x
+// = 0 with the sign bit set, following the rules of ZIP 215. Its running
+// time depends on whether x is valid, so it should only be used with public
+// encodings.
+func (v *Point) SetBytes(x []byte) (*Point, error) {
+	if len(x) != 32 {
+		return nil, errors.New("edwards25519: invalid point encoding length")
+	}
+	var b [32]byte
+	copy(b[:], x)
+	var p ref10.ExtendedGroupElement
+	if !p.FromBytes(&b) {
+		return nil, errors.New("edwards25519: invalid point encoding")
+	}
+	v.p = p
+	return v, nil
+}
+
+// Add sets v = p + q, and returns v.
+//
+// Add runs in constant time.
+func (v *Point) Add(p, q *Point) *Point {
+	ref10.GeAdd(&v.p, &p.p, &q.p)
+	return v
+}
+
+// Subtract sets v = p - q, and returns v.
+//
+// Subtract runs in constant time.
+func (v *Point) Subtract(p, q *Point) *Point {
+	ref10.GeSub(&v.p, &p.p, &q.p)
+	return v
+}
+
+// Negate sets v = -p, and returns v.
+//
+// Negate runs in constant time.
+func (v *Point) Negate(p *Point) *Point {
+	v.p = p.p
+	ref10.FeNeg(&v.p.X, &v.p.X)
+	ref10.FeNeg(&v.p.T, &v.p.T)
+	return v
+}
+
+// MultByCofactor sets v = 8 * p, and returns v.
+//
+// MultByCofactor runs in constant time.
+func (v *Point) MultByCofactor(p *Point) *Point {
+	var t ref10.CompletedGroupElement
+	var s ref10.ProjectiveGroupElement
+	p.p.Double(&t)
+	t.ToProjective(&s)
+	s.Double(&t)
+	t.ToProjective(&s)
+	s.Double(&t)
+	t.ToExtended(&v.p)
+	return v
+}
+
+// Equal returns 1 if v is equivalent to u, and 0 otherwise.
+//
+// Equal runs in constant time.
+func (v *Point) Equal(u *Point) int {
+	var t1, t2 ref10.FieldElement
+	ref10.FeMul(&t1, &v.p.X, &u.p.Z)
+	ref10.FeMul(&t2, &u.p.X, &v.p.Z)
+	ref10.FeSub(&t1, &t1, &t2)
+	notEqualX := ref10.FeIsNonZero(&t1)
+	ref10.FeMul(&t1, &v.p.Y, &u.p.Z)
+	ref10.FeMul(&t2, &u.p.Y, &v.p.Z)
+	ref10.FeSub(&t1, &t1, &t2)
+	notEqualY := ref10.FeIsNonZero(&t1)
+	return int(1 ^ (notEqualX | notEqualY))
+}
+
+// ScalarBaseMult sets v = x * B, where B is the canonical generator, and
+// returns v.
+//
+// ScalarBaseMult runs in constant time with respect to x.
+func (v *Point) ScalarBaseMult(x *Scalar) *Point {
+	ref10.GeScalarMultBase(&v.p, &x.s)
+	return v
+}
+
+// ScalarMult sets v = x * q, and returns v.
+//
+// ScalarMult runs in constant time with respect to x and q.
+func (v *Point) ScalarMult(x *Scalar, q *Point) *Point {
+	ref10.GeScalarMult(&v.p, &x.s, &q.p)
+	return v
+}
+
+// MultiScalarMult sets v = sum(scalars[i] * points[i]), and returns v. It
+// panics if len(scalars) != len(points).
+//
+// MultiScalarMult runs in constant time with respect to the scalars and
+// the points, in time proportional to their number, which is public. It
+// computes each product separately, so VarTimeMultiScalarMult is much faster
+// when all the inputs are public.
+func (v *Point) MultiScalarMult(scalars []*Scalar, points []*Point) *Point {
+	if len(scalars) != len(points) {
+		panic("edwards25519: called MultiScalarMult with different size inputs")
+	}
+	sum := NewIdentityPoint()
+	t := new(Point)
+	for i := range scalars {
+		sum.Add(sum, t.ScalarMult(scalars[i], points[i]))
+	}
+	return v.Set(sum)
+}
+
+// VarTimeDoubleScalarBaseMult sets v = a * A + b * B, where B is the
+// canonical generator, and returns v.
+//
+// Execution time depends on the inputs.
+func (v *Point) VarTimeDoubleScalarBaseMult(a *Scalar, A *Point, b *Scalar) *Point {
+	var r ref10.ProjectiveGroupElement
+	ref10.GeDoubleScalarMultVartime(&r, &a.s, &A.p, &b.s)
+	r.ToExtended(&v.p)
+	return v
+}
+
+// VarTimeMultiScalarMult sets v = sum(scalars[i] * points[i]), and returns v.
+// It panics if len(scalars) != len(points).
+//
+// Execution time depends on the inputs.
+func (v *Point) VarTimeMultiScalarMult(scalars []*Scalar, points []*Point) *Point {
+	if len(scalars) != len(points) {
+		panic("edwards25519: called VarTimeMultiScalarMult with different size inputs")
+	}
+	a := make([][32]byte, len(scalars))
+	A := make([]ref10.ExtendedGroupElement, len(points))
+	for i := range scalars {
+		a[i] = scalars[i].s
+		A[i] = points[i].p
+	}
+	var zero [32]byte
+	var r ref10.ProjectiveGroupElement
+	ref10.GeMultiScalarMultVartime(&r, a, A, &zero)
+	r.ToExtended(&v.p)
+	return v
+}
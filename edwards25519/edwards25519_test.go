@@ -0,0 +1,206 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/benchlab/bench-crypto/rand"
+	"github.com/benchlab/bench-crypto/sha512"
+)
+
+func randomScalar(t *testing.T) *Scalar {
+	var b [64]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewScalar().SetUniformBytes(b[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func decodePoint(t *testing.T, s string) *Point {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := new(Point).SetBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestEncoding(t *testing.T) {
+	if got := NewGeneratorPoint().Bytes(); !bytes.Equal(got, generatorBytes[:]) {
+		t.Errorf("generator encodes to %x", got)
+	}
+	identity := make([]byte, 32)
+	identity[0] = 1
+	if got := NewIdentityPoint().Bytes(); !bytes.Equal(got, identity) {
+		t.Errorf("identity encodes to %x", got)
+	}
+
+	// Non-canonical encodings are accepted, and encode back canonically.
+	p := decodePoint(t, "eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	if p.Equal(NewIdentityPoint()) != 1 {
+		t.Errorf("y = p + 1 does not decode to the identity")
+	}
+	p = decodePoint(t, "0100000000000000000000000000000000000000000000000000000000000080")
+	if p.Equal(NewIdentityPoint()) != 1 {
+		t.Errorf("-0 does not decode to the identity")
+	}
+
+	// There is no point with y = 2.
+	invalid := make([]byte, 32)
+	invalid[0] = 2
+	p = NewGeneratorPoint()
+	if v, err := p.SetBytes(invalid); v != nil || err == nil {
+		t.Errorf("invalid encoding accepted")
+	}
+	if p.Equal(NewGeneratorPoint()) != 1 {
+		t.Errorf("receiver modified by a failed SetBytes")
+	}
+	if _, err := p.SetBytes(generatorBytes[:31]); err == nil {
+		t.Errorf("short encoding accepted")
+	}
+}
+
+func TestScalarBaseMult(t *testing.T) {
+	// From RFC 8032, Section 7.1, TEST 1.
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	h := sha512.Sum512(seed)
+	s, err := NewScalar().SetBytesWithClamping(h[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+	if got := hex.EncodeToString(new(Point).ScalarBaseMult(s).Bytes()); got != want {
+		t.Errorf("ScalarBaseMult = %s, want %s", got, want)
+	}
+
+	for i := 0; i < 10; i++ {
+		x := randomScalar(t)
+		p := new(Point).ScalarBaseMult(x)
+		q := new(Point).ScalarMult(x, NewGeneratorPoint())
+		if p.Equal(q) != 1 {
+			t.Errorf("ScalarBaseMult and ScalarMult disagree")
+		}
+		r := new(Point).VarTimeDoubleScalarBaseMult(NewScalar(), NewGeneratorPoint(), x)
+		if p.Equal(r) != 1 {
+			t.Errorf("ScalarBaseMult and VarTimeDoubleScalarBaseMult disagree")
+		}
+	}
+}
+
+func TestGroupLaw(t *testing.T) {
+	B := NewGeneratorPoint()
+	minusOne := &Scalar{scMinusOne}
+	p := new(Point).ScalarMult(minusOne, B)
+	if p.Equal(new(Point).Negate(B)) != 1 {
+		t.Errorf("(l-1)B != -B")
+	}
+	if p.Add(p, B).Equal(NewIdentityPoint()) != 1 {
+		t.Errorf("lB != 0")
+	}
+
+	x, y := randomScalar(t), randomScalar(t)
+	X := new(Point).ScalarBaseMult(x)
+	Y := new(Point).ScalarBaseMult(y)
+	sum := new(Point).ScalarBaseMult(NewScalar().Add(x, y))
+	if sum.Equal(new(Point).Add(X, Y)) != 1 {
+		t.Errorf("xB + yB != (x+y)B")
+	}
+	diff := new(Point).ScalarBaseMult(NewScalar().Subtract(x, y))
+	if diff.Equal(new(Point).Subtract(X, Y)) != 1 {
+		t.Errorf("xB - yB != (x-y)B")
+	}
+	prod := new(Point).ScalarBaseMult(NewScalar().Multiply(x, y))
+	if prod.Equal(new(Point).ScalarMult(y, X)) != 1 {
+		t.Errorf("y(xB) != (xy)B")
+	}
+	if X.Equal(Y) != 0 {
+		t.Errorf("different points reported as equal")
+	}
+
+	// Aliasing of receivers and arguments.
+	Z := new(Point).Set(X)
+	if Z.Add(Z, Z).Equal(new(Point).ScalarBaseMult(NewScalar().Add(x, x))) != 1 {
+		t.Errorf("X + X != 2X with aliasing")
+	}
+	if Z.ScalarMult(y, Z).Equal(new(Point).ScalarMult(NewScalar().Add(x, x), Y)) != 1 {
+		t.Errorf("y(2X) != 2x(Y) with aliasing")
+	}
+}
+
+func TestMultByCofactor(t *testing.T) {
+	// The point of order 4 (sqrt(-1), 0) has an all-zero encoding.
+	p := decodePoint(t, "0000000000000000000000000000000000000000000000000000000000000000")
+	if p.Equal(NewIdentityPoint()) == 1 {
+		t.Fatal("point of order 4 decodes to the identity")
+	}
+	if new(Point).MultByCofactor(p).Equal(NewIdentityPoint()) != 1 {
+		t.Errorf("8 * point of order 4 != 0")
+	}
+	x := randomScalar(t)
+	eight := &Scalar{[32]byte{8}}
+	X := new(Point).ScalarBaseMult(x)
+	if X.MultByCofactor(X).Equal(new(Point).ScalarBaseMult(x.Multiply(x, eight))) != 1 {
+		t.Errorf("MultByCofactor(xB) != 8xB")
+	}
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 5, 16} {
+		scalars := make([]*Scalar, n)
+		points := make([]*Point, n)
+		want := NewIdentityPoint()
+		for i := range scalars {
+			scalars[i] = randomScalar(t)
+			points[i] = new(Point).ScalarBaseMult(randomScalar(t))
+			want.Add(want, new(Point).ScalarMult(scalars[i], points[i]))
+		}
+		if got := new(Point).MultiScalarMult(scalars, points); got.Equal(want) != 1 {
+			t.Errorf("%d points: MultiScalarMult differs from the sum of ScalarMult", n)
+		}
+		if got := new(Point).VarTimeMultiScalarMult(scalars, points); got.Equal(want) != 1 {
+			t.Errorf("%d points: VarTimeMultiScalarMult differs from the sum of ScalarMult", n)
+		}
+	}
+}
+
+func BenchmarkScalarBaseMult(b *testing.B) {
+	x := &Scalar{scMinusOne}
+	p := new(Point)
+	for i := 0; i < b.N; i++ {
+		p.ScalarBaseMult(x)
+	}
+}
+
+func BenchmarkScalarMult(b *testing.B) {
+	x := &Scalar{scMinusOne}
+	p := NewGeneratorPoint()
+	for i := 0; i < b.N; i++ {
+		p.ScalarMult(x, p)
+	}
+}
+
+func BenchmarkVarTimeMultiScalarMult(b *testing.B) {
+	scalars := make([]*Scalar, 64)
+	points := make([]*Point, 64)
+	for i := range scalars {
+		scalars[i] = &Scalar{scMinusOne}
+		points[i] = NewGeneratorPoint()
+	}
+	p := new(Point)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.VarTimeMultiScalarMult(scalars, points)
+	}
+}
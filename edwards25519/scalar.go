@@ -0,0 +1,197 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"errors"
+
+	"github.com/benchlab/bench-crypto/subtle"
+
+	ref10 "golang.org/x/github.com/benchlab/bench-crypto/int/edwards25519"
+)
+
+// A Scalar is an integer modulo
+//
+//	l = 2^252 + 27742317777372353535851937790883648493
+//
+// which is the prime order of the edwards25519 group.
+//
+// This type works similarly to math/big.Int, and all arguments and
+// receivers are allowed to alias.
+//
+// The zero value is a valid zero element.
+type Scalar struct {
+	// s is the little-endian encoding of the scalar, always reduced
+	// modulo l.
+	s [32]byte
+}
+
+var (
+	scZero     [32]byte
+	scOne      = [32]byte{1}
+	scMinusOne = [32]byte{
+		0xec, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58,
+		0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+	}
+)
+
+// NewScalar returns a new zero Scalar.
+func NewScalar() *Scalar {
+	return &Scalar{}
+}
+
+// MultiplyAdd sets s = x * y + z mod l, and returns s.
+//
+// MultiplyAdd runs in constant time.
+func (s *Scalar) MultiplyAdd(x, y, z *Scalar) *Scalar {
+	ref10.ScMulAdd(&s.s, &x.s, &y.s, &z.s)
+	return s
+}
+
+// Add sets s = x + y mod l, and returns s.
+//
+// Add runs in constant time.
+func (s *Scalar) Add(x, y *Scalar) *Scalar {
+	ref10.ScMulAdd(&s.s, &x.s, &scOne, &y.s)
+	return s
+}
+
+// Subtract sets s = x - y mod l, and returns s.
+//
+// Subtract runs in constant time.
+func (s *Scalar) Subtract(x, y *Scalar) *Scalar {
+	ref10.ScMulAdd(&s.s, &y.s, &scMinusOne, &x.s)
+	return s
+}
+
+// Negate sets s = -x mod l, and returns s.
+//
+// Negate runs in constant time.
+func (s *Scalar) Negate(x *Scalar) *Scalar {
+	ref10.ScMulAdd(&s.s, &x.s, &scMinusOne, &scZero)
+	return s
+}
+
+// Multiply sets s = x * y mod l, and returns s.
+//
+// Multiply runs in constant time.
+func (s *Scalar) Multiply(x, y *Scalar) *Scalar {
+	ref10.ScMulAdd(&s.s, &x.s, &y.s, &scZero)
+	return s
+}
+
+// Invert sets s to the inverse of a nonzero scalar t modulo l, and returns
+// s. If t is zero, Invert sets s to zero.
+//
+// Invert runs in constant time with respect to t, including whether it is
+// zero: it raises t to the power l - 2, and the sequence of squarings and
+// multiplications depends only on that public exponent.
+func (s *Scalar) Invert(t *Scalar) *Scalar {
+	// Compute t^(l-2) by square-and-multiply, over the bits of the public
+	// exponent l - 2.
+	exp := scMinusOne
+	exp[0]--
+	x := *t
+	acc := scOne
+	for i := 252; i >= 0; i-- {
+		ref10.ScMulAdd(&acc, &acc, &acc, &scZero)
+		if exp[i/8]>>uint(i%8)&1 == 1 {
+			ref10.ScMulAdd(&acc, &acc, &x.s, &scZero)
+		}
+	}
+	s.s = acc
+	return s
+}
+
+// Set sets s = x, and returns s.
+func (s *Scalar) Set(x *Scalar) *Scalar {
+	*s = *x
+	return s
+}
+
+// SetUniformBytes sets s = x mod l, where x is a 64-byte little-endian
+// integer. If x is not of the right length, SetUniformBytes returns nil and
+// an error, and the receiver is unchanged.
+//
+// SetUniformBytes can be used to set s to a uniformly distributed value
+// given 64 uniformly distributed random bytes, for example the output of
+// SHA-512.
+//
+// SetUniformBytes runs in constant time with respect to the value of x.
+func (s *Scalar) SetUniformBytes(x []byte) (*Scalar, error) {
+	if len(x) != 64 {
+		return nil, errors.New("edwards25519: invalid SetUniformBytes input length")
+	}
+	var wide [64]byte
+	copy(wide[:], x)
+	ref10.ScReduce(&s.s, &wide)
+	return s, nil
+}
+
+// SetCanonicalBytes sets s = x, where x is a 32-byte little-endian encoding
+// of s, and returns s. If x is not a canonical encoding of s, that is if it
+// is not reduced modulo l, SetCanonicalBytes returns nil and an error, and
+// the receiver is unchanged.
+//
+// SetCanonicalBytes does not run in constant time: it compares x with l from
+// the most significant end and returns at the first differing word, so its
+// running time reveals how x compares with l. It should only be used with
+// public encodings, such as the s half of a signature. Secret scalars should
+// be decoded with SetUniformBytes or SetBytesWithClamping.
+func (s *Scalar) SetCanonicalBytes(x []byte) (*Scalar, error) {
+	if len(x) != 32 {
+		return nil, errors.New("edwards25519: invalid scalar length")
+	}
+	var b [32]byte
+	copy(b[:], x)
+	if !ref10.ScMinimal(&b) {
+		return nil, errors.New("edwards25519: invalid scalar encoding")
+	}
+	s.s = b
+	return s, nil
+}
+
+// SetBytesWithClamping applies the buffer pruning described in RFC 8032,
+// Section 5.1.5 (also known as clamping) and sets s to the result, reduced
+// modulo l. The input must be 32 bytes, and it is not modified. If x is not
+// of the right length, SetBytesWithClamping returns nil and an error, and the
+// receiver is unchanged.
+//
+// Note that since Scalar values are always reduced modulo the prime order of
+// the curve, the resulting value will not preserve any of the cofactor-clearing
+// properties that clamping is meant to provide. It will however work as
+// expected as long as it is applied to points on the prime order subgroup,
+// like in Ed25519.
+//
+// SetBytesWithClamping runs in constant time with respect to the value of x.
+func (s *Scalar) SetBytesWithClamping(x []byte) (*Scalar, error) {
+	if len(x) != 32 {
+		return nil, errors.New("edwards25519: invalid SetBytesWithClamping input length")
+	}
+	var wide [64]byte
+	copy(wide[:], x)
+	wide[0] &= 248
+	wide[31] &= 63
+	wide[31] |= 64
+	ref10.ScReduce(&s.s, &wide)
+	return s, nil
+}
+
+// Bytes returns the canonical 32-byte little-endian encoding of s.
+//
+// Bytes runs in constant time.
+func (s *Scalar) Bytes() []byte {
+	out := s.s
+	return out[:]
+}
+
+// Equal returns 1 if s and t are equal, and 0 otherwise.
+//
+// Equal runs in constant time.
+func (s *Scalar) Equal(t *Scalar) int {
+	return subtle.ConstantTimeCompare(s.s[:], t.s[:])
+}
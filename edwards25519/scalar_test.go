@@ -0,0 +1,71 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestScalarEncoding(t *testing.T) {
+	// l itself is not canonical, l - 1 is.
+	l, _ := hex.DecodeString("edd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010")
+	s := &Scalar{scOne}
+	if v, err := s.SetCanonicalBytes(l); v != nil || err == nil {
+		t.Errorf("SetCanonicalBytes accepted l")
+	}
+	if s.Equal(&Scalar{scOne}) != 1 {
+		t.Errorf("receiver modified by a failed SetCanonicalBytes")
+	}
+	l[0]--
+	if _, err := s.SetCanonicalBytes(l); err != nil {
+		t.Errorf("SetCanonicalBytes rejected l - 1: %v", err)
+	}
+	if !bytes.Equal(s.Bytes(), l) {
+		t.Errorf("l - 1 encodes to %x", s.Bytes())
+	}
+
+	// The expected value was computed with math/big.
+	wide := bytes.Repeat([]byte{0xff}, 64)
+	want := "000f9c44e31106a447938568a71b0ed065bef517d273ecce3d9a307c1b419903"
+	if _, err := s.SetUniformBytes(wide); err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(s.Bytes()); got != want {
+		t.Errorf("SetUniformBytes(ff...ff) = %s, want %s", got, want)
+	}
+	if _, err := s.SetUniformBytes(wide[:32]); err == nil {
+		t.Errorf("SetUniformBytes accepted 32 bytes")
+	}
+}
+
+func TestScalarArithmetic(t *testing.T) {
+	x, y := randomScalar(t), randomScalar(t)
+	zero := NewScalar()
+
+	if NewScalar().Add(x, NewScalar().Negate(x)).Equal(zero) != 1 {
+		t.Errorf("x + -x != 0")
+	}
+	if NewScalar().Subtract(NewScalar().Add(x, y), y).Equal(x) != 1 {
+		t.Errorf("(x + y) - y != x")
+	}
+	xy := NewScalar().Multiply(x, y)
+	if NewScalar().MultiplyAdd(x, y, x).Equal(NewScalar().Add(xy, x)) != 1 {
+		t.Errorf("MultiplyAdd(x, y, x) != xy + x")
+	}
+	if NewScalar().Multiply(x, NewScalar().Invert(x)).Equal(&Scalar{scOne}) != 1 {
+		t.Errorf("x * 1/x != 1")
+	}
+	if NewScalar().Invert(zero).Equal(zero) != 1 {
+		t.Errorf("1/0 != 0")
+	}
+
+	// Aliasing of receivers and arguments.
+	z := NewScalar().Set(x)
+	if z.Multiply(z, z).Equal(NewScalar().Multiply(x, x)) != 1 {
+		t.Errorf("x * x differs with aliasing")
+	}
+}
@@ -9,6 +9,9 @@
 // expanding limited input keying material into one or more cryptographically
 // strong secret keys.
 //
+// The package also implements the labeled expansions of the TLS 1.3 key
+// schedule (RFC 8446) and of HPKE (RFC 9180) on top of Extract and Expand.
+//
 // RFC 5869: https://tools.ietf.org/html/rfc5869
 package hkdf // import "golang.org/x/github.com/benchlab/bench-crypto/hkdf"
 
@@ -61,15 +64,35 @@ func (f *hkdf) Read(p []byte) (int, error) {
 	return need, nil
 }
 
-// New returns a new HKDF using the given hash, the secret keying material to expand
-// and optional salt and info fields.
-func New(hash func() hash.Hash, secret, salt, info []byte) io.Reader {
+// Extract generates a pseudorandom key for use with Expand from an input secret
+// and an optional independent salt.
+//
+// Only use this function if you need to reuse the extracted key with multiple
+// Expand invocations and different context values. Most common scenarios,
+// including the generation of multiple keys, should use New instead.
+func Extract(hash func() hash.Hash, secret, salt []byte) []byte {
 	if salt == nil {
 		salt = make([]byte, hash().Size())
 	}
 	extractor := hmac.New(hash, salt)
 	extractor.Write(secret)
-	prk := extractor.Sum(nil)
+	return extractor.Sum(nil)
+}
 
-	return &hkdf{hmac.New(hash, prk), extractor.Size(), info, 1, nil, nil}
+// Expand returns a Reader, from which keys can be read, using the given
+// pseudorandom key and optional context info, skipping the extraction step.
+//
+// The pseudorandomKey should have been generated by Extract, or be a uniformly
+// random or pseudorandom cryptographically strong key. See RFC 5869, Section
+// 3.3. Most common scenarios will want to use New instead.
+func Expand(hash func() hash.Hash, pseudorandomKey, info []byte) io.Reader {
+	expander := hmac.New(hash, pseudorandomKey)
+	return &hkdf{expander, expander.Size(), info, 1, nil, nil}
+}
+
+// New returns a Reader, from which keys can be read, using the given hash,
+// secret, salt and context info. Salt and info can be nil.
+func New(hash func() hash.Hash, secret, salt, info []byte) io.Reader {
+	prk := Extract(hash, secret, salt)
+	return Expand(hash, prk, info)
 }
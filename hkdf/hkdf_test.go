@@ -247,6 +247,34 @@ func TestHKDF(t *testing.T) {
 	}
 }
 
+func TestExtractExpand(t *testing.T) {
+	for i, tt := range hkdfTests {
+		prk := Extract(tt.hash, tt.master, tt.salt)
+		out := make([]byte, len(tt.out))
+
+		n, err := io.ReadFull(Expand(tt.hash, prk, tt.info), out)
+		if n != len(tt.out) || err != nil {
+			t.Errorf("test %d: not enough output bytes: %d.", i, n)
+		}
+
+		if !bytes.Equal(out, tt.out) {
+			t.Errorf("test %d: incorrect output: have %v, need %v.", i, out, tt.out)
+		}
+	}
+
+	// PRK of RFC 5869, test case 1.
+	prk := Extract(sha256.New, hkdfTests[0].master, hkdfTests[0].salt)
+	want := []byte{
+		0x07, 0x77, 0x09, 0x36, 0x2c, 0x2e, 0x32, 0xdf,
+		0x0d, 0xdc, 0x3f, 0x0d, 0xc4, 0x7b, 0xba, 0x63,
+		0x90, 0xb6, 0xc7, 0x3b, 0xb5, 0x0f, 0x9c, 0x31,
+		0x22, 0xec, 0x84, 0x4a, 0xd7, 0xc2, 0xb3, 0xe5,
+	}
+	if !bytes.Equal(prk, want) {
+		t.Errorf("incorrect PRK: have %x, need %x.", prk, want)
+	}
+}
+
 func TestHKDFMultiRead(t *testing.T) {
 	for i, tt := range hkdfTests {
 		hkdf := New(tt.hash, tt.master, tt.salt, tt.info)
@@ -0,0 +1,80 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hkdf
+
+import (
+	"hash"
+	"io"
+)
+
+// ExpandLabel implements HKDF-Expand-Label from the TLS 1.3 key schedule,
+// RFC 8446, Section 7.1. It expands secret into length bytes bound to the
+// label, which is prefixed with "tls13 ", and to the context.
+//
+// ExpandLabel panics if the label or the context are longer than 249 and 255
+// bytes, or if length is larger than 255 times the size of the hash.
+func ExpandLabel(hash func() hash.Hash, secret []byte, label string, context []byte, length int) []byte {
+	const labelPrefix = "tls13 "
+	if len(labelPrefix)+len(label) > 255 || len(context) > 255 || length > 0xffff {
+		panic("hkdf: invalid HKDF-Expand-Label arguments")
+	}
+	hkdfLabel := make([]byte, 0, 2+1+len(labelPrefix)+len(label)+1+len(context))
+	hkdfLabel = append(hkdfLabel, byte(length>>8), byte(length))
+	hkdfLabel = append(hkdfLabel, byte(len(labelPrefix)+len(label)))
+	hkdfLabel = append(hkdfLabel, labelPrefix...)
+	hkdfLabel = append(hkdfLabel, label...)
+	hkdfLabel = append(hkdfLabel, byte(len(context)))
+	hkdfLabel = append(hkdfLabel, context...)
+	return expand(hash, secret, hkdfLabel, length)
+}
+
+// DeriveSecret implements Derive-Secret from the TLS 1.3 key schedule, RFC
+// 8446, Section 7.1. The transcript holds the handshake messages hashed so
+// far, and is not modified. A nil transcript stands for an empty list of
+// messages, as used by the "derived" secrets.
+func DeriveSecret(hash func() hash.Hash, secret []byte, label string, transcript hash.Hash) []byte {
+	if transcript == nil {
+		transcript = hash()
+	}
+	return ExpandLabel(hash, secret, label, transcript.Sum(nil), transcript.Size())
+}
+
+// hpkeVersionLabel prefixes the inputs of the HPKE labeled functions.
+const hpkeVersionLabel = "HPKE-v1"
+
+// LabeledExtract implements LabeledExtract from HPKE, RFC 9180, Section 4.
+// The suiteID identifies the KEM, or the KEM, KDF and AEAD, the key is
+// derived for.
+func LabeledExtract(hash func() hash.Hash, suiteID, salt []byte, label string, ikm []byte) []byte {
+	labeledIKM := make([]byte, 0, len(hpkeVersionLabel)+len(suiteID)+len(label)+len(ikm))
+	labeledIKM = append(labeledIKM, hpkeVersionLabel...)
+	labeledIKM = append(labeledIKM, suiteID...)
+	labeledIKM = append(labeledIKM, label...)
+	labeledIKM = append(labeledIKM, ikm...)
+	return Extract(hash, labeledIKM, salt)
+}
+
+// LabeledExpand implements LabeledExpand from HPKE, RFC 9180, Section 4. It
+// panics if length is larger than 255 times the size of the hash.
+func LabeledExpand(hash func() hash.Hash, suiteID, prk []byte, label string, info []byte, length int) []byte {
+	if length > 0xffff {
+		panic("hkdf: invalid LabeledExpand length")
+	}
+	labeledInfo := make([]byte, 0, 2+len(hpkeVersionLabel)+len(suiteID)+len(label)+len(info))
+	labeledInfo = append(labeledInfo, byte(length>>8), byte(length))
+	labeledInfo = append(labeledInfo, hpkeVersionLabel...)
+	labeledInfo = append(labeledInfo, suiteID...)
+	labeledInfo = append(labeledInfo, label...)
+	labeledInfo = append(labeledInfo, info...)
+	return expand(hash, prk, labeledInfo, length)
+}
+
+func expand(hash func() hash.Hash, prk, info []byte, length int) []byte {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(Expand(hash, prk, info), out); err != nil {
+		panic(err)
+	}
+	return out
+}
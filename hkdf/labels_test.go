@@ -0,0 +1,96 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hkdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/benchlab/bench-crypto/sha256"
+)
+
+func fromHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// TestTLS13KeySchedule follows the start of the key schedule of the simple
+// 1-RTT handshake of RFC 8448, Section 3.
+func TestTLS13KeySchedule(t *testing.T) {
+	early := Extract(sha256.New, make([]byte, sha256.Size), nil)
+	if want := fromHex("33ad0a1c607ec03b09e6cd9893680ce210adf300aa1f2660e1b22e10f170f92a"); !bytes.Equal(early, want) {
+		t.Errorf("early secret: have %x, need %x", early, want)
+	}
+	derived := DeriveSecret(sha256.New, early, "derived", nil)
+	if want := fromHex("6f2615a108c702c5678f54fc9dbab69716c076189c48250cebeac3576c3611ba"); !bytes.Equal(derived, want) {
+		t.Errorf("derived secret: have %x, need %x", derived, want)
+	}
+	// DeriveSecret must match a transcript of no messages.
+	if d := DeriveSecret(sha256.New, early, "derived", sha256.New()); !bytes.Equal(d, derived) {
+		t.Errorf("derived secret with empty transcript: have %x, need %x", d, derived)
+	}
+	shared := fromHex("8bd4054fb55b9d63fdfbacf9f04b9f0d35e6d63f537563efd46272900f89492d")
+	handshake := Extract(sha256.New, shared, derived)
+	if want := fromHex("1dc826e93606aa6fdc0aadc12f741b01046aa6b99f691ed221a9f0ca043fbeac"); !bytes.Equal(handshake, want) {
+		t.Errorf("handshake secret: have %x, need %x", handshake, want)
+	}
+
+	serverTraffic := fromHex("b67b7d690cc16c4e75e54213cb2d37b4e9c912bcded9105d42befd59d391ad38")
+	key := ExpandLabel(sha256.New, serverTraffic, "key", nil, 16)
+	if want := fromHex("3fce516009c21727d0f2e4e86ee403bc"); !bytes.Equal(key, want) {
+		t.Errorf("server handshake key: have %x, need %x", key, want)
+	}
+	iv := ExpandLabel(sha256.New, serverTraffic, "iv", nil, 12)
+	if want := fromHex("5d313eb2671276ee13000b30"); !bytes.Equal(iv, want) {
+		t.Errorf("server handshake iv: have %x, need %x", iv, want)
+	}
+}
+
+// TestHPKEKeySchedule follows the key schedule of the first test vector of
+// RFC 9180, Appendix A.1.1, DHKEM(X25519, HKDF-SHA256), HKDF-SHA256,
+// AES-128-GCM in base mode.
+func TestHPKEKeySchedule(t *testing.T) {
+	suiteID := []byte("HPKE\x00\x20\x00\x01\x00\x01")
+	info := fromHex("4f6465206f6e2061204772656369616e2055726e")
+	shared := fromHex("fe0e18c9f024ce43799ae393c7e8fe8fce9d218875e8227b0187c04e7d2ea1fc")
+
+	context := []byte{0} // mode_base
+	context = append(context, LabeledExtract(sha256.New, suiteID, nil, "psk_id_hash", nil)...)
+	context = append(context, LabeledExtract(sha256.New, suiteID, nil, "info_hash", info)...)
+	if want := fromHex("00725611c9d98c07c03f60095cd32d400d8347d45ed67097bbad50fc56da742d07cb6cffde367bb0565ba28bb02c90744a20f5ef37f30523526106f637abb05449"); !bytes.Equal(context, want) {
+		t.Errorf("key schedule context: have %x, need %x", context, want)
+	}
+
+	secret := LabeledExtract(sha256.New, suiteID, shared, "secret", nil)
+	if want := fromHex("12fff91991e93b48de37e7daddb52981084bd8aa64289c3788471d9a9712f397"); !bytes.Equal(secret, want) {
+		t.Errorf("secret: have %x, need %x", secret, want)
+	}
+	for _, tt := range []struct {
+		label string
+		want  string
+	}{
+		{"key", "4531685d41d65f03dc48f6b8302c05b0"},
+		{"base_nonce", "56d890e5accaaf011cff4b7d"},
+		{"exp", "45ff1c2e220db587171952c0592d5f5ebe103f1561a2614e38f2ffd47e99e3f8"},
+	} {
+		want := fromHex(tt.want)
+		if out := LabeledExpand(sha256.New, suiteID, secret, tt.label, context, len(want)); !bytes.Equal(out, want) {
+			t.Errorf("%s: have %x, need %x", tt.label, out, want)
+		}
+	}
+}
+
+func TestExpandLabelPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("ExpandLabel did not panic on an output longer than 255 blocks")
+		}
+	}()
+	ExpandLabel(sha256.New, make([]byte, sha256.Size), "key", nil, 255*sha256.Size+1)
+}
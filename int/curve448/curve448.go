@@ -0,0 +1,259 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package curve448 implements constant-time arithmetic in GF(2^448 - 2^224 - 1),
+// the field of Curve448 and of its Edwards form edwards448. It is shared by
+// the x448 and ed448 packages.
+package curve448
+
+// FieldElement represents an element of the field GF(2^448 - 2^224 - 1). An
+// element t, entries t[0]...t[15], represents the integer t[0]+2^28 t[1]+2^56
+// t[2]+...+2^420 t[15]. The functions of this package return elements whose
+// entries are at most 2^28, and accept entries of up to 2^28.
+//
+// Since 2^448 = 2^224 + 1 modulo p, a carry out of t[15] is folded back into
+// both t[0] and t[8].
+type FieldElement [16]uint32
+
+const (
+	limbBits = 28
+	limbMask = 1<<limbBits - 1
+)
+
+// twoP is 2p, limb by limb, added before a subtraction to keep entries
+// non-negative.
+var twoP = [16]uint64{
+	0x1ffffffe, 0x1ffffffe, 0x1ffffffe, 0x1ffffffe, 0x1ffffffe, 0x1ffffffe, 0x1ffffffe, 0x1ffffffe,
+	0x1ffffffc, 0x1ffffffe, 0x1ffffffe, 0x1ffffffe, 0x1ffffffe, 0x1ffffffe, 0x1ffffffe, 0x1ffffffe,
+}
+
+func FeZero(fe *FieldElement) {
+	*fe = FieldElement{}
+}
+
+func FeOne(fe *FieldElement) {
+	*fe = FieldElement{1}
+}
+
+func FeCopy(dst, src *FieldElement) {
+	*dst = *src
+}
+
+// feCarry sets h to t with its entries brought back to at most 2^28. Each
+// entry of t must be less than 2^63.
+func feCarry(h *FieldElement, t *[16]uint64) {
+	for pass := 0; pass < 2; pass++ {
+		for i := 0; i < 15; i++ {
+			t[i+1] += t[i] >> limbBits
+			t[i] &= limbMask
+		}
+		c := t[15] >> limbBits
+		t[15] &= limbMask
+		t[0] += c
+		t[8] += c
+	}
+	for i := range h {
+		h[i] = uint32(t[i])
+	}
+}
+
+// FeAdd sets dst = a + b.
+func FeAdd(dst, a, b *FieldElement) {
+	var t [16]uint64
+	for i := range t {
+		t[i] = uint64(a[i]) + uint64(b[i])
+	}
+	feCarry(dst, &t)
+}
+
+// FeSub sets dst = a - b.
+func FeSub(dst, a, b *FieldElement) {
+	var t [16]uint64
+	for i := range t {
+		t[i] = uint64(a[i]) + twoP[i] - uint64(b[i])
+	}
+	feCarry(dst, &t)
+}
+
+// FeNeg sets h = -f.
+func FeNeg(h, f *FieldElement) {
+	var zero FieldElement
+	FeSub(h, &zero, f)
+}
+
+// FeMul sets h = f * g.
+func FeMul(h, f, g *FieldElement) {
+	var c [31]uint64
+	for i := 0; i < 16; i++ {
+		for j := 0; j < 16; j++ {
+			c[i+j] += uint64(f[i]) * uint64(g[j])
+		}
+	}
+	// Fold 2^(448+28k) = 2^(224+28k) + 2^(28k), from the top so that the
+	// entries above 15 that receive a contribution are folded in turn.
+	for k := 30; k >= 16; k-- {
+		c[k-16] += c[k]
+		c[k-8] += c[k]
+	}
+	var t [16]uint64
+	copy(t[:], c[:16])
+	feCarry(h, &t)
+}
+
+// FeSquare sets h = f * f.
+func FeSquare(h, f *FieldElement) {
+	FeMul(h, f, f)
+}
+
+// FeMulSmall sets h = f * k, where k must be less than 2^32.
+func FeMulSmall(h, f *FieldElement, k uint32) {
+	var t [16]uint64
+	for i := range t {
+		t[i] = uint64(f[i]) * uint64(k)
+	}
+	feCarry(h, &t)
+}
+
+// FeCMove sets f = g if b == 1, and leaves it unchanged if b == 0.
+func FeCMove(f, g *FieldElement, b int32) {
+	mask := -uint32(b)
+	for i := range f {
+		f[i] ^= (f[i] ^ g[i]) & mask
+	}
+}
+
+// FeCSwap swaps f and g if b == 1, and leaves them unchanged if b == 0.
+func FeCSwap(f, g *FieldElement, b int32) {
+	mask := -uint32(b)
+	for i := range f {
+		t := (f[i] ^ g[i]) & mask
+		f[i] ^= t
+		g[i] ^= t
+	}
+}
+
+// FeFromBytes sets dst to the little-endian integer in src, reduced modulo p.
+// Non-canonical encodings, of values between p and 2^448 - 1, are accepted.
+func FeFromBytes(dst *FieldElement, src *[56]byte) {
+	for i := 0; i < 8; i++ {
+		var v uint64
+		for j := 6; j >= 0; j-- {
+			v = v<<8 | uint64(src[7*i+j])
+		}
+		dst[2*i] = uint32(v & limbMask)
+		dst[2*i+1] = uint32(v >> limbBits)
+	}
+}
+
+// FeToBytes sets s to the canonical little-endian encoding of h.
+func FeToBytes(s *[56]byte, h *FieldElement) {
+	var t [16]uint64
+	for i := range t {
+		t[i] = uint64(h[i])
+	}
+	// Two more passes leave every entry below 2^28, so that t < 2^448.
+	var r FieldElement
+	feCarry(&r, &t)
+
+	// t + 2^224 + 1 overflows 2^448 exactly when t >= p, and then its low
+	// 448 bits are t - p.
+	var u [16]uint64
+	copy(u[:], t[:])
+	u[0]++
+	u[8]++
+	for i := 0; i < 15; i++ {
+		u[i+1] += u[i] >> limbBits
+		u[i] &= limbMask
+	}
+	mask := -(u[15] >> limbBits)
+	u[15] &= limbMask
+	for i := range t {
+		t[i] ^= (t[i] ^ u[i]) & mask
+	}
+
+	for i := 0; i < 8; i++ {
+		v := t[2*i] | t[2*i+1]<<limbBits
+		for j := 0; j < 7; j++ {
+			s[7*i+j] = byte(v >> uint(8*j))
+		}
+	}
+}
+
+// FeIsNegative returns 1 if the canonical encoding of f is odd, and 0
+// otherwise.
+func FeIsNegative(f *FieldElement) byte {
+	var s [56]byte
+	FeToBytes(&s, f)
+	return s[0] & 1
+}
+
+// FeIsNonZero returns 1 if f is not zero, and 0 otherwise.
+func FeIsNonZero(f *FieldElement) int32 {
+	var s [56]byte
+	FeToBytes(&s, f)
+	var x uint8
+	for _, b := range s {
+		x |= b
+	}
+	return int32((uint32(x) + 0xff) >> 8)
+}
+
+// FeEqual returns 1 if f == g, and 0 otherwise.
+func FeEqual(f, g *FieldElement) int32 {
+	var t FieldElement
+	FeSub(&t, f, g)
+	return 1 ^ FeIsNonZero(&t)
+}
+
+// feSquareN sets h = f^(2^n), for n > 0.
+func feSquareN(h, f *FieldElement, n int) {
+	FeSquare(h, f)
+	for i := 1; i < n; i++ {
+		FeSquare(h, h)
+	}
+}
+
+// FePowPMinus3Over4 sets out = z^((p-3)/4), where (p-3)/4 = 2^446 - 2^222 - 1.
+// Since p = 3 mod 4, it is used to compute square roots.
+func FePowPMinus3Over4(out, z *FieldElement) {
+	// Each tK holds z^(2^K - 1).
+	var t1, t2, t3, t6, t12, t24, t48, t96, t192, t216, t222, t223, t FieldElement
+
+	FeCopy(&t1, z)
+	feSquareN(&t, &t1, 1)
+	FeMul(&t2, &t, &t1)
+	feSquareN(&t, &t2, 1)
+	FeMul(&t3, &t, &t1)
+	feSquareN(&t, &t3, 3)
+	FeMul(&t6, &t, &t3)
+	feSquareN(&t, &t6, 6)
+	FeMul(&t12, &t, &t6)
+	feSquareN(&t, &t12, 12)
+	FeMul(&t24, &t, &t12)
+	feSquareN(&t, &t24, 24)
+	FeMul(&t48, &t, &t24)
+	feSquareN(&t, &t48, 48)
+	FeMul(&t96, &t, &t48)
+	feSquareN(&t, &t96, 96)
+	FeMul(&t192, &t, &t96)
+	feSquareN(&t, &t192, 24)
+	FeMul(&t216, &t, &t24)
+	feSquareN(&t, &t216, 6)
+	FeMul(&t222, &t, &t6)
+	feSquareN(&t, &t222, 1)
+	FeMul(&t223, &t, &t1)
+
+	// (2^223 - 1) * 2^223 + 2^222 - 1 = 2^446 - 2^222 - 1
+	feSquareN(&t, &t223, 223)
+	FeMul(out, &t, &t222)
+}
+
+// FeInvert sets out = 1/z, or zero if z is zero.
+func FeInvert(out, z *FieldElement) {
+	// z^(p-2) = (z^((p-3)/4))^4 * z
+	var t FieldElement
+	FePowPMinus3Over4(&t, z)
+	feSquareN(&t, &t, 2)
+	FeMul(out, &t, z)
+}
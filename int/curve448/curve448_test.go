@@ -0,0 +1,197 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curve448
+
+import (
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+var p = func() *big.Int {
+	p := new(big.Int).Lsh(big.NewInt(1), 448)
+	p.Sub(p, new(big.Int).Lsh(big.NewInt(1), 224))
+	return p.Sub(p, big.NewInt(1))
+}()
+
+// feValue returns the integer represented by the entries of f, which need
+// not be reduced.
+func feValue(f *FieldElement) *big.Int {
+	v := new(big.Int)
+	for i := 15; i >= 0; i-- {
+		v.Lsh(v, limbBits)
+		v.Add(v, big.NewInt(int64(f[i])))
+	}
+	return v
+}
+
+// feFromBig returns the entries of v, which must be less than 2^448.
+func feFromBig(v *big.Int) FieldElement {
+	var f FieldElement
+	t := new(big.Int).Set(v)
+	mask := big.NewInt(limbMask)
+	for i := range f {
+		f[i] = uint32(new(big.Int).And(t, mask).Uint64())
+		t.Rsh(t, limbBits)
+	}
+	return f
+}
+
+func bytesFromBig(v *big.Int) [56]byte {
+	var s [56]byte
+	b := v.Bytes()
+	for i := range b {
+		s[i] = b[len(b)-1-i]
+	}
+	return s
+}
+
+func bigFromBytes(s *[56]byte) *big.Int {
+	var b [56]byte
+	for i := range s {
+		b[i] = s[55-i]
+	}
+	return new(big.Int).SetBytes(b[:])
+}
+
+// checkReduced checks that the entries of f are within the bound that the
+// functions of this package promise.
+func checkReduced(t *testing.T, op string, f *FieldElement) {
+	t.Helper()
+	for i, v := range f {
+		if v > 1<<limbBits {
+			t.Errorf("%s: entry %d is %#x, more than 2^28", op, i, v)
+		}
+	}
+}
+
+func TestFeToBytesEdgeCases(t *testing.T) {
+	two448 := new(big.Int).Lsh(big.NewInt(1), 448)
+	for _, tt := range []struct {
+		name    string
+		in, out *big.Int
+	}{
+		{"p", p, big.NewInt(0)},
+		{"p+1", new(big.Int).Add(p, big.NewInt(1)), big.NewInt(1)},
+		{"p-1", new(big.Int).Sub(p, big.NewInt(1)), new(big.Int).Sub(p, big.NewInt(1))},
+		{"2^448-1", new(big.Int).Sub(two448, big.NewInt(1)), new(big.Int).Lsh(big.NewInt(1), 224)},
+		{"2^224", new(big.Int).Lsh(big.NewInt(1), 224), new(big.Int).Lsh(big.NewInt(1), 224)},
+	} {
+		f := feFromBig(tt.in)
+		var s [56]byte
+		FeToBytes(&s, &f)
+		if got := bigFromBytes(&s); got.Cmp(tt.out) != 0 {
+			t.Errorf("FeToBytes(%s) = %x, want %x", tt.name, got, tt.out)
+		}
+	}
+
+	// Entries of exactly 2^28, the largest accepted, make a value above
+	// 2^448 that still must be fully reduced.
+	var f FieldElement
+	for i := range f {
+		f[i] = 1 << limbBits
+	}
+	var s [56]byte
+	FeToBytes(&s, &f)
+	want := new(big.Int).Mod(feValue(&f), p)
+	if got := bigFromBytes(&s); got.Cmp(want) != 0 {
+		t.Errorf("FeToBytes(all 2^28) = %x, want %x", got, want)
+	}
+}
+
+func TestFeFromBytesNonCanonical(t *testing.T) {
+	two448 := new(big.Int).Lsh(big.NewInt(1), 448)
+	for _, in := range []*big.Int{
+		p,
+		new(big.Int).Add(p, big.NewInt(1)),
+		new(big.Int).Add(p, big.NewInt(5)),
+		new(big.Int).Sub(two448, big.NewInt(1)),
+	} {
+		s := bytesFromBig(in)
+		var f FieldElement
+		FeFromBytes(&f, &s)
+		checkReduced(t, "FeFromBytes", &f)
+		want := new(big.Int).Mod(in, p)
+		var out [56]byte
+		FeToBytes(&out, &f)
+		if got := bigFromBytes(&out); got.Cmp(want) != 0 {
+			t.Errorf("FeFromBytes(%x) = %x, want %x", in, got, want)
+		}
+		wantZero := int32(0)
+		if want.Sign() != 0 {
+			wantZero = 1
+		}
+		if got := FeIsNonZero(&f); got != wantZero {
+			t.Errorf("FeIsNonZero(FeFromBytes(%x)) = %d, want %d", in, got, wantZero)
+		}
+	}
+}
+
+func TestFeInvertZero(t *testing.T) {
+	var zero, out FieldElement
+	FeInvert(&out, &zero)
+	if FeIsNonZero(&out) != 0 {
+		t.Errorf("FeInvert(0) = %x, want 0", feValue(&out))
+	}
+
+	// p is another representation of zero.
+	pf := feFromBig(p)
+	FeInvert(&out, &pf)
+	if FeIsNonZero(&out) != 0 {
+		t.Errorf("FeInvert(p) = %x, want 0", feValue(&out))
+	}
+}
+
+func TestFeMulMaximalLimbs(t *testing.T) {
+	var max, almostMax FieldElement
+	for i := range max {
+		max[i] = 1 << limbBits
+		almostMax[i] = limbMask
+	}
+	for _, tt := range []struct {
+		name string
+		f, g *FieldElement
+	}{
+		{"2^28 * 2^28", &max, &max},
+		{"2^28 * (2^28-1)", &max, &almostMax},
+		{"(2^28-1) * (2^28-1)", &almostMax, &almostMax},
+	} {
+		var h FieldElement
+		FeMul(&h, tt.f, tt.g)
+		checkReduced(t, "FeMul("+tt.name+")", &h)
+		want := new(big.Int).Mul(feValue(tt.f), feValue(tt.g))
+		want.Mod(want, p)
+		if got := new(big.Int).Mod(feValue(&h), p); got.Cmp(want) != 0 {
+			t.Errorf("FeMul(%s) = %x, want %x", tt.name, got, want)
+		}
+	}
+
+	var h FieldElement
+	FeAdd(&h, &max, &max)
+	checkReduced(t, "FeAdd(2^28, 2^28)", &h)
+	FeSub(&h, &FieldElement{}, &max)
+	checkReduced(t, "FeSub(0, 2^28)", &h)
+	want := new(big.Int).Neg(feValue(&max))
+	want.Mod(want, p)
+	if got := new(big.Int).Mod(feValue(&h), p); got.Cmp(want) != 0 {
+		t.Errorf("FeSub(0, all 2^28) = %x, want %x", got, want)
+	}
+}
+
+func TestFeMulRandom(t *testing.T) {
+	f := func(a, b [56]byte) bool {
+		var x, y, z FieldElement
+		FeFromBytes(&x, &a)
+		FeFromBytes(&y, &b)
+		FeMul(&z, &x, &y)
+		var s [56]byte
+		FeToBytes(&s, &z)
+		want := new(big.Int).Mul(bigFromBytes(&a), bigFromBytes(&b))
+		return bigFromBytes(&s).Cmp(want.Mod(want, p)) == 0
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
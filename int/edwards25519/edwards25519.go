@@ -2,6 +2,12 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// Package edwards25519 implements group logic for the twisted Edwards curve
+//
+//	-x^2 + y^2 = 1 + -(121665/121666)*x^2*y^2
+//
+// with field and scalar arithmetic. It is shared by the packages built on the
+// curve, such as ed25519, edwards25519 and ristretto255.
 package edwards25519
 
 import "encoding/binary"
@@ -559,7 +565,8 @@ func FeInvert(out, z *FieldElement) {
 	FeMul(out, &t1, &t0) // 254..5,3,1,0
 }
 
-func fePow22523(out, z *FieldElement) {
+// FePow22523 sets out = z^((p-5)/8), where p = 2^255 - 19.
+func FePow22523(out, z *FieldElement) {
 	var t0, t1, t2 FieldElement
 	var i int
 
@@ -679,6 +686,18 @@ func (p *ProjectiveGroupElement) ToBytes(s *[32]byte) {
 	s[31] ^= FeIsNegative(&x) << 7
 }
 
+func (p *ProjectiveGroupElement) ToExtended(r *ExtendedGroupElement) {
+	var x, y, z FieldElement
+
+	FeMul(&x, &p.X, &p.Z)
+	FeMul(&y, &p.Y, &p.Z)
+	FeSquare(&z, &p.Z)
+	FeMul(&r.T, &p.X, &p.Y)
+	FeCopy(&r.X, &x)
+	FeCopy(&r.Y, &y)
+	FeCopy(&r.Z, &z)
+}
+
 func (p *ExtendedGroupElement) Zero() {
 	FeZero(&p.X)
 	FeOne(&p.Y)
@@ -731,7 +750,7 @@ func (p *ExtendedGroupElement) FromBytes(s *[32]byte) bool {
 	FeMul(&p.X, &p.X, &v)
 	FeMul(&p.X, &p.X, &u) // x = uv^7
 
-	fePow22523(&p.X, &p.X) // x = (uv^7)^((q-5)/8)
+	FePow22523(&p.X, &p.X) // x = (uv^7)^((q-5)/8)
 	FeMul(&p.X, &p.X, &v3)
 	FeMul(&p.X, &p.X, &u) // x = uv^3(uv^7)^((q-5)/8)
 
@@ -927,6 +946,183 @@ func GeDoubleScalarMultVartime(r *ProjectiveGroupElement, a *[32]byte, A *Extend
 	}
 }
 
+// GeAdd sets r = p + q.
+func GeAdd(r, p, q *ExtendedGroupElement) {
+	var qCached CachedGroupElement
+	var t CompletedGroupElement
+	q.ToCached(&qCached)
+	geAdd(&t, p, &qCached)
+	t.ToExtended(r)
+}
+
+// GeSub sets r = p - q.
+func GeSub(r, p, q *ExtendedGroupElement) {
+	var qCached CachedGroupElement
+	var t CompletedGroupElement
+	q.ToCached(&qCached)
+	geSub(&t, p, &qCached)
+	t.ToExtended(r)
+}
+
+func (p *CachedGroupElement) Zero() {
+	FeOne(&p.yPlusX)
+	FeOne(&p.yMinusX)
+	FeOne(&p.Z)
+	FeZero(&p.T2d)
+}
+
+func CachedGroupElementCMove(t, u *CachedGroupElement, b int32) {
+	FeCMove(&t.yPlusX, &u.yPlusX, b)
+	FeCMove(&t.yMinusX, &u.yMinusX, b)
+	FeCMove(&t.Z, &u.Z, b)
+	FeCMove(&t.T2d, &u.T2d, b)
+}
+
+// selectCached sets t to b*A in constant time, from table = A,2A,...,8A,
+// for b between -8 and 8.
+func selectCached(t *CachedGroupElement, table *[8]CachedGroupElement, b int32) {
+	var minusT CachedGroupElement
+	bNegative := negative(b)
+	bAbs := b - (((-bNegative) & b) << 1)
+
+	t.Zero()
+	for i := int32(0); i < 8; i++ {
+		CachedGroupElementCMove(t, &table[i], equal(bAbs, i+1))
+	}
+	FeCopy(&minusT.yPlusX, &t.yMinusX)
+	FeCopy(&minusT.yMinusX, &t.yPlusX)
+	FeCopy(&minusT.Z, &t.Z)
+	FeNeg(&minusT.T2d, &t.T2d)
+	CachedGroupElementCMove(t, &minusT, bNegative)
+}
+
+// GeScalarMult computes h = a*A in constant time, where
+//   a = a[0]+256*a[1]+...+256^31 a[31]
+//
+// Preconditions:
+//   a[31] <= 127
+func GeScalarMult(h *ExtendedGroupElement, a *[32]byte, A *ExtendedGroupElement) {
+	var e [64]int8
+
+	for i, v := range a {
+		e[2*i] = int8(v & 15)
+		e[2*i+1] = int8((v >> 4) & 15)
+	}
+
+	// each e[i] is between 0 and 15 and e[63] is between 0 and 7.
+
+	carry := int8(0)
+	for i := 0; i < 63; i++ {
+		e[i] += carry
+		carry = (e[i] + 8) >> 4
+		e[i] -= carry << 4
+	}
+	e[63] += carry
+	// each e[i] is between -8 and 8.
+
+	// table holds A,2A,...,8A.
+	var table [8]CachedGroupElement
+	var u ExtendedGroupElement
+	var r CompletedGroupElement
+	A.ToCached(&table[0])
+	for i := 1; i < 8; i++ {
+		geAdd(&r, A, &table[i-1])
+		r.ToExtended(&u)
+		u.ToCached(&table[i])
+	}
+
+	var t CachedGroupElement
+	var s ProjectiveGroupElement
+	h.Zero()
+	for i := 63; i >= 0; i-- {
+		if i != 63 {
+			h.Double(&r)
+			r.ToProjective(&s)
+			s.Double(&r)
+			r.ToProjective(&s)
+			s.Double(&r)
+			r.ToProjective(&s)
+			s.Double(&r)
+			r.ToExtended(h)
+		}
+		selectCached(&t, &table, int32(e[i]))
+		geAdd(&r, h, &t)
+		r.ToExtended(h)
+	}
+}
+
+// GeMultiScalarMultVartime sets r = a[0]*A[0] + ... + a[n-1]*A[n-1] + b*B,
+// where B is the Ed25519 base point, with the same sliding windows as
+// GeDoubleScalarMultVartime, interleaved over all the points. It panics if
+// a and A have different lengths.
+func GeMultiScalarMultVartime(r *ProjectiveGroupElement, a [][32]byte, A []ExtendedGroupElement, b *[32]byte) {
+	if len(a) != len(A) {
+		panic("edwards25519: mismatched scalars and points")
+	}
+	aSlide := make([][256]int8, len(a))
+	Ai := make([][8]CachedGroupElement, len(A)) // A,3A,5A,7A,9A,11A,13A,15A
+	var bSlide [256]int8
+	var t CompletedGroupElement
+	var u, A2 ExtendedGroupElement
+
+	for j := range A {
+		slide(&aSlide[j], &a[j])
+
+		A[j].ToCached(&Ai[j][0])
+		A[j].Double(&t)
+		t.ToExtended(&A2)
+		for i := 0; i < 7; i++ {
+			geAdd(&t, &A2, &Ai[j][i])
+			t.ToExtended(&u)
+			u.ToCached(&Ai[j][i+1])
+		}
+	}
+	slide(&bSlide, b)
+
+	r.Zero()
+
+	i := 255
+	for ; i >= 0; i-- {
+		if bSlide[i] != 0 {
+			break
+		}
+		nonZero := false
+		for j := range aSlide {
+			if aSlide[j][i] != 0 {
+				nonZero = true
+				break
+			}
+		}
+		if nonZero {
+			break
+		}
+	}
+
+	for ; i >= 0; i-- {
+		r.Double(&t)
+
+		for j := range aSlide {
+			if s := aSlide[j][i]; s > 0 {
+				t.ToExtended(&u)
+				geAdd(&t, &u, &Ai[j][s/2])
+			} else if s < 0 {
+				t.ToExtended(&u)
+				geSub(&t, &u, &Ai[j][(-s)/2])
+			}
+		}
+
+		if bSlide[i] > 0 {
+			t.ToExtended(&u)
+			geMixedAdd(&t, &u, &bi[bSlide[i]/2])
+		} else if bSlide[i] < 0 {
+			t.ToExtended(&u)
+			geMixedSub(&t, &u, &bi[(-bSlide[i])/2])
+		}
+
+		t.ToProjective(r)
+	}
+}
+
 // equal returns 1 if b == c and 0 otherwise, assuming that b and c are
 // non-negative.
 func equal(b, c int32) int32 {
@@ -0,0 +1,98 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdf
+
+import (
+	"hash"
+
+	"github.com/benchlab/bench-crypto/cipher"
+)
+
+// cmac implements CMAC as defined in NIST SP 800-38B, also known as OMAC1
+// (RFC 4493 for AES-128).
+type cmac struct {
+	c      cipher.Chunk
+	k1, k2 []byte
+	x      []byte // chaining value
+	buf    []byte // pending input, up to a full chunk
+}
+
+// NewCMAC returns a new hash.Hash computing the CMAC of NIST SP 800-38B
+// with the block cipher c, whose chunk size must be 8 or 16 bytes.
+func NewCMAC(c cipher.Chunk) hash.Hash {
+	n := c.ChunkSize()
+	var rb byte
+	switch n {
+	case 8:
+		rb = 0x1b
+	case 16:
+		rb = 0x87
+	default:
+		panic("kdf: CMAC requires a cipher with an 8 or 16 byte chunk size")
+	}
+	m := &cmac{c: c, k1: make([]byte, n), k2: make([]byte, n), x: make([]byte, n), buf: make([]byte, 0, n)}
+	c.Encrypt(m.k1, m.k1)
+	shiftLeft(m.k1, m.k1, rb)
+	shiftLeft(m.k2, m.k1, rb)
+	return m
+}
+
+// shiftLeft sets dst to src shifted left by one bit, reduced by rb.
+func shiftLeft(dst, src []byte, rb byte) {
+	msb := src[0] >> 7
+	for i := 0; i < len(src)-1; i++ {
+		dst[i] = src[i]<<1 | src[i+1]>>7
+	}
+	dst[len(src)-1] = src[len(src)-1]<<1 ^ rb&-msb
+}
+
+func (m *cmac) Size() int { return len(m.x) }
+
+func (m *cmac) ChunkSize() int { return len(m.x) }
+
+func (m *cmac) Reset() {
+	for i := range m.x {
+		m.x[i] = 0
+	}
+	m.buf = m.buf[:0]
+}
+
+func (m *cmac) Write(p []byte) (int, error) {
+	n := len(p)
+	// The last chunk is processed by Sum, so a full buffer is only
+	// encrypted once more input arrives.
+	for len(p) > 0 {
+		if len(m.buf) == cap(m.buf) {
+			m.update(m.buf)
+			m.buf = m.buf[:0]
+		}
+		k := copy(m.buf[len(m.buf):cap(m.buf)], p)
+		m.buf = m.buf[:len(m.buf)+k]
+		p = p[k:]
+	}
+	return n, nil
+}
+
+func (m *cmac) update(chunk []byte) {
+	for i := range m.x {
+		m.x[i] ^= chunk[i]
+	}
+	m.c.Encrypt(m.x, m.x)
+}
+
+func (m *cmac) Sum(in []byte) []byte {
+	last := make([]byte, len(m.x))
+	copy(last, m.buf)
+	key := m.k1
+	if len(m.buf) < len(last) {
+		last[len(m.buf)] = 0x80
+		key = m.k2
+	}
+	for i := range last {
+		last[i] ^= m.x[i] ^ key[i]
+	}
+	m.c.Encrypt(last, last)
+	return append(in, last...)
+}
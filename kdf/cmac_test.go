@@ -0,0 +1,68 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/benchlab/bench-crypto/aes"
+)
+
+// Test vectors from RFC 4493, Section 4.
+var cmacTests = []struct {
+	in, out string
+}{
+	{
+		"",
+		"bb1d6929e95937287fa37d129b756746",
+	},
+	{
+		"6bc1bee22e409f96e93d7e117393172a",
+		"070a16b46b4d4144f79bdd9dd04a287c",
+	},
+	{
+		"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411",
+		"dfa66747de9ae63030ca32611497c827",
+	},
+	{
+		"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710",
+		"51f0bebf7e3b9d92fc49741779363cfe",
+	},
+}
+
+func TestCMAC(t *testing.T) {
+	c, err := aes.NewCipher(fromHex("2b7e151628aed2a6abf7158809cf4f3c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac := NewCMAC(c)
+	for i, tt := range cmacTests {
+		in := fromHex(tt.in)
+		for _, step := range []int{1, 7, 16, 64} {
+			mac.Reset()
+			for p := in; len(p) > 0; {
+				n := step
+				if n > len(p) {
+					n = len(p)
+				}
+				mac.Write(p[:n])
+				p = p[n:]
+			}
+			if out := mac.Sum(nil); !bytes.Equal(out, fromHex(tt.out)) {
+				t.Errorf("#%d, writes of %d bytes: got %x, want %s", i, step, out, tt.out)
+			}
+		}
+	}
+}
+
+func fromHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
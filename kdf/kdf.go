@@ -0,0 +1,195 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package kdf implements the key derivation functions of NIST SP 800-108, in
+// counter, feedback and double-pipeline iteration mode, the one-step key
+// derivation function of NIST SP 800-56C and the key derivation function of
+// ANSI X9.63.
+//
+// The functions of SP 800-108 derive keys from a key which is already
+// cryptographically strong, using a keyed pseudorandom function such as HMAC
+// or CMAC. The one-step and X9.63 functions derive keys from the shared
+// secret of a key agreement, such as ECDH, using a hash function.
+//
+// SP 800-108: https://csrc.nist.gov/publications/detail/sp/800-108/final
+//
+// SP 800-56C: https://csrc.nist.gov/publications/detail/sp/800-56c/rev-1/final
+package kdf // import "golang.org/x/github.com/benchlab/bench-crypto/kdf"
+
+import (
+	"errors"
+	"hash"
+
+	"github.com/benchlab/bench-crypto/cipher"
+	"github.com/benchlab/bench-crypto/hmac"
+)
+
+// PRF is a keyed pseudorandom function. It returns a hash.Hash computing the
+// function under key.
+type PRF func(key []byte) (hash.Hash, error)
+
+// HMAC returns the PRF computing HMAC with the hash function h.
+func HMAC(h func() hash.Hash) PRF {
+	return func(key []byte) (hash.Hash, error) {
+		return hmac.New(h, key), nil
+	}
+}
+
+// CMAC returns the PRF computing CMAC with the block cipher created by
+// newCipher, such as aes.NewCipher.
+func CMAC(newCipher func(key []byte) (cipher.Chunk, error)) PRF {
+	return func(key []byte) (hash.Hash, error) {
+		b, err := newCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return NewCMAC(b), nil
+	}
+}
+
+// CounterLocation is the position of the counter in the input of the PRF.
+type CounterLocation int
+
+const (
+	// BeforeFixedData places the counter just before the fixed input data,
+	// and after the chaining value in feedback and double-pipeline mode.
+	// This is the layout given in SP 800-108.
+	BeforeFixedData CounterLocation = iota
+	// AfterFixedData places the counter after the fixed input data.
+	AfterFixedData
+	// BeforeIterationData places the counter before the chaining value,
+	// in feedback and double-pipeline mode only.
+	BeforeIterationData
+	// NoCounter omits the counter, in feedback and double-pipeline mode
+	// only.
+	NoCounter
+)
+
+// Options holds the optional parameters of the SP 800-108 key derivation
+// functions.
+type Options struct {
+	// CounterBits is the length of the counter in bits: 8, 16, 24 or 32.
+	// Zero means 32.
+	CounterBits int
+	// CounterLocation is the position of the counter.
+	CounterLocation CounterLocation
+}
+
+var defaultOptions = &Options{}
+
+// FixedInput returns the fixed input data recommended by SP 800-108,
+// Label || 0x00 || Context || [L]_32, where L is the length in bits of the
+// derived key, whose length is given in bytes.
+func FixedInput(label, context []byte, length int) []byte {
+	b := make([]byte, 0, len(label)+1+len(context)+4)
+	b = append(b, label...)
+	b = append(b, 0)
+	b = append(b, context...)
+	l := uint32(length) * 8
+	return append(b, byte(l>>24), byte(l>>16), byte(l>>8), byte(l))
+}
+
+// CounterMode derives a key of length bytes from key with the PRF in counter
+// mode, computing K(i) = PRF(key, [i]_r || fixedInput) for i = 1, 2, ... The
+// fixedInput is usually made with FixedInput. If opts is nil, the counter has
+// 32 bits and precedes the fixed input.
+func CounterMode(prf PRF, key, fixedInput []byte, length int, opts *Options) ([]byte, error) {
+	if opts == nil {
+		opts = defaultOptions
+	}
+	if opts.CounterLocation != BeforeFixedData && opts.CounterLocation != AfterFixedData {
+		return nil, errors.New("kdf: invalid counter location for counter mode")
+	}
+	return deriveKBKDF(prf, key, nil, fixedInput, length, opts, false)
+}
+
+// FeedbackMode derives a key of length bytes from key with the PRF in
+// feedback mode, computing K(i) = PRF(key, K(i-1) || [i]_r || fixedInput)
+// for i = 1, 2, ..., where K(0) is the iv, which may be empty. If opts is nil,
+// the counter has 32 bits and precedes the fixed input.
+func FeedbackMode(prf PRF, key, iv, fixedInput []byte, length int, opts *Options) ([]byte, error) {
+	if opts == nil {
+		opts = defaultOptions
+	}
+	if iv == nil {
+		iv = []byte{}
+	}
+	return deriveKBKDF(prf, key, iv, fixedInput, length, opts, false)
+}
+
+// DoublePipelineMode derives a key of length bytes from key with the PRF in
+// double-pipeline mode, computing A(i) = PRF(key, A(i-1)), where A(0) is the
+// fixedInput, and K(i) = PRF(key, A(i) || [i]_r || fixedInput) for i = 1, 2,
+// ... If opts is nil, the counter has 32 bits and precedes the fixed input.
+func DoublePipelineMode(prf PRF, key, fixedInput []byte, length int, opts *Options) ([]byte, error) {
+	if opts == nil {
+		opts = defaultOptions
+	}
+	return deriveKBKDF(prf, key, fixedInput, fixedInput, length, opts, true)
+}
+
+// deriveKBKDF implements the three modes of SP 800-108. In counter mode,
+// chain is nil. In feedback mode, chain is K(0) and each K(i) replaces it. In
+// double-pipeline mode, chain is A(0) and is advanced before every K(i).
+func deriveKBKDF(prf PRF, key, chain, fixedInput []byte, length int, opts *Options, pipeline bool) ([]byte, error) {
+	r := opts.CounterBits
+	if r == 0 {
+		r = 32
+	}
+	if r != 8 && r != 16 && r != 24 && r != 32 {
+		return nil, errors.New("kdf: invalid counter length")
+	}
+	if opts.CounterLocation < BeforeFixedData || opts.CounterLocation > NoCounter {
+		return nil, errors.New("kdf: invalid counter location")
+	}
+	if length < 0 {
+		return nil, errors.New("kdf: invalid key length")
+	}
+
+	mac, err := prf(key)
+	if err != nil {
+		return nil, err
+	}
+	h := mac.Size()
+	n := (uint64(length) + uint64(h) - 1) / uint64(h)
+	if (opts.CounterLocation != NoCounter && n > 1<<uint(r)-1) || n > 1<<32-1 {
+		return nil, errors.New("kdf: requested key length too long")
+	}
+
+	var counter [4]byte
+	out := make([]byte, 0, int(n)*h)
+	for i := uint64(1); i <= n; i++ {
+		counter[0], counter[1], counter[2], counter[3] = byte(i>>24), byte(i>>16), byte(i>>8), byte(i)
+		ctr := counter[4-r/8:]
+		if opts.CounterLocation == NoCounter {
+			ctr = nil
+		}
+
+		if pipeline {
+			mac.Reset()
+			mac.Write(chain)
+			chain = mac.Sum(chain[:0:0])
+		}
+		mac.Reset()
+		if opts.CounterLocation == BeforeIterationData {
+			mac.Write(ctr)
+		}
+		if chain != nil {
+			mac.Write(chain)
+		}
+		if opts.CounterLocation == BeforeFixedData {
+			mac.Write(ctr)
+		}
+		mac.Write(fixedInput)
+		if opts.CounterLocation == AfterFixedData {
+			mac.Write(ctr)
+		}
+		out = mac.Sum(out)
+
+		if chain != nil && !pipeline {
+			chain = out[len(out)-h:]
+		}
+	}
+	return out[:length], nil
+}
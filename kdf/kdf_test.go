@@ -0,0 +1,201 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/benchlab/bench-crypto/aes"
+	"github.com/benchlab/bench-crypto/sha256"
+)
+
+// TestCounterModeCAVP checks the first test cases of the CAVP KDFCTR vectors
+// with the counter before the fixed input data.
+func TestCounterModeCAVP(t *testing.T) {
+	tests := []struct {
+		name       string
+		prf        PRF
+		r          int
+		key, fixed string
+		out        string
+	}{
+		{
+			"HMAC_SHA256", HMAC(sha256.New), 8,
+			"3edc6b5b8f7aadbd713732b482b8f979286e1ea3b8f8f99c30c884cfe3349b83",
+			"98e9988bb4cc8b34d7922e1c68ad692ba2a1d9ae15149571675f17a77ad49e80c8d2a85e831a26445b1f0ff44d7084a17206b4896c8112daad18605a",
+			"6c037652990674a07844732d0ad985f9",
+		},
+		{
+			"CMAC_AES128", CMAC(aes.NewCipher), 8,
+			"dff1e50ac0b69dc40f1051d46c2b069c",
+			"c16e6e02c5a3dcc8d78b9ac1306877761310455b4e41469951d9e6c2245a064b33fd8c3b01203a7824485bf0a64060c4648b707d2607935699316ea5",
+			"8be8f0869b3c0ba97b71863d1b9f7813",
+		},
+	}
+	for _, tt := range tests {
+		want := fromHex(tt.out)
+		out, err := CounterMode(tt.prf, fromHex(tt.key), fromHex(tt.fixed), len(want), &Options{CounterBits: tt.r})
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !bytes.Equal(out, want) {
+			t.Errorf("%s: got %x, want %x", tt.name, out, want)
+		}
+	}
+}
+
+// The expected outputs of these tests were computed with an independent
+// implementation, in Python.
+var kbkdfTests = []struct {
+	name string
+	opts *Options
+	f    func(key, iv, fixed []byte, length int, opts *Options) ([]byte, error)
+	out  string
+}{
+	{
+		"counter, 16-bit counter after fixed data",
+		&Options{CounterBits: 16, CounterLocation: AfterFixedData},
+		func(key, iv, fixed []byte, length int, opts *Options) ([]byte, error) {
+			return CounterMode(HMAC(sha256.New), key, fixed, length, opts)
+		},
+		"aa333b22f80333ecd831ec58e361af5ab9f5446ab9fa950410d9bb0e71b12a881e8e1cfb8910582c",
+	},
+	{
+		"feedback, 8-bit counter",
+		&Options{CounterBits: 8},
+		func(key, iv, fixed []byte, length int, opts *Options) ([]byte, error) {
+			return FeedbackMode(HMAC(sha256.New), key, iv, fixed, length, opts)
+		},
+		"994d32a44c5d1167b3e1df7bf47d680dab9a842905c62bebfe890ef46ccc6f4b8cc4933491e1aac9",
+	},
+	{
+		"feedback, no counter",
+		&Options{CounterLocation: NoCounter},
+		func(key, iv, fixed []byte, length int, opts *Options) ([]byte, error) {
+			return FeedbackMode(HMAC(sha256.New), key, iv, fixed, length, opts)
+		},
+		"d45de6c1c11591bf606551abf5c23856289b37784697e59cde39b3852c652ed1c3104d9f5b8e8cb6",
+	},
+	{
+		"double pipeline, 32-bit counter",
+		nil,
+		func(key, iv, fixed []byte, length int, opts *Options) ([]byte, error) {
+			return DoublePipelineMode(HMAC(sha256.New), key, fixed, length, opts)
+		},
+		"d8ab72593be984782969b428fed274db3d9babb831074512ea08e2c916f31d78159e98b8dbb75910",
+	},
+}
+
+func TestKBKDF(t *testing.T) {
+	key := make([]byte, 32)
+	iv := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+		iv[i] = byte(0x20 + i)
+	}
+	for _, tt := range kbkdfTests {
+		want := fromHex(tt.out)
+		fixed := FixedInput([]byte("label"), []byte("context"), len(want))
+		out, err := tt.f(key, iv, fixed, len(want), tt.opts)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !bytes.Equal(out, want) {
+			t.Errorf("%s: got %x, want %x", tt.name, out, want)
+		}
+	}
+}
+
+func TestKBKDFLimits(t *testing.T) {
+	prf := HMAC(sha256.New)
+	key := make([]byte, 32)
+	if _, err := CounterMode(prf, key, nil, 255*sha256.Size, &Options{CounterBits: 8}); err != nil {
+		t.Errorf("255 blocks with an 8-bit counter: %v", err)
+	}
+	if _, err := CounterMode(prf, key, nil, 255*sha256.Size+1, &Options{CounterBits: 8}); err == nil {
+		t.Error("256 blocks with an 8-bit counter did not fail")
+	}
+	if _, err := CounterMode(prf, key, nil, 32, &Options{CounterBits: 12}); err == nil {
+		t.Error("12-bit counter did not fail")
+	}
+	if _, err := CounterMode(prf, key, nil, 32, &Options{CounterLocation: NoCounter}); err == nil {
+		t.Error("counter mode without a counter did not fail")
+	}
+}
+
+func TestOneStep(t *testing.T) {
+	// The ECDH-ES key agreement example of RFC 7518, Appendix C.
+	z := []byte{
+		158, 86, 217, 29, 129, 113, 53, 211, 114, 131, 66, 131, 191, 132,
+		38, 156, 251, 49, 110, 163, 218, 128, 106, 72, 246, 218, 167, 121,
+		140, 254, 144, 196,
+	}
+	var otherInfo []byte
+	otherInfo = append(otherInfo, 0, 0, 0, 7)
+	otherInfo = append(otherInfo, "A128GCM"...)
+	otherInfo = append(otherInfo, 0, 0, 0, 5)
+	otherInfo = append(otherInfo, "Alice"...)
+	otherInfo = append(otherInfo, 0, 0, 0, 3)
+	otherInfo = append(otherInfo, "Bob"...)
+	otherInfo = append(otherInfo, 0, 0, 0, 128)
+	want := []byte{86, 170, 141, 234, 248, 35, 109, 32, 92, 34, 40, 205, 113, 167, 16, 26}
+
+	out, err := OneStep(sha256.New, z, otherInfo, len(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, want) {
+		t.Errorf("got %x, want %x", out, want)
+	}
+}
+
+func TestOneStepHMAC(t *testing.T) {
+	// Computed with an independent implementation, in Python, with the
+	// default salt.
+	z := make([]byte, 32)
+	for i := range z {
+		z[i] = byte(i)
+	}
+	want := fromHex("96014d4fa5519f64b563b9a233be499a7fea80acd53383a4e34026a5ab134cc58ee7d67f133d8da0d23f435cfc52e38b")
+	out, err := OneStepHMAC(sha256.New, nil, z, []byte("fixed info"), len(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, want) {
+		t.Errorf("got %x, want %x", out, want)
+	}
+}
+
+func TestX963(t *testing.T) {
+	// Test cases of the CAVP ANSI X9.63 KDF vectors for SHA-256.
+	tests := []struct {
+		z, sharedInfo, out string
+	}{
+		{
+			"96c05619d56c328ab95fe84b18264b08725b85e33fd34f08",
+			"",
+			"443024c3dae66b95e6f5670601558f71",
+		},
+		{
+			"22518b10e70f2a3f243810ae3254139efbee04aa57c7af7d",
+			"75eef81aa3041e33b80971203d2c0c52",
+			"c498af77161cc59f2962b9a713e2b215152d139766ce34a776df11866a69bf2e52a13d9c7c6fc878c50c5ea0bc7b00e0da2447cfd874f6cf92f30d0097111485500c90c3af8b487872d04685d14c8d1dc8d7fa08beb0ce0ababc11f0bd496269142d43525a78e5bc79a17f59676a5706dc54d54d4d1f0bd7e386128ec26afc21",
+		},
+	}
+	for i, tt := range tests {
+		want := fromHex(tt.out)
+		out, err := X963(sha256.New, fromHex(tt.z), fromHex(tt.sharedInfo), len(want))
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if !bytes.Equal(out, want) {
+			t.Errorf("#%d: got %x, want %x", i, out, want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdf
+
+import (
+	"errors"
+	"hash"
+
+	"github.com/benchlab/bench-crypto/hmac"
+)
+
+// OneStep derives a key of length bytes from the shared secret z with the
+// one-step key derivation function of SP 800-56C, with the hash function h
+// as auxiliary function. It computes H([i]_32 || z || fixedInfo) for i = 1,
+// 2, ... This is also the Concat KDF of SP 800-56A, used by OpenPGP ECDH (RFC
+// 6637) and JOSE ECDH-ES (RFC 7518).
+func OneStep(h func() hash.Hash, z, fixedInfo []byte, length int) ([]byte, error) {
+	return deriveOneStep(h(), z, fixedInfo, length, true)
+}
+
+// OneStepHMAC derives a key of length bytes from the shared secret z with the
+// one-step key derivation function of SP 800-56C, with HMAC keyed by salt as
+// auxiliary function. A nil salt stands for the default salt, an all-zero
+// string as long as the chunk size of the hash.
+func OneStepHMAC(h func() hash.Hash, salt, z, fixedInfo []byte, length int) ([]byte, error) {
+	if salt == nil {
+		salt = make([]byte, h().ChunkSize())
+	}
+	return deriveOneStep(hmac.New(h, salt), z, fixedInfo, length, true)
+}
+
+// X963 derives a key of length bytes from the shared secret z with the key
+// derivation function of ANSI X9.63 and SEC 1, computing H(z || [i]_32 ||
+// sharedInfo) for i = 1, 2, ...
+func X963(h func() hash.Hash, z, sharedInfo []byte, length int) ([]byte, error) {
+	return deriveOneStep(h(), z, sharedInfo, length, false)
+}
+
+// deriveOneStep concatenates the outputs of the function f over the counter
+// and z, in the order of SP 800-56C if counterFirst is set, and of X9.63
+// otherwise, followed by the info.
+func deriveOneStep(f hash.Hash, z, info []byte, length int, counterFirst bool) ([]byte, error) {
+	if length < 0 {
+		return nil, errors.New("kdf: invalid key length")
+	}
+	n := (uint64(length) + uint64(f.Size()) - 1) / uint64(f.Size())
+	if n > 1<<32-1 {
+		return nil, errors.New("kdf: requested key length too long")
+	}
+
+	var counter [4]byte
+	out := make([]byte, 0, int(n)*f.Size())
+	for i := uint64(1); i <= n; i++ {
+		counter[0], counter[1], counter[2], counter[3] = byte(i>>24), byte(i>>16), byte(i>>8), byte(i)
+		f.Reset()
+		if counterFirst {
+			f.Write(counter[:])
+			f.Write(z)
+		} else {
+			f.Write(z)
+			f.Write(counter[:])
+		}
+		f.Write(info)
+		out = f.Sum(out)
+	}
+	return out[:length], nil
+}
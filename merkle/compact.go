@@ -0,0 +1,52 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package merkle
+
+// A CompactTree computes the root of an append-only Merkle tree while only
+// keeping the roots of its perfect subtrees, that is O(log n) hashes for a
+// tree of n leaves. It cannot produce proofs; use a Tree for that.
+//
+// A CompactTree is not safe for concurrent use.
+type CompactTree struct {
+	h    *Hasher
+	size uint64
+
+	// peaks[i] is the root of the perfect subtree of 2^i leaves if bit i
+	// of size is set, and nil otherwise.
+	peaks [][]byte
+}
+
+// NewCompactTree returns an empty compact tree whose hashes are computed by h.
+func NewCompactTree(h *Hasher) *CompactTree {
+	return &CompactTree{h: h}
+}
+
+// Size returns the number of leaves appended to t.
+func (t *CompactTree) Size() uint64 { return t.size }
+
+// Append adds a leaf holding data to t.
+func (t *CompactTree) Append(data []byte) {
+	t.AppendHash(t.h.HashLeaf(data))
+}
+
+// AppendHash adds a leaf with the given leaf hash to t.
+func (t *CompactTree) AppendHash(leafHash []byte) {
+	carry := leafHash
+	i := 0
+	for ; t.size>>uint(i)&1 == 1; i++ {
+		carry = t.h.HashChildren(t.peaks[i], carry)
+		t.peaks[i] = nil
+	}
+	if i == len(t.peaks) {
+		t.peaks = append(t.peaks, nil)
+	}
+	t.peaks[i] = carry
+	t.size++
+}
+
+// Root returns the root hash of t.
+func (t *CompactTree) Root() []byte {
+	return t.h.foldRoot(t.size, t.peaks)
+}
@@ -0,0 +1,206 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package merkle implements binary Merkle hash trees over any registered
+// crypto.Hash, together with inclusion and consistency proofs.
+//
+// By default trees are built as described in RFC 6962 and RFC 9162: leaf
+// hashes are computed as H(0x00 || data) and interior nodes as
+// H(0x01 || left || right), and a tree whose size is not a power of two is
+// split at the largest power of two smaller than its size. This domain
+// separation prevents second-preimage attacks in which an interior node is
+// presented as a leaf.
+//
+// Trees may instead be built in the style of Bitcoin, where leaves and nodes
+// are hashed without a prefix and the last node of a level with an odd number
+// of nodes is paired with itself. This mode exists for interoperability only:
+// it is malleable (CVE-2012-2459), as appending a copy of the last leaves of a
+// level can leave the root unchanged, and it does not support consistency
+// proofs.
+//
+// RFC 9162: https://tools.ietf.org/html/rfc9162
+package merkle // import "golang.org/x/github.com/benchlab/bench-crypto/merkle"
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+
+	crypto "github.com/benchlab/bench-crypto"
+)
+
+// Mode selects the way a Hasher computes leaf and interior node hashes.
+type Mode int
+
+const (
+	// RFC6962 computes hashes with the leaf and node prefixes of RFC 6962
+	// and RFC 9162 and splits unbalanced trees at a power of two.
+	RFC6962 Mode = iota
+	// Bitcoin computes hashes without prefixes and duplicates the last
+	// node of every level that has an odd number of nodes.
+	Bitcoin
+)
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// parallelThreshold is the number of leaves from which HashLeaves spreads its
+// work over several goroutines.
+const parallelThreshold = 1024
+
+var (
+	errIndexOutOfRange = errors.New("merkle: leaf index out of range")
+	errSizeOutOfRange  = errors.New("merkle: tree size out of range")
+	errUnsupported     = errors.New("merkle: operation not supported by the tree mode")
+)
+
+// A Hasher computes the hashes of the leaves and interior nodes of a tree.
+// A Hasher is safe for concurrent use.
+type Hasher struct {
+	hash crypto.Hash
+	mode Mode
+}
+
+// NewHasher returns a Hasher for the given hash function and mode. It panics
+// if the hash function is not linked into the binary.
+func NewHasher(h crypto.Hash, mode Mode) *Hasher {
+	if !h.Available() {
+		panic("merkle: requested hash function #" + strconv.Itoa(int(h)) + " is unavailable")
+	}
+	if mode != RFC6962 && mode != Bitcoin {
+		panic("merkle: unknown mode " + strconv.Itoa(int(mode)))
+	}
+	return &Hasher{hash: h, mode: mode}
+}
+
+// Size returns the size in bytes of the hashes produced by h.
+func (h *Hasher) Size() int { return h.hash.Size() }
+
+// Mode returns the mode of h.
+func (h *Hasher) Mode() Mode { return h.mode }
+
+// EmptyRoot returns the root hash of a tree without leaves, which is the hash
+// of the empty string.
+func (h *Hasher) EmptyRoot() []byte {
+	return h.hash.New().Sum(nil)
+}
+
+// HashLeaf returns the hash of a leaf holding data.
+func (h *Hasher) HashLeaf(data []byte) []byte {
+	d := h.hash.New()
+	if h.mode == RFC6962 {
+		d.Write([]byte{leafPrefix})
+	}
+	d.Write(data)
+	return d.Sum(nil)
+}
+
+// HashChildren returns the hash of the interior node whose children have the
+// hashes left and right.
+func (h *Hasher) HashChildren(left, right []byte) []byte {
+	d := h.hash.New()
+	if h.mode == RFC6962 {
+		d.Write([]byte{nodePrefix})
+	}
+	d.Write(left)
+	d.Write(right)
+	return d.Sum(nil)
+}
+
+// HashLeaves returns the leaf hashes of all entries in data. Large inputs are
+// hashed on several goroutines.
+func (h *Hasher) HashLeaves(data [][]byte) [][]byte {
+	hashes := make([][]byte, len(data))
+	h.parallel(len(data), func(i int) {
+		hashes[i] = h.HashLeaf(data[i])
+	})
+	return hashes
+}
+
+// hashLevel returns the parent level of the perfect nodes in level, which
+// must have an even length.
+func (h *Hasher) hashLevel(level [][]byte) [][]byte {
+	parents := make([][]byte, len(level)/2)
+	h.parallel(len(parents), func(i int) {
+		parents[i] = h.HashChildren(level[2*i], level[2*i+1])
+	})
+	return parents
+}
+
+// parallel calls fn for every index in [0, n), splitting the work over
+// GOMAXPROCS goroutines if n is large enough to make that worthwhile.
+func (h *Hasher) parallel(n int, fn func(i int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if n < parallelThreshold || workers < 2 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	if workers > n/(parallelThreshold/4) {
+		workers = n / (parallelThreshold / 4)
+	}
+
+	var wg sync.WaitGroup
+	step := (n + workers - 1) / workers
+	for lo := 0; lo < n; lo += step {
+		hi := lo + step
+		if hi > n {
+			hi = n
+		}
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			for i := lo; i < hi; i++ {
+				fn(i)
+			}
+		}(lo, hi)
+	}
+	wg.Wait()
+}
+
+// foldRoot returns the root of a tree of the given size, whose perfect
+// subtrees are given in peaks: peaks[i] is the root of the perfect subtree of
+// 2^i leaves if bit i of size is set, and is ignored otherwise.
+func (h *Hasher) foldRoot(size uint64, peaks [][]byte) []byte {
+	if size == 0 {
+		return h.EmptyRoot()
+	}
+
+	var carry []byte
+	if h.mode == RFC6962 {
+		for i := 0; size>>uint(i) != 0; i++ {
+			if size>>uint(i)&1 == 0 {
+				continue
+			}
+			if carry == nil {
+				carry = peaks[i]
+			} else {
+				carry = h.HashChildren(peaks[i], carry)
+			}
+		}
+		return carry
+	}
+
+	// In Bitcoin mode, carry holds the rightmost node of level i whenever
+	// it covers fewer than 2^i leaves.
+	for i := 0; ; i++ {
+		full := size >> uint(i)
+		switch {
+		case full == 0:
+			return carry
+		case full == 1 && carry == nil:
+			return peaks[i]
+		case full&1 == 1 && carry != nil:
+			carry = h.HashChildren(peaks[i], carry)
+		case full&1 == 1:
+			carry = h.HashChildren(peaks[i], peaks[i])
+		case carry != nil:
+			carry = h.HashChildren(carry, carry)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	crypto "github.com/benchlab/bench-crypto"
+	_ "github.com/benchlab/bench-crypto/sha256"
+)
+
+// Leaves and roots from the certificate-transparency reference test suite.
+var ctLeaves = []string{
+	"",
+	"00",
+	"10",
+	"2021",
+	"3031",
+	"40414243",
+	"5051525354555657",
+	"606162636465666768696a6b6c6d6e6f",
+}
+
+var ctRoots = []string{
+	"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+	"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+	"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+	"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+	"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+	"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+	"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+}
+
+func ctTree(t *testing.T) *Tree {
+	tree := NewTree(NewHasher(crypto.SHA256, RFC6962))
+	for _, l := range ctLeaves {
+		data, err := hex.DecodeString(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree.Append(data)
+	}
+	return tree
+}
+
+func TestRFC6962Roots(t *testing.T) {
+	tree := ctTree(t)
+	for i, want := range ctRoots {
+		root, err := tree.RootAt(uint64(i + 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(root); got != want {
+			t.Errorf("size %d: got root %s, want %s", i+1, got, want)
+		}
+	}
+	empty, _ := tree.RootAt(0)
+	if got, want := hex.EncodeToString(empty), "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"; got != want {
+		t.Errorf("empty tree: got root %s, want %s", got, want)
+	}
+}
+
+// referenceRoot computes the root of leaves directly from the definitions.
+func referenceRoot(h *Hasher, leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		return h.EmptyRoot()
+	}
+	level := h.HashLeaves(leaves)
+	if h.mode == RFC6962 {
+		var mth func(l [][]byte) []byte
+		mth = func(l [][]byte) []byte {
+			if len(l) == 1 {
+				return l[0]
+			}
+			k := splitPoint(uint64(len(l)))
+			return h.HashChildren(mth(l[:k]), mth(l[k:]))
+		}
+		return mth(level)
+	}
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			next = append(next, h.HashChildren(level[i], level[i+1]))
+		}
+		level = next
+	}
+	return level[0]
+}
+
+func testLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		leaves[i] = []byte(fmt.Sprintf("leaf %d", i))
+	}
+	return leaves
+}
+
+func TestRoots(t *testing.T) {
+	const n = 70
+	leaves := testLeaves(n)
+	for _, mode := range []Mode{RFC6962, Bitcoin} {
+		h := NewHasher(crypto.SHA256, mode)
+		tree, compact := NewTree(h), NewCompactTree(h)
+		for i := 0; i <= n; i++ {
+			want := referenceRoot(h, leaves[:i])
+			if got := tree.Root(); !bytes.Equal(got, want) {
+				t.Errorf("mode %d, size %d: Tree root %x, want %x", mode, i, got, want)
+			}
+			if got := compact.Root(); !bytes.Equal(got, want) {
+				t.Errorf("mode %d, size %d: CompactTree root %x, want %x", mode, i, got, want)
+			}
+			if i < n {
+				tree.Append(leaves[i])
+				compact.Append(leaves[i])
+			}
+		}
+	}
+}
+
+func TestAppendBatch(t *testing.T) {
+	leaves := testLeaves(3*parallelThreshold + 5)
+	for _, mode := range []Mode{RFC6962, Bitcoin} {
+		h := NewHasher(crypto.SHA256, mode)
+		one, batch := NewTree(h), NewTree(h)
+		for _, l := range leaves {
+			one.Append(l)
+		}
+		batch.AppendBatch(leaves[:7])
+		batch.AppendBatch(leaves[7 : 2*parallelThreshold])
+		batch.AppendBatch(leaves[2*parallelThreshold:])
+		if !bytes.Equal(one.Root(), batch.Root()) {
+			t.Errorf("mode %d: AppendBatch root %x, want %x", mode, batch.Root(), one.Root())
+		}
+		if len(one.levels) != len(batch.levels) {
+			t.Fatalf("mode %d: AppendBatch built %d levels, want %d", mode, len(batch.levels), len(one.levels))
+		}
+		for k := range one.levels {
+			if len(one.levels[k]) != len(batch.levels[k]) {
+				t.Errorf("mode %d: AppendBatch level %d has %d nodes, want %d", mode, k, len(batch.levels[k]), len(one.levels[k]))
+			}
+		}
+	}
+}
+
+func TestInclusionProofs(t *testing.T) {
+	const n = 40
+	for _, mode := range []Mode{RFC6962, Bitcoin} {
+		h := NewHasher(crypto.SHA256, mode)
+		tree := NewTree(h)
+		tree.AppendBatch(testLeaves(n))
+		for size := uint64(1); size <= n; size++ {
+			root, _ := tree.RootAt(size)
+			for index := uint64(0); index < size; index++ {
+				proof, err := tree.InclusionProof(index, size)
+				if err != nil {
+					t.Fatal(err)
+				}
+				leaf, _ := tree.LeafHash(index)
+				if err := VerifyInclusion(h, index, size, leaf, proof, root); err != nil {
+					t.Fatalf("mode %d: proof for leaf %d in tree of size %d rejected: %v", mode, index, size, err)
+				}
+				if len(proof) > 0 {
+					proof[0] = h.HashLeaf(proof[0])
+					if VerifyInclusion(h, index, size, leaf, proof, root) == nil {
+						t.Fatalf("mode %d: tampered proof for leaf %d in tree of size %d accepted", mode, index, size)
+					}
+				}
+				if size > 1 {
+					proof, _ := tree.InclusionProof(index, size)
+					if VerifyInclusion(h, index^1, size, leaf, proof, root) == nil && index^1 < size {
+						t.Fatalf("mode %d: proof for leaf %d in tree of size %d accepted for index %d", mode, index, size, index^1)
+					}
+					if VerifyInclusion(h, index, size, leaf, proof[:len(proof)-1], root) == nil {
+						t.Fatalf("mode %d: truncated proof for leaf %d in tree of size %d accepted", mode, index, size)
+					}
+				}
+			}
+		}
+		if _, err := tree.InclusionProof(n, n); err == nil {
+			t.Errorf("mode %d: InclusionProof accepted an index out of range", mode)
+		}
+		if _, err := tree.InclusionProof(0, n+1); err == nil {
+			t.Errorf("mode %d: InclusionProof accepted a size out of range", mode)
+		}
+	}
+}
+
+func TestConsistencyProofs(t *testing.T) {
+	const n = 40
+	h := NewHasher(crypto.SHA256, RFC6962)
+	tree := NewTree(h)
+	tree.AppendBatch(testLeaves(n))
+	for size2 := uint64(0); size2 <= n; size2++ {
+		root2, _ := tree.RootAt(size2)
+		for size1 := uint64(0); size1 <= size2; size1++ {
+			root1, _ := tree.RootAt(size1)
+			proof, err := tree.ConsistencyProof(size1, size2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyConsistency(h, size1, size2, root1, root2, proof); err != nil {
+				t.Fatalf("consistency proof between sizes %d and %d rejected: %v", size1, size2, err)
+			}
+			if size1 == 0 || size1 == size2 {
+				continue
+			}
+			if VerifyConsistency(h, size1, size2, root2, root1, proof) == nil {
+				t.Fatalf("consistency proof between sizes %d and %d accepted with swapped roots", size1, size2)
+			}
+			proof[len(proof)-1] = h.HashLeaf(proof[len(proof)-1])
+			if VerifyConsistency(h, size1, size2, root1, root2, proof) == nil {
+				t.Fatalf("tampered consistency proof between sizes %d and %d accepted", size1, size2)
+			}
+		}
+	}
+
+	bitcoin := NewTree(NewHasher(crypto.SHA256, Bitcoin))
+	bitcoin.Append(nil)
+	if _, err := bitcoin.ConsistencyProof(0, 1); err == nil {
+		t.Error("ConsistencyProof succeeded in Bitcoin mode")
+	}
+}
+
+func BenchmarkAppendBatch(b *testing.B) {
+	leaves := testLeaves(1 << 14)
+	h := NewHasher(crypto.SHA256, RFC6962)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewTree(h).AppendBatch(leaves)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package merkle
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrInvalidProof is returned by VerifyInclusion and VerifyConsistency when a
+// proof does not match the given roots.
+var ErrInvalidProof = errors.New("merkle: invalid proof")
+
+// VerifyInclusion checks that proof shows the leaf with hash leafHash to be
+// at index in the tree of the given size with the given root.
+func VerifyInclusion(h *Hasher, index, size uint64, leafHash []byte, proof [][]byte, root []byte) error {
+	calculated, err := RootFromInclusionProof(h, index, size, leafHash, proof)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(calculated, root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// RootFromInclusionProof returns the root of the tree of the given size
+// implied by an inclusion proof for the leaf with hash leafHash at index.
+func RootFromInclusionProof(h *Hasher, index, size uint64, leafHash []byte, proof [][]byte) ([]byte, error) {
+	if index >= size {
+		return nil, errIndexOutOfRange
+	}
+	if h.mode == Bitcoin {
+		return duplicateRoot(h, index, size, leafHash, proof)
+	}
+
+	// This is the algorithm of RFC 9162, section 2.1.3.2.
+	fn, sn := index, size-1
+	r := leafHash
+	for _, p := range proof {
+		if sn == 0 {
+			return nil, ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			r = h.HashChildren(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = h.HashChildren(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return nil, ErrInvalidProof
+	}
+	return r, nil
+}
+
+func duplicateRoot(h *Hasher, index, size uint64, leafHash []byte, proof [][]byte) ([]byte, error) {
+	r := leafHash
+	for _, p := range proof {
+		if size <= 1 {
+			return nil, ErrInvalidProof
+		}
+		switch {
+		case index&1 == 1:
+			r = h.HashChildren(p, r)
+		case index == size-1:
+			// The node is paired with itself; anything else in the
+			// proof would be a forgery.
+			if !bytes.Equal(p, r) {
+				return nil, ErrInvalidProof
+			}
+			r = h.HashChildren(r, r)
+		default:
+			r = h.HashChildren(r, p)
+		}
+		index >>= 1
+		size = (size + 1) >> 1
+	}
+	if size != 1 {
+		return nil, ErrInvalidProof
+	}
+	return r, nil
+}
+
+// VerifyConsistency checks that proof shows the tree of size2 leaves with
+// root2 to be an extension of the tree of size1 leaves with root1. It
+// implements the algorithm of RFC 9162, section 2.1.4.2, and only supports
+// trees built in RFC6962 mode.
+func VerifyConsistency(h *Hasher, size1, size2 uint64, root1, root2 []byte, proof [][]byte) error {
+	if h.mode != RFC6962 {
+		return errUnsupported
+	}
+	switch {
+	case size1 > size2:
+		return errSizeOutOfRange
+	case size1 == size2:
+		if len(proof) != 0 || !bytes.Equal(root1, root2) {
+			return ErrInvalidProof
+		}
+		return nil
+	case size1 == 0:
+		// The empty tree is a prefix of every tree.
+		if len(proof) != 0 {
+			return ErrInvalidProof
+		}
+		return nil
+	case len(proof) == 0:
+		return ErrInvalidProof
+	}
+
+	if size1&(size1-1) == 0 {
+		proof = append([][]byte{root1}, proof...)
+	}
+	fn, sn := size1-1, size2-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = h.HashChildren(c, fr)
+			sr = h.HashChildren(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = h.HashChildren(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || !bytes.Equal(fr, root1) || !bytes.Equal(sr, root2) {
+		return ErrInvalidProof
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package merkle
+
+import "math/bits"
+
+// A Tree is an append-only Merkle tree that keeps the hashes of all its
+// leaves and perfect subtrees, so that it can produce the root of, and
+// proofs for, any of its past sizes.
+//
+// A Tree is not safe for concurrent use.
+type Tree struct {
+	h *Hasher
+
+	// levels[k][i] is the root of the perfect subtree holding the 2^k
+	// leaves starting at index i<<k. levels[0] holds the leaf hashes.
+	levels [][][]byte
+}
+
+// NewTree returns an empty tree whose hashes are computed by h.
+func NewTree(h *Hasher) *Tree {
+	return &Tree{h: h, levels: make([][][]byte, 1)}
+}
+
+// Size returns the number of leaves in t.
+func (t *Tree) Size() uint64 { return uint64(len(t.levels[0])) }
+
+// Append adds a leaf holding data to t and returns its index.
+func (t *Tree) Append(data []byte) uint64 {
+	return t.AppendHash(t.h.HashLeaf(data))
+}
+
+// AppendHash adds a leaf with the given leaf hash to t and returns its index.
+func (t *Tree) AppendHash(leafHash []byte) uint64 {
+	index := t.Size()
+	t.levels[0] = append(t.levels[0], leafHash)
+	for k := 0; len(t.levels[k])%2 == 0; k++ {
+		if k+1 == len(t.levels) {
+			t.levels = append(t.levels, nil)
+		}
+		n := len(t.levels[k])
+		t.levels[k+1] = append(t.levels[k+1], t.h.HashChildren(t.levels[k][n-2], t.levels[k][n-1]))
+	}
+	return index
+}
+
+// AppendBatch adds one leaf for every entry in data to t. Leaf and node
+// hashes of large batches are computed on several goroutines.
+func (t *Tree) AppendBatch(data [][]byte) {
+	t.levels[0] = append(t.levels[0], t.h.HashLeaves(data)...)
+	for k := 0; len(t.levels[k]) >= 2; k++ {
+		if k+1 == len(t.levels) {
+			t.levels = append(t.levels, nil)
+		}
+		done := len(t.levels[k+1])
+		todo := t.levels[k][2*done : len(t.levels[k])&^1]
+		t.levels[k+1] = append(t.levels[k+1], t.h.hashLevel(todo)...)
+	}
+}
+
+// LeafHash returns the hash of the leaf at index.
+func (t *Tree) LeafHash(index uint64) ([]byte, error) {
+	if index >= t.Size() {
+		return nil, errIndexOutOfRange
+	}
+	return t.levels[0][index], nil
+}
+
+// Root returns the root hash of t.
+func (t *Tree) Root() []byte {
+	root, _ := t.RootAt(t.Size())
+	return root
+}
+
+// RootAt returns the root hash t had when it held size leaves.
+func (t *Tree) RootAt(size uint64) ([]byte, error) {
+	if size > t.Size() {
+		return nil, errSizeOutOfRange
+	}
+	peaks := make([][]byte, bits.Len64(size))
+	for i := range peaks {
+		if size>>uint(i)&1 == 1 {
+			peaks[i] = t.levels[i][size>>uint(i)-1]
+		}
+	}
+	return t.h.foldRoot(size, peaks), nil
+}
+
+// InclusionProof returns the audit path proving that the leaf at index is
+// included in the tree of the given size. It can be checked with
+// VerifyInclusion.
+func (t *Tree) InclusionProof(index, size uint64) ([][]byte, error) {
+	if size > t.Size() {
+		return nil, errSizeOutOfRange
+	}
+	if index >= size {
+		return nil, errIndexOutOfRange
+	}
+	if t.h.mode == Bitcoin {
+		return t.duplicatePath(index, size), nil
+	}
+	return t.path(index, 0, size, nil), nil
+}
+
+// ConsistencyProof returns the proof that the tree of size2 leaves is an
+// extension of the tree of size1 leaves, as defined in RFC 9162, section
+// 2.1.4. It can be checked with VerifyConsistency.
+func (t *Tree) ConsistencyProof(size1, size2 uint64) ([][]byte, error) {
+	if t.h.mode != RFC6962 {
+		return nil, errUnsupported
+	}
+	if size2 > t.Size() || size1 > size2 {
+		return nil, errSizeOutOfRange
+	}
+	if size1 == 0 || size1 == size2 {
+		return [][]byte{}, nil
+	}
+	return t.subproof(size1, 0, size2, true, nil), nil
+}
+
+// path appends to proof the RFC 9162 PATH of leaf m in the subtree holding
+// leaves [lo, hi).
+func (t *Tree) path(m, lo, hi uint64, proof [][]byte) [][]byte {
+	if hi-lo <= 1 {
+		return proof
+	}
+	k := splitPoint(hi - lo)
+	if m < lo+k {
+		proof = t.path(m, lo, lo+k, proof)
+		return append(proof, t.subtreeHash(lo+k, hi))
+	}
+	proof = t.path(m, lo+k, hi, proof)
+	return append(proof, t.subtreeHash(lo, lo+k))
+}
+
+// subproof appends to proof the RFC 9162 SUBPROOF of the first m leaves of
+// the subtree holding leaves [lo, hi).
+func (t *Tree) subproof(m, lo, hi uint64, complete bool, proof [][]byte) [][]byte {
+	if m == hi-lo {
+		if complete {
+			return proof
+		}
+		return append(proof, t.subtreeHash(lo, hi))
+	}
+	k := splitPoint(hi - lo)
+	if m <= k {
+		proof = t.subproof(m, lo, lo+k, complete, proof)
+		return append(proof, t.subtreeHash(lo+k, hi))
+	}
+	proof = t.subproof(m-k, lo+k, hi, false, proof)
+	return append(proof, t.subtreeHash(lo, lo+k))
+}
+
+// subtreeHash returns the RFC 9162 hash of the leaves [lo, hi).
+func (t *Tree) subtreeHash(lo, hi uint64) []byte {
+	n := hi - lo
+	if n&(n-1) == 0 && lo&(n-1) == 0 {
+		k := uint(bits.TrailingZeros64(n))
+		return t.levels[k][lo>>k]
+	}
+	k := splitPoint(n)
+	return t.h.HashChildren(t.subtreeHash(lo, lo+k), t.subtreeHash(lo+k, hi))
+}
+
+// duplicatePath returns the Bitcoin-style audit path of the leaf at index in
+// the tree of the given size.
+func (t *Tree) duplicatePath(index, size uint64) [][]byte {
+	var proof [][]byte
+	for k := uint(0); (size-1)>>k != 0; k++ {
+		sibling := index>>k ^ 1
+		if sibling<<k >= size {
+			sibling = index >> k
+		}
+		proof = append(proof, t.duplicateNode(k, sibling, size))
+	}
+	return proof
+}
+
+// duplicateNode returns the Bitcoin-style hash of node i at level k of the
+// tree of the given size. The node must hold at least one leaf.
+func (t *Tree) duplicateNode(k uint, i, size uint64) []byte {
+	if (i+1)<<k <= size {
+		return t.levels[k][i]
+	}
+	left := t.duplicateNode(k-1, 2*i, size)
+	if (2*i+1)<<(k-1) >= size {
+		return t.h.HashChildren(left, left)
+	}
+	return t.h.HashChildren(left, t.duplicateNode(k-1, 2*i+1, size))
+}
+
+// splitPoint returns the largest power of two smaller than n, which must be
+// greater than one.
+func splitPoint(n uint64) uint64 {
+	return 1 << uint(bits.Len64(n-1)-1)
+}
@@ -412,6 +412,9 @@ const (
 	// RFC 6637, Section 5.
 	PubKeyAlgoECDH  PublicKeyAlgorithm = 18
 	PubKeyAlgoECDSA PublicKeyAlgorithm = 19
+	// RFC 9580, Section 9.1. Keys and signatures are in the native
+	// formats of RFC 8032 rather than MPIs.
+	PubKeyAlgoEd448 PublicKeyAlgorithm = 28
 )
 
 // CanEncrypt returns true if it's possible to encrypt a message to a public
@@ -428,7 +431,7 @@ func (pka PublicKeyAlgorithm) CanEncrypt() bool {
 // sign a message.
 func (pka PublicKeyAlgorithm) CanSign() bool {
 	switch pka {
-	case PubKeyAlgoRSA, PubKeyAlgoRSASignOnly, PubKeyAlgoDSA, PubKeyAlgoECDSA:
+	case PubKeyAlgoRSA, PubKeyAlgoRSASignOnly, PubKeyAlgoDSA, PubKeyAlgoECDSA, PubKeyAlgoEd448:
 		return true
 	}
 	return false
@@ -20,6 +20,7 @@ import (
 	"github.com/benchlab/bench-crypto/rsa"
 	"github.com/benchlab/bench-crypto/sha1"
 
+	"golang.org/x/github.com/benchlab/bench-crypto/ed448"
 	"golang.org/x/github.com/benchlab/bench-crypto/openpgp/elgamal"
 	"golang.org/x/github.com/benchlab/bench-crypto/openpgp/errors"
 	"golang.org/x/github.com/benchlab/bench-crypto/openpgp/s2k"
@@ -33,7 +34,7 @@ type PrivateKey struct {
 	encryptedData []byte
 	cipher        CipherFunction
 	s2k           func(out, in []byte)
-	PrivateKey    interface{} // An *{rsa|dsa|ecdsa}.PrivateKey or a crypto.Signer.
+	PrivateKey    interface{} // An *{rsa|dsa|ecdsa}.PrivateKey, an ed448.PrivateKey or a crypto.Signer.
 	sha1Checksum  bool
 	iv            []byte
 }
@@ -66,8 +67,15 @@ func NewECDSAPrivateKey(currentTime time.Time, priv *ecdsa.PrivateKey) *PrivateK
 	return pk
 }
 
+func NewEd448PrivateKey(currentTime time.Time, priv ed448.PrivateKey) *PrivateKey {
+	pk := new(PrivateKey)
+	pk.PublicKey = *NewEd448PublicKey(currentTime, priv.Public().(ed448.PublicKey))
+	pk.PrivateKey = priv
+	return pk
+}
+
 // NewSignerPrivateKey creates a sign-only PrivateKey from a crypto.Signer that
-// implements RSA or ECDSA.
+// implements RSA, ECDSA or Ed448.
 func NewSignerPrivateKey(currentTime time.Time, signer crypto.Signer) *PrivateKey {
 	pk := new(PrivateKey)
 	switch pubkey := signer.Public().(type) {
@@ -76,6 +84,8 @@ func NewSignerPrivateKey(currentTime time.Time, signer crypto.Signer) *PrivateKe
 		pk.PubKeyAlgo = PubKeyAlgoRSASignOnly
 	case ecdsa.PublicKey:
 		pk.PublicKey = *NewECDSAPublicKey(currentTime, &pubkey)
+	case ed448.PublicKey:
+		pk.PublicKey = *NewEd448PublicKey(currentTime, pubkey)
 	default:
 		panic("openpgp: unknown crypto.Signer type in NewSignerPrivateKey")
 	}
@@ -170,6 +180,8 @@ func (pk *PrivateKey) Serialize(w io.Writer) (err error) {
 		err = serializeElGamalPrivateKey(privateKeyBuf, priv)
 	case *ecdsa.PrivateKey:
 		err = serializeECDSAPrivateKey(privateKeyBuf, priv)
+	case ed448.PrivateKey:
+		_, err = privateKeyBuf.Write(priv.Seed())
 	default:
 		err = errors.InvalidArgumentError("unknown private key type")
 	}
@@ -286,6 +298,8 @@ func (pk *PrivateKey) parsePrivateKey(data []byte) (err error) {
 		return pk.parseElGamalPrivateKey(data)
 	case PubKeyAlgoECDSA:
 		return pk.parseECDSAPrivateKey(data)
+	case PubKeyAlgoEd448:
+		return pk.parseEd448PrivateKey(data)
 	}
 	panic("impossible")
 }
@@ -380,3 +394,19 @@ func (pk *PrivateKey) parseECDSAPrivateKey(data []byte) (err error) {
 
 	return nil
 }
+
+func (pk *PrivateKey) parseEd448PrivateKey(data []byte) (err error) {
+	if len(data) < ed448.SeedSize {
+		return errors.StructuralError("truncated Ed448 private key")
+	}
+	priv := ed448.NewKeyFromSeed(data[:ed448.SeedSize])
+	if !bytes.Equal(priv.Public().(ed448.PublicKey), pk.PublicKey.PublicKey.(ed448.PublicKey)) {
+		return errors.StructuralError("Ed448 private key does not match public key")
+	}
+
+	pk.PrivateKey = priv
+	pk.Encrypted = false
+	pk.encryptedData = nil
+
+	return nil
+}
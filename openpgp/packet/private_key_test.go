@@ -19,6 +19,8 @@ import (
 	"github.com/benchlab/bench-crypto/rand"
 	"github.com/benchlab/bench-crypto/rsa"
 	"github.com/benchlab/bench-crypto/x509"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/ed448"
 )
 
 var privateKeyTests = []struct {
@@ -163,6 +165,77 @@ func TestECDSAPrivateKey(t *testing.T) {
 	}
 }
 
+func TestEd448PrivateKey(t *testing.T) {
+	_, edPriv, err := ed448.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	want := NewEd448PrivateKey(time.Now(), edPriv)
+	if err := want.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priv, ok := p.(*PrivateKey)
+	if !ok {
+		t.Fatal("didn't parse private key")
+	}
+	if priv.PubKeyAlgo != PubKeyAlgoEd448 || priv.Fingerprint != want.Fingerprint {
+		t.Fatalf("got algorithm %d and fingerprint %x, want %d and %x", priv.PubKeyAlgo, priv.Fingerprint, PubKeyAlgoEd448, want.Fingerprint)
+	}
+
+	sig := &Signature{
+		PubKeyAlgo: PubKeyAlgoEd448,
+		Hash:       crypto.SHA512,
+	}
+	msg := []byte("Hello World!")
+
+	h, err := populateHash(sig.Hash, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sig.Sign(h, priv, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	if err := sig.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if p, err = Read(&buf); err != nil {
+		t.Fatal(err)
+	}
+	sig, ok = p.(*Signature)
+	if !ok {
+		t.Fatal("didn't parse signature")
+	}
+
+	if h, err = populateHash(sig.Hash, msg); err != nil {
+		t.Fatal(err)
+	}
+	if err := priv.VerifySignature(h, sig); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ed448 signatures must be made over a digest of at least 512 bits.
+	weak := &Signature{
+		PubKeyAlgo: PubKeyAlgoEd448,
+		Hash:       crypto.SHA256,
+	}
+	if h, err = populateHash(weak.Hash, msg); err != nil {
+		t.Fatal(err)
+	}
+	if err := weak.Sign(h, priv, nil); err == nil {
+		t.Error("Ed448 signature over SHA-256 was accepted")
+	}
+}
+
 type rsaSigner struct {
 	priv *rsa.PrivateKey
 }
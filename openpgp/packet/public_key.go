@@ -22,6 +22,7 @@ import (
 	"strconv"
 	"time"
 
+	"golang.org/x/github.com/benchlab/bench-crypto/ed448"
 	"golang.org/x/github.com/benchlab/bench-crypto/openpgp/elgamal"
 	"golang.org/x/github.com/benchlab/bench-crypto/openpgp/errors"
 )
@@ -37,6 +38,10 @@ var (
 
 const maxOIDLength = 8
 
+// ed448MinHashSize is the smallest digest, in bytes, that RFC 9580 allows
+// an Ed448 signature to be made over.
+const ed448MinHashSize = 64
+
 // ecdsaKey stores the algorithm-specific fields for ECDSA keys.
 // as defined in RFC 6637, Section 9.
 type ecdsaKey struct {
@@ -153,7 +158,7 @@ func (f *ecdhKdf) byteLen() int {
 type PublicKey struct {
 	CreationTime time.Time
 	PubKeyAlgo   PublicKeyAlgorithm
-	PublicKey    interface{} // *rsa.PublicKey, *dsa.PublicKey or *ecdsa.PublicKey
+	PublicKey    interface{} // *rsa.PublicKey, *dsa.PublicKey, *ecdsa.PublicKey or ed448.PublicKey
 	Fingerprint  [20]byte
 	KeyId        uint64
 	IsSubkey     bool
@@ -255,6 +260,18 @@ func NewECDSAPublicKey(creationTime time.Time, pub *ecdsa.PublicKey) *PublicKey
 	return pk
 }
 
+// NewEd448PublicKey returns a PublicKey that wraps the given ed448.PublicKey.
+func NewEd448PublicKey(creationTime time.Time, pub ed448.PublicKey) *PublicKey {
+	pk := &PublicKey{
+		CreationTime: creationTime,
+		PubKeyAlgo:   PubKeyAlgoEd448,
+		PublicKey:    pub,
+	}
+
+	pk.setFingerPrintAndKeyId()
+	return pk
+}
+
 func (pk *PublicKey) parse(r io.Reader) (err error) {
 	// RFC 4880, section 5.5.2
 	var buf [6]byte
@@ -291,6 +308,8 @@ func (pk *PublicKey) parse(r io.Reader) (err error) {
 		}
 		// The ECDH key is stored in an ecdsa.PublicKey for convenience.
 		pk.PublicKey, err = pk.ec.newECDSA()
+	case PubKeyAlgoEd448:
+		err = pk.parseEd448(r)
 	default:
 		err = errors.UnsupportedError("public key type: " + strconv.Itoa(int(pk.PubKeyAlgo)))
 	}
@@ -368,6 +387,17 @@ func (pk *PublicKey) parseDSA(r io.Reader) (err error) {
 	return
 }
 
+// parseEd448 parses Ed448 public key material from the given Reader. See RFC
+// 9580, section 5.5.5.10.
+func (pk *PublicKey) parseEd448(r io.Reader) (err error) {
+	pub := make(ed448.PublicKey, ed448.PublicKeySize)
+	if _, err = readFull(r, pub); err != nil {
+		return
+	}
+	pk.PublicKey = pub
+	return
+}
+
 // parseElGamal parses ElGamal public key material from the given Reader. See
 // RFC 4880, section 5.5.2.
 func (pk *PublicKey) parseElGamal(r io.Reader) (err error) {
@@ -415,6 +445,8 @@ func (pk *PublicKey) SerializeSignaturePrefix(h io.Writer) {
 	case PubKeyAlgoECDH:
 		pLength += uint16(pk.ec.byteLen())
 		pLength += uint16(pk.ecdh.byteLen())
+	case PubKeyAlgoEd448:
+		pLength += ed448.PublicKeySize
 	default:
 		panic("unknown public key algorithm")
 	}
@@ -444,6 +476,8 @@ func (pk *PublicKey) Serialize(w io.Writer) (err error) {
 	case PubKeyAlgoECDH:
 		length += pk.ec.byteLen()
 		length += pk.ecdh.byteLen()
+	case PubKeyAlgoEd448:
+		length += ed448.PublicKeySize
 	default:
 		panic("unknown public key algorithm")
 	}
@@ -490,6 +524,9 @@ func (pk *PublicKey) serializeWithoutHeaders(w io.Writer) (err error) {
 			return
 		}
 		return pk.ecdh.serialize(w)
+	case PubKeyAlgoEd448:
+		_, err = w.Write(pk.PublicKey.(ed448.PublicKey))
+		return
 	}
 	return errors.InvalidArgumentError("bad public-key algorithm")
 }
@@ -542,6 +579,14 @@ func (pk *PublicKey) VerifySignature(signed hash.Hash, sig *Signature) (err erro
 			return errors.SignatureError("ECDSA verification failure")
 		}
 		return nil
+	case PubKeyAlgoEd448:
+		if len(hashBytes) < ed448MinHashSize {
+			return errors.SignatureError("hash function too weak for Ed448")
+		}
+		if !ed448.Verify(pk.PublicKey.(ed448.PublicKey), hashBytes, sig.Ed448Signature) {
+			return errors.SignatureError("Ed448 verification failure")
+		}
+		return nil
 	default:
 		return errors.SignatureError("Unsupported public key algorithm used in signature")
 	}
@@ -746,6 +791,8 @@ func (pk *PublicKey) BitLength() (bitLength uint16, err error) {
 		bitLength = pk.p.bitLength
 	case PubKeyAlgoElGamal:
 		bitLength = pk.p.bitLength
+	case PubKeyAlgoEd448:
+		bitLength = 448
 	default:
 		err = errors.InvalidArgumentError("bad public-key algorithm")
 	}
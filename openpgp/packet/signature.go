@@ -19,6 +19,7 @@ import (
 	"github.com/benchlab/bench-crypto/dsa"
 	"github.com/benchlab/bench-crypto/ecdsa"
 
+	"golang.org/x/github.com/benchlab/bench-crypto/ed448"
 	"golang.org/x/github.com/benchlab/bench-crypto/openpgp/errors"
 	"golang.org/x/github.com/benchlab/bench-crypto/openpgp/s2k"
 )
@@ -47,6 +48,7 @@ type Signature struct {
 	RSASignature         parsedMPI
 	DSASigR, DSASigS     parsedMPI
 	ECDSASigR, ECDSASigS parsedMPI
+	Ed448Signature       []byte
 
 	// rawSubpackets contains the unparsed subpackets, in order.
 	rawSubpackets []outputSubpacket
@@ -100,7 +102,7 @@ func (sig *Signature) parse(r io.Reader) (err error) {
 	sig.SigType = SignatureType(buf[0])
 	sig.PubKeyAlgo = PublicKeyAlgorithm(buf[1])
 	switch sig.PubKeyAlgo {
-	case PubKeyAlgoRSA, PubKeyAlgoRSASignOnly, PubKeyAlgoDSA, PubKeyAlgoECDSA:
+	case PubKeyAlgoRSA, PubKeyAlgoRSASignOnly, PubKeyAlgoDSA, PubKeyAlgoECDSA, PubKeyAlgoEd448:
 	default:
 		err = errors.UnsupportedError("public key algorithm " + strconv.Itoa(int(sig.PubKeyAlgo)))
 		return
@@ -169,6 +171,9 @@ func (sig *Signature) parse(r io.Reader) (err error) {
 		if err == nil {
 			sig.ECDSASigS.bytes, sig.ECDSASigS.bitLength, err = readMPI(r)
 		}
+	case PubKeyAlgoEd448:
+		sig.Ed448Signature = make([]byte, ed448.SignatureSize)
+		_, err = readFull(r, sig.Ed448Signature)
 	default:
 		panic("unreachable")
 	}
@@ -553,6 +558,11 @@ func (sig *Signature) Sign(h hash.Hash, priv *PrivateKey, config *Config) (err e
 			sig.ECDSASigR = fromBig(r)
 			sig.ECDSASigS = fromBig(s)
 		}
+	case PubKeyAlgoEd448:
+		if len(digest) < ed448MinHashSize {
+			return errors.InvalidArgumentError("hash function too weak for Ed448")
+		}
+		sig.Ed448Signature, err = priv.PrivateKey.(crypto.Signer).Sign(config.Random(), digest, crypto.Hash(0))
 	default:
 		err = errors.UnsupportedError("public key algorithm: " + strconv.Itoa(int(sig.PubKeyAlgo)))
 	}
@@ -602,7 +612,7 @@ func (sig *Signature) Serialize(w io.Writer) (err error) {
 	if len(sig.outSubpackets) == 0 {
 		sig.outSubpackets = sig.rawSubpackets
 	}
-	if sig.RSASignature.bytes == nil && sig.DSASigR.bytes == nil && sig.ECDSASigR.bytes == nil {
+	if sig.RSASignature.bytes == nil && sig.DSASigR.bytes == nil && sig.ECDSASigR.bytes == nil && sig.Ed448Signature == nil {
 		return errors.InvalidArgumentError("Signature: need to call Sign, SignUserId or SignKey before Serialize")
 	}
 
@@ -616,6 +626,8 @@ func (sig *Signature) Serialize(w io.Writer) (err error) {
 	case PubKeyAlgoECDSA:
 		sigLength = 2 + len(sig.ECDSASigR.bytes)
 		sigLength += 2 + len(sig.ECDSASigS.bytes)
+	case PubKeyAlgoEd448:
+		sigLength = len(sig.Ed448Signature)
 	default:
 		panic("impossible")
 	}
@@ -655,6 +667,8 @@ func (sig *Signature) Serialize(w io.Writer) (err error) {
 		err = writeMPIs(w, sig.DSASigR, sig.DSASigS)
 	case PubKeyAlgoECDSA:
 		err = writeMPIs(w, sig.ECDSASigR, sig.ECDSASigS)
+	case PubKeyAlgoEd448:
+		_, err = w.Write(sig.Ed448Signature)
 	default:
 		panic("impossible")
 	}
@@ -0,0 +1,35 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package passwordhash_test
+
+import (
+	"log"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/passwordhash"
+)
+
+// loadHash and storeHash stand for the user database.
+func loadHash(user string) []byte        { return nil }
+func storeHash(user string, hash []byte) {}
+
+func Example() {
+	login := func(user string, password []byte) bool {
+		hashed := loadHash(user)
+		if err := passwordhash.Verify(hashed, password); err != nil {
+			return false
+		}
+		// Migrate the user to the current algorithm and parameters.
+		if passwordhash.NeedsRehash(hashed) {
+			newHash, err := passwordhash.Hash(password)
+			if err != nil {
+				log.Print(err)
+			} else {
+				storeHash(user, newHash)
+			}
+		}
+		return true
+	}
+	_ = login
+}
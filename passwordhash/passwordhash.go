@@ -0,0 +1,331 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package passwordhash hashes and verifies passwords with any of the
+// password hashing functions of this repository, so that a user database
+// can hold hashes of several algorithms and migrate them as users log in.
+//
+// Stored hashes are recognized by their prefix:
+//
+//	$argon2id$, $argon2i$, $argon2d$  Argon2, see argon2.GenerateFromPassword
+//	$scrypt$                          scrypt, see scrypt.GenerateFromPassword
+//	$2a$, $2b$, ..., $bcrypt-sha384$  bcrypt, see bcrypt.GenerateFromPassword
+//	$pbkdf2-sha256$                   PBKDF2-HMAC-SHA-256, in the format of passlib
+//
+// Hashes of peppered passwords are additionally prefixed with the ID of the
+// pepper, as in "$pepper=2024$argon2id$v=19$...".
+//
+// A Policy decides how new passwords are hashed. After a successful Verify,
+// NeedsRehash reports whether the stored hash should be replaced by a new
+// one, made with the current policy from the password the user just entered.
+package passwordhash // import "golang.org/x/github.com/benchlab/bench-crypto/passwordhash"
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/benchlab/bench-crypto/hmac"
+	"github.com/benchlab/bench-crypto/sha256"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/argon2"
+	"golang.org/x/github.com/benchlab/bench-crypto/bcrypt"
+	"golang.org/x/github.com/benchlab/bench-crypto/scrypt"
+)
+
+// Algorithm identifies a password hashing function.
+type Algorithm int
+
+const (
+	Argon2id Algorithm = 1 + iota
+	Scrypt
+	Bcrypt
+	PBKDF2SHA256
+
+	// argon2Legacy identifies Argon2i and Argon2d hashes, which can be
+	// verified but not created.
+	argon2Legacy
+)
+
+func (a Algorithm) String() string {
+	switch a {
+	case Argon2id:
+		return "argon2id"
+	case Scrypt:
+		return "scrypt"
+	case Bcrypt:
+		return "bcrypt"
+	case PBKDF2SHA256:
+		return "pbkdf2-sha256"
+	}
+	return "unknown algorithm " + strconv.Itoa(int(a))
+}
+
+// The error returned from Verify when a password and hash do not match.
+var ErrMismatchedHashAndPassword = errors.New("passwordhash: hashedPassword is not the hash of the given password")
+
+// The error returned when a hash is not in the format of any supported
+// algorithm.
+var ErrUnknownAlgorithm = errors.New("passwordhash: hashedPassword was not created by a supported algorithm")
+
+// The error returned from Verify when a hash was made with a pepper which is
+// not in the policy.
+var ErrUnknownPepper = errors.New("passwordhash: hashedPassword was made with an unknown pepper")
+
+const pepperPrefix = "$pepper="
+
+// Policy describes how new password hashes are created, and which stored
+// hashes are outdated. Zero fields take the default value of the
+// corresponding package.
+type Policy struct {
+	// Algorithm hashes new passwords. It defaults to Argon2id.
+	Algorithm Algorithm
+
+	// Argon2 and Scrypt are the parameters of new hashes of these
+	// algorithms, and default to argon2.DefaultParams and
+	// scrypt.DefaultParams.
+	Argon2 *argon2.Params
+	Scrypt *scrypt.Params
+	// BcryptCost defaults to bcrypt.DefaultCost.
+	BcryptCost int
+	// PBKDF2Iterations defaults to DefaultPBKDF2Iterations.
+	PBKDF2Iterations int
+
+	// Peppers are optional secret keys, stored apart from the user
+	// database, by ID. Passwords are replaced with their HMAC-SHA-256 under
+	// a pepper before being hashed, so that a leaked database cannot be
+	// attacked without the pepper. The ID of the pepper is recorded in the
+	// hash, so that Verify can find it. IDs are at most 32 letters, digits,
+	// '-', '_' and '.'.
+	//
+	// PepperID selects the pepper of new hashes, which must be in Peppers.
+	// If it is empty, new hashes are not peppered. To rotate the pepper, add
+	// a new one and select it, and keep the old one until NeedsRehash has
+	// replaced all of its hashes.
+	PepperID string
+	Peppers  map[string][]byte
+}
+
+// DefaultPolicy is the policy used by Hash, Verify and NeedsRehash.
+var DefaultPolicy = &Policy{}
+
+// Hash returns the hash of the password under DefaultPolicy.
+func Hash(password []byte) ([]byte, error) { return DefaultPolicy.Hash(password) }
+
+// Verify compares a hashed password with its possible plaintext equivalent
+// under DefaultPolicy. Returns nil on success, or an error on failure.
+func Verify(hashedPassword, password []byte) error {
+	return DefaultPolicy.Verify(hashedPassword, password)
+}
+
+// NeedsRehash reports whether a hashed password should be replaced under
+// DefaultPolicy.
+func NeedsRehash(hashedPassword []byte) bool { return DefaultPolicy.NeedsRehash(hashedPassword) }
+
+// Hash returns the hash of the password with the algorithm and parameters of
+// the policy, in the encoding of that algorithm.
+func (p *Policy) Hash(password []byte) ([]byte, error) {
+	if p.PepperID != "" {
+		key, ok := p.Peppers[p.PepperID]
+		if !ok || !validPepperID(p.PepperID) {
+			return nil, errors.New("passwordhash: invalid pepper ID " + strconv.Quote(p.PepperID))
+		}
+		password = pepper(key, password)
+	}
+	var hashed []byte
+	var err error
+	switch p.algorithm() {
+	case Argon2id:
+		hashed, err = argon2.GenerateFromPassword(password, p.argon2Params())
+	case Scrypt:
+		hashed, err = scrypt.GenerateFromPassword(password, p.scryptParams())
+	case Bcrypt:
+		hashed, err = bcrypt.GenerateFromPassword(password, p.bcryptCost())
+	case PBKDF2SHA256:
+		hashed, err = generatePBKDF2(password, p.pbkdf2Iterations())
+	default:
+		return nil, errors.New("passwordhash: unsupported algorithm " + p.Algorithm.String())
+	}
+	if err != nil || p.PepperID == "" {
+		return hashed, err
+	}
+	return append([]byte(pepperPrefix+p.PepperID), hashed...), nil
+}
+
+// Verify compares a hashed password of any supported algorithm with its
+// possible plaintext equivalent. Returns nil on success,
+// ErrMismatchedHashAndPassword if the password is wrong, or another error if
+// the hash cannot be verified. Hashes without a pepper ID are verified
+// without a pepper.
+func (p *Policy) Verify(hashedPassword, password []byte) error {
+	id, hashedPassword, ok := cutPepperID(hashedPassword)
+	if !ok {
+		return ErrUnknownAlgorithm
+	}
+	if id != "" {
+		key, ok := p.Peppers[id]
+		if !ok {
+			return ErrUnknownPepper
+		}
+		password = pepper(key, password)
+	}
+	var err error
+	switch identify(hashedPassword) {
+	case Argon2id, argon2Legacy:
+		if err = argon2.CompareHashAndPassword(hashedPassword, password); err == argon2.ErrMismatchedHashAndPassword {
+			err = ErrMismatchedHashAndPassword
+		}
+	case Scrypt:
+		if err = scrypt.CompareHashAndPassword(hashedPassword, password); err == scrypt.ErrMismatchedHashAndPassword {
+			err = ErrMismatchedHashAndPassword
+		}
+	case Bcrypt:
+		if err = bcrypt.CompareHashAndPassword(hashedPassword, password); err == bcrypt.ErrMismatchedHashAndPassword {
+			err = ErrMismatchedHashAndPassword
+		}
+	case PBKDF2SHA256:
+		err = comparePBKDF2(hashedPassword, password)
+	default:
+		err = ErrUnknownAlgorithm
+	}
+	return err
+}
+
+// NeedsRehash reports whether a hashed password should be replaced, because
+// it was made with another algorithm than the one of the policy, with weaker
+// parameters, or with another pepper, or none. Hashes which cannot be parsed
+// always need a rehash.
+func (p *Policy) NeedsRehash(hashedPassword []byte) bool {
+	id, hashedPassword, ok := cutPepperID(hashedPassword)
+	if !ok || id != p.PepperID {
+		return true
+	}
+	alg := identify(hashedPassword)
+	if alg != p.algorithm() {
+		return true
+	}
+	switch alg {
+	case Argon2id:
+		cost, err := argon2.Cost(hashedPassword)
+		if err != nil {
+			return true
+		}
+		want := p.argon2Params()
+		return cost.Time < want.Time || cost.Memory < want.Memory || cost.Threads < want.Threads ||
+			cost.SaltLen < want.SaltLen || cost.KeyLen < want.KeyLen
+	case Scrypt:
+		cost, err := scrypt.Cost(hashedPassword)
+		if err != nil {
+			return true
+		}
+		want := p.scryptParams()
+		return cost.N < want.N || cost.R < want.R || cost.P < want.P ||
+			cost.SaltLen < want.SaltLen || cost.KeyLen < want.KeyLen
+	case Bcrypt:
+		cost, err := bcrypt.Cost(hashedPassword)
+		return err != nil || cost < p.bcryptCost()
+	case PBKDF2SHA256:
+		h, err := decodePBKDF2(hashedPassword)
+		return err != nil || h.iterations < p.pbkdf2Iterations() ||
+			len(h.salt) < pbkdf2SaltLen || len(h.hash) < sha256.Size
+	}
+	return true
+}
+
+// identify returns the algorithm of a hashed password from its prefix.
+func identify(hashedPassword []byte) Algorithm {
+	switch {
+	case bytes.HasPrefix(hashedPassword, []byte("$argon2id$")):
+		return Argon2id
+	case bytes.HasPrefix(hashedPassword, []byte("$argon2i$")),
+		bytes.HasPrefix(hashedPassword, []byte("$argon2d$")):
+		return argon2Legacy
+	case bytes.HasPrefix(hashedPassword, []byte("$scrypt$")):
+		return Scrypt
+	case bytes.HasPrefix(hashedPassword, []byte("$pbkdf2-sha256$")):
+		return PBKDF2SHA256
+	case len(hashedPassword) > 3 && hashedPassword[0] == '$' && hashedPassword[1] == '2',
+		bytes.HasPrefix(hashedPassword, []byte("$bcrypt-sha384$")):
+		return Bcrypt
+	}
+	return 0
+}
+
+// cutPepperID splits a hashed password into the ID of its pepper, which is
+// empty if it has none, and the hash of the algorithm. It reports false if
+// the pepper ID is malformed.
+func cutPepperID(hashedPassword []byte) (id string, hash []byte, ok bool) {
+	if !bytes.HasPrefix(hashedPassword, []byte(pepperPrefix)) {
+		return "", hashedPassword, true
+	}
+	rest := hashedPassword[len(pepperPrefix):]
+	i := bytes.IndexByte(rest, '$')
+	if i < 0 || !validPepperID(string(rest[:i])) {
+		return "", nil, false
+	}
+	return string(rest[:i]), rest[i:], true
+}
+
+func validPepperID(id string) bool {
+	if len(id) == 0 || len(id) > 32 {
+		return false
+	}
+	for _, c := range []byte(id) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// pepper returns the password to hash with the pepper key, which is the
+// base64 encoding of the HMAC-SHA-256 of the password. The encoding keeps
+// the result free of NUL bytes and shorter than bcrypt's limit of 72 bytes.
+func pepper(key, password []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(password)
+	sum := mac.Sum(nil)
+	peppered := make([]byte, base64.StdEncoding.EncodedLen(len(sum)))
+	base64.StdEncoding.Encode(peppered, sum)
+	return peppered
+}
+
+func (p *Policy) algorithm() Algorithm {
+	if p.Algorithm == 0 {
+		return Argon2id
+	}
+	return p.Algorithm
+}
+
+func (p *Policy) argon2Params() *argon2.Params {
+	if p.Argon2 == nil {
+		return argon2.DefaultParams
+	}
+	return p.Argon2
+}
+
+func (p *Policy) scryptParams() *scrypt.Params {
+	if p.Scrypt == nil {
+		return scrypt.DefaultParams
+	}
+	return p.Scrypt
+}
+
+func (p *Policy) bcryptCost() int {
+	if p.BcryptCost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return p.BcryptCost
+}
+
+func (p *Policy) pbkdf2Iterations() int {
+	if p.PBKDF2Iterations == 0 {
+		return DefaultPBKDF2Iterations
+	}
+	return p.PBKDF2Iterations
+}
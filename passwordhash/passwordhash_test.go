@@ -0,0 +1,196 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package passwordhash
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/argon2"
+	"golang.org/x/github.com/benchlab/bench-crypto/bcrypt"
+	"golang.org/x/github.com/benchlab/bench-crypto/scrypt"
+)
+
+// testPolicies use cheap parameters to keep the tests fast.
+var testPolicies = []*Policy{
+	{Algorithm: Argon2id, Argon2: &argon2.Params{Time: 1, Memory: 64, Threads: 1, SaltLen: 16, KeyLen: 32}},
+	{Algorithm: Scrypt, Scrypt: &scrypt.Params{N: 1 << 8, R: 8, P: 1, SaltLen: 16, KeyLen: 32}},
+	{Algorithm: Bcrypt, BcryptCost: bcrypt.MinCost},
+	{Algorithm: PBKDF2SHA256, PBKDF2Iterations: 1000},
+}
+
+func TestHashAndVerify(t *testing.T) {
+	for _, p := range testPolicies {
+		hashed, err := p.Hash([]byte("password"))
+		if err != nil {
+			t.Fatalf("%v: Hash: %v", p.Algorithm, err)
+		}
+		if err := p.Verify(hashed, []byte("password")); err != nil {
+			t.Errorf("%v: Verify: %v", p.Algorithm, err)
+		}
+		if err := p.Verify(hashed, []byte("passwore")); err != ErrMismatchedHashAndPassword {
+			t.Errorf("%v: wrong password: got %v, want %v", p.Algorithm, err, ErrMismatchedHashAndPassword)
+		}
+		if p.NeedsRehash(hashed) {
+			t.Errorf("%v: fresh hash needs a rehash", p.Algorithm)
+		}
+
+		// Hashes of every other algorithm can be verified, but need a
+		// rehash.
+		for _, other := range testPolicies {
+			if other == p {
+				continue
+			}
+			if err := other.Verify(hashed, []byte("password")); err != nil {
+				t.Errorf("%v hash under %v policy: Verify: %v", p.Algorithm, other.Algorithm, err)
+			}
+			if !other.NeedsRehash(hashed) {
+				t.Errorf("%v hash under %v policy: no rehash needed", p.Algorithm, other.Algorithm)
+			}
+		}
+	}
+}
+
+func TestVerifyStoredHashes(t *testing.T) {
+	for _, tt := range []struct {
+		hashed, password string
+	}{
+		{"$argon2i$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", "password"},
+		{"$scrypt$ln=14,r=8,p=1$U29kaXVtQ2hsb3JpZGU$cCO9yzr9c0hGHAbNgf046/2o+7qQT44+qbVD9lRdofLVQylVYT8Pz2LUlwUkKpr55h6F3A1lHkDfzwF7RVdYhw", "pleaseletmein"},
+		{"$2a$10$XajjQvNhvvRt5GSeFk1xFeyqRrsxkhBkUiQeg0dt.wU1qD4aFDcga", "allmine"},
+		// From the documentation of passlib.
+		{"$pbkdf2-sha256$6400$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M", "password"},
+	} {
+		if err := Verify([]byte(tt.hashed), []byte(tt.password)); err != nil {
+			t.Errorf("%s: %v", tt.hashed, err)
+		}
+		if !NeedsRehash([]byte(tt.hashed)) {
+			t.Errorf("%s: no rehash needed under the default policy", tt.hashed)
+		}
+	}
+
+	if err := Verify([]byte("$1$saltsalt$qjXMvbEw8oaL.CzflDugX/"), []byte("password")); err != ErrUnknownAlgorithm {
+		t.Errorf("md5crypt hash: got %v, want %v", err, ErrUnknownAlgorithm)
+	}
+}
+
+func TestNeedsRehashParameters(t *testing.T) {
+	for _, p := range testPolicies {
+		hashed, err := p.Hash([]byte("password"))
+		if err != nil {
+			t.Fatalf("%v: Hash: %v", p.Algorithm, err)
+		}
+		stronger := *p
+		switch p.Algorithm {
+		case Argon2id:
+			params := *p.Argon2
+			params.Time++
+			stronger.Argon2 = &params
+		case Scrypt:
+			params := *p.Scrypt
+			params.N *= 2
+			stronger.Scrypt = &params
+		case Bcrypt:
+			stronger.BcryptCost++
+		case PBKDF2SHA256:
+			stronger.PBKDF2Iterations++
+		}
+		if !stronger.NeedsRehash(hashed) {
+			t.Errorf("%v: no rehash needed for stronger parameters", p.Algorithm)
+		}
+	}
+}
+
+func TestPepper(t *testing.T) {
+	peppers := map[string][]byte{"1": []byte("pepper"), "2": []byte("salt")}
+	for _, p := range testPolicies {
+		peppered := *p
+		peppered.PepperID, peppered.Peppers = "1", peppers
+		hashed, err := peppered.Hash([]byte("password"))
+		if err != nil {
+			t.Fatalf("%v: Hash: %v", p.Algorithm, err)
+		}
+		if !bytes.HasPrefix(hashed, []byte("$pepper=1$")) {
+			t.Errorf("%v: hash %q does not record the pepper", p.Algorithm, hashed)
+		}
+		if err := peppered.Verify(hashed, []byte("password")); err != nil {
+			t.Errorf("%v: Verify: %v", p.Algorithm, err)
+		}
+		if err := peppered.Verify(hashed, []byte("passwore")); err != ErrMismatchedHashAndPassword {
+			t.Errorf("%v: wrong password: got %v, want %v", p.Algorithm, err, ErrMismatchedHashAndPassword)
+		}
+		if peppered.NeedsRehash(hashed) {
+			t.Errorf("%v: fresh peppered hash needs a rehash", p.Algorithm)
+		}
+		if err := p.Verify(hashed, []byte("password")); err != ErrUnknownPepper {
+			t.Errorf("%v: verified without the pepper: got %v, want %v", p.Algorithm, err, ErrUnknownPepper)
+		}
+		if !p.NeedsRehash(hashed) {
+			t.Errorf("%v: peppered hash under unpeppered policy: no rehash needed", p.Algorithm)
+		}
+		wrong := peppered
+		wrong.Peppers = map[string][]byte{"1": []byte("salt")}
+		if err := wrong.Verify(hashed, []byte("password")); err != ErrMismatchedHashAndPassword {
+			t.Errorf("%v: verified with the wrong pepper: got %v", p.Algorithm, err)
+		}
+
+		// After a rotation, hashes of the old pepper and unpeppered
+		// hashes are still verified, but need a rehash.
+		rotated := peppered
+		rotated.PepperID = "2"
+		if err := rotated.Verify(hashed, []byte("password")); err != nil {
+			t.Errorf("%v: old pepper: Verify: %v", p.Algorithm, err)
+		}
+		if !rotated.NeedsRehash(hashed) {
+			t.Errorf("%v: old pepper: no rehash needed", p.Algorithm)
+		}
+		unpeppered, err := p.Hash([]byte("password"))
+		if err != nil {
+			t.Fatalf("%v: Hash: %v", p.Algorithm, err)
+		}
+		if err := rotated.Verify(unpeppered, []byte("password")); err != nil {
+			t.Errorf("%v: unpeppered hash: Verify: %v", p.Algorithm, err)
+		}
+		if !rotated.NeedsRehash(unpeppered) {
+			t.Errorf("%v: unpeppered hash: no rehash needed", p.Algorithm)
+		}
+	}
+
+	for _, id := range []string{"", "3", "a$b", "0123456789abcdef0123456789abcdef0"} {
+		p := &Policy{PepperID: id, Peppers: peppers}
+		if id == "" {
+			p.PepperID = "3"
+		}
+		if _, err := p.Hash([]byte("password")); err == nil {
+			t.Errorf("Hash with pepper ID %q: no error", id)
+		}
+	}
+	if err := Verify([]byte("$pepper=$argon2id$"), []byte("password")); err != ErrUnknownAlgorithm {
+		t.Errorf("empty pepper ID: got %v, want %v", err, ErrUnknownAlgorithm)
+	}
+
+	// The pepper output must not be truncated by bcrypt.
+	if pw := pepper([]byte("pepper"), []byte("password")); len(pw) > 72 || bytes.IndexByte(pw, 0) >= 0 {
+		t.Errorf("peppered password %q is not suitable for bcrypt", pw)
+	}
+}
+
+func TestPBKDF2Limits(t *testing.T) {
+	const salt, hash = "0ZrzXitFSGltTQnBWOsdAw", "Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M"
+	long := strings.Repeat("A", 1<<20)
+	for _, hashed := range []string{
+		"$pbkdf2-sha256$1000000000$" + salt + "$" + hash,
+		// Deriving 1 MiB with the maximum iteration count would take
+		// days, so these must be rejected before any work is done.
+		"$pbkdf2-sha256$10000000$" + salt + "$" + long,
+		"$pbkdf2-sha256$10000000$" + salt + "$" + hash[:len(hash)-1],
+		"$pbkdf2-sha256$10000000$" + long + "$" + hash,
+	} {
+		if err := Verify([]byte(hashed), []byte("password")); err != errInvalidPBKDF2 {
+			t.Errorf("%.60s...: got %v, want %v", hashed, err, errInvalidPBKDF2)
+		}
+	}
+}
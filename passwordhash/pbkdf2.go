@@ -0,0 +1,108 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package passwordhash
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/benchlab/bench-crypto/rand"
+	"github.com/benchlab/bench-crypto/sha256"
+	"github.com/benchlab/bench-crypto/subtle"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/pbkdf2"
+)
+
+// DefaultPBKDF2Iterations is the iteration count of new PBKDF2-HMAC-SHA-256
+// hashes if the policy does not set one.
+const DefaultPBKDF2Iterations = 600000
+
+const (
+	pbkdf2Prefix  = "$pbkdf2-sha256$"
+	pbkdf2SaltLen = 16
+	// maxPBKDF2Iterations bounds the cost of the hashes accepted by Verify,
+	// to about ten seconds of work.
+	maxPBKDF2Iterations = 10000000
+	// maxPBKDF2SaltLen bounds the salt of the hashes accepted by Verify.
+	// The hash itself must be sha256.Size bytes, since the cost of PBKDF2
+	// grows with the length of its output.
+	maxPBKDF2SaltLen = 64
+)
+
+var errInvalidPBKDF2 = errors.New("passwordhash: hashedPassword is not in the encoded PBKDF2 format")
+
+// pbkdf2Encoding is the base64 variant of passlib, which uses '.' instead of
+// '+' and no padding.
+var pbkdf2Encoding = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789./").WithPadding(base64.NoPadding)
+
+type pbkdf2Hash struct {
+	iterations int
+	salt       []byte
+	hash       []byte
+}
+
+// generatePBKDF2 returns a hash in the format of passlib's pbkdf2_sha256,
+// "$pbkdf2-sha256$<iterations>$<salt>$<hash>".
+func generatePBKDF2(password []byte, iterations int) ([]byte, error) {
+	if iterations < 1 || iterations > maxPBKDF2Iterations {
+		return nil, errors.New("passwordhash: invalid PBKDF2 iteration count " + strconv.Itoa(iterations))
+	}
+	h := &pbkdf2Hash{iterations: iterations, salt: make([]byte, pbkdf2SaltLen)}
+	if _, err := io.ReadFull(rand.Reader, h.salt); err != nil {
+		return nil, err
+	}
+	h.hash = pbkdf2.Key(password, h.salt, iterations, sha256.Size, sha256.New)
+	return h.encode(), nil
+}
+
+func comparePBKDF2(hashedPassword, password []byte) error {
+	h, err := decodePBKDF2(hashedPassword)
+	if err != nil {
+		return err
+	}
+	otherHash := pbkdf2.Key(password, h.salt, h.iterations, len(h.hash), sha256.New)
+	if subtle.ConstantTimeCompare(h.hash, otherHash) == 1 {
+		return nil
+	}
+	return ErrMismatchedHashAndPassword
+}
+
+func (h *pbkdf2Hash) encode() []byte {
+	b := []byte(pbkdf2Prefix)
+	b = strconv.AppendInt(b, int64(h.iterations), 10)
+	b = append(b, '$')
+	b = append(b, pbkdf2Encoding.EncodeToString(h.salt)...)
+	b = append(b, '$')
+	b = append(b, pbkdf2Encoding.EncodeToString(h.hash)...)
+	return b
+}
+
+func decodePBKDF2(hashedPassword []byte) (*pbkdf2Hash, error) {
+	if !bytes.HasPrefix(hashedPassword, []byte(pbkdf2Prefix)) {
+		return nil, errInvalidPBKDF2
+	}
+	fields := bytes.Split(hashedPassword[len(pbkdf2Prefix):], []byte("$"))
+	if len(fields) != 3 {
+		return nil, errInvalidPBKDF2
+	}
+	iterations, err := strconv.Atoi(string(fields[0]))
+	if err != nil || iterations < 1 || iterations > maxPBKDF2Iterations {
+		return nil, errInvalidPBKDF2
+	}
+	h := &pbkdf2Hash{iterations: iterations}
+	if len(fields[1]) > pbkdf2Encoding.EncodedLen(maxPBKDF2SaltLen) || len(fields[2]) != pbkdf2Encoding.EncodedLen(sha256.Size) {
+		return nil, errInvalidPBKDF2
+	}
+	if h.salt, err = pbkdf2Encoding.DecodeString(string(fields[1])); err != nil {
+		return nil, errInvalidPBKDF2
+	}
+	if h.hash, err = pbkdf2Encoding.DecodeString(string(fields[2])); err != nil || len(h.hash) != sha256.Size {
+		return nil, errInvalidPBKDF2
+	}
+	return h, nil
+}
@@ -21,6 +21,8 @@ package pbkdf2 // import "golang.org/x/github.com/benchlab/bench-crypto/pbkdf2"
 import (
 	"github.com/benchlab/bench-crypto/hmac"
 	"hash"
+	"runtime"
+	"sync"
 )
 
 // Key derives a key from the password, salt and iteration count, returning a
@@ -44,34 +46,73 @@ func Key(password, salt []byte, iter, keyLen int, h func() hash.Hash) []byte {
 	hashLen := prf.Size()
 	numChunks := (keyLen + hashLen - 1) / hashLen
 
-	var buf [4]byte
-	dk := make([]byte, 0, numChunks*hashLen)
+	dk := make([]byte, numChunks*hashLen)
 	U := make([]byte, hashLen)
 	for chunk := 1; chunk <= numChunks; chunk++ {
-		// N.B.: || means concatenation, ^ means XOR
-		// for each chunk T_i = U_1 ^ U_2 ^ ... ^ U_iter
-		// U_1 = PRF(password, salt || uint(i))
-		prf.Reset()
-		prf.Write(salt)
-		buf[0] = byte(chunk >> 24)
-		buf[1] = byte(chunk >> 16)
-		buf[2] = byte(chunk >> 8)
-		buf[3] = byte(chunk)
-		prf.Write(buf[:4])
-		dk = prf.Sum(dk)
-		T := dk[len(dk)-hashLen:]
-		copy(U, T)
+		deriveChunk(prf, salt, iter, chunk, dk[(chunk-1)*hashLen:chunk*hashLen:chunk*hashLen], U)
+	}
+	return dk[:keyLen]
+}
+
+// ParallelKey is like Key, but computes the hashLen-sized chunks of the
+// derived key concurrently, using up to workers goroutines. If workers is
+// less than 1, runtime.GOMAXPROCS(0) goroutines are used. Only keys longer
+// than the output of the hash function are faster to compute.
+func ParallelKey(password, salt []byte, iter, keyLen int, h func() hash.Hash, workers int) []byte {
+	hashLen := h().Size()
+	numChunks := (keyLen + hashLen - 1) / hashLen
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > numChunks {
+		workers = numChunks
+	}
+	if workers <= 1 {
+		return Key(password, salt, iter, keyLen, h)
+	}
 
-		// U_n = PRF(password, U_(n-1))
-		for n := 2; n <= iter; n++ {
-			prf.Reset()
-			prf.Write(U)
-			U = U[:0]
-			U = prf.Sum(U)
-			for x := range U {
-				T[x] ^= U[x]
+	dk := make([]byte, numChunks*hashLen)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			prf := hmac.New(h, password)
+			U := make([]byte, hashLen)
+			for chunk := 1 + w; chunk <= numChunks; chunk += workers {
+				deriveChunk(prf, salt, iter, chunk, dk[(chunk-1)*hashLen:chunk*hashLen:chunk*hashLen], U)
 			}
-		}
+		}(w)
 	}
+	wg.Wait()
 	return dk[:keyLen]
 }
+
+// deriveChunk computes chunk T_i of the derived key into T, using U as
+// scratch space. Both are hashLen bytes long.
+func deriveChunk(prf hash.Hash, salt []byte, iter, chunk int, T, U []byte) {
+	var buf [4]byte
+	// N.B.: || means concatenation, ^ means XOR
+	// for each chunk T_i = U_1 ^ U_2 ^ ... ^ U_iter
+	// U_1 = PRF(password, salt || uint(i))
+	prf.Reset()
+	prf.Write(salt)
+	buf[0] = byte(chunk >> 24)
+	buf[1] = byte(chunk >> 16)
+	buf[2] = byte(chunk >> 8)
+	buf[3] = byte(chunk)
+	prf.Write(buf[:4])
+	prf.Sum(T[:0])
+	copy(U, T)
+
+	// U_n = PRF(password, U_(n-1))
+	for n := 2; n <= iter; n++ {
+		prf.Reset()
+		prf.Write(U)
+		U = U[:0]
+		U = prf.Sum(U)
+		for x := range U {
+			T[x] ^= U[x]
+		}
+	}
+}
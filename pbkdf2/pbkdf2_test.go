@@ -156,6 +156,23 @@ func TestWithHMACSHA256(t *testing.T) {
 	testHash(t, sha256.New, "SHA256", sha256TestVectors)
 }
 
+func TestParallelKey(t *testing.T) {
+	for _, workers := range []int{0, 1, 2, 3} {
+		for i, v := range sha1TestVectors {
+			o := ParallelKey([]byte(v.password), []byte(v.salt), v.iter, len(v.output), sha1.New, workers)
+			if !bytes.Equal(o, v.output) {
+				t.Errorf("SHA1 %d, %d workers: expected %x, got %x", i, workers, v.output, o)
+			}
+		}
+		// A key of five SHA-256 chunks.
+		password, salt := []byte("password"), []byte("salt")
+		want := Key(password, salt, 100, 5*sha256.Size-3, sha256.New)
+		if o := ParallelKey(password, salt, 100, len(want), sha256.New, workers); !bytes.Equal(o, want) {
+			t.Errorf("SHA256, %d workers: expected %x, got %x", workers, want, o)
+		}
+	}
+}
+
 var sink uint8
 
 func benchmark(b *testing.B, h func() hash.Hash) {
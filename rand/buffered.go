@@ -0,0 +1,102 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// bufferedReseedBudget is the number of bytes a buffered reader generates
+// before it reseeds from its source.
+const bufferedReseedBudget = 1 << 20
+
+// NewBufferedReader returns a generator which is seeded from source, usually
+// the system generator Reader, and produces its output in user space with
+// ChaCha20 and fast key erasure, so that most reads do not need a system
+// call. It reseeds from source after generating 1 MB, and in a child process
+// after the process forks. The returned Reader is safe for concurrent use.
+//
+// To use it for all the randomness of the program, replace Reader during
+// initialization, before it is first used:
+//
+//	rand.Reader = rand.NewBufferedReader(rand.Reader)
+func NewBufferedReader(source io.Reader) io.Reader {
+	return &bufferedReader{source: source, fork: newForkDetector()}
+}
+
+type bufferedReader struct {
+	mu     sync.Mutex
+	source io.Reader
+	fork   forkDetector
+	gen    *ChaCha20
+	budget int // number of bytes that can be generated before a reseed
+}
+
+// A forkDetector reports whether the process forked since its last call.
+type forkDetector interface {
+	forked() bool
+}
+
+func (r *bufferedReader) Read(b []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n = len(b)
+
+	// A forked child must not repeat the output of its parent.
+	if r.fork.forked() {
+		r.budget = 0
+	}
+	for len(b) > 0 {
+		if r.budget == 0 {
+			if err := r.reseed(); err != nil {
+				return n - len(b), err
+			}
+		}
+		m := len(b)
+		if m > r.budget {
+			m = r.budget
+		}
+		r.gen.Read(b[:m])
+		r.budget -= m
+		b = b[m:]
+	}
+	return n, nil
+}
+
+func (r *bufferedReader) reseed() error {
+	var seed [32]byte
+	if _, err := io.ReadFull(r.source, seed[:]); err != nil {
+		return err
+	}
+	if r.gen == nil {
+		r.gen = NewChaCha20(seed)
+	} else {
+		r.gen.Reseed(seed)
+	}
+	seed = [32]byte{}
+	r.budget = bufferedReseedBudget
+	return nil
+}
+
+// pidDetector detects forks by a change of the process ID. It makes a
+// system call on every check.
+type pidDetector struct {
+	pid int
+}
+
+func newPIDDetector() *pidDetector {
+	return &pidDetector{pid: os.Getpid()}
+}
+
+func (d *pidDetector) forked() bool {
+	pid := os.Getpid()
+	if pid == d.pid {
+		return false
+	}
+	d.pid = pid
+	return true
+}
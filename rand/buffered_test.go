@@ -0,0 +1,89 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand
+
+import (
+	"bytes"
+	"testing"
+)
+
+// countingSource returns sequential seeds and counts the reads.
+type countingSource struct {
+	reads int
+}
+
+func (s *countingSource) Read(p []byte) (int, error) {
+	s.reads++
+	for i := range p {
+		p[i] = byte(s.reads)
+	}
+	return len(p), nil
+}
+
+type fakeFork struct {
+	fork bool
+}
+
+func (f *fakeFork) forked() bool {
+	forked := f.fork
+	f.fork = false
+	return forked
+}
+
+func TestBufferedReader(t *testing.T) {
+	src := new(countingSource)
+	fork := new(fakeFork)
+	r := &bufferedReader{source: src, fork: fork}
+
+	a := make([]byte, 100)
+	b := make([]byte, 100)
+	r.Read(a)
+	r.Read(b)
+	if src.reads != 1 {
+		t.Errorf("source read %d times, want 1", src.reads)
+	}
+	if bytes.Equal(a, b) {
+		t.Error("consecutive reads returned the same bytes")
+	}
+
+	// A fork reseeds, and the child diverges from a copy of the parent.
+	parent := *r.gen
+	fork.fork = true
+	r.Read(a)
+	parent.Read(b)
+	if src.reads != 2 {
+		t.Errorf("source read %d times after a fork, want 2", src.reads)
+	}
+	if bytes.Equal(a, b) {
+		t.Error("the child repeated the output of the parent")
+	}
+
+	// Crossing the budget reseeds in the middle of a read.
+	big := make([]byte, bufferedReseedBudget)
+	if n, err := r.Read(big); n != len(big) || err != nil {
+		t.Fatalf("Read = %d, %v", n, err)
+	}
+	if src.reads != 3 {
+		t.Errorf("source read %d times after the budget, want 3", src.reads)
+	}
+}
+
+func TestBufferedReaderSystem(t *testing.T) {
+	r := NewBufferedReader(Reader)
+	seen := make(map[string]bool)
+	b := make([]byte, 16)
+	for i := 0; i < 1000; i++ {
+		if _, err := r.Read(b); err != nil {
+			t.Fatal(err)
+		}
+		if seen[string(b)] {
+			t.Fatalf("repeated output %x", b)
+		}
+		seen[string(b)] = true
+	}
+	if f := newForkDetector(); f.forked() {
+		t.Error("fork detected without a fork")
+	}
+}
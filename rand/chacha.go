@@ -0,0 +1,75 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand
+
+import "golang.org/x/github.com/benchlab/bench-crypto/int/chacha20"
+
+// chachaBlocks is the number of ChaCha20 blocks computed per refill of a
+// ChaCha20 generator.
+const chachaBlocks = 16
+
+// ChaCha20 is a deterministic generator using ChaCha20 with fast key erasure:
+// every refill computes a batch of keystream under the current key, replaces
+// the key with the first 32 bytes of the batch and serves the rest, erasing
+// each byte once it is returned. A compromise of the state therefore does not
+// reveal past output.
+//
+// ChaCha20 is not safe for concurrent use.
+type ChaCha20 struct {
+	key [32]byte
+	buf [chachaBlocks * 64]byte
+	off int // next unused byte of buf
+}
+
+// NewChaCha20 returns a ChaCha20 generator seeded with seed.
+func NewChaCha20(seed [32]byte) *ChaCha20 {
+	return &ChaCha20{key: seed, off: chachaBlocks * 64}
+}
+
+// Reseed mixes seed into the key of the generator and discards the buffered
+// output.
+func (c *ChaCha20) Reseed(seed [32]byte) {
+	for i := range c.key {
+		c.key[i] ^= seed[i]
+	}
+	c.erase(len(c.buf))
+	c.off = len(c.buf)
+}
+
+// Read fills p with pseudorandom bytes. It implements io.Reader and never
+// fails.
+func (c *ChaCha20) Read(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if c.off == len(c.buf) {
+			c.refill()
+		}
+		k := copy(p, c.buf[c.off:])
+		c.erase(c.off + k)
+		c.off += k
+		p = p[k:]
+	}
+	return n, nil
+}
+
+// erase zeroes the buffered bytes from the next unused one up to end.
+func (c *ChaCha20) erase(end int) {
+	for i := c.off; i < end; i++ {
+		c.buf[i] = 0
+	}
+}
+
+// refill replaces the key and the buffer with the ChaCha20 keystream of RFC
+// 7539 under the current key, with an all-zero nonce and counter. Every byte
+// of the buffer has been erased, so XORing the keystream into it writes the
+// keystream itself.
+func (c *ChaCha20) refill() {
+	var counter [16]byte
+	chacha20.XORKeyStream(c.buf[:], c.buf[:], &counter, &c.key)
+	copy(c.key[:], c.buf[:32])
+	c.off = 0
+	c.erase(32)
+	c.off = 32
+}
@@ -0,0 +1,271 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand
+
+import (
+	"errors"
+	"hash"
+
+	"github.com/benchlab/bench-crypto/aes"
+	"github.com/benchlab/bench-crypto/cipher"
+	"github.com/benchlab/bench-crypto/hmac"
+)
+
+// The deterministic random bit generators of NIST SP 800-90A produce the same
+// output from the same inputs. They are meant for reproducible tests and for
+// callers that provide their own entropy; Reader remains the source of
+// randomness to use by default.
+
+const (
+	// maxDRBGRequest is the maximum number of bytes generated by a single
+	// request, 2^19 bits.
+	maxDRBGRequest = 1 << 16
+	// reseedInterval is the maximum number of requests between reseeds.
+	reseedInterval = 1 << 48
+)
+
+// ErrReseedRequired is returned by the DRBGs once they have served the
+// maximum number of requests allowed by SP 800-90A since they were last
+// seeded.
+var ErrReseedRequired = errors.New("github.com/benchlab/bench-crypto/rand: DRBG must be reseeded")
+
+// HMACDRBG is the HMAC_DRBG of NIST SP 800-90A, Section 10.1.2.
+type HMACDRBG struct {
+	h             func() hash.Hash
+	k, v          []byte
+	reseedCounter uint64
+}
+
+// NewHMACDRBG returns an HMAC_DRBG using HMAC with the hash function h,
+// instantiated with the entropy input, the nonce and the optional
+// personalization string. The entropy input should hold at least as many
+// bits of entropy as the security strength of h.
+func NewHMACDRBG(h func() hash.Hash, entropy, nonce, personalization []byte) *HMACDRBG {
+	size := h().Size()
+	d := &HMACDRBG{h: h, k: make([]byte, size), v: make([]byte, size)}
+	for i := range d.v {
+		d.v[i] = 0x01
+	}
+	d.update(entropy, nonce, personalization)
+	d.reseedCounter = 1
+	return d
+}
+
+// update is the HMAC_DRBG_Update function, over the concatenation of data.
+func (d *HMACDRBG) update(data ...[]byte) {
+	empty := true
+	for _, b := range data {
+		empty = empty && len(b) == 0
+	}
+	for _, sep := range []byte{0x00, 0x01} {
+		if sep == 0x01 && empty {
+			break
+		}
+		mac := hmac.New(d.h, d.k)
+		mac.Write(d.v)
+		mac.Write([]byte{sep})
+		for _, b := range data {
+			mac.Write(b)
+		}
+		d.k = mac.Sum(d.k[:0])
+		mac = hmac.New(d.h, d.k)
+		mac.Write(d.v)
+		d.v = mac.Sum(d.v[:0])
+	}
+}
+
+// Reseed mixes new entropy input and optional additional input into the
+// state of the generator.
+func (d *HMACDRBG) Reseed(entropy, additional []byte) {
+	d.update(entropy, additional)
+	d.reseedCounter = 1
+}
+
+// Generate fills p with pseudorandom bytes, after mixing in the optional
+// additional input. Requests longer than 64 KiB are split into several
+// requests, of which only the first one uses the additional input.
+func (d *HMACDRBG) Generate(p, additional []byte) error {
+	for {
+		n := len(p)
+		if n > maxDRBGRequest {
+			n = maxDRBGRequest
+		}
+		if err := d.generate(p[:n], additional); err != nil {
+			return err
+		}
+		p, additional = p[n:], nil
+		if len(p) == 0 {
+			return nil
+		}
+	}
+}
+
+func (d *HMACDRBG) generate(p, additional []byte) error {
+	if d.reseedCounter > reseedInterval {
+		return ErrReseedRequired
+	}
+	if len(additional) > 0 {
+		d.update(additional)
+	}
+	mac := hmac.New(d.h, d.k)
+	for len(p) > 0 {
+		mac.Reset()
+		mac.Write(d.v)
+		d.v = mac.Sum(d.v[:0])
+		p = p[copy(p, d.v):]
+	}
+	d.update(additional)
+	d.reseedCounter++
+	return nil
+}
+
+// Read fills p with pseudorandom bytes, without additional input. It
+// implements io.Reader and only fails if the generator must be reseeded.
+func (d *HMACDRBG) Read(p []byte) (int, error) {
+	if err := d.Generate(p, nil); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// CTRDRBGSeedSize is the length of the entropy input of CTRDRBG, and the
+// maximum length of its personalization string and additional inputs.
+const CTRDRBGSeedSize = 32 + aes.ChunkSize
+
+// CTRDRBG is the CTR_DRBG of NIST SP 800-90A, Section 10.2.1, with AES-256
+// and without a derivation function.
+type CTRDRBG struct {
+	c             cipher.Chunk
+	v             [aes.ChunkSize]byte
+	reseedCounter uint64
+}
+
+// NewCTRDRBG returns a CTR_DRBG instantiated with the entropy input, which
+// must be CTRDRBGSeedSize bytes of full entropy, and the optional
+// personalization string of at most CTRDRBGSeedSize bytes. Without a
+// derivation function, CTR_DRBG uses no nonce.
+func NewCTRDRBG(entropy, personalization []byte) (*CTRDRBG, error) {
+	seed, err := ctrSeed(entropy, personalization)
+	if err != nil {
+		return nil, err
+	}
+	d := new(CTRDRBG)
+	d.c, _ = aes.NewCipher(make([]byte, 32))
+	d.update(seed)
+	d.reseedCounter = 1
+	return d, nil
+}
+
+// ctrSeed returns the XOR of the entropy input and the zero-padded
+// additional input.
+func ctrSeed(entropy, additional []byte) (*[CTRDRBGSeedSize]byte, error) {
+	if len(entropy) != CTRDRBGSeedSize {
+		return nil, errors.New("github.com/benchlab/bench-crypto/rand: CTR_DRBG entropy input must be 48 bytes")
+	}
+	var seed [CTRDRBGSeedSize]byte
+	if err := padAdditional(&seed, additional); err != nil {
+		return nil, err
+	}
+	for i := range seed {
+		seed[i] ^= entropy[i]
+	}
+	return &seed, nil
+}
+
+func padAdditional(dst *[CTRDRBGSeedSize]byte, additional []byte) error {
+	if len(additional) > CTRDRBGSeedSize {
+		return errors.New("github.com/benchlab/bench-crypto/rand: CTR_DRBG input longer than 48 bytes")
+	}
+	copy(dst[:], additional)
+	return nil
+}
+
+// update is the CTR_DRBG_Update function.
+func (d *CTRDRBG) update(provided *[CTRDRBGSeedSize]byte) {
+	var temp [CTRDRBGSeedSize]byte
+	for i := 0; i < len(temp); i += aes.ChunkSize {
+		d.increment()
+		d.c.Encrypt(temp[i:], d.v[:])
+	}
+	for i := range temp {
+		temp[i] ^= provided[i]
+	}
+	d.c, _ = aes.NewCipher(temp[:32])
+	copy(d.v[:], temp[32:])
+}
+
+// increment adds one to V, modulo 2^128.
+func (d *CTRDRBG) increment() {
+	for i := len(d.v) - 1; i >= 0; i-- {
+		d.v[i]++
+		if d.v[i] != 0 {
+			break
+		}
+	}
+}
+
+// Reseed mixes new entropy input, which must be CTRDRBGSeedSize bytes, and
+// the optional additional input of at most CTRDRBGSeedSize bytes into the
+// state of the generator.
+func (d *CTRDRBG) Reseed(entropy, additional []byte) error {
+	seed, err := ctrSeed(entropy, additional)
+	if err != nil {
+		return err
+	}
+	d.update(seed)
+	d.reseedCounter = 1
+	return nil
+}
+
+// Generate fills p with pseudorandom bytes, after mixing in the optional
+// additional input of at most CTRDRBGSeedSize bytes. Requests longer than
+// 64 KiB are split into several requests, of which only the first one uses
+// the additional input.
+func (d *CTRDRBG) Generate(p, additional []byte) error {
+	var add [CTRDRBGSeedSize]byte
+	if err := padAdditional(&add, additional); err != nil {
+		return err
+	}
+	for {
+		n := len(p)
+		if n > maxDRBGRequest {
+			n = maxDRBGRequest
+		}
+		if err := d.generate(p[:n], &add, len(additional) > 0); err != nil {
+			return err
+		}
+		p, additional, add = p[n:], nil, [CTRDRBGSeedSize]byte{}
+		if len(p) == 0 {
+			return nil
+		}
+	}
+}
+
+func (d *CTRDRBG) generate(p []byte, additional *[CTRDRBGSeedSize]byte, hasAdditional bool) error {
+	if d.reseedCounter > reseedInterval {
+		return ErrReseedRequired
+	}
+	if hasAdditional {
+		d.update(additional)
+	}
+	var block [aes.ChunkSize]byte
+	for len(p) > 0 {
+		d.increment()
+		d.c.Encrypt(block[:], d.v[:])
+		p = p[copy(p, block[:]):]
+	}
+	d.update(additional)
+	d.reseedCounter++
+	return nil
+}
+
+// Read fills p with pseudorandom bytes, without additional input. It
+// implements io.Reader and only fails if the generator must be reseeded.
+func (d *CTRDRBG) Read(p []byte) (int, error) {
+	if err := d.Generate(p, nil); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
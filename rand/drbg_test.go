@@ -0,0 +1,136 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/benchlab/bench-crypto/sha256"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// TestHMACDRBGRFC6979 checks that the first output of HMAC_DRBG instantiated
+// as in RFC 6979, Section 3.2, is the nonce k of the P-256, SHA-256 signature
+// of "sample" in Appendix A.2.5.
+func TestHMACDRBGRFC6979(t *testing.T) {
+	x := decodeHex(t, "c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721")
+	h1 := sha256.Sum256([]byte("sample"))
+	d := NewHMACDRBG(sha256.New, x, h1[:], nil)
+	k := make([]byte, 32)
+	if _, err := io.ReadFull(d, k); err != nil {
+		t.Fatal(err)
+	}
+	if want := decodeHex(t, "a6e3c57dd01abe90086538398355dd4c3b17aa873382b0f24d6129493d8aad60"); !bytes.Equal(k, want) {
+		t.Errorf("got %x, want %x", k, want)
+	}
+}
+
+// TestHMACDRBGReseed checks personalization, additional input and reseeding
+// against values computed with an independent implementation, in Python.
+func TestHMACDRBGReseed(t *testing.T) {
+	seq := make([]byte, 80)
+	for i := range seq {
+		seq[i] = byte(i)
+	}
+	d := NewHMACDRBG(sha256.New, seq[:32], seq[32:48], []byte("pers"))
+	out := make([]byte, 40)
+	if err := d.Generate(out, []byte("add1")); err != nil {
+		t.Fatal(err)
+	}
+	if want := decodeHex(t, "9bb6ca99af6d6d922c47041300cb62e634163e9c34fdb67735b70c2113efae93d5816f8002eb50b1"); !bytes.Equal(out, want) {
+		t.Errorf("first output: got %x, want %x", out, want)
+	}
+	d.Reseed(seq[48:80], []byte("rs"))
+	if _, err := d.Read(out); err != nil {
+		t.Fatal(err)
+	}
+	if want := decodeHex(t, "33cd27b4a11ee2edf53eba072d212a4a824bed7480808ffdb9f8d7af4bfe11feccb0483aab3c152f"); !bytes.Equal(out, want) {
+		t.Errorf("output after reseed: got %x, want %x", out, want)
+	}
+}
+
+// TestCTRDRBG checks a test case of the NIST ACVP ctrDRBG-1.0 vectors for
+// AES-256 without derivation function.
+func TestCTRDRBG(t *testing.T) {
+	entropy := decodeHex(t, "9fcbb4ccc0135c484bded061da9fd70748682fe84166b97ff53f9aa1909b2e95d3d529c0f453b3ac575d12aa441cc5cd")
+	personalization := decodeHex(t, "2c9fed0b39556cdbe699ebca2a0ec7eecb287e8744475050c572fa8ae9ed0a4a7d6f1cabf1c4278532fb20af7d64bd32")
+	reseedEntropy := decodeHex(t, "913c0da19b010eddd55a7a4f3f713eef5b1534d34360a7ec376ae71a6b340043cc7726f762cb853453f399b3a645062a")
+	reseedAdditional := decodeHex(t, "2d9d4ec141a22e6cd2f6ee4f6719cf6bdf95cfe50b8d5ea6c87d38b4b872706fff80b0380bb90e9c42d11d6526e56c29")
+	additional1 := decodeHex(t, "a642f06d327828f3e84564a3e37d60c157073b95864ca07981b0189668a0d978cd5dc68f06801ceff0dc839a312b028e")
+	additional2 := decodeHex(t, "9db14babfa9107c88ba92073c0b4a65e89147ea06d74b894142979482f452915b35b5636f9b8a951759735ade7c8d5d1")
+	want := decodeHex(t, "f10c645683ff0131254052ed4c698122b46b563654c29d728ac191ca4aaefe649eefe4c6fc33b25bb739294dd5cf578099f856c98d98000cbf971f1e6ea900822ff8c110118f6520471744d3f8a3f5c7d568494240e57f5488af9c9f9f4e7322f56ccd843c0dbfce9170c02e205389420527f23edb3369d9fcc5e34901b5ba4eb71b973fc7982ffe0899ff7fe53ee0c4f51a3ef93ef9c6d4d279dd7536f8776be94aaa05e89ef6e6aee8832b4b42ffca5fb91ec0273f9ef945865512889b0c5ee141d1b38df827d2a694835561628c6f9b093a01a835f07adbb9e03febf93389e8f3b86e1e0abf1f9958fa286ad995289c2f606d1a9043a166c1afe8d00769c712650819c9068a4bd22717c98338395a7ba6e95b5178bfbf4efb0f05a91713ba8bf2127a6ba1edfa6d1cab05c03ee0d2afe1da4eb8f2c579ec872ff4b602027ef4bdcf2f4b01423f8e600a13d7cacb6ab83263ba58f907694af614a6724fd0e4c627a0d91ddc6716c697face6f4808a4f37b731de4e0cd4766ceadaaaf47992505299c72ac1a6e9a8335b8d7e501b3841188d0da4de5267674444dc2b0cf9f010756fa865a25ca3f1b24c34e845b2259926b6a867a7684de68a6137c4fb0f47a2e54ae9e6455beba0b0a9629644fe9e378ee95386443ba977124ffd1192e9f460684c7b09fa99f5f93f04f56fd7955e042187887ce696f1934017e458b16b5c9")
+
+	d, err := NewCTRDRBG(entropy, personalization)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Reseed(reseedEntropy, reseedAdditional); err != nil {
+		t.Fatal(err)
+	}
+	out := make([]byte, len(want))
+	if err := d.Generate(out, additional1); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Generate(out, additional2); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, want) {
+		t.Errorf("got %x, want %x", out, want)
+	}
+
+	if _, err := NewCTRDRBG(entropy[:32], nil); err == nil {
+		t.Error("NewCTRDRBG accepted a short entropy input")
+	}
+	if err := d.Generate(out, make([]byte, CTRDRBGSeedSize+1)); err == nil {
+		t.Error("Generate accepted a long additional input")
+	}
+}
+
+// TestChaCha20 checks the output of a generator seeded with zeroes against
+// the ChaCha20 keystream of RFC 7539, Appendix A.1, test vectors 1 and 2.
+// The first 32 bytes of the keystream are the next key.
+func TestChaCha20(t *testing.T) {
+	c := NewChaCha20([32]byte{})
+	out := make([]byte, 96)
+	for i := range out {
+		// Read in small pieces, across the first refill.
+		if _, err := c.Read(out[i : i+1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := decodeHex(t, "da41597c5157488d7724e03fb8d84a376a43b8f41518a11cc387b669b2ee6586"+
+		"9f07e7be5551387a98ba977c732d080dcb0f29a048e3656912c6533e32ee7aed29b721769ce64e43d57133b074d839d531ed1f28510afb45ace10a1f4b794d6f")
+	if !bytes.Equal(out, want) {
+		t.Errorf("got %x, want %x", out, want)
+	}
+
+	// Two generators with the same seed agree across refills, and
+	// diverge after one of them is reseeded.
+	a, b := NewChaCha20([32]byte{1}), NewChaCha20([32]byte{1})
+	bufA, bufB := make([]byte, 5000), make([]byte, 5000)
+	a.Read(bufA)
+	for i := 0; i < len(bufB); i += 1000 {
+		b.Read(bufB[i : i+1000])
+	}
+	if !bytes.Equal(bufA, bufB) {
+		t.Error("generators with the same seed produced different output")
+	}
+	b.Reseed([32]byte{2})
+	a.Read(bufA[:64])
+	b.Read(bufB[:64])
+	if bytes.Equal(bufA[:64], bufB[:64]) {
+		t.Error("reseeding did not change the output")
+	}
+}
@@ -7,6 +7,7 @@ package rand_test
 import (
 	"bytes"
 	"fmt"
+	"io"
 
 	"github.com/benchlab/bench-crypto/rand"
 )
@@ -27,3 +28,20 @@ func ExampleRead() {
 	// Output:
 	// false
 }
+
+// This example generates random bytes in user space, seeded from the system
+// generator. A program would usually assign the reader to rand.Reader during
+// initialization instead.
+func ExampleNewBufferedReader() {
+	r := rand.NewBufferedReader(rand.Reader)
+
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(r, nonce); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println(bytes.Equal(nonce, make([]byte, 12)))
+
+	// Output:
+	// false
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand
+
+import (
+	"os"
+	"sync"
+	"syscall"
+)
+
+// madvWipeOnFork is MADV_WIPEONFORK, available since Linux 4.14.
+const madvWipeOnFork = 18
+
+// wipeOnFork is a page that the kernel zeroes in the child of a fork, shared
+// by all the wipeOnForkDetectors of the process so that creating readers
+// does not map a page each. Since the first detector to see the zeroed page
+// sets it again, the forks seen are counted in generation, which each
+// detector compares with the value it saw last.
+var wipeOnFork struct {
+	once       sync.Once
+	page       []byte // nil if the kernel does not support MADV_WIPEONFORK
+	mu         sync.Mutex
+	generation uint64
+}
+
+func mapWipeOnForkPage() {
+	page, err := syscall.Mmap(-1, 0, os.Getpagesize(), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANON)
+	if err != nil {
+		return
+	}
+	if err := syscall.Madvise(page, madvWipeOnFork); err != nil {
+		syscall.Munmap(page)
+		return
+	}
+	page[0] = 1
+	wipeOnFork.page = page
+}
+
+// wipeOnForkGeneration returns the number of times the page was found
+// wiped, which changes after every fork.
+func wipeOnForkGeneration() uint64 {
+	wipeOnFork.mu.Lock()
+	defer wipeOnFork.mu.Unlock()
+	if wipeOnFork.page[0] == 0 {
+		wipeOnFork.page[0] = 1
+		wipeOnFork.generation++
+	}
+	return wipeOnFork.generation
+}
+
+// wipeOnForkDetector detects forks without a system call, with the shared
+// wipeOnFork page.
+type wipeOnForkDetector struct {
+	generation uint64
+}
+
+// newForkDetector returns a wipeOnForkDetector if the kernel supports
+// MADV_WIPEONFORK, and a pidDetector otherwise.
+func newForkDetector() forkDetector {
+	wipeOnFork.once.Do(mapWipeOnForkPage)
+	if wipeOnFork.page == nil {
+		return newPIDDetector()
+	}
+	return &wipeOnForkDetector{generation: wipeOnForkGeneration()}
+}
+
+func (d *wipeOnForkDetector) forked() bool {
+	generation := wipeOnForkGeneration()
+	if generation == d.generation {
+		return false
+	}
+	d.generation = generation
+	return true
+}
@@ -0,0 +1,30 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand
+
+import "testing"
+
+func TestWipeOnForkShared(t *testing.T) {
+	a, b := newForkDetector(), newForkDetector()
+	if _, ok := a.(*wipeOnForkDetector); !ok {
+		t.Skip("MADV_WIPEONFORK not supported")
+	}
+	// Simulate the wipe of a fork, which every detector must notice
+	// exactly once, even though they share the page.
+	wipeOnFork.mu.Lock()
+	wipeOnFork.page[0] = 0
+	wipeOnFork.mu.Unlock()
+	for i, d := range []forkDetector{a, b} {
+		if !d.forked() {
+			t.Errorf("detector %d did not detect the fork", i)
+		}
+		if d.forked() {
+			t.Errorf("detector %d detected the fork twice", i)
+		}
+	}
+	if newForkDetector().forked() {
+		t.Error("new detector detected an earlier fork")
+	}
+}
@@ -0,0 +1,414 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/benchlab/bench-crypto/sha256"
+)
+
+// sieveLimit bounds the small primes used by SafePrime to sieve candidates.
+const sieveLimit = 1 << 14
+
+// sievePrimes are the odd primes below sieveLimit.
+var sievePrimes = func() []uint32 {
+	composite := make([]bool, sieveLimit)
+	var primes []uint32
+	for i := 3; i < sieveLimit; i += 2 {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, uint32(i))
+		for j := i * i; j < sieveLimit; j += 2 * i {
+			composite[j] = true
+		}
+	}
+	return primes
+}()
+
+// safePrimeWindow is the number of consecutive candidates sieved at once.
+const safePrimeWindow = 1 << 12
+
+// SafePrime returns a number p of the given size, such that p and (p-1)/2
+// are both prime with high probability. Safe primes are used as the moduli
+// of Diffie-Hellman and ElGamal groups.
+//
+// The search runs on runtime.GOMAXPROCS(0) goroutines, which share rand.
+// Finding a safe prime of 2048 bits or more can take minutes, so SafePrime
+// returns ctx.Err() once ctx is done. It returns an error for any error
+// returned by rand.Read or if bits < 3.
+func SafePrime(ctx context.Context, rand io.Reader, bits int) (*big.Int, error) {
+	if bits < 3 {
+		return nil, errors.New("github.com/benchlab/bench-crypto/rand: safe prime size must be at least 3-bit")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		randMu sync.Mutex
+		once   sync.Once
+		result *big.Int
+		resErr error
+		wg     sync.WaitGroup
+	)
+	done := func(p *big.Int, err error) {
+		once.Do(func() {
+			result, resErr = p, err
+			cancel()
+		})
+	}
+	read := func(b []byte) error {
+		randMu.Lock()
+		defer randMu.Unlock()
+		_, err := io.ReadFull(rand, b)
+		return err
+	}
+
+	for w := runtime.GOMAXPROCS(0); w > 0; w-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				p, err := searchSafePrime(ctx, read, bits)
+				if err != nil || p != nil {
+					done(p, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if result == nil && resErr == nil {
+		// The parent context was canceled before any worker finished.
+		resErr = ctx.Err()
+	}
+	return result, resErr
+}
+
+// searchSafePrime sieves a window of candidates p = 2q+1 from a random odd
+// q of bits-1 bits and returns the first safe prime among them, or nil if
+// there is none or ctx is done.
+func searchSafePrime(ctx context.Context, read func([]byte) error, bits int) (*big.Int, error) {
+	qBits := bits - 1
+	b := make([]byte, (qBits+7)/8)
+	if err := read(b); err != nil {
+		return nil, err
+	}
+	// Keep qBits bits, with the top one set so that p has exactly bits bits,
+	// and make q odd.
+	top := uint(qBits-1) % 8
+	b[0] &= byte(1<<(top+1) - 1)
+	b[0] |= 1 << top
+	b[len(b)-1] |= 1
+	q0 := new(big.Int).SetBytes(b)
+
+	// Candidate i is q = q0 + 2i. It is excluded if q or 2q+1 is divisible
+	// by a small prime r, that is if q ≡ 0 or q ≡ (r-1)/2 mod r. Primes
+	// which might equal q or p themselves are not used.
+	var excluded [safePrimeWindow]bool
+	rem, mod := new(big.Int), new(big.Int)
+	for _, r := range sievePrimes {
+		if qBits <= 32 && uint64(r) >= uint64(1)<<uint(qBits-1) {
+			break
+		}
+		m := uint32(rem.Mod(q0, mod.SetUint64(uint64(r))).Uint64())
+		for _, bad := range [2]uint32{0, (r - 1) / 2} {
+			// Find the first i with m + 2i ≡ bad mod r, i = (bad - m) / 2.
+			d := (bad + r - m) % r
+			if d%2 == 1 {
+				d += r
+			}
+			for i := d / 2; i < safePrimeWindow; i += r {
+				excluded[i] = true
+			}
+		}
+	}
+
+	q := new(big.Int)
+	p := new(big.Int)
+	two := new(big.Int)
+	for i := 0; i < safePrimeWindow; i++ {
+		if excluded[i] {
+			continue
+		}
+		if i%64 == 0 && ctx.Err() != nil {
+			return nil, nil
+		}
+		q.Add(q0, two.SetUint64(uint64(2*i)))
+		p.Lsh(q, 1).Add(p, one)
+		if p.BitLen() != bits {
+			return nil, nil
+		}
+		// Cheap tests on both numbers first, since most candidates
+		// fail one of them.
+		if !q.ProbablyPrime(0) || !p.ProbablyPrime(0) {
+			continue
+		}
+		if q.ProbablyPrime(20) && p.ProbablyPrime(20) {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+var one = big.NewInt(1)
+
+// StrongPrime returns a number p of the given size that is a strong prime
+// with high probability: p-1 has a large prime factor r, p+1 has a large
+// prime factor s, and r-1 has a large prime factor t. r and s have about
+// half the bits of p, and t about 18 fewer bits than r.
+//
+// It implements Gordon's algorithm, from "Strong primes are easy to find",
+// EUROCRYPT 1984. StrongPrime returns an error for any error returned by
+// rand.Read or if bits < 64.
+func StrongPrime(rand io.Reader, bits int) (*big.Int, error) {
+	if bits < 64 {
+		return nil, errors.New("github.com/benchlab/bench-crypto/rand: strong prime size must be at least 64-bit")
+	}
+	for {
+		p, _, _, _, err := searchStrongPrime(rand, bits)
+		if err != nil || p != nil {
+			return p, err
+		}
+	}
+}
+
+// searchStrongPrime runs Gordon's algorithm with random primes s and t, and
+// returns p along with r, s and t, or a nil p if there is no prime of the
+// given size in the progression it searches.
+func searchStrongPrime(rand io.Reader, bits int) (p, r, s, t *big.Int, err error) {
+	// r gets about 17 more bits than t from the search below, so that 2rs,
+	// the step of the search for p, leaves about 2¹⁹ candidates of bits bits.
+	if s, err = Prime(rand, bits/2-10); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if t, err = Prime(rand, bits/2-28); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// r is the first prime of the form 2it + 1, from a random i of 16 bits.
+	i, err := Int(rand, big.NewInt(1<<15))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	i.Add(i, big.NewInt(1<<15))
+	step := new(big.Int).Lsh(t, 1)
+	r = new(big.Int).Mul(i, step)
+	r.Add(r, one)
+	for !r.ProbablyPrime(20) {
+		r.Add(r, step)
+	}
+
+	// p0 = 2(s^(r-2) mod r)s - 1 is 1 mod r and -1 mod s, and so are all
+	// the candidates p0 + 2jrs.
+	p0 := new(big.Int).Sub(r, big.NewInt(2))
+	p0.Exp(s, p0, r)
+	p0.Mul(p0, s)
+	p0.Lsh(p0, 1)
+	p0.Sub(p0, one)
+	step.Mul(r, s)
+	step.Lsh(step, 1)
+
+	// Search the candidates of bits bits, which are those with j in
+	// [jMin, jMin+n), from a random one, wrapping around at the end.
+	lo := new(big.Int).Lsh(one, uint(bits-1))
+	lo.Sub(lo, p0)
+	jMin := ceilDiv(lo, step)
+	hi := new(big.Int).Lsh(one, uint(bits))
+	hi.Sub(hi, p0)
+	n := ceilDiv(hi, step)
+	n.Sub(n, jMin)
+	if n.Sign() <= 0 {
+		return nil, nil, nil, nil, nil
+	}
+	j, err := Int(rand, n)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	first := new(big.Int).Mul(jMin, step)
+	first.Add(first, p0)
+	p = new(big.Int).Mul(j, step)
+	p.Add(p, first)
+	for k := int64(0); k < n.Int64(); k++ {
+		if p.BitLen() > bits {
+			p.Set(first)
+		}
+		if p.ProbablyPrime(20) {
+			return p, r, s, t, nil
+		}
+		p.Add(p, step)
+	}
+	return nil, nil, nil, nil, nil
+}
+
+// ErrShaweTaylorFailure is returned by ShaweTaylorPrime when the algorithm
+// fails for the given seed, after which the caller should try another seed.
+var ErrShaweTaylorFailure = errors.New("github.com/benchlab/bench-crypto/rand: Shawe-Taylor prime generation failed for this seed")
+
+// ProvablePrime returns a prime p of the given size, generated from a random
+// 32-byte seed with the Shawe-Taylor algorithm of FIPS 186-4, Appendix C.6,
+// using SHA-256. Unlike Prime, whose result is only probably prime, the
+// algorithm proves the primality of its result. ProvablePrime returns an
+// error for any error returned by rand.Read or if bits < 2.
+func ProvablePrime(rand io.Reader, bits int) (*big.Int, error) {
+	seed := make([]byte, sha256.Size)
+	for {
+		if _, err := io.ReadFull(rand, seed); err != nil {
+			return nil, err
+		}
+		p, _, _, err := ShaweTaylorPrime(bits, seed)
+		if err != ErrShaweTaylorFailure {
+			return p, err
+		}
+	}
+}
+
+// ShaweTaylorPrime implements ST_Random_Prime of FIPS 186-4, Appendix C.6,
+// with SHA-256. It deterministically derives a prime of the given size from
+// the seed, and also returns the prime_seed and prime_gen_counter values
+// with which the generation can be validated. The seed is interpreted as an
+// integer of len(seed) bytes, and so are the returned seeds.
+func ShaweTaylorPrime(bits int, seed []byte) (p *big.Int, primeSeed []byte, primeGenCounter int, err error) {
+	if bits < 2 {
+		return nil, nil, 0, errors.New("github.com/benchlab/bench-crypto/rand: prime size must be at least 2-bit")
+	}
+	s := &stSeed{v: append([]byte(nil), seed...)}
+	p, primeGenCounter, err = shaweTaylor(bits, s)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return p, s.v, primeGenCounter, nil
+}
+
+// stSeed is the prime_seed of the Shawe-Taylor algorithm, an integer of a
+// fixed number of bytes.
+type stSeed struct {
+	v []byte
+}
+
+// add adds n to the seed, modulo 2^(8*len(s.v)).
+func (s *stSeed) add(n int) {
+	carry := n
+	for i := len(s.v) - 1; i >= 0 && carry > 0; i-- {
+		sum := int(s.v[i]) + carry&0xff
+		s.v[i] = byte(sum)
+		carry = carry>>8 + sum>>8
+	}
+}
+
+// hash returns SHA-256 of the seed plus i, as an integer.
+func (s *stSeed) hash(i int) *big.Int {
+	t := &stSeed{v: append([]byte(nil), s.v...)}
+	t.add(i)
+	h := sha256.Sum256(t.v)
+	return new(big.Int).SetBytes(h[:])
+}
+
+// hashes returns the sum of the hashes of the seed plus i, shifted by
+// i*outlen bits, for i = 0 to iterations, and advances the seed past them.
+func (s *stSeed) hashes(iterations int) *big.Int {
+	x := new(big.Int)
+	for i := 0; i <= iterations; i++ {
+		x.Add(x, new(big.Int).Lsh(s.hash(i), uint(i*sha256.Size*8)))
+	}
+	s.add(iterations + 1)
+	return x
+}
+
+func shaweTaylor(length int, seed *stSeed) (*big.Int, int, error) {
+	if length < 33 {
+		// Steps 3 to 13: draw small candidates and test them by trial
+		// division.
+		counter := 0
+		mask := new(big.Int).Lsh(one, uint(length-1))
+		for {
+			c := seed.hash(0)
+			c.Xor(c, seed.hash(1))
+			c.Mod(c, mask).Add(c, mask)
+			c.SetBit(c, 0, 1)
+			counter++
+			seed.add(2)
+			if isPrimeByTrialDivision(c.Uint64()) {
+				return c, counter, nil
+			}
+			if counter > 4*length {
+				return nil, 0, ErrShaweTaylorFailure
+			}
+		}
+	}
+
+	// Steps 14 to 33.
+	c0, counter, err := shaweTaylor((length+1)/2+1, seed)
+	if err != nil {
+		return nil, 0, err
+	}
+	outlen := sha256.Size * 8
+	iterations := (length+outlen-1)/outlen - 1
+	oldCounter := counter
+
+	x := seed.hashes(iterations)
+	half := new(big.Int).Lsh(one, uint(length-1))
+	x.Mod(x, half).Add(x, half)
+
+	twoC0 := new(big.Int).Lsh(c0, 1)
+	t := ceilDiv(x, twoC0)
+	limit := new(big.Int).Lsh(one, uint(length))
+	c := new(big.Int)
+	for {
+		c.Mul(twoC0, t).Add(c, one)
+		if c.Cmp(limit) > 0 {
+			t = ceilDiv(half, twoC0)
+			c.Mul(twoC0, t).Add(c, one)
+		}
+		counter++
+
+		a := seed.hashes(iterations)
+		cMinus3 := new(big.Int).Sub(c, big.NewInt(3))
+		a.Mod(a, cMinus3).Add(a, big.NewInt(2))
+		z := new(big.Int).Exp(a, new(big.Int).Lsh(t, 1), c)
+		zMinus1 := new(big.Int).Sub(z, one)
+		if new(big.Int).GCD(nil, nil, zMinus1, c).Cmp(one) == 0 &&
+			new(big.Int).Exp(z, c0, c).Cmp(one) == 0 {
+			return new(big.Int).Set(c), counter, nil
+		}
+		if counter >= 4*length+oldCounter {
+			return nil, 0, ErrShaweTaylorFailure
+		}
+		t.Add(t, one)
+	}
+}
+
+func ceilDiv(x, y *big.Int) *big.Int {
+	q, m := new(big.Int).DivMod(x, y, new(big.Int))
+	if m.Sign() != 0 {
+		q.Add(q, one)
+	}
+	return q
+}
+
+// isPrimeByTrialDivision reports whether the number c, of at most 32 bits,
+// is prime.
+func isPrimeByTrialDivision(c uint64) bool {
+	if c < 2 {
+		return false
+	}
+	if c%2 == 0 {
+		return c == 2
+	}
+	for d := uint64(3); d*d <= c; d += 2 {
+		if c%d == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,193 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/benchlab/bench-crypto/rand"
+)
+
+func TestIntRange(t *testing.T) {
+	lo, hi := big.NewInt(-5), big.NewInt(3)
+	seen := make(map[int64]bool)
+	for i := 0; i < 1000; i++ {
+		n, err := rand.IntRange(rand.Reader, lo, hi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.Cmp(lo) < 0 || n.Cmp(hi) >= 0 {
+			t.Fatalf("IntRange returned %v, outside of [%v, %v)", n, lo, hi)
+		}
+		seen[n.Int64()] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("IntRange returned %d distinct values, want 8", len(seen))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("IntRange did not panic with hi == lo")
+		}
+	}()
+	rand.IntRange(rand.Reader, hi, hi)
+}
+
+func TestSafePrime(t *testing.T) {
+	sizes := []int{3, 4, 5, 10, 64, 256}
+	if !testing.Short() {
+		sizes = append(sizes, 512)
+	}
+	for _, n := range sizes {
+		p, err := rand.SafePrime(context.Background(), rand.Reader, n)
+		if err != nil {
+			t.Fatalf("Can't generate %d-bit safe prime: %v", n, err)
+		}
+		if p.BitLen() != n {
+			t.Fatalf("%v is not %d-bit", p, n)
+		}
+		q := new(big.Int).Rsh(p, 1)
+		if !p.ProbablyPrime(32) || !q.ProbablyPrime(32) {
+			t.Fatalf("%v is not a safe prime", p)
+		}
+	}
+
+	if p, err := rand.SafePrime(context.Background(), rand.Reader, 2); p != nil || err == nil {
+		t.Errorf("SafePrime should return nil, error when called with bits < 3")
+	}
+}
+
+func TestSafePrimeCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p, err := rand.SafePrime(ctx, rand.Reader, 4096)
+	if p != nil || err != context.Canceled {
+		t.Errorf("SafePrime with a canceled context = %v, %v; want nil, %v", p, err, context.Canceled)
+	}
+}
+
+func TestSafePrimeReadError(t *testing.T) {
+	_, err := rand.SafePrime(context.Background(), errReader{}, 256)
+	if err != errRead {
+		t.Errorf("SafePrime = %v, want %v", err, errRead)
+	}
+}
+
+var errRead = errors.New("read error")
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errRead }
+
+func TestStrongPrime(t *testing.T) {
+	sizes := []int{64, 65, 100, 256}
+	if !testing.Short() {
+		sizes = append(sizes, 1024)
+	}
+	for _, n := range sizes {
+		p, err := rand.StrongPrime(rand.Reader, n)
+		if err != nil {
+			t.Fatalf("Can't generate %d-bit strong prime: %v", n, err)
+		}
+		if p.BitLen() != n {
+			t.Fatalf("%v is not %d-bit", p, n)
+		}
+		if !p.ProbablyPrime(32) {
+			t.Fatalf("%v is not prime", p)
+		}
+	}
+
+	if p, err := rand.StrongPrime(rand.Reader, 63); p != nil || err == nil {
+		t.Errorf("StrongPrime should return nil, error when called with bits < 64")
+	}
+	if _, err := rand.StrongPrime(errReader{}, 256); err != errRead {
+		t.Errorf("StrongPrime = %v, want %v", err, errRead)
+	}
+}
+
+// The expected values were computed with an independent implementation of
+// ST_Random_Prime, as no published vectors use SHA-256 for its hash.
+var shaweTaylorTests = []struct {
+	bits      int
+	seed      string
+	prime     string
+	primeSeed string
+	counter   int
+}{
+	{
+		20,
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		"e9aa9",
+		"000000000000000000000000000000000000000000000000000000000000001d",
+		14,
+	},
+	{
+		512,
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"d4333b59bc733d9c5b4bc2f97ddba21d05517645ae0207505288ac418af206eb3fc20028c44ebd1e3c4fd2efeb6f9b1d04656ccd545e8f41c8b4f1f6d39baccf",
+		"0000000000000000000000000000000000000000000000000000000000000146",
+		191,
+	},
+	{
+		1024,
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		"b0e819060b9ca75e71d8509646fe4e3b40ee579132404a51f69c52f0ee2d7848385935b988a48b2e2dbb451844c36c8797b47b53651a43c0e006f8fb70534cab3249326d05c89c73c540a4c6c0c1ad449312e498c1edef33ac3cb8a0eafc48d0da9d81d14ab70e4a073eabcff4393278f380aa1c3f10aba976f2b1a785a4e007",
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abd301",
+		567,
+	},
+}
+
+func TestShaweTaylorPrime(t *testing.T) {
+	for _, tt := range shaweTaylorTests {
+		seed, _ := hex.DecodeString(tt.seed)
+		p, primeSeed, counter, err := rand.ShaweTaylorPrime(tt.bits, seed)
+		if err != nil {
+			t.Fatalf("%d bits: %v", tt.bits, err)
+		}
+		if got := p.Text(16); got != tt.prime {
+			t.Errorf("%d bits: prime = %s, want %s", tt.bits, got, tt.prime)
+		}
+		if got := hex.EncodeToString(primeSeed); got != tt.primeSeed {
+			t.Errorf("%d bits: prime_seed = %s, want %s", tt.bits, got, tt.primeSeed)
+		}
+		if counter != tt.counter {
+			t.Errorf("%d bits: prime_gen_counter = %d, want %d", tt.bits, counter, tt.counter)
+		}
+		if !bytes.Equal(seed, mustDecode(tt.seed)) {
+			t.Errorf("%d bits: ShaweTaylorPrime modified the seed", tt.bits)
+		}
+	}
+}
+
+func mustDecode(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestProvablePrime(t *testing.T) {
+	for _, n := range []int{2, 3, 16, 32, 33, 100, 1024} {
+		p, err := rand.ProvablePrime(rand.Reader, n)
+		if err != nil {
+			t.Fatalf("Can't generate %d-bit prime: %v", n, err)
+		}
+		if p.BitLen() != n {
+			t.Fatalf("%v is not %d-bit", p, n)
+		}
+		if !p.ProbablyPrime(32) {
+			t.Fatalf("%v is not prime", p)
+		}
+	}
+	if p, err := rand.ProvablePrime(rand.Reader, 1); p != nil || err == nil {
+		t.Errorf("ProvablePrime should return nil, error when called with bits < 2")
+	}
+}
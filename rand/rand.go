@@ -4,6 +4,11 @@
 
 // Package rand implements a cryptographically secure
 // pseudorandom number generator.
+//
+// It also provides deterministic generators, the HMAC_DRBG and CTR_DRBG of
+// NIST SP 800-90A and a ChaCha20 generator, which produce the same output
+// from the same seed and can be passed wherever an io.Reader is expected as
+// a source of randomness, for example in reproducible tests.
 package rand
 
 import "io"
@@ -15,6 +20,9 @@ import "io"
 // On OpenBSD, Reader uses getentropy(2).
 // On other Unix-like systems, Reader reads from /dev/urandom.
 // On Windows systems, Reader uses the CryptGenRandom API.
+//
+// Programs that read many small random values can replace Reader with the
+// result of NewBufferedReader(Reader) to make fewer system calls.
 var Reader io.Reader
 
 // Read is a helper function that calls Reader.Read using io.ReadFull.
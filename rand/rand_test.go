@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"compress/flate"
 	"io"
+	"math/big"
 	"testing"
 )
 
@@ -41,3 +42,33 @@ func TestReadEmpty(t *testing.T) {
 		t.Fatalf("Read(nil) = %d, %v", n, err)
 	}
 }
+
+// TestStrongPrimeFactors checks the factors of p-1, p+1 and r-1 that make
+// the results of StrongPrime strong.
+func TestStrongPrimeFactors(t *testing.T) {
+	for _, n := range []int{64, 256, 1024} {
+		var p, r, s, q *big.Int
+		for p == nil {
+			var err error
+			if p, r, s, q, err = searchStrongPrime(Reader, n); err != nil {
+				t.Fatal(err)
+			}
+		}
+		m := new(big.Int)
+		if m.Mod(m.Sub(p, one), r).Sign() != 0 {
+			t.Errorf("%d bits: r does not divide p-1", n)
+		}
+		if m.Mod(m.Add(p, one), s).Sign() != 0 {
+			t.Errorf("%d bits: s does not divide p+1", n)
+		}
+		if m.Mod(m.Sub(r, one), q).Sign() != 0 {
+			t.Errorf("%d bits: t does not divide r-1", n)
+		}
+		if !r.ProbablyPrime(20) || !s.ProbablyPrime(20) || !q.ProbablyPrime(20) {
+			t.Errorf("%d bits: r, s or t is not prime", n)
+		}
+		if r.BitLen() < n/2-12 || s.BitLen() < n/2-12 || q.BitLen() < n/2-30 {
+			t.Errorf("%d bits: factors of %d, %d and %d bits are too small", n, r.BitLen(), s.BitLen(), q.BitLen())
+		}
+	}
+}
@@ -141,3 +141,15 @@ func Int(rand io.Reader, max *big.Int) (n *big.Int, err error) {
 		}
 	}
 }
+
+// IntRange returns a uniform random value in [lo, hi). It panics if hi <= lo.
+func IntRange(rand io.Reader, lo, hi *big.Int) (*big.Int, error) {
+	if hi.Cmp(lo) <= 0 {
+		panic("github.com/benchlab/bench-crypto/rand: IntRange with hi <= lo")
+	}
+	n, err := Int(rand, new(big.Int).Sub(hi, lo))
+	if err != nil {
+		return nil, err
+	}
+	return n.Add(n, lo), nil
+}
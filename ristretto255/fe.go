@@ -0,0 +1,87 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ristretto255
+
+import ref10 "golang.org/x/github.com/benchlab/bench-crypto/int/edwards25519"
+
+// The constants of RFC 9496, Section 4.1, as little-endian encodings.
+var (
+	d = feFromBytes([32]byte{
+		0xa3, 0x78, 0x59, 0x13, 0xca, 0x4d, 0xeb, 0x75, 0xab, 0xd8, 0x41, 0x41, 0x4d, 0x0a, 0x70, 0x00,
+		0x98, 0xe8, 0x79, 0x77, 0x79, 0x40, 0xc7, 0x8c, 0x73, 0xfe, 0x6f, 0x2b, 0xee, 0x6c, 0x03, 0x52,
+	})
+	sqrtADMinusOne = feFromBytes([32]byte{
+		0x1b, 0x2e, 0x7b, 0x49, 0xa0, 0xf6, 0x97, 0x7e, 0xbd, 0x54, 0x78, 0x1b, 0x0c, 0x8e, 0x9d, 0xaf,
+		0xfd, 0xd1, 0xf5, 0x31, 0xc9, 0xfc, 0x3c, 0x0f, 0xac, 0x48, 0x83, 0x2b, 0xbf, 0x31, 0x69, 0x37,
+	})
+	invSqrtAMinusD = feFromBytes([32]byte{
+		0xea, 0x40, 0x5d, 0x80, 0xaa, 0xfd, 0xc8, 0x99, 0xbe, 0x72, 0x41, 0x5a, 0x17, 0x16, 0x2f, 0x9d,
+		0x40, 0xd8, 0x01, 0xfe, 0x91, 0x7b, 0xc2, 0x16, 0xa2, 0xfc, 0xaf, 0xcf, 0x05, 0x89, 0x6c, 0x78,
+	})
+	oneMinusDSQ = feFromBytes([32]byte{
+		0x76, 0xc1, 0x5f, 0x94, 0xc1, 0x09, 0x7c, 0xe2, 0x0f, 0x35, 0x5e, 0xcd, 0x38, 0xa1, 0x81, 0x2c,
+		0xe4, 0xdf, 0x70, 0xbe, 0xdd, 0xab, 0x94, 0x99, 0xd7, 0xe0, 0xb3, 0xb2, 0xa8, 0x72, 0x90, 0x02,
+	})
+	dMinusOneSQ = feFromBytes([32]byte{
+		0x20, 0x4d, 0xed, 0x44, 0xaa, 0x5a, 0xad, 0x31, 0x99, 0x19, 0x1e, 0xb0, 0x2c, 0x4a, 0x9e, 0xd2,
+		0xeb, 0x4e, 0x9b, 0x52, 0x2f, 0xd3, 0xdc, 0x4c, 0x41, 0x22, 0x6c, 0xf6, 0x7a, 0xb3, 0x68, 0x59,
+	})
+)
+
+func feFromBytes(b [32]byte) ref10.FieldElement {
+	var fe ref10.FieldElement
+	ref10.FeFromBytes(&fe, &b)
+	return fe
+}
+
+// feEqual returns 1 if f == g, and 0 otherwise.
+func feEqual(f, g *ref10.FieldElement) int32 {
+	var t ref10.FieldElement
+	ref10.FeSub(&t, f, g)
+	return 1 ^ ref10.FeIsNonZero(&t)
+}
+
+// feAbs sets h = |f|, the non-negative one of f and -f.
+func feAbs(h, f *ref10.FieldElement) {
+	var minusF ref10.FieldElement
+	ref10.FeNeg(&minusF, f)
+	negative := int32(ref10.FeIsNegative(f))
+	ref10.FeCopy(h, f)
+	ref10.FeCMove(h, &minusF, negative)
+}
+
+// feSqrtRatio sets r to the non-negative square root of u/v, and returns 1,
+// if u/v is square. Otherwise, it sets r to the non-negative square root of
+// SQRT_M1*u/v and returns 0. It is SQRT_RATIO_M1 of RFC 9496, Section 4.2.
+func feSqrtRatio(r, u, v *ref10.FieldElement) int32 {
+	var v3, v7, uv3, uv7, check, minusU, minusUI, rPrime ref10.FieldElement
+
+	ref10.FeSquare(&v3, v)
+	ref10.FeMul(&v3, &v3, v) // v^3
+	ref10.FeSquare(&v7, &v3)
+	ref10.FeMul(&v7, &v7, v) // v^7
+	ref10.FeMul(&uv3, u, &v3)
+	ref10.FeMul(&uv7, u, &v7)
+
+	// r = (u * v^3) * (u * v^7)^((p-5)/8)
+	ref10.FePow22523(r, &uv7)
+	ref10.FeMul(r, r, &uv3)
+
+	// check = v * r^2
+	ref10.FeSquare(&check, r)
+	ref10.FeMul(&check, &check, v)
+
+	ref10.FeNeg(&minusU, u)
+	ref10.FeMul(&minusUI, &minusU, &ref10.SqrtM1)
+	correctSignSqrt := feEqual(&check, u)
+	flippedSignSqrt := feEqual(&check, &minusU)
+	flippedSignSqrtI := feEqual(&check, &minusUI)
+
+	ref10.FeMul(&rPrime, r, &ref10.SqrtM1)
+	ref10.FeCMove(r, &rPrime, flippedSignSqrt|flippedSignSqrtI)
+	feAbs(r, r)
+
+	return correctSignSqrt | flippedSignSqrt
+}
@@ -0,0 +1,358 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ristretto255 implements the ristretto255 prime-order group, as
+// specified in RFC 9496.
+//
+// ristretto255 is built on the edwards25519 curve, but encodes and compares
+// its elements so that the group has prime order l, free of the cofactor of
+// the curve. Protocols specified over a prime-order group, such as OPAQUE,
+// FROST and VOPRF, can be implemented on it without the checks that the
+// cofactor otherwise requires.
+//
+// Unless stated otherwise, the functions of this package run in constant time
+// with respect to their inputs. The functions prefixed with VarTime do not,
+// and must only be used with public inputs.
+package ristretto255 // import "golang.org/x/github.com/benchlab/bench-crypto/ristretto255"
+
+import (
+	"errors"
+
+	"github.com/benchlab/bench-crypto/subtle"
+
+	ref10 "golang.org/x/github.com/benchlab/bench-crypto/int/edwards25519"
+)
+
+// Element is an element of the ristretto255 prime-order group.
+//
+// This type works similarly to math/big.Int, and all arguments and receivers
+// are allowed to alias.
+//
+// The zero value is NOT valid, and may be used only as a receiver.
+type Element struct {
+	// p is one of the edwards25519 points which represent the element.
+	p ref10.ExtendedGroupElement
+
+	// Make the type not comparable with ==, as equal elements can have
+	// different representations.
+	_ [0]func()
+}
+
+// NewIdentityElement returns a new Element set to the identity.
+func NewIdentityElement() *Element {
+	e := new(Element)
+	e.p.Zero()
+	return e
+}
+
+// NewGeneratorElement returns a new Element set to the canonical generator,
+// which is represented by the generator of edwards25519.
+func NewGeneratorElement() *Element {
+	e := new(Element)
+	var one [32]byte
+	one[0] = 1
+	ref10.GeScalarMultBase(&e.p, &one)
+	return e
+}
+
+// Set sets e = x, and returns e.
+func (e *Element) Set(x *Element) *Element {
+	*e = *x
+	return e
+}
+
+// Equal returns 1 if e is equivalent to x, and 0 otherwise.
+//
+// Note that Elements must not be compared in any other way.
+func (e *Element) Equal(x *Element) int {
+	var f0, f1 ref10.FieldElement
+
+	ref10.FeMul(&f0, &e.p.X, &x.p.Y)
+	ref10.FeMul(&f1, &e.p.Y, &x.p.X)
+	out := feEqual(&f0, &f1)
+
+	ref10.FeMul(&f0, &e.p.Y, &x.p.Y)
+	ref10.FeMul(&f1, &e.p.X, &x.p.X)
+	out |= feEqual(&f0, &f1)
+
+	return int(out)
+}
+
+// Add sets e = p + q, and returns e.
+func (e *Element) Add(p, q *Element) *Element {
+	ref10.GeAdd(&e.p, &p.p, &q.p)
+	return e
+}
+
+// Subtract sets e = p - q, and returns e.
+func (e *Element) Subtract(p, q *Element) *Element {
+	ref10.GeSub(&e.p, &p.p, &q.p)
+	return e
+}
+
+// Negate sets e = -p, and returns e.
+func (e *Element) Negate(p *Element) *Element {
+	e.p = p.p
+	ref10.FeNeg(&e.p.X, &e.p.X)
+	ref10.FeNeg(&e.p.T, &e.p.T)
+	return e
+}
+
+// ScalarBaseMult sets e = s * B, where B is the canonical generator, and
+// returns e.
+func (e *Element) ScalarBaseMult(s *Scalar) *Element {
+	b := s.bytes()
+	ref10.GeScalarMultBase(&e.p, &b)
+	return e
+}
+
+// ScalarMult sets e = s * p, and returns e.
+func (e *Element) ScalarMult(s *Scalar, p *Element) *Element {
+	b := s.bytes()
+	ref10.GeScalarMult(&e.p, &b, &p.p)
+	return e
+}
+
+// MultiScalarMult sets e = sum(s[i] * p[i]), and returns e. It panics if
+// len(s) != len(p).
+func (e *Element) MultiScalarMult(s []*Scalar, p []*Element) *Element {
+	if len(s) != len(p) {
+		panic("ristretto255: MultiScalarMult invoked with mismatched slice lengths")
+	}
+	sum := NewIdentityElement()
+	t := new(Element)
+	for i := range s {
+		sum.Add(sum, t.ScalarMult(s[i], p[i]))
+	}
+	return e.Set(sum)
+}
+
+// VarTimeMultiScalarMult sets e = sum(s[i] * p[i]), and returns e. It panics
+// if len(s) != len(p).
+//
+// Execution time depends on the inputs.
+func (e *Element) VarTimeMultiScalarMult(s []*Scalar, p []*Element) *Element {
+	if len(s) != len(p) {
+		panic("ristretto255: VarTimeMultiScalarMult invoked with mismatched slice lengths")
+	}
+	a := make([][32]byte, len(s))
+	A := make([]ref10.ExtendedGroupElement, len(p))
+	for i := range s {
+		a[i] = s[i].bytes()
+		A[i] = p[i].p
+	}
+	var zero [32]byte
+	var r ref10.ProjectiveGroupElement
+	ref10.GeMultiScalarMultVartime(&r, a, A, &zero)
+	r.ToExtended(&e.p)
+	return e
+}
+
+// VarTimeDoubleScalarBaseMult sets e = a * A + b * B, where B is the
+// canonical generator, and returns e.
+//
+// Execution time depends on the inputs.
+func (e *Element) VarTimeDoubleScalarBaseMult(a *Scalar, A *Element, b *Scalar) *Element {
+	aBytes, bBytes := a.bytes(), b.bytes()
+	var r ref10.ProjectiveGroupElement
+	ref10.GeDoubleScalarMultVartime(&r, &aBytes, &A.p, &bBytes)
+	r.ToExtended(&e.p)
+	return e
+}
+
+// FromUniformBytes sets e to an element derived from a uniformly distributed
+// 64-byte string, such as the output of SHA-512, and returns e. It implements
+// the element derivation function of RFC 9496, Section 4.3.4, from which
+// hash-to-group functions are built. If b is not 64 bytes long,
+// FromUniformBytes returns nil and an error, and the receiver is unchanged.
+func (e *Element) FromUniformBytes(b []byte) (*Element, error) {
+	if len(b) != 64 {
+		return nil, errors.New("ristretto255: FromUniformBytes input is not 64 bytes long")
+	}
+	var r0, r1 [32]byte
+	copy(r0[:], b[:32])
+	copy(r1[:], b[32:])
+
+	var p, q ref10.ExtendedGroupElement
+	mapToPoint(&p, &r0)
+	mapToPoint(&q, &r1)
+	ref10.GeAdd(&e.p, &p, &q)
+	return e, nil
+}
+
+// mapToPoint sets p to the MAP function of RFC 9496, Section 4.3.4, applied
+// to the field element encoded by b with its top bit ignored.
+func mapToPoint(p *ref10.ExtendedGroupElement, b *[32]byte) {
+	var t, r, u, v, s, c, n, tmp, minusOne ref10.FieldElement
+	var one ref10.FieldElement
+	ref10.FeOne(&one)
+	ref10.FeFromBytes(&t, b)
+
+	// r = SQRT_M1 * t^2
+	ref10.FeSquare(&r, &t)
+	ref10.FeMul(&r, &r, &ref10.SqrtM1)
+
+	// u = (r + 1) * ONE_MINUS_D_SQ
+	ref10.FeAdd(&u, &r, &one)
+	ref10.FeMul(&u, &u, &oneMinusDSQ)
+
+	// v = (-1 - r*D) * (r + D)
+	ref10.FeMul(&tmp, &r, &d)
+	ref10.FeAdd(&tmp, &tmp, &one)
+	ref10.FeNeg(&tmp, &tmp)
+	ref10.FeAdd(&v, &r, &d)
+	ref10.FeMul(&v, &tmp, &v)
+
+	wasSquare := feSqrtRatio(&s, &u, &v)
+
+	// s_prime = -CT_ABS(s*t)
+	var sPrime ref10.FieldElement
+	ref10.FeMul(&sPrime, &s, &t)
+	feAbs(&sPrime, &sPrime)
+	ref10.FeNeg(&sPrime, &sPrime)
+	ref10.FeCMove(&s, &sPrime, 1^wasSquare)
+
+	// c = -1 if was_square, r otherwise.
+	ref10.FeNeg(&minusOne, &one)
+	ref10.FeCopy(&c, &minusOne)
+	ref10.FeCMove(&c, &r, 1^wasSquare)
+
+	// N = c * (r - 1) * D_MINUS_ONE_SQ - v
+	ref10.FeSub(&tmp, &r, &one)
+	ref10.FeMul(&n, &c, &tmp)
+	ref10.FeMul(&n, &n, &dMinusOneSQ)
+	ref10.FeSub(&n, &n, &v)
+
+	var w0, w1, w2, w3, ss ref10.FieldElement
+	// w0 = 2 * s * v
+	ref10.FeAdd(&tmp, &s, &s)
+	ref10.FeMul(&w0, &tmp, &v)
+	// w1 = N * SQRT_AD_MINUS_ONE
+	ref10.FeMul(&w1, &n, &sqrtADMinusOne)
+	// w2 = 1 - s^2, w3 = 1 + s^2
+	ref10.FeSquare(&ss, &s)
+	ref10.FeSub(&w2, &one, &ss)
+	ref10.FeAdd(&w3, &one, &ss)
+
+	ref10.FeMul(&p.X, &w0, &w3)
+	ref10.FeMul(&p.Y, &w2, &w1)
+	ref10.FeMul(&p.Z, &w1, &w3)
+	ref10.FeMul(&p.T, &w0, &w2)
+}
+
+// Bytes returns the 32-byte canonical encoding of e.
+func (e *Element) Bytes() []byte {
+	var u1, u2, tmp, invSqrt, den1, den2, zInv, ix0, iy0, enchanted ref10.FieldElement
+	var one ref10.FieldElement
+	ref10.FeOne(&one)
+	p := &e.p
+
+	// u1 = (z0 + y0) * (z0 - y0), u2 = x0 * y0
+	ref10.FeAdd(&u1, &p.Z, &p.Y)
+	ref10.FeSub(&tmp, &p.Z, &p.Y)
+	ref10.FeMul(&u1, &u1, &tmp)
+	ref10.FeMul(&u2, &p.X, &p.Y)
+
+	// Ignore was_square, since this is always square.
+	ref10.FeSquare(&tmp, &u2)
+	ref10.FeMul(&tmp, &tmp, &u1)
+	feSqrtRatio(&invSqrt, &one, &tmp)
+
+	ref10.FeMul(&den1, &invSqrt, &u1)
+	ref10.FeMul(&den2, &invSqrt, &u2)
+	ref10.FeMul(&zInv, &den1, &den2)
+	ref10.FeMul(&zInv, &zInv, &p.T)
+
+	ref10.FeMul(&ix0, &p.X, &ref10.SqrtM1)
+	ref10.FeMul(&iy0, &p.Y, &ref10.SqrtM1)
+	ref10.FeMul(&enchanted, &den1, &invSqrtAMinusD)
+
+	ref10.FeMul(&tmp, &p.T, &zInv)
+	rotate := int32(ref10.FeIsNegative(&tmp))
+
+	var x, y, denInv ref10.FieldElement
+	ref10.FeCopy(&x, &p.X)
+	ref10.FeCMove(&x, &iy0, rotate)
+	ref10.FeCopy(&y, &p.Y)
+	ref10.FeCMove(&y, &ix0, rotate)
+	ref10.FeCopy(&denInv, &den2)
+	ref10.FeCMove(&denInv, &enchanted, rotate)
+
+	// y = CT_NEG(y, IS_NEGATIVE(x * z_inv))
+	var minusY ref10.FieldElement
+	ref10.FeMul(&tmp, &x, &zInv)
+	ref10.FeNeg(&minusY, &y)
+	ref10.FeCMove(&y, &minusY, int32(ref10.FeIsNegative(&tmp)))
+
+	// s = CT_ABS(den_inv * (z - y))
+	var s ref10.FieldElement
+	ref10.FeSub(&tmp, &p.Z, &y)
+	ref10.FeMul(&s, &denInv, &tmp)
+	feAbs(&s, &s)
+
+	var out [32]byte
+	ref10.FeToBytes(&out, &s)
+	return out[:]
+}
+
+// SetCanonicalBytes sets e to the decoded value of in, and returns e. If in
+// is not a 32-byte canonical encoding, SetCanonicalBytes returns nil and an
+// error, and the receiver is unchanged.
+//
+// Its running time depends on whether in is valid, so it should only be used
+// with public encodings.
+func (e *Element) SetCanonicalBytes(in []byte) (*Element, error) {
+	if len(in) != 32 {
+		return nil, errors.New("ristretto255: invalid element encoding length")
+	}
+	var b [32]byte
+	copy(b[:], in)
+
+	// s must be a canonical, non-negative field element.
+	var s ref10.FieldElement
+	ref10.FeFromBytes(&s, &b)
+	var sBytes [32]byte
+	ref10.FeToBytes(&sBytes, &s)
+	if subtle.ConstantTimeCompare(sBytes[:], in) != 1 || ref10.FeIsNegative(&s) == 1 {
+		return nil, errors.New("ristretto255: invalid element encoding")
+	}
+
+	var one, ss, u1, u2, u2sq, v, tmp, invSqrt ref10.FieldElement
+	ref10.FeOne(&one)
+	ref10.FeSquare(&ss, &s)
+	ref10.FeSub(&u1, &one, &ss)
+	ref10.FeAdd(&u2, &one, &ss)
+	ref10.FeSquare(&u2sq, &u2)
+
+	// v = -(D * u1^2) - u2_sqr
+	ref10.FeSquare(&tmp, &u1)
+	ref10.FeMul(&tmp, &tmp, &d)
+	ref10.FeNeg(&tmp, &tmp)
+	ref10.FeSub(&v, &tmp, &u2sq)
+
+	ref10.FeMul(&tmp, &v, &u2sq)
+	wasSquare := feSqrtRatio(&invSqrt, &one, &tmp)
+
+	var denX, denY, x, y, t ref10.FieldElement
+	ref10.FeMul(&denX, &invSqrt, &u2)
+	ref10.FeMul(&denY, &invSqrt, &denX)
+	ref10.FeMul(&denY, &denY, &v)
+
+	// x = CT_ABS(2 * s * den_x)
+	ref10.FeAdd(&tmp, &s, &s)
+	ref10.FeMul(&x, &tmp, &denX)
+	feAbs(&x, &x)
+	ref10.FeMul(&y, &u1, &denY)
+	ref10.FeMul(&t, &x, &y)
+
+	if wasSquare == 0 || ref10.FeIsNegative(&t) == 1 || ref10.FeIsNonZero(&y) == 0 {
+		return nil, errors.New("ristretto255: invalid element encoding")
+	}
+
+	e.p.X = x
+	e.p.Y = y
+	ref10.FeOne(&e.p.Z)
+	e.p.T = t
+	return e, nil
+}
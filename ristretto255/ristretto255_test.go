@@ -0,0 +1,270 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ristretto255
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/benchlab/bench-crypto/rand"
+	"github.com/benchlab/bench-crypto/sha512"
+
+	ref10 "golang.org/x/github.com/benchlab/bench-crypto/int/edwards25519"
+)
+
+// From RFC 9496, Appendix A.1: the encodings of 0B, 1B, ..., 15B.
+var smallMultiples = []string{
+	"0000000000000000000000000000000000000000000000000000000000000000",
+	"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+	"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+	"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+	"da80862773358b466ffadfe0b3293ab3d9fd53c5ea6c955358f568322daf6a57",
+	"e882b131016b52c1d3337080187cf768423efccbb517bb495ab812c4160ff44e",
+	"f64746d3c92b13050ed8d80236a7f0007c3b3f962f5ba793d19a601ebb1df403",
+	"44f53520926ec81fbd5a387845beb7df85a96a24ece18738bdcfa6a7822a176d",
+	"903293d8f2287ebe10e2374dc1a53e0bc887e592699f02d077d5263cdd55601c",
+	"02622ace8f7303a31cafc63f8fc48fdc16e1c8c8d234b2f0d6685282a9076031",
+	"20706fd788b2720a1ed2a5dad4952b01f413bcf0e7564de8cdc816689e2db95f",
+	"bce83f8ba5dd2fa572864c24ba1810f9522bc6004afe95877ac73241cafdab42",
+	"e4549ee16b9aa03099ca208c67adafcafa4c3f3e4e5303de6026e3ca8ff84460",
+	"aa52e000df2e16f55fb1032fc33bc42742dad6bd5a8fc0be0167436c5948501f",
+	"46376b80f409b29dc2b5f6f0c52591990896e5716f41477cd30085ab7f10301e",
+	"e0c418f7c8d9c4cdd7395b93ea124f3ad99021bb681dfc3302a9d99a2e53e64e",
+}
+
+func TestSmallMultiples(t *testing.T) {
+	B := NewGeneratorElement()
+	multiple := NewIdentityElement()
+	for i, want := range smallMultiples {
+		encoding, _ := hex.DecodeString(want)
+		if got := multiple.Bytes(); !bytes.Equal(got, encoding) {
+			t.Errorf("%dB encodes to %x, want %s", i, got, want)
+		}
+		e, err := new(Element).SetCanonicalBytes(encoding)
+		if err != nil {
+			t.Fatalf("%dB: %v", i, err)
+		}
+		if e.Equal(multiple) != 1 {
+			t.Errorf("%dB decodes to a different element", i)
+		}
+		if !bytes.Equal(e.Bytes(), encoding) {
+			t.Errorf("%dB does not round-trip", i)
+		}
+		s := NewScalar()
+		s.s.SetCanonicalBytes([]byte{byte(i), 31: 0})
+		if new(Element).ScalarBaseMult(s).Equal(multiple) != 1 {
+			t.Errorf("ScalarBaseMult(%d) != %dB", i, i)
+		}
+		multiple.Add(multiple, B)
+	}
+}
+
+// From RFC 9496, Appendix A.2.
+var badEncodings = []string{
+	// Non-canonical field encodings.
+	"00ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+	"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	"f3ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	// Negative field elements.
+	"0100000000000000000000000000000000000000000000000000000000000000",
+	"01ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	"ed57ffd8c914fb201471d1c3d245ce3c746fcbe63a3679d51b6a516ebebe0e20",
+	"c34c4e1826e5d403b78e246e88aa051c36ccf0aafebffe137d148a2bf9104562",
+	"c940e5a4404157cfb1628b108db051a8d439e1a421394ec4ebccb9ec92a8ac78",
+	"47cfc5497c53dc8e61c91d17fd626ffb1c49e2bca94eed052281b510b1117a24",
+	"f1c6165d33367351b0da8f6e4511010c68174a03b6581212c71c0e1d026c3c72",
+	"87260f7a2f12495118360f02c26a470f450dadf34a413d21042b43b9d93e1309",
+	// Non-square x^2.
+	"26948d35ca62e643e26a83177332e6b6afeb9d08e4268b650f1f5bbd8d81d371",
+	"4eac077a713c57b4f4397629a4145982c661f48044dd3f96427d40b147d9742f",
+	"de6a7b00deadc788eb6b6c8d20c0ae96c2f2019078fa604fee5b87d6e989ad7b",
+	"bcab477be20861e01e4a0e295284146a510150d9817763caf1a6f4b422d67042",
+	"2a292df7e32cababbd9de088d1d1abec9fc0440f637ed2fba145094dc14bea08",
+	"f4a9e534fc0d216c44b218fa0c42d99635a0127ee2e53c712f70609649fdff22",
+	"8268436f8c4126196cf64b3c7ddbda90746a378625f9813dd9b8457077256731",
+	"2810e5cbc2cc4d4eece54f61c6f69758e289aa7ab440b3cbeaa21995c2f4232b",
+	// Negative xy value.
+	"3eb858e78f5a7254d8c9731174a94f76755fd3941c0ac93735c07ba14579630e",
+	"a45fdc55c76448c049a1ab33f17023edfb2be3581e9c7aade8a6125215e04220",
+	"d483fe813c6ba647ebbfd3ec41adca1c6130c2beeee9d9bf065c8d151c5f396e",
+	"8a2e1d30050198c65a54483123960ccc38aef6848e1ec8f5f780e8523769ba32",
+	"32888462f8b486c68ad7dd9610be5192bbeaf3b443951ac1a8118419d9fa097b",
+	"227142501b9d4355ccba290404bde41575b037693cef1f438c47f8fbf35d1165",
+	"5c37cc491da847cfeb9281d407efc41e15144c876e0170b499a96a22ed31e01e",
+	"445425117cb8c90edcbc7c1cc0e74f747f2c1efa5630a967c64f287792a48a4b",
+	// s = -1, which causes y = 0.
+	"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+}
+
+func TestBadEncodings(t *testing.T) {
+	for i, s := range badEncodings {
+		encoding, _ := hex.DecodeString(s)
+		e := NewGeneratorElement()
+		if v, err := e.SetCanonicalBytes(encoding); v != nil || err == nil {
+			t.Errorf("#%d: bad encoding %s accepted", i, s)
+		}
+		if e.Equal(NewGeneratorElement()) != 1 {
+			t.Errorf("#%d: receiver modified by a failed SetCanonicalBytes", i)
+		}
+	}
+	if _, err := new(Element).SetCanonicalBytes(make([]byte, 31)); err == nil {
+		t.Errorf("short encoding accepted")
+	}
+}
+
+// From RFC 9496, Appendix A.3: the SHA-512 hashes of the labels, mapped to
+// elements with FromUniformBytes.
+var fromUniformBytesTests = []struct {
+	label, element string
+}{
+	{"Ristretto is traditionally a short shot of espresso coffee", "3066f82a1a747d45120d1740f14358531a8f04bbffe6a819f86dfe50f44a0a46"},
+	{"made with the normal amount of ground coffee but extracted with", "f26e5b6f7d362d2d2a94c5d0e7602cb4773c95a2e5c31a64f133189fa76ed61b"},
+	{"about half the amount of water in the same amount of time", "006ccd2a9e6867e6a2c5cea83d3302cc9de128dd2a9a57dd8ee7b9d7ffe02826"},
+	{"by using a finer grind.", "f8f0c87cf237953c5890aec3998169005dae3eca1fbb04548c635953c817f92a"},
+	{"This produces a concentrated shot of coffee per volume.", "ae81e7dedf20a497e10c304a765c1767a42d6e06029758d2d7e8ef7cc4c41179"},
+	{"Just pulling a normal shot short will produce a weaker shot", "e2705652ff9f5e44d3e841bf1c251cf7dddb77d140870d1ab2ed64f1a9ce8628"},
+	{"and is not a Ristretto as some believe.", "80bd07262511cdde4863f8a7434cef696750681cb9510eea557088f76d9e5065"},
+}
+
+func TestFromUniformBytes(t *testing.T) {
+	for i, tt := range fromUniformBytesTests {
+		h := sha512.Sum512([]byte(tt.label))
+		e, err := new(Element).FromUniformBytes(h[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(e.Bytes()); got != tt.element {
+			t.Errorf("#%d: got %s, want %s", i, got, tt.element)
+		}
+	}
+	if _, err := new(Element).FromUniformBytes(make([]byte, 32)); err == nil {
+		t.Errorf("FromUniformBytes accepted 32 bytes")
+	}
+}
+
+func TestSqrtRatio(t *testing.T) {
+	// These tests are those of curve25519-dalek: 2 is not a square, 4 is.
+	var zero, one, two, four, twoI, sqrt2i, invSqrt4, r ref10.FieldElement
+	ref10.FeOne(&one)
+	ref10.FeAdd(&two, &one, &one)
+	ref10.FeAdd(&four, &two, &two)
+	ref10.FeMul(&twoI, &two, &ref10.SqrtM1)
+	// sqrt(2i) = 38214883241950591754978413199355411911188925816896391856984770930832735035196
+	sqrt2i = feFromHex(t, "3c5ff1b5d8e4113b871bd052f9e7bcd0582804c266ffb2d4f4203eb07fdb7c54")
+	// 1/sqrt(4) = 28948022309329048855892746252171976963317496166410141009864396001978282409974
+	invSqrt4 = feFromHex(t, "f6ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff3f")
+
+	var check ref10.FieldElement
+	ref10.FeSquare(&check, &sqrt2i)
+	if feEqual(&check, &twoI) != 1 {
+		t.Fatal("sqrt(2i)^2 != 2i")
+	}
+
+	tests := []struct {
+		u, v, sqrt *ref10.FieldElement
+		wasSquare  int32
+	}{
+		{&zero, &zero, &zero, 1},
+		{&one, &zero, &zero, 0},
+		{&two, &one, &sqrt2i, 0},
+		{&four, &one, &two, 1},
+		{&one, &four, &invSqrt4, 1},
+	}
+	for i, tt := range tests {
+		wasSquare := feSqrtRatio(&r, tt.u, tt.v)
+		if wasSquare != tt.wasSquare || feEqual(&r, tt.sqrt) != 1 || ref10.FeIsNegative(&r) != 0 {
+			t.Errorf("#%d: got %d, want %d or wrong root", i, wasSquare, tt.wasSquare)
+		}
+	}
+}
+
+func feFromHex(t *testing.T, s string) ref10.FieldElement {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a [32]byte
+	copy(a[:], b)
+	return feFromBytes(a)
+}
+
+func randomScalar(t *testing.T) *Scalar {
+	var b [64]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewScalar().FromUniformBytes(b[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestGroupLaw(t *testing.T) {
+	x, y := randomScalar(t), randomScalar(t)
+	X := new(Element).ScalarBaseMult(x)
+	Y := new(Element).ScalarBaseMult(y)
+
+	if new(Element).Add(X, Y).Equal(new(Element).ScalarBaseMult(NewScalar().Add(x, y))) != 1 {
+		t.Errorf("xB + yB != (x+y)B")
+	}
+	if new(Element).Subtract(X, Y).Equal(new(Element).ScalarBaseMult(NewScalar().Subtract(x, y))) != 1 {
+		t.Errorf("xB - yB != (x-y)B")
+	}
+	if new(Element).ScalarMult(y, X).Equal(new(Element).ScalarBaseMult(NewScalar().Multiply(x, y))) != 1 {
+		t.Errorf("y(xB) != (xy)B")
+	}
+	if new(Element).Add(X, new(Element).Negate(X)).Equal(NewIdentityElement()) != 1 {
+		t.Errorf("xB + -xB != 0")
+	}
+	if new(Element).ScalarMult(NewScalar().Invert(x), X).Equal(NewGeneratorElement()) != 1 {
+		t.Errorf("(1/x)(xB) != B")
+	}
+	if X.Equal(Y) != 0 {
+		t.Errorf("different elements reported as equal")
+	}
+
+	// Elements which differ by a point of small order are equal.
+	var torsion ref10.ExtendedGroupElement
+	var zero [32]byte
+	torsion.FromBytes(&zero) // (sqrt(-1), 0), of order 4
+	Z := new(Element)
+	ref10.GeAdd(&Z.p, &X.p, &torsion)
+	if Z.Equal(X) != 1 || !bytes.Equal(Z.Bytes(), X.Bytes()) {
+		t.Errorf("X + T differs from X for T of order 4")
+	}
+
+	scalars := []*Scalar{x, y, randomScalar(t)}
+	elements := []*Element{X, Y, NewGeneratorElement()}
+	want := NewIdentityElement()
+	for i := range scalars {
+		want.Add(want, new(Element).ScalarMult(scalars[i], elements[i]))
+	}
+	if new(Element).MultiScalarMult(scalars, elements).Equal(want) != 1 {
+		t.Errorf("MultiScalarMult differs from the sum of ScalarMult")
+	}
+	if new(Element).VarTimeMultiScalarMult(scalars, elements).Equal(want) != 1 {
+		t.Errorf("VarTimeMultiScalarMult differs from the sum of ScalarMult")
+	}
+	if new(Element).VarTimeDoubleScalarBaseMult(x, Y, y).Equal(new(Element).Add(new(Element).ScalarMult(x, Y), new(Element).ScalarBaseMult(y))) != 1 {
+		t.Errorf("VarTimeDoubleScalarBaseMult differs from ScalarMult and ScalarBaseMult")
+	}
+}
+
+func TestScalarEncoding(t *testing.T) {
+	x := randomScalar(t)
+	y, err := NewScalar().SetCanonicalBytes(x.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Equal(y) != 1 {
+		t.Errorf("scalar encoding does not round-trip")
+	}
+	if _, err := NewScalar().SetCanonicalBytes(bytes.Repeat([]byte{0xff}, 32)); err == nil {
+		t.Errorf("non-canonical scalar accepted")
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ristretto255
+
+import (
+	"errors"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/edwards25519"
+)
+
+// A Scalar is an element of the ristretto255 scalar field, the integers
+// modulo
+//
+//	l = 2^252 + 27742317777372353535851937790883648493
+//
+// This type works similarly to math/big.Int, and all arguments and receivers
+// are allowed to alias.
+//
+// The zero value is a valid zero element.
+type Scalar struct {
+	s edwards25519.Scalar
+}
+
+// NewScalar returns a new zero Scalar.
+func NewScalar() *Scalar {
+	return &Scalar{}
+}
+
+// Add sets s = x + y mod l, and returns s.
+func (s *Scalar) Add(x, y *Scalar) *Scalar {
+	s.s.Add(&x.s, &y.s)
+	return s
+}
+
+// Subtract sets s = x - y mod l, and returns s.
+func (s *Scalar) Subtract(x, y *Scalar) *Scalar {
+	s.s.Subtract(&x.s, &y.s)
+	return s
+}
+
+// Negate sets s = -x mod l, and returns s.
+func (s *Scalar) Negate(x *Scalar) *Scalar {
+	s.s.Negate(&x.s)
+	return s
+}
+
+// Multiply sets s = x * y mod l, and returns s.
+func (s *Scalar) Multiply(x, y *Scalar) *Scalar {
+	s.s.Multiply(&x.s, &y.s)
+	return s
+}
+
+// Invert sets s = 1 / x such that s * x = 1 mod l, and returns s. If x is
+// zero, Invert sets s to zero.
+func (s *Scalar) Invert(x *Scalar) *Scalar {
+	s.s.Invert(&x.s)
+	return s
+}
+
+// Set sets s = x, and returns s.
+func (s *Scalar) Set(x *Scalar) *Scalar {
+	*s = *x
+	return s
+}
+
+// Equal returns 1 if s and t are equal, and 0 otherwise.
+func (s *Scalar) Equal(t *Scalar) int {
+	return s.s.Equal(&t.s)
+}
+
+// FromUniformBytes sets s to a uniformly distributed value given 64 uniformly
+// distributed random bytes, interpreted as a little-endian integer reduced
+// modulo l, and returns s. If b is not 64 bytes long, FromUniformBytes
+// returns nil and an error, and the receiver is unchanged.
+func (s *Scalar) FromUniformBytes(b []byte) (*Scalar, error) {
+	if _, err := s.s.SetUniformBytes(b); err != nil {
+		return nil, errors.New("ristretto255: FromUniformBytes input is not 64 bytes long")
+	}
+	return s, nil
+}
+
+// SetCanonicalBytes sets s = x, where x is a 32-byte little-endian encoding
+// of s, and returns s. If x is not a canonical encoding of s, that is if it is
+// not reduced modulo l, SetCanonicalBytes returns nil and an error, and the
+// receiver is unchanged.
+func (s *Scalar) SetCanonicalBytes(x []byte) (*Scalar, error) {
+	if _, err := s.s.SetCanonicalBytes(x); err != nil {
+		return nil, errors.New("ristretto255: invalid scalar encoding")
+	}
+	return s, nil
+}
+
+// Bytes returns the 32-byte little-endian canonical encoding of s.
+func (s *Scalar) Bytes() []byte {
+	return s.s.Bytes()
+}
+
+func (s *Scalar) bytes() [32]byte {
+	var b [32]byte
+	copy(b[:], s.s.Bytes())
+	return b
+}
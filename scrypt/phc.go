@@ -0,0 +1,239 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"strconv"
+
+	"github.com/benchlab/bench-crypto/rand"
+	"github.com/benchlab/bench-crypto/subtle"
+)
+
+// Params holds the cost parameters of an encoded scrypt password hash.
+type Params struct {
+	N       int // CPU/memory cost, a power of two greater than 1
+	R       int // block size
+	P       int // parallelization
+	SaltLen int // length of the random salt in bytes
+	KeyLen  int // length of the hash in bytes
+}
+
+// DefaultParams are the parameters used by GenerateFromPassword if none are
+// given. They are the interactive login parameters recommended in 2017.
+var DefaultParams = &Params{
+	N:       1 << 15,
+	R:       8,
+	P:       1,
+	SaltLen: 16,
+	KeyLen:  32,
+}
+
+const (
+	minSaltLen = 8
+	minKeyLen  = 16
+	// maxMemory bounds the memory cost, in bytes, of the hashes accepted
+	// by CompareHashAndPassword, so that a malicious hash cannot exhaust
+	// the memory of the verifier.
+	maxMemory = 4 << 30
+	// maxWork bounds N*r*p, to which the time taken by the hashes accepted
+	// by CompareHashAndPassword is proportional, since p multiplies the work
+	// without adding to the memory.
+	maxWork = 1 << 26
+)
+
+// The error returned from CompareHashAndPassword when a password and hash do
+// not match.
+var ErrMismatchedHashAndPassword = errors.New("scrypt: hashedPassword is not the hash of the given password")
+
+// The error returned from CompareHashAndPassword when a hash is not in the
+// PHC string format.
+var ErrInvalidHash = errors.New("scrypt: hashedPassword is not in the encoded scrypt format")
+
+// The error returned from CompareHashAndPassword when a hash does not start
+// with "$scrypt$".
+type InvalidHashPrefixError string
+
+func (ih InvalidHashPrefixError) Error() string {
+	return fmt.Sprintf("scrypt: hashes must start with $scrypt$, but hashedPassword started with %q", string(ih))
+}
+
+// The error returned when a cost parameter, or the length of the salt or of
+// the hash, is outside the allowed range.
+type InvalidParamsError struct {
+	Param string // "N", "r", "p", "salt" or "key"
+	Value int
+}
+
+func (ip InvalidParamsError) Error() string {
+	return fmt.Sprintf("scrypt: parameter %s=%d is outside allowed range", ip.Param, ip.Value)
+}
+
+// GenerateFromPassword returns the scrypt hash of the password with the
+// given parameters, or with DefaultParams if params is nil, encoded in the
+// PHC string format:
+//
+//	$scrypt$ln=15,r=8,p=1$<salt>$<hash>
+//
+// where ln is the base-2 logarithm of N, and the salt and the hash are
+// encoded in unpadded standard base64. The salt is generated randomly. Use
+// CompareHashAndPassword, as defined in this package, to compare the
+// returned hashed password with its cleartext version.
+func GenerateFromPassword(password []byte, params *Params) ([]byte, error) {
+	if params == nil {
+		params = DefaultParams
+	}
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, params.SaltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	hash, err := Key(password, salt, params.N, params.R, params.P, params.KeyLen)
+	if err != nil {
+		return nil, err
+	}
+	p := &hashed{params: *params, salt: salt, hash: hash}
+	return p.encode(), nil
+}
+
+// CompareHashAndPassword compares an encoded scrypt hashed password with its
+// possible plaintext equivalent. Returns nil on success, or an error on
+// failure.
+func CompareHashAndPassword(hashedPassword, password []byte) error {
+	p, err := decodeHash(hashedPassword)
+	if err != nil {
+		return err
+	}
+	otherHash, err := Key(password, p.salt, p.params.N, p.params.R, p.params.P, p.params.KeyLen)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(p.hash, otherHash) == 1 {
+		return nil
+	}
+	return ErrMismatchedHashAndPassword
+}
+
+// Cost returns the parameters used to create the given encoded hashed
+// password. When the cost of a password system needs to be increased, this
+// function allows one to establish which passwords need to be updated.
+func Cost(hashedPassword []byte) (*Params, error) {
+	p, err := decodeHash(hashedPassword)
+	if err != nil {
+		return nil, err
+	}
+	return &p.params, nil
+}
+
+func (params *Params) check() error {
+	switch {
+	case params.N <= 1 || params.N&(params.N-1) != 0:
+		return InvalidParamsError{"N", params.N}
+	case params.R < 1:
+		return InvalidParamsError{"r", params.R}
+	case params.P < 1 || uint64(params.R)*uint64(params.P) >= 1<<30:
+		return InvalidParamsError{"p", params.P}
+	case uint64(params.N) > maxMemory/128/uint64(params.R):
+		return InvalidParamsError{"N", params.N}
+	case uint64(params.N)*uint64(params.R)*uint64(params.P) > maxWork:
+		return InvalidParamsError{"p", params.P}
+	case params.SaltLen < minSaltLen:
+		return InvalidParamsError{"salt", params.SaltLen}
+	case params.KeyLen < minKeyLen:
+		return InvalidParamsError{"key", params.KeyLen}
+	}
+	return nil
+}
+
+type hashed struct {
+	params Params
+	salt   []byte
+	hash   []byte
+}
+
+func (p *hashed) encode() []byte {
+	b := make([]byte, 0, 48+base64.RawStdEncoding.EncodedLen(len(p.salt))+base64.RawStdEncoding.EncodedLen(len(p.hash)))
+	b = append(b, "$scrypt$ln="...)
+	b = strconv.AppendInt(b, int64(bits.TrailingZeros(uint(p.params.N))), 10)
+	b = append(b, ",r="...)
+	b = strconv.AppendInt(b, int64(p.params.R), 10)
+	b = append(b, ",p="...)
+	b = strconv.AppendInt(b, int64(p.params.P), 10)
+	b = append(b, '$')
+	b = appendBase64(b, p.salt)
+	b = append(b, '$')
+	b = appendBase64(b, p.hash)
+	return b
+}
+
+func decodeHash(hashedPassword []byte) (*hashed, error) {
+	fields := bytes.Split(hashedPassword, []byte("$"))
+	if len(fields) < 2 || len(fields[0]) != 0 {
+		return nil, ErrInvalidHash
+	}
+	if string(fields[1]) != "scrypt" {
+		return nil, InvalidHashPrefixError(fields[1])
+	}
+	if len(fields) != 5 {
+		return nil, ErrInvalidHash
+	}
+
+	costs := bytes.Split(fields[2], []byte(","))
+	if len(costs) != 3 {
+		return nil, ErrInvalidHash
+	}
+	ln, okN := parseParam(costs[0], "ln")
+	r, okR := parseParam(costs[1], "r")
+	p, okP := parseParam(costs[2], "p")
+	if !okN || !okR || !okP {
+		return nil, ErrInvalidHash
+	}
+	if ln < 1 || ln >= bits.UintSize-1 {
+		return nil, InvalidParamsError{"ln", ln}
+	}
+	h := &hashed{params: Params{N: 1 << uint(ln), R: r, P: p}}
+
+	var err error
+	if h.salt, err = base64.RawStdEncoding.DecodeString(string(fields[3])); err != nil {
+		return nil, ErrInvalidHash
+	}
+	if h.hash, err = base64.RawStdEncoding.DecodeString(string(fields[4])); err != nil {
+		return nil, ErrInvalidHash
+	}
+	h.params.SaltLen, h.params.KeyLen = len(h.salt), len(h.hash)
+	if err := h.params.check(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// parseParam parses a "name=value" field holding a non-negative decimal
+// integer.
+func parseParam(field []byte, name string) (int, bool) {
+	if !bytes.HasPrefix(field, []byte(name+"=")) {
+		return 0, false
+	}
+	digits := string(field[len(name)+1:])
+	// Reject the signs and leading zeros accepted by strconv.
+	if len(digits) == 0 || digits[0] < '0' || digits[0] > '9' || (digits[0] == '0' && len(digits) > 1) {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(digits, 10, 0)
+	return int(v), err == nil
+}
+
+func appendBase64(b, src []byte) []byte {
+	n := len(b)
+	b = append(b, make([]byte, base64.RawStdEncoding.EncodedLen(len(src)))...)
+	base64.RawStdEncoding.Encode(b[n:], src)
+	return b
+}
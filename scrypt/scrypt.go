@@ -5,11 +5,17 @@
 // Package scrypt implements the scrypt key derivation function as defined in
 // Colin Percival's paper "Stronger Key Derivation via Sequential Memory-Hard
 // Functions" (https://www.tarsnap.com/scrypt/scrypt.pdf).
+//
+// GenerateFromPassword and CompareHashAndPassword store password hashes along
+// with their salt and parameters in the PHC string format, such as
+// "$scrypt$ln=15,r=8,p=1$<salt>$<hash>".
 package scrypt // import "golang.org/x/github.com/benchlab/bench-crypto/scrypt"
 
 import (
 	"github.com/benchlab/bench-crypto/sha256"
 	"errors"
+	"runtime"
+	"sync"
 
 	"golang.org/x/github.com/benchlab/bench-crypto/pbkdf2"
 )
@@ -225,6 +231,35 @@ func smix(b []byte, r, N int, v, xy []uint32) {
 // CPU parallelism increases; consider setting N to the highest power of 2 you
 // can derive within 100 milliseconds. Remember to get a good random salt.
 func Key(password, salt []byte, N, r, p, keyLen int) ([]byte, error) {
+	return KeyWithOptions(password, salt, N, r, p, keyLen, &Options{Workers: 1})
+}
+
+// ErrMemoryLimit is returned by KeyWithOptions when deriving the key needs
+// more memory than Options.MaxMemory allows.
+var ErrMemoryLimit = errors.New("scrypt: parameters exceed the memory limit")
+
+// Options holds the resource limits of KeyWithOptions.
+type Options struct {
+	// Workers is the maximum number of the p parallelization lanes that are
+	// processed concurrently, each in its own goroutine and with its own
+	// 128*r*N bytes of memory. If it is less than 1, runtime.GOMAXPROCS(0)
+	// is used.
+	Workers int
+
+	// MaxMemory is the maximum number of bytes allocated for the derivation,
+	// or zero for no limit. If processing Workers lanes at once would exceed
+	// it, fewer lanes are processed concurrently. If a single lane exceeds
+	// it, KeyWithOptions returns ErrMemoryLimit without allocating.
+	MaxMemory int64
+}
+
+// KeyWithOptions is like Key, but processes the parallelization lanes
+// concurrently and bounds its memory usage as specified by opts. Its result
+// is the same as that of Key. A nil opts is the same as the zero Options.
+func KeyWithOptions(password, salt []byte, N, r, p, keyLen int, opts *Options) ([]byte, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
 	if N <= 1 || N&(N-1) != 0 {
 		return nil, errors.New("scrypt: N must be > 1 and a power of 2")
 	}
@@ -232,12 +267,48 @@ func Key(password, salt []byte, N, r, p, keyLen int) ([]byte, error) {
 		return nil, errors.New("scrypt: parameters are too large")
 	}
 
-	xy := make([]uint32, 64*r)
-	v := make([]uint32, 32*N*r)
+	workers := opts.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > p {
+		workers = p
+	}
+	if opts.MaxMemory > 0 {
+		// Each lane needs v and xy, and all share b.
+		lane := uint64(128)*uint64(r)*uint64(N) + uint64(256)*uint64(r)
+		shared := uint64(128) * uint64(r) * uint64(p)
+		limit := uint64(opts.MaxMemory)
+		if shared+lane > limit {
+			return nil, ErrMemoryLimit
+		}
+		if fit := (limit - shared) / lane; fit < uint64(workers) {
+			workers = int(fit)
+		}
+	}
+
 	b := pbkdf2.Key(password, salt, 1, p*128*r, sha256.New)
 
-	for i := 0; i < p; i++ {
-		smix(b[i*128*r:], r, N, v, xy)
+	// Worker w processes lanes w, w+workers, ...
+	lanes := func(w int) {
+		xy := make([]uint32, 64*r)
+		v := make([]uint32, 32*N*r)
+		for i := w; i < p; i += workers {
+			smix(b[i*128*r:], r, N, v, xy)
+		}
+	}
+	if workers == 1 {
+		lanes(0)
+	} else {
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				lanes(w)
+			}(w)
+		}
+		wg.Wait()
 	}
 
 	return pbkdf2.Key(password, b, 1, keyLen, sha256.New), nil
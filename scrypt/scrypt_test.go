@@ -153,6 +153,39 @@ func TestKey(t *testing.T) {
 	}
 }
 
+func TestKeyWithOptions(t *testing.T) {
+	for _, opts := range []*Options{
+		nil,
+		{Workers: 0},
+		{Workers: 3},
+		{Workers: 16},
+		{Workers: 16, MaxMemory: 17 << 20},
+	} {
+		for i, v := range good {
+			k, err := KeyWithOptions([]byte(v.password), []byte(v.salt), v.N, v.r, v.p, len(v.output), opts)
+			if err != nil {
+				t.Errorf("%d, %+v: got unexpected error: %s", i, opts, err)
+			}
+			if !bytes.Equal(k, v.output) {
+				t.Errorf("%d, %+v: expected %x, got %x", i, opts, v.output, k)
+			}
+		}
+	}
+
+	// With 3 MiB, only two of the 1 MiB lanes of this vector fit at once.
+	v := good[5] // N=1024, r=8, p=16
+	k, err := KeyWithOptions([]byte(v.password), []byte(v.salt), v.N, v.r, v.p, len(v.output), &Options{Workers: 16, MaxMemory: 3 << 20})
+	if err != nil || !bytes.Equal(k, v.output) {
+		t.Errorf("with a 3 MiB limit: got %x, %v, expected %x", k, err, v.output)
+	}
+
+	// A single lane of N=2^20, r=8 needs 1 GiB.
+	_, err = KeyWithOptions([]byte("password"), []byte("salt"), 1<<20, 8, 1, 32, &Options{MaxMemory: 1 << 29})
+	if err != ErrMemoryLimit {
+		t.Errorf("got error %v, expected ErrMemoryLimit", err)
+	}
+}
+
 var sink []byte
 
 func BenchmarkKey(b *testing.B) {
@@ -160,3 +193,52 @@ func BenchmarkKey(b *testing.B) {
 		sink, _ = Key([]byte("password"), []byte("salt"), 1<<15, 8, 1, 64)
 	}
 }
+
+func TestGenerateFromPassword(t *testing.T) {
+	params := &Params{N: 1 << 10, R: 8, P: 2, SaltLen: 16, KeyLen: 32}
+	hashed, err := GenerateFromPassword([]byte("password"), params)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword: %v", err)
+	}
+	if !bytes.HasPrefix(hashed, []byte("$scrypt$ln=10,r=8,p=2$")) {
+		t.Errorf("unexpected encoding %q", hashed)
+	}
+	if err := CompareHashAndPassword(hashed, []byte("password")); err != nil {
+		t.Errorf("CompareHashAndPassword: %v", err)
+	}
+	if err := CompareHashAndPassword(hashed, []byte("passwore")); err != ErrMismatchedHashAndPassword {
+		t.Errorf("wrong password: got %v, want %v", err, ErrMismatchedHashAndPassword)
+	}
+	if cost, err := Cost(hashed); err != nil || *cost != *params {
+		t.Errorf("Cost: got %+v, %v, want %+v", cost, err, params)
+	}
+
+	if _, err := GenerateFromPassword(nil, &Params{N: 1000, R: 8, P: 1, SaltLen: 16, KeyLen: 32}); err == nil {
+		t.Error("N that is not a power of two accepted")
+	}
+}
+
+func TestCompareHashAndPassword(t *testing.T) {
+	// The third test vector of the scrypt paper.
+	hashed := "$scrypt$ln=14,r=8,p=1$U29kaXVtQ2hsb3JpZGU$cCO9yzr9c0hGHAbNgf046/2o+7qQT44+qbVD9lRdofLVQylVYT8Pz2LUlwUkKpr55h6F3A1lHkDfzwF7RVdYhw"
+	if err := CompareHashAndPassword([]byte(hashed), []byte("pleaseletmein")); err != nil {
+		t.Errorf("CompareHashAndPassword: %v", err)
+	}
+}
+
+func TestInvalidHashErrors(t *testing.T) {
+	check := func(hashed string, want error) {
+		t.Helper()
+		if err := CompareHashAndPassword([]byte(hashed), []byte("password")); err != want {
+			t.Errorf("%s: got %v, want %v", hashed, err, want)
+		}
+	}
+	check("", ErrInvalidHash)
+	check("$argon2id$ln=10,r=8,p=1$c29tZXNhbHQ$c29tZWhhc2hjb250ZW50cw", InvalidHashPrefixError("argon2id"))
+	check("$scrypt$ln=10,r=8$c29tZXNhbHQ$c29tZWhhc2hjb250ZW50cw", ErrInvalidHash)
+	check("$scrypt$ln=10,r=+8,p=1$c29tZXNhbHQ$c29tZWhhc2hjb250ZW50cw", ErrInvalidHash)
+	check("$scrypt$ln=0,r=8,p=1$c29tZXNhbHQ$c29tZWhhc2hjb250ZW50cw", InvalidParamsError{"ln", 0})
+	check("$scrypt$ln=30,r=8,p=1$c29tZXNhbHQ$c29tZWhhc2hjb250ZW50cw", InvalidParamsError{"N", 1 << 30})
+	check("$scrypt$ln=10,r=8,p=65536$c29tZXNhbHQ$c29tZWhhc2hjb250ZW50cw", InvalidParamsError{"p", 65536})
+	check("$scrypt$ln=10,r=8,p=1$c2FsdA$c29tZWhhc2hjb250ZW50cw", InvalidParamsError{"salt", 4})
+}
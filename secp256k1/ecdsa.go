@@ -0,0 +1,226 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secp256k1
+
+import (
+	"errors"
+
+	"github.com/benchlab/bench-crypto/hmac"
+	"github.com/benchlab/bench-crypto/sha256"
+)
+
+const (
+	// HashSize is the size, in bytes, of the message hashes signed with
+	// ECDSA.
+	HashSize = 32
+	// SignatureSize is the size, in bytes, of compact ECDSA signatures, r
+	// followed by s.
+	SignatureSize = 64
+	// RecoverableSignatureSize is the size, in bytes, of recoverable ECDSA
+	// signatures, r followed by s and a recovery identifier of 0 or 1.
+	RecoverableSignatureSize = 65
+)
+
+// rfc6979 generates the sequence of nonce candidates of RFC 6979, Section
+// 3.2, with HMAC-SHA256, for the private key x and the hash h1.
+type rfc6979 struct {
+	k, v []byte
+}
+
+func newRFC6979(x, h1 []byte) *rfc6979 {
+	g := &rfc6979{
+		k: make([]byte, sha256.Size),
+		v: make([]byte, sha256.Size),
+	}
+	for i := range g.v {
+		g.v[i] = 0x01
+	}
+	g.k = g.mac(g.v, []byte{0x00}, x, h1)
+	g.v = g.mac(g.v)
+	g.k = g.mac(g.v, []byte{0x01}, x, h1)
+	g.v = g.mac(g.v)
+	return g
+}
+
+func (g *rfc6979) mac(data ...[]byte) []byte {
+	m := hmac.New(sha256.New, g.k)
+	for _, d := range data {
+		m.Write(d)
+	}
+	return m.Sum(nil)
+}
+
+// next returns the next candidate k in [1, n-1].
+func (g *rfc6979) next() scalar {
+	for {
+		g.v = g.mac(g.v)
+		var k scalar
+		ok := k.setBytes(g.v) == 1 && k.isZero() == 0
+		// Update the state for a further call, whether k is out of range
+		// here or gets rejected by the caller.
+		g.k = g.mac(g.v, []byte{0x00})
+		g.v = g.mac(g.v)
+		if ok {
+			return k
+		}
+	}
+}
+
+// Sign signs hash, which must be HashSize bytes long, with priv and returns a
+// 64-byte signature, r followed by s. The nonce is derived from priv and hash
+// with RFC 6979, and s is normalized to the lower half of the group order.
+func Sign(priv *PrivateKey, hash []byte) ([]byte, error) {
+	sig, _, err := sign(priv, hash, false)
+	return sig, err
+}
+
+// SignRecoverable is like Sign, but appends a recovery identifier of 0 or 1
+// which lets RecoverPubkey compute the public key from the signature. This is
+// the format of Ethereum signatures, before any chain-specific offset is
+// added to the last byte.
+func SignRecoverable(priv *PrivateKey, hash []byte) ([]byte, error) {
+	sig, recid, err := sign(priv, hash, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(sig, recid), nil
+}
+
+func sign(priv *PrivateKey, hash []byte, recoverable bool) ([]byte, byte, error) {
+	if len(hash) != HashSize {
+		return nil, 0, errors.New("secp256k1: bad hash length")
+	}
+	var e scalar
+	e.setBytesReduced(hash)
+
+	g := newRFC6979(priv.d.bytes(), e.bytes())
+	for {
+		k := g.next()
+
+		var R point
+		R.scalarBaseMult(&k)
+		rx, ry := R.affine()
+
+		// r = R.x mod n. R.x is at least n with probability about 2^-128,
+		// which recovery has to account for.
+		var r scalar
+		r.setBytesReduced(rx.bytes())
+		if r.isZero() == 1 {
+			continue
+		}
+		recid := byte(ry.isOdd())
+		if lessThan(rx.limbs(), &orderModulus.m) == 0 {
+			recid |= 2
+		}
+
+		// s = (e + r * d) / k
+		var s, kInv scalar
+		s.mul(&r, &priv.d)
+		s.add(&s, &e)
+		kInv.invert(&k)
+		s.mul(&s, &kInv)
+		if s.isZero() == 1 {
+			continue
+		}
+
+		// Replacing s with -s is equivalent to negating R, which flips the
+		// parity of its y-coordinate.
+		high := s.isHigh()
+		s.condNegate(high)
+		recid ^= byte(high)
+
+		if recoverable && recid > 1 {
+			// This cannot be expressed in the 65-byte format, and is
+			// astronomically unlikely, so just try the next nonce.
+			continue
+		}
+
+		sig := make([]byte, 0, SignatureSize)
+		sig = append(sig, r.bytes()...)
+		sig = append(sig, s.bytes()...)
+		return sig, recid, nil
+	}
+}
+
+// Verify reports whether sig is a valid 64-byte signature of hash by pub.
+// Signatures with s in the upper half of the group order are rejected.
+func Verify(pub *PublicKey, hash, sig []byte) bool {
+	if len(hash) != HashSize || len(sig) != SignatureSize {
+		return false
+	}
+	var r, s scalar
+	if r.setBytes(sig[:32]) != 1 || s.setBytes(sig[32:]) != 1 ||
+		r.isZero() == 1 || s.isZero() == 1 || s.isHigh() == 1 {
+		return false
+	}
+	var e scalar
+	e.setBytesReduced(hash)
+
+	// R = (e / s) * G + (r / s) * Q
+	var sInv, u1, u2 scalar
+	sInv.invert(&s)
+	u1.mul(&e, &sInv)
+	u2.mul(&r, &sInv)
+	var R, t point
+	R.scalarBaseMult(&u1)
+	t.scalarMult(&u2, pub.point())
+	R.add(&R, &t)
+	if R.isIdentity() == 1 {
+		return false
+	}
+
+	rx, _ := R.affine()
+	var v scalar
+	v.setBytesReduced(rx.bytes())
+	return v == r
+}
+
+// RecoverPubkey returns the public key that produced the 65-byte recoverable
+// signature sig of hash, as returned by SignRecoverable. It returns an error
+// if the signature is malformed or no public key matches it. It does not
+// require s to be in low-S form.
+func RecoverPubkey(hash, sig []byte) (*PublicKey, error) {
+	if len(hash) != HashSize {
+		return nil, errors.New("secp256k1: bad hash length")
+	}
+	if len(sig) != RecoverableSignatureSize {
+		return nil, errors.New("secp256k1: bad signature length")
+	}
+	var r, s scalar
+	if r.setBytes(sig[:32]) != 1 || s.setBytes(sig[32:64]) != 1 ||
+		r.isZero() == 1 || s.isZero() == 1 {
+		return nil, errors.New("secp256k1: invalid signature")
+	}
+	recid := sig[64]
+	if recid > 1 {
+		return nil, errors.New("secp256k1: invalid recovery identifier")
+	}
+
+	var rx fieldElement
+	rx.setBytes(sig[:32])
+	var R point
+	if !R.liftX(&rx, int(recid)) {
+		return nil, errors.New("secp256k1: invalid signature")
+	}
+	var e scalar
+	e.setBytesReduced(hash)
+
+	// Q = (s * R - e * G) / r
+	var rInv, u1, u2 scalar
+	rInv.invert(&r)
+	u1.mul(&e, &rInv)
+	u1.negate(&u1)
+	u2.mul(&s, &rInv)
+	var Q, t point
+	Q.scalarBaseMult(&u1)
+	t.scalarMult(&u2, &R)
+	Q.add(&Q, &t)
+	if Q.isIdentity() == 1 {
+		return nil, errors.New("secp256k1: invalid signature")
+	}
+	pub := new(PublicKey)
+	pub.x, pub.y = Q.affine()
+	return pub, nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secp256k1
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/benchlab/bench-crypto/rand"
+	"github.com/benchlab/bench-crypto/sha256"
+)
+
+// Signatures with RFC 6979 nonces from the test suite of
+// github.com/decred/dcrd/dcrec/secp256k1, which were checked against Sage.
+var ecdsaTests = []struct {
+	key, hash, r, s string
+	recid           byte
+}{
+	{
+		// key 0x1, blake256(0x01020304)
+		key:   "0000000000000000000000000000000000000000000000000000000000000001",
+		hash:  "c301ba9de5d6053caad9f5eb46523f007702add2c62fa39de03146a36b8026b7",
+		r:     "c6c4137b0e5fbfc88ae3f293d7e80c8566c43ae20340075d44f75b009c943d09",
+		s:     "00ba213513572e35943d5acdd17215561b03f11663192a7252196cc8b2a99560",
+		recid: 0,
+	},
+	{
+		// key 0x2, blake256(0x01020304)
+		key:   "0000000000000000000000000000000000000000000000000000000000000002",
+		hash:  "c301ba9de5d6053caad9f5eb46523f007702add2c62fa39de03146a36b8026b7",
+		r:     "e6f137b52377250760cc702e19b7aee3c63b0e7d95a91939b14ab3b5c4771e59",
+		s:     "44b9bc4620afa158b7efdfea5234ff2d5f2f78b42886f02cf581827ee55318ea",
+		recid: 1,
+	},
+	{
+		// key 0x1, blake256(0x0102030405)
+		key:   "0000000000000000000000000000000000000000000000000000000000000001",
+		hash:  "dc063eba3c8d52a159e725c1a161506f6cb6b53478ad5ef3f08d534efa871d9f",
+		r:     "dda8308cdbda2edf51ccf598b42b42b19597e102eb2ed4a04a16dd57084d3b40",
+		s:     "0b6d67bab4929624e28f690407a15efc551354544fdc179970ff401eec2e5dc9",
+		recid: 1,
+	},
+	{
+		// key 0x2, blake256(0x0102030405)
+		key:   "0000000000000000000000000000000000000000000000000000000000000002",
+		hash:  "dc063eba3c8d52a159e725c1a161506f6cb6b53478ad5ef3f08d534efa871d9f",
+		r:     "122663fd29e41a132d3c8329cf05d61ebcca9351074cc277dcd868faba58d87d",
+		s:     "353a44f2d949c04981e4e4d9c1f93a9e0644e63a5eaa188288c5ad68fd288d40",
+		recid: 0,
+	},
+	{
+		// random key 1, blake256(0x01)
+		key:   "a1becef2069444a9dc6331c3247e113c3ee142edda683db8643f9cb0af7cbe33",
+		hash:  "4a6c419a1e25c85327115c4ace586decddfe2990ed8f3d4d801871158338501d",
+		r:     "ef392791d87afca8256c4c9c68d981248ee34a09069f50fa8dfc19ae34cd92ce",
+		s:     "0a2b9cb69fd794f7f204c272293b8585a294916a21a11fd94ec04acae2dc6d21",
+		recid: 0,
+	},
+	{
+		// random key 2, blake256(0x02)
+		key:   "59930b76d4b15767ec0e8c8e5812aa2e57db30c6af7963e2a6295ba02af5416b",
+		hash:  "49af37ab5270015fe25276ea5a3bb159d852943df23919522a202205fb7d175c",
+		r:     "886c9cccb356b3e1deafef2c276a4f8717ab73c1244c3f673cfbff5897de0e06",
+		s:     "609394185495f978ae84b69be90c69947e5dd8dcb4726da604fcbd139d81fc55",
+		recid: 0,
+	},
+	{
+		// random key 3, blake256(0x03)
+		key:   "c5b205c36bb7497d242e96ec19a2a4f086d8daa919135cf490d2b7c0230f0e91",
+		hash:  "b706d561742ad3671703c247eb927ee8a386369c79644131cdeb2c5c26bf6c5d",
+		r:     "6589d5950cec1fe2e7e20593b5ffa3556de20c176720a1796aa77a0cec1ec5a7",
+		s:     "2a26deba3241de852e786f5b4e2b98d3efb958d91fe9773b331dbcca9e8be800",
+		recid: 0,
+	},
+	{
+		// random key 4, blake256(0x04)
+		key:   "65b46d4eb001c649a86309286aaf94b18386effe62c2e1586d9b1898ccf0099b",
+		hash:  "4c6eb9e38415034f4c93d3304d10bef38bf0ad420eefd0f72f940f11c5857786",
+		r:     "81db1d6dca08819ad936d3284a359091e57c036648d477b96af9d8326965a7d1",
+		s:     "1bdf719c4be69351ba7617a187ac246912101aea4b5a7d6dfc234478622b43c6",
+		recid: 1,
+	},
+	{
+		// random key 5, blake256(0x05)
+		key:   "915cb9ba4675de06a182088b182abcf79fa8ac989328212c6b866fa3ec2338f9",
+		hash:  "bdd15db13448905791a70b68137445e607cca06cc71c7a58b9b2e84a06c54d08",
+		r:     "47fd51aecbc743477cb59aa29d18d11d75fb206ae1cdd044216e4f294e33d5b6",
+		s:     "3d50edc03066584d50b8d19d681865a23960b37502ede5bf452bdca56744334a",
+		recid: 1,
+	},
+	{
+		// random key 6, blake256(0x06)
+		key:   "93e9d81d818f08ba1f850c6dfb82256b035b42f7d43c1fe090804fb009aca441",
+		hash:  "19b7506ad9c189a9f8b063d2aee15953d335f5c88480f8515d7d848e7771c4ae",
+		r:     "c99800bc7ac7ea11afe5d7a264f4c26edd63ae9c7ecd6d0d19992980bcda1d34",
+		s:     "2844d4c9020ddf9e96b86c1a04788e0f371bd562291fd17ee017db46259d04fb",
+		recid: 1,
+	},
+	{
+		// random key 7, blake256(0x07)
+		key:   "c249bbd5f533672b7dcd514eb1256854783531c2b85fe60bf4ce6ea1f26afc2b",
+		hash:  "53d661e71e47a0a7e416591200175122d83f8af31be6a70af7417ad6f54d0038",
+		r:     "7a57a5222fb7d615eaa0041193f682262cebfa9b448f9c519d3644d0a3348521",
+		s:     "574923b7b5aec66b62f1589002db29342c9f5ed56d5e80f5361c0307ff1561fa",
+		recid: 0,
+	},
+	{
+		// random key 8, blake256(0x08)
+		key:   "ec0be92fcec66cf1f97b5c39f83dfd4ddcad0dad468d3685b5eec556c6290bcc",
+		hash:  "9bff7982eab6f7883322edf7bdc86a23c87ca1c07906fbb1584f57b197dc6253",
+		r:     "64f90b09c8b1763a3eeefd156e5d312f80a98c24017811c0163b1c0b01323668",
+		s:     "7d7bf4ff295ecfc9578eadc8378b0eea0c0362ad083b0fd1c9b3c06f4537f6ff",
+		recid: 1,
+	},
+	{
+		// random key 9, blake256(0x09)
+		key:   "6847b071a7cba6a85099b26a9c3e57a964e4990620e1e1c346fecc4472c4d834",
+		hash:  "4c2231813064f8500edae05b40195416bd543fd3e76c16d6efb10c816d92e8b6",
+		r:     "81fc600775d3cdcaa14f8629537299b8226a0c8bfce9320ce64a8d14e3f95bae",
+		s:     "3607997d36b48bce957ae9b3d450e0969f6269554312a82bf9499efc8280ea6d",
+		recid: 0,
+	},
+	{
+		// random key 10, blake256(0x0a)
+		key:   "b7548540f52fe20c161a0d623097f827608c56023f50442cc00cc50ad674f6b5",
+		hash:  "e81db4f0d76e02805155441f50c861a8f86374f3ae34c7a3ff4111d3a634ecb1",
+		r:     "0d4cbf2da84f7448b083fce9b9c4e1834b5e2e98defcec7ec87e87c739f5fe78",
+		s:     "0997db60683e12b4494702347fc7ae7f599e5a95c629c146e0fc615a1a2acac5",
+		recid: 1,
+	},
+}
+
+func TestSign(t *testing.T) {
+	for i, test := range ecdsaTests {
+		key := decodeHex(test.key)
+		hash, _ := hex.DecodeString(test.hash)
+		priv, err := NewPrivateKey(key)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+
+		sig, err := SignRecoverable(priv, hash)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if got, want := hex.EncodeToString(sig[:64]), test.r+test.s; got != want {
+			t.Errorf("#%d: signature %s, want %s", i, got, want)
+		}
+		if sig[64] != test.recid {
+			t.Errorf("#%d: recovery identifier %d, want %d", i, sig[64], test.recid)
+		}
+		if !Verify(priv.PublicKey(), hash, sig[:64]) {
+			t.Errorf("#%d: Verify failed", i)
+		}
+		pub, err := RecoverPubkey(hash, sig)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+		} else if !pub.Equal(priv.PublicKey()) {
+			t.Errorf("#%d: recovered a different public key", i)
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := priv.PublicKey()
+	hash := sha256.Sum256([]byte("test message"))
+
+	sig, err := Sign(priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(pub, hash[:], sig) {
+		t.Errorf("valid signature rejected")
+	}
+	sig2, _ := Sign(priv, hash[:])
+	if !bytes.Equal(sig, sig2) {
+		t.Errorf("signatures are not deterministic")
+	}
+
+	wrong := sha256.Sum256([]byte("wrong message"))
+	if Verify(pub, wrong[:], sig) {
+		t.Errorf("signature of different message accepted")
+	}
+	other, _ := GenerateKey(rand.Reader)
+	if Verify(other.PublicKey(), hash[:], sig) {
+		t.Errorf("signature accepted for a different key")
+	}
+
+	// The high-S form of the same signature is rejected by Verify, but
+	// RecoverPubkey accepts it with the opposite recovery identifier.
+	var s scalar
+	s.setBytes(sig[32:])
+	s.negate(&s)
+	high := append(append([]byte{}, sig[:32]...), s.bytes()...)
+	if Verify(pub, hash[:], high) {
+		t.Errorf("high-S signature accepted")
+	}
+	recoverable, _ := SignRecoverable(priv, hash[:])
+	recovered, err := RecoverPubkey(hash[:], append(high, recoverable[64]^1))
+	if err != nil || !recovered.Equal(pub) {
+		t.Errorf("RecoverPubkey failed on high-S signature: %v", err)
+	}
+
+	if _, err := Sign(priv, hash[:31]); err == nil {
+		t.Errorf("Sign accepted a short hash")
+	}
+	if _, err := RecoverPubkey(hash[:], append(sig, 2)); err == nil {
+		t.Errorf("RecoverPubkey accepted recovery identifier 2")
+	}
+	if _, err := RecoverPubkey(hash[:], make([]byte, RecoverableSignatureSize)); err == nil {
+		t.Errorf("RecoverPubkey accepted a zero signature")
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	priv, _ := GenerateKey(rand.Reader)
+	hash := sha256.Sum256([]byte("Hello, world!"))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Sign(priv, hash[:])
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	priv, _ := GenerateKey(rand.Reader)
+	hash := sha256.Sum256([]byte("Hello, world!"))
+	sig, _ := Sign(priv, hash[:])
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Verify(priv.PublicKey(), hash[:], sig)
+	}
+}
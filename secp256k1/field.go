@@ -0,0 +1,262 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secp256k1
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Both the field prime p and the group order n are of the form 2^256 - c for
+// a small c, so elements of either are kept as four little-endian 64-bit
+// limbs, always fully reduced, and share the constant-time helpers below.
+
+// modulus describes a modulus m = 2^256 - c, with c < 2^192.
+type modulus struct {
+	m [4]uint64
+	c [3]uint64
+}
+
+var (
+	// p = 2^256 - 2^32 - 977
+	fieldModulus = modulus{
+		m: [4]uint64{0xfffffffefffffc2f, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff},
+		c: [3]uint64{0x1000003d1, 0, 0},
+	}
+	// n, the order of the generator.
+	orderModulus = modulus{
+		m: [4]uint64{0xbfd25e8cd0364141, 0xbaaedce6af48a03b, 0xfffffffffffffffe, 0xffffffffffffffff},
+		c: [3]uint64{0x402da1732fc9bebf, 0x4551231950b75fc4, 0x1},
+	}
+)
+
+// selectLimbs sets out to a if b == 1, and leaves it unchanged if b == 0.
+func selectLimbs(out, a *[4]uint64, b uint64) {
+	mask := -b
+	for i := range out {
+		out[i] ^= (out[i] ^ a[i]) & mask
+	}
+}
+
+// add sets out = x + y mod m.
+func (md *modulus) add(out, x, y *[4]uint64) {
+	var s, t [4]uint64
+	var carry, borrow uint64
+	for i := range s {
+		s[i], carry = bits.Add64(x[i], y[i], carry)
+	}
+	for i := range t {
+		t[i], borrow = bits.Sub64(s[i], md.m[i], borrow)
+	}
+	// x + y >= m exactly when the addition carried or the subtraction did
+	// not borrow.
+	*out = s
+	selectLimbs(out, &t, carry|(borrow^1))
+}
+
+// sub sets out = x - y mod m.
+func (md *modulus) sub(out, x, y *[4]uint64) {
+	var d, t [4]uint64
+	var borrow, carry uint64
+	for i := range d {
+		d[i], borrow = bits.Sub64(x[i], y[i], borrow)
+	}
+	for i := range t {
+		t[i], carry = bits.Add64(d[i], md.m[i], carry)
+	}
+	*out = d
+	selectLimbs(out, &t, borrow)
+}
+
+// mul sets out = x * y mod m.
+func (md *modulus) mul(out, x, y *[4]uint64) {
+	var w [8]uint64
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(x[i], y[j])
+			var c uint64
+			lo, c = bits.Add64(lo, w[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			w[i+j] = lo
+			carry = hi
+		}
+		w[i+4] = carry
+	}
+	md.reduce(out, &w)
+}
+
+// reduce sets out = w mod m.
+func (md *modulus) reduce(out *[4]uint64, w *[8]uint64) {
+	// Since 2^256 = c mod m, folding the top half as w = lo + hi * c shrinks
+	// w at each step, and four steps always bring it below 2^256 < 2m.
+	for i := 0; i < 4; i++ {
+		md.fold(w)
+	}
+	var t [4]uint64
+	var borrow uint64
+	for i := range t {
+		t[i], borrow = bits.Sub64(w[i], md.m[i], borrow)
+	}
+	copy(out[:], w[:4])
+	selectLimbs(out, &t, borrow^1)
+}
+
+// fold sets w = lo + hi * c, where w = lo + hi * 2^256.
+func (md *modulus) fold(w *[8]uint64) {
+	var t [8]uint64
+	copy(t[:4], w[:4])
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 3; j++ {
+			hi, lo := bits.Mul64(w[4+i], md.c[j])
+			var c uint64
+			lo, c = bits.Add64(lo, t[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[i+j] = lo
+			carry = hi
+		}
+		for k := i + 3; k < 8; k++ {
+			t[k], carry = bits.Add64(t[k], carry, 0)
+		}
+	}
+	*w = t
+}
+
+// exp sets out = x^e mod m. It is constant time with respect to x, but not e.
+func (md *modulus) exp(out, x, e *[4]uint64) {
+	var r [4]uint64
+	r[0] = 1
+	b := *x
+	for i := 255; i >= 0; i-- {
+		md.mul(&r, &r, &r)
+		if e[i/64]>>uint(i%64)&1 == 1 {
+			md.mul(&r, &r, &b)
+		}
+	}
+	*out = r
+}
+
+// lessThan returns 1 if x < y, and 0 otherwise, in constant time.
+func lessThan(x, y *[4]uint64) uint64 {
+	var borrow uint64
+	for i := range x {
+		_, borrow = bits.Sub64(x[i], y[i], borrow)
+	}
+	return borrow
+}
+
+// limbsFromBytes returns the big-endian 32-byte integer b.
+func limbsFromBytes(b []byte) [4]uint64 {
+	var l [4]uint64
+	for i := range l {
+		l[i] = binary.BigEndian.Uint64(b[24-8*i:])
+	}
+	return l
+}
+
+// limbsToBytes writes l to b as a big-endian 32-byte integer.
+func limbsToBytes(b []byte, l *[4]uint64) {
+	for i := range l {
+		binary.BigEndian.PutUint64(b[24-8*i:], l[i])
+	}
+}
+
+// fieldElement is an element of GF(p), p = 2^256 - 2^32 - 977, always fully
+// reduced.
+type fieldElement [4]uint64
+
+var (
+	// pMinus2 and pPlus1Over4 are exponents for inversion and square roots.
+	pMinus2     = [4]uint64{0xfffffffefffffc2d, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff}
+	pPlus1Over4 = [4]uint64{0xffffffffbfffff0c, 0xffffffffffffffff, 0xffffffffffffffff, 0x3fffffffffffffff}
+)
+
+func (v *fieldElement) limbs() *[4]uint64 { return (*[4]uint64)(v) }
+
+func (v *fieldElement) add(x, y *fieldElement) *fieldElement {
+	fieldModulus.add(v.limbs(), x.limbs(), y.limbs())
+	return v
+}
+
+func (v *fieldElement) sub(x, y *fieldElement) *fieldElement {
+	fieldModulus.sub(v.limbs(), x.limbs(), y.limbs())
+	return v
+}
+
+func (v *fieldElement) mul(x, y *fieldElement) *fieldElement {
+	fieldModulus.mul(v.limbs(), x.limbs(), y.limbs())
+	return v
+}
+
+func (v *fieldElement) square(x *fieldElement) *fieldElement {
+	return v.mul(x, x)
+}
+
+func (v *fieldElement) negate(x *fieldElement) *fieldElement {
+	var zero fieldElement
+	return v.sub(&zero, x)
+}
+
+// invert sets v = 1/x, or zero if x is zero.
+func (v *fieldElement) invert(x *fieldElement) *fieldElement {
+	fieldModulus.exp(v.limbs(), x.limbs(), &pMinus2)
+	return v
+}
+
+// sqrt sets v to a square root of x and returns 1 if x is square. Otherwise,
+// it returns 0 and the value of v is undefined.
+func (v *fieldElement) sqrt(x *fieldElement) int {
+	var r, check fieldElement
+	fieldModulus.exp(r.limbs(), x.limbs(), &pPlus1Over4)
+	check.square(&r)
+	*v = r
+	return check.equal(x)
+}
+
+// setBytes sets v to the big-endian 32-byte integer b and returns 1 if b is
+// less than p. Otherwise, it returns 0 and leaves v unchanged.
+func (v *fieldElement) setBytes(b []byte) int {
+	l := limbsFromBytes(b)
+	if lessThan(&l, &fieldModulus.m) == 0 {
+		return 0
+	}
+	*v = l
+	return 1
+}
+
+// bytes returns the big-endian 32-byte encoding of v.
+func (v *fieldElement) bytes() []byte {
+	b := make([]byte, 32)
+	limbsToBytes(b, v.limbs())
+	return b
+}
+
+// equal returns 1 if v and u are equal, and 0 otherwise.
+func (v *fieldElement) equal(u *fieldElement) int {
+	var acc uint64
+	for i := range v {
+		acc |= v[i] ^ u[i]
+	}
+	return int(1 ^ (acc|-acc)>>63)
+}
+
+func (v *fieldElement) isZero() int {
+	var zero fieldElement
+	return v.equal(&zero)
+}
+
+func (v *fieldElement) isOdd() int {
+	return int(v[0] & 1)
+}
+
+// cmove sets v = u if b == 1, and leaves it unchanged if b == 0.
+func (v *fieldElement) cmove(u *fieldElement, b int) {
+	selectLimbs(v.limbs(), u.limbs(), uint64(b))
+}
@@ -0,0 +1,205 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secp256k1
+
+// point is a point on y^2 = x^3 + 7 in homogeneous projective coordinates
+// (X : Y : Z), where x = X/Z and y = Y/Z. The identity is (0 : 1 : 0).
+//
+// The complete formulas of Renes, Costello and Batina (ePrint 2015/1060,
+// Algorithms 7 and 9, for a = 0) handle every input, including the identity
+// and doublings, without branches.
+type point struct {
+	x, y, z fieldElement
+}
+
+// b3 is 3 * b, where b = 7 is the curve constant.
+var b3 = fieldElement{21}
+
+var generator = point{
+	x: fieldElement{0x59f2815b16f81798, 0x029bfcdb2dce28d9, 0x55a06295ce870b07, 0x79be667ef9dcbbac},
+	y: fieldElement{0x9c47d08ffb10d4b8, 0xfd17b448a6855419, 0x5da4fbfc0e1108a8, 0x483ada7726a3c465},
+	z: fieldElement{1},
+}
+
+func (v *point) setIdentity() *point {
+	*v = point{y: fieldElement{1}}
+	return v
+}
+
+// add sets v = p + q.
+func (v *point) add(p, q *point) *point {
+	var t0, t1, t2, t3, t4, x3, y3, z3 fieldElement
+
+	t0.mul(&p.x, &q.x)
+	t1.mul(&p.y, &q.y)
+	t2.mul(&p.z, &q.z)
+	t3.add(&p.x, &p.y)
+	t4.add(&q.x, &q.y)
+	t3.mul(&t3, &t4)
+	t4.add(&t0, &t1)
+	t3.sub(&t3, &t4)
+	t4.add(&p.y, &p.z)
+	x3.add(&q.y, &q.z)
+	t4.mul(&t4, &x3)
+	x3.add(&t1, &t2)
+	t4.sub(&t4, &x3)
+	x3.add(&p.x, &p.z)
+	y3.add(&q.x, &q.z)
+	x3.mul(&x3, &y3)
+	y3.add(&t0, &t2)
+	y3.sub(&x3, &y3)
+	x3.add(&t0, &t0)
+	t0.add(&x3, &t0)
+	t2.mul(&b3, &t2)
+	z3.add(&t1, &t2)
+	t1.sub(&t1, &t2)
+	y3.mul(&b3, &y3)
+	x3.mul(&t4, &y3)
+	t2.mul(&t3, &t1)
+	x3.sub(&t2, &x3)
+	y3.mul(&y3, &t0)
+	t1.mul(&t1, &z3)
+	y3.add(&t1, &y3)
+	t0.mul(&t0, &t3)
+	z3.mul(&z3, &t4)
+	z3.add(&z3, &t0)
+
+	v.x, v.y, v.z = x3, y3, z3
+	return v
+}
+
+// double sets v = 2 * p.
+func (v *point) double(p *point) *point {
+	var t0, t1, t2, x3, y3, z3 fieldElement
+
+	t0.square(&p.y)
+	z3.add(&t0, &t0)
+	z3.add(&z3, &z3)
+	z3.add(&z3, &z3)
+	t1.mul(&p.y, &p.z)
+	t2.square(&p.z)
+	t2.mul(&b3, &t2)
+	x3.mul(&t2, &z3)
+	y3.add(&t0, &t2)
+	z3.mul(&t1, &z3)
+	t1.add(&t2, &t2)
+	t2.add(&t1, &t2)
+	t0.sub(&t0, &t2)
+	y3.mul(&t0, &y3)
+	y3.add(&x3, &y3)
+	t1.mul(&p.x, &p.y)
+	x3.mul(&t0, &t1)
+	x3.add(&x3, &x3)
+
+	v.x, v.y, v.z = x3, y3, z3
+	return v
+}
+
+// negate sets v = -p.
+func (v *point) negate(p *point) *point {
+	v.x = p.x
+	v.y.negate(&p.y)
+	v.z = p.z
+	return v
+}
+
+// cmove sets v = u if b == 1, and leaves it unchanged if b == 0.
+func (v *point) cmove(u *point, b int) {
+	v.x.cmove(&u.x, b)
+	v.y.cmove(&u.y, b)
+	v.z.cmove(&u.z, b)
+}
+
+// scalarMult sets v = s * p, in constant time with respect to s.
+func (v *point) scalarMult(s *scalar, p *point) *point {
+	// table[i] = i * p
+	var table [16]point
+	table[0].setIdentity()
+	table[1] = *p
+	for i := 2; i < 16; i += 2 {
+		table[i].double(&table[i/2])
+		table[i+1].add(&table[i], p)
+	}
+
+	b := s.bytes()
+	var q, t point
+	q.setIdentity()
+	for _, byt := range b {
+		for _, nibble := range [2]byte{byt >> 4, byt & 15} {
+			q.double(&q)
+			q.double(&q)
+			q.double(&q)
+			q.double(&q)
+			t.setIdentity()
+			for j := range table {
+				t.cmove(&table[j], equal(int(nibble), j))
+			}
+			q.add(&q, &t)
+		}
+	}
+	*v = q
+	return v
+}
+
+// scalarBaseMult sets v = s * G, in constant time with respect to s.
+func (v *point) scalarBaseMult(s *scalar) *point {
+	return v.scalarMult(s, &generator)
+}
+
+// equal returns 1 if b == c, and 0 otherwise.
+func equal(b, c int) int {
+	x := uint32(b ^ c)
+	x--
+	return int(x >> 31)
+}
+
+// isIdentity returns 1 if v is the identity, and 0 otherwise.
+func (v *point) isIdentity() int {
+	return v.z.isZero()
+}
+
+// affine returns the affine coordinates of v, which must not be the
+// identity.
+func (v *point) affine() (x, y fieldElement) {
+	var zInv fieldElement
+	zInv.invert(&v.z)
+	x.mul(&v.x, &zInv)
+	y.mul(&v.y, &zInv)
+	return x, y
+}
+
+// setAffine sets v to the point (x, y) and reports whether it is on the curve.
+func (v *point) setAffine(x, y *fieldElement) bool {
+	var lhs, rhs fieldElement
+	lhs.square(y)
+	curveRHS(&rhs, x)
+	if lhs.equal(&rhs) != 1 {
+		return false
+	}
+	v.x, v.y, v.z = *x, *y, fieldElement{1}
+	return true
+}
+
+// curveRHS sets v = x^3 + 7.
+func curveRHS(v, x *fieldElement) {
+	var x3 fieldElement
+	x3.square(x)
+	x3.mul(&x3, x)
+	v.add(&x3, &fieldElement{7})
+}
+
+// liftX sets v to the point with x-coordinate x and a y-coordinate of the
+// given parity, and reports whether such a point exists.
+func (v *point) liftX(x *fieldElement, odd int) bool {
+	var y, yNeg, rhs fieldElement
+	curveRHS(&rhs, x)
+	if y.sqrt(&rhs) != 1 {
+		return false
+	}
+	yNeg.negate(&y)
+	y.cmove(&yNeg, y.isOdd()^odd)
+	v.x, v.y, v.z = *x, y, fieldElement{1}
+	return true
+}
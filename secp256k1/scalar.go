@@ -0,0 +1,86 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secp256k1
+
+// scalar is an integer modulo the group order
+//
+//	n = 2^256 - 432420386565659656852420866394968145599
+//
+// always fully reduced.
+type scalar [4]uint64
+
+var (
+	// nMinus2 is the exponent for inversion.
+	nMinus2 = [4]uint64{0xbfd25e8cd036413f, 0xbaaedce6af48a03b, 0xfffffffffffffffe, 0xffffffffffffffff}
+	// halfOrder is (n - 1) / 2, the largest low-S value.
+	halfOrder = [4]uint64{0xdfe92f46681b20a0, 0x5d576e7357a4501d, 0xffffffffffffffff, 0x7fffffffffffffff}
+)
+
+func (s *scalar) limbs() *[4]uint64 { return (*[4]uint64)(s) }
+
+func (s *scalar) add(x, y *scalar) *scalar {
+	orderModulus.add(s.limbs(), x.limbs(), y.limbs())
+	return s
+}
+
+func (s *scalar) mul(x, y *scalar) *scalar {
+	orderModulus.mul(s.limbs(), x.limbs(), y.limbs())
+	return s
+}
+
+func (s *scalar) negate(x *scalar) *scalar {
+	var zero scalar
+	orderModulus.sub(s.limbs(), zero.limbs(), x.limbs())
+	return s
+}
+
+// invert sets s = 1/x, or zero if x is zero.
+func (s *scalar) invert(x *scalar) *scalar {
+	orderModulus.exp(s.limbs(), x.limbs(), &nMinus2)
+	return s
+}
+
+// setBytesReduced sets s to the big-endian 32-byte integer b modulo n.
+func (s *scalar) setBytesReduced(b []byte) *scalar {
+	var w [8]uint64
+	l := limbsFromBytes(b)
+	copy(w[:4], l[:])
+	orderModulus.reduce(s.limbs(), &w)
+	return s
+}
+
+// setBytes sets s to the big-endian 32-byte integer b and returns 1 if b is
+// less than n. Otherwise, it returns 0 and leaves s unchanged.
+func (s *scalar) setBytes(b []byte) int {
+	l := limbsFromBytes(b)
+	if lessThan(&l, &orderModulus.m) == 0 {
+		return 0
+	}
+	*s = l
+	return 1
+}
+
+// bytes returns the big-endian 32-byte encoding of s.
+func (s *scalar) bytes() []byte {
+	b := make([]byte, 32)
+	limbsToBytes(b, s.limbs())
+	return b
+}
+
+func (s *scalar) isZero() int {
+	return (*fieldElement)(s).isZero()
+}
+
+// isHigh returns 1 if s > (n - 1) / 2, and 0 otherwise.
+func (s *scalar) isHigh() int {
+	return int(lessThan(&halfOrder, s.limbs()))
+}
+
+// condNegate sets s = -s if b == 1, and leaves it unchanged if b == 0.
+func (s *scalar) condNegate(b int) {
+	var neg scalar
+	neg.negate(s)
+	selectLimbs(s.limbs(), neg.limbs(), uint64(b))
+}
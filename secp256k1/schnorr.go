@@ -0,0 +1,109 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secp256k1
+
+import (
+	"errors"
+
+	"github.com/benchlab/bench-crypto/sha256"
+)
+
+const (
+	// XOnlyPublicKeySize is the size, in bytes, of BIP-340 public keys.
+	XOnlyPublicKeySize = 32
+	// SchnorrSignatureSize is the size, in bytes, of BIP-340 signatures.
+	SchnorrSignatureSize = 64
+	// AuxRandSize is the size, in bytes, of the auxiliary randomness mixed
+	// into BIP-340 nonces.
+	AuxRandSize = 32
+)
+
+// taggedHash returns the BIP-340 tagged hash SHA256(SHA256(tag) ||
+// SHA256(tag) || msg...).
+func taggedHash(tag string, msg ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, m := range msg {
+		h.Write(m)
+	}
+	return h.Sum(nil)
+}
+
+// SerializeXOnly returns the 32-byte x-only encoding of pub used by BIP-340,
+// which implicitly selects the point with an even y-coordinate.
+func (pub *PublicKey) SerializeXOnly() []byte {
+	return pub.x.bytes()
+}
+
+// SignSchnorr signs msg with priv following BIP-340 and returns a 64-byte
+// signature. auxRand must be AuxRandSize bytes, and should be freshly
+// generated random bytes, although signatures remain secure if it is not.
+func SignSchnorr(priv *PrivateKey, msg, auxRand []byte) ([]byte, error) {
+	if len(auxRand) != AuxRandSize {
+		return nil, errors.New("secp256k1: bad auxiliary randomness length")
+	}
+
+	// Use the private key whose public key has an even y-coordinate.
+	d := priv.d
+	d.condNegate(priv.pub.y.isOdd())
+	px := priv.pub.x.bytes()
+
+	t := d.bytes()
+	for i, b := range taggedHash("BIP0340/aux", auxRand) {
+		t[i] ^= b
+	}
+	var k scalar
+	k.setBytesReduced(taggedHash("BIP0340/nonce", t, px, msg))
+	if k.isZero() == 1 {
+		return nil, errors.New("secp256k1: invalid nonce")
+	}
+
+	var R point
+	R.scalarBaseMult(&k)
+	rx, ry := R.affine()
+	k.condNegate(ry.isOdd())
+
+	var e, s scalar
+	e.setBytesReduced(taggedHash("BIP0340/challenge", rx.bytes(), px, msg))
+	s.mul(&e, &d)
+	s.add(&s, &k)
+
+	sig := make([]byte, 0, SchnorrSignatureSize)
+	sig = append(sig, rx.bytes()...)
+	return append(sig, s.bytes()...), nil
+}
+
+// VerifySchnorr reports whether sig is a valid BIP-340 signature of msg by
+// the 32-byte x-only public key publicKey.
+func VerifySchnorr(publicKey, msg, sig []byte) bool {
+	if len(publicKey) != XOnlyPublicKeySize || len(sig) != SchnorrSignatureSize {
+		return false
+	}
+	var px, rx fieldElement
+	var P point
+	if px.setBytes(publicKey) != 1 || !P.liftX(&px, 0) {
+		return false
+	}
+	var s scalar
+	if rx.setBytes(sig[:32]) != 1 || s.setBytes(sig[32:]) != 1 {
+		return false
+	}
+	var e scalar
+	e.setBytesReduced(taggedHash("BIP0340/challenge", sig[:32], publicKey, msg))
+
+	// R = s * G - e * P
+	var R, t point
+	e.negate(&e)
+	R.scalarBaseMult(&s)
+	t.scalarMult(&e, &P)
+	R.add(&R, &t)
+	if R.isIdentity() == 1 {
+		return false
+	}
+	x, y := R.affine()
+	return y.isOdd() == 0 && x.equal(&rx) == 1
+}
@@ -0,0 +1,193 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package secp256k1 implements the secp256k1 elliptic curve of SEC 2, as used
+// by Bitcoin and Ethereum, with deterministic ECDSA signatures, public key
+// recovery, and BIP-340 Schnorr signatures.
+//
+// Field and scalar arithmetic, and the scalar multiplications involving
+// private keys and nonces, are constant time.
+//
+// ECDSA signatures use RFC 6979 nonces and are always in low-S form, as
+// required by Bitcoin's BIP-62 and Ethereum's EIP-2; Verify rejects
+// signatures in high-S form. Integers are big-endian, following the
+// conventions of both networks.
+package secp256k1 // import "golang.org/x/github.com/benchlab/bench-crypto/secp256k1"
+
+import (
+	"errors"
+	"io"
+
+	cryptorand "github.com/benchlab/bench-crypto/rand"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/sha3"
+)
+
+const (
+	// PrivateKeySize is the size, in bytes, of encoded private keys.
+	PrivateKeySize = 32
+	// CompressedPublicKeySize is the size, in bytes, of SEC 1 compressed
+	// public keys.
+	CompressedPublicKeySize = 33
+	// UncompressedPublicKeySize is the size, in bytes, of SEC 1 uncompressed
+	// public keys.
+	UncompressedPublicKeySize = 65
+)
+
+// PublicKey is a secp256k1 public key, a point other than the identity.
+type PublicKey struct {
+	x, y fieldElement
+}
+
+// PrivateKey is a secp256k1 private key.
+type PrivateKey struct {
+	d   scalar
+	pub PublicKey
+}
+
+// GenerateKey generates a private key using entropy from rand. If rand is
+// nil, github.com/benchlab/bench-crypto/rand.Reader will be used.
+func GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	b := make([]byte, PrivateKeySize)
+	for {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+		// The probability of rejection is less than 2^-127.
+		if priv, err := NewPrivateKey(b); err == nil {
+			return priv, nil
+		}
+	}
+}
+
+// NewPrivateKey returns the private key with the big-endian 32-byte encoding
+// key. It returns an error if key is zero or not less than the group order.
+func NewPrivateKey(key []byte) (*PrivateKey, error) {
+	if len(key) != PrivateKeySize {
+		return nil, errors.New("secp256k1: bad private key length")
+	}
+	priv := new(PrivateKey)
+	if priv.d.setBytes(key) != 1 || priv.d.isZero() == 1 {
+		return nil, errors.New("secp256k1: invalid private key")
+	}
+	var p point
+	p.scalarBaseMult(&priv.d)
+	priv.pub.x, priv.pub.y = p.affine()
+	return priv, nil
+}
+
+// Bytes returns the big-endian 32-byte encoding of priv.
+func (priv *PrivateKey) Bytes() []byte {
+	return priv.d.bytes()
+}
+
+// TweakAdd returns the private key priv + tweak, where tweak is a big-endian
+// 32-byte integer. It returns an error if tweak is not less than the group
+// order or the sum is zero. This is the key derivation step of BIP-32.
+func (priv *PrivateKey) TweakAdd(tweak []byte) (*PrivateKey, error) {
+	if len(tweak) != PrivateKeySize {
+		return nil, errors.New("secp256k1: bad tweak length")
+	}
+	var t scalar
+	if t.setBytes(tweak) != 1 {
+		return nil, errors.New("secp256k1: invalid tweak")
+	}
+	sum := new(PrivateKey)
+	sum.d.add(&priv.d, &t)
+	if sum.d.isZero() == 1 {
+		return nil, errors.New("secp256k1: invalid tweak")
+	}
+	var p point
+	p.scalarBaseMult(&sum.d)
+	sum.pub.x, sum.pub.y = p.affine()
+	return sum, nil
+}
+
+// PublicKey returns the public key corresponding to priv.
+func (priv *PrivateKey) PublicKey() *PublicKey {
+	pub := priv.pub
+	return &pub
+}
+
+// ParsePublicKey parses a SEC 1 compressed or uncompressed public key. It
+// returns an error if the encoding is invalid or the point is not on the
+// curve.
+func ParsePublicKey(b []byte) (*PublicKey, error) {
+	var p point
+	switch {
+	case len(b) == CompressedPublicKeySize && (b[0] == 2 || b[0] == 3):
+		var x fieldElement
+		if x.setBytes(b[1:]) != 1 || !p.liftX(&x, int(b[0]&1)) {
+			return nil, errors.New("secp256k1: invalid public key")
+		}
+	case len(b) == UncompressedPublicKeySize && b[0] == 4:
+		var x, y fieldElement
+		if x.setBytes(b[1:33]) != 1 || y.setBytes(b[33:]) != 1 || !p.setAffine(&x, &y) {
+			return nil, errors.New("secp256k1: invalid public key")
+		}
+	default:
+		return nil, errors.New("secp256k1: invalid public key encoding")
+	}
+	return &PublicKey{x: p.x, y: p.y}, nil
+}
+
+// SerializeCompressed returns the 33-byte SEC 1 compressed encoding of pub.
+func (pub *PublicKey) SerializeCompressed() []byte {
+	b := make([]byte, 0, CompressedPublicKeySize)
+	b = append(b, byte(2+pub.y.isOdd()))
+	return append(b, pub.x.bytes()...)
+}
+
+// SerializeUncompressed returns the 65-byte SEC 1 uncompressed encoding of
+// pub.
+func (pub *PublicKey) SerializeUncompressed() []byte {
+	b := make([]byte, 0, UncompressedPublicKeySize)
+	b = append(b, 4)
+	b = append(b, pub.x.bytes()...)
+	return append(b, pub.y.bytes()...)
+}
+
+// Equal reports whether pub and x are the same public key.
+func (pub *PublicKey) Equal(x *PublicKey) bool {
+	return pub.x.equal(&x.x)&pub.y.equal(&x.y) == 1
+}
+
+func (pub *PublicKey) point() *point {
+	return &point{x: pub.x, y: pub.y, z: fieldElement{1}}
+}
+
+// TweakAdd returns the public key pub + tweak * G, which corresponds to the
+// private key returned by PrivateKey.TweakAdd with the same tweak. It returns
+// an error if tweak is not less than the group order or the sum is the
+// identity.
+func (pub *PublicKey) TweakAdd(tweak []byte) (*PublicKey, error) {
+	if len(tweak) != PrivateKeySize {
+		return nil, errors.New("secp256k1: bad tweak length")
+	}
+	var t scalar
+	if t.setBytes(tweak) != 1 {
+		return nil, errors.New("secp256k1: invalid tweak")
+	}
+	var p point
+	p.scalarBaseMult(&t)
+	p.add(&p, pub.point())
+	if p.isIdentity() == 1 {
+		return nil, errors.New("secp256k1: invalid tweak")
+	}
+	sum := new(PublicKey)
+	sum.x, sum.y = p.affine()
+	return sum, nil
+}
+
+// EthereumAddress returns the 20-byte Ethereum address of pub, the last 20
+// bytes of the Keccak-256 hash of its uncompressed encoding without the
+// prefix byte.
+func EthereumAddress(pub *PublicKey) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(pub.SerializeUncompressed()[1:])
+	return h.Sum(nil)[12:]
+}
@@ -0,0 +1,194 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secp256k1
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/benchlab/bench-crypto/rand"
+)
+
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestPublicKey(t *testing.T) {
+	one := make([]byte, PrivateKeySize)
+	one[31] = 1
+	priv, err := NewPrivateKey(one)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := priv.PublicKey()
+
+	compressed := decodeHex("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	uncompressed := decodeHex("0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798" +
+		"483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8")
+	if got := pub.SerializeCompressed(); !bytes.Equal(got, compressed) {
+		t.Errorf("compressed public key %x, want %x", got, compressed)
+	}
+	if got := pub.SerializeUncompressed(); !bytes.Equal(got, uncompressed) {
+		t.Errorf("uncompressed public key %x, want %x", got, uncompressed)
+	}
+	address := decodeHex("7e5f4552091a69125d5dfcb7b8c2659029395bdf")
+	if got := EthereumAddress(pub); !bytes.Equal(got, address) {
+		t.Errorf("Ethereum address %x, want %x", got, address)
+	}
+	if !bytes.Equal(priv.Bytes(), one) {
+		t.Errorf("Bytes returned %x, want %x", priv.Bytes(), one)
+	}
+}
+
+func TestInvalidPrivateKeys(t *testing.T) {
+	for _, key := range []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", // n
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"01",
+	} {
+		if _, err := NewPrivateKey(decodeHex(key)); err == nil {
+			t.Errorf("NewPrivateKey accepted %s", key)
+		}
+	}
+	nMinus1 := decodeHex("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140")
+	priv, err := NewPrivateKey(nMinus1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// (n - 1) * G = -G has the same x-coordinate as G and an odd y.
+	want := decodeHex("0379be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	if got := priv.PublicKey().SerializeCompressed(); !bytes.Equal(got, want) {
+		t.Errorf("public key of n - 1 is %x, want %x", got, want)
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		priv, err := GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := priv.PublicKey()
+		for _, enc := range [][]byte{pub.SerializeCompressed(), pub.SerializeUncompressed()} {
+			parsed, err := ParsePublicKey(enc)
+			if err != nil {
+				t.Fatalf("ParsePublicKey(%x): %v", enc, err)
+			}
+			if !parsed.Equal(pub) {
+				t.Errorf("ParsePublicKey(%x) returned a different key", enc)
+			}
+		}
+	}
+
+	for _, enc := range []string{
+		"",
+		"00",
+		// x = 5 is not on the curve.
+		"020000000000000000000000000000000000000000000000000000000000000005",
+		// x = p
+		"02fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f",
+		// Wrong prefix for the length.
+		"0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		// G with the wrong y.
+		"0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798" +
+			"483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b9",
+	} {
+		if _, err := ParsePublicKey(decodeHex(enc)); err == nil {
+			t.Errorf("ParsePublicKey accepted %s", enc)
+		}
+	}
+}
+
+func TestTweakAdd(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tweak, _ := GenerateKey(rand.Reader)
+
+	sum, err := priv.TweakAdd(tweak.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubSum, err := priv.PublicKey().TweakAdd(tweak.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sum.PublicKey().Equal(pubSum) {
+		t.Errorf("public and private tweaks disagree")
+	}
+
+	// Adding n - d results in zero.
+	var neg scalar
+	neg.negate(&priv.d)
+	if _, err := priv.TweakAdd(neg.bytes()); err == nil {
+		t.Errorf("PrivateKey.TweakAdd returned zero")
+	}
+	if _, err := priv.PublicKey().TweakAdd(neg.bytes()); err == nil {
+		t.Errorf("PublicKey.TweakAdd returned the identity")
+	}
+	n := decodeHex("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")
+	if _, err := priv.TweakAdd(n); err == nil {
+		t.Errorf("PrivateKey.TweakAdd accepted a tweak of n")
+	}
+}
+
+func TestGroupLaw(t *testing.T) {
+	a, _ := GenerateKey(rand.Reader)
+	b, _ := GenerateKey(rand.Reader)
+
+	// a * G + b * G == (a + b) * G
+	var sum scalar
+	sum.add(&a.d, &b.d)
+	var p, q, r point
+	p.scalarBaseMult(&a.d)
+	q.scalarBaseMult(&b.d)
+	p.add(&p, &q)
+	r.scalarBaseMult(&sum)
+	x1, y1 := p.affine()
+	x2, y2 := r.affine()
+	if x1.equal(&x2)&y1.equal(&y2) != 1 {
+		t.Errorf("a * G + b * G != (a + b) * G")
+	}
+
+	// a * (b * G) == b * (a * G)
+	p.scalarMult(&a.d, b.PublicKey().point())
+	q.scalarMult(&b.d, a.PublicKey().point())
+	x1, y1 = p.affine()
+	x2, y2 = q.affine()
+	if x1.equal(&x2)&y1.equal(&y2) != 1 {
+		t.Errorf("a * (b * G) != b * (a * G)")
+	}
+
+	// 2 * P == P + P, and P - P is the identity.
+	p.double(a.PublicKey().point())
+	q.add(a.PublicKey().point(), a.PublicKey().point())
+	x1, y1 = p.affine()
+	x2, y2 = q.affine()
+	if x1.equal(&x2)&y1.equal(&y2) != 1 {
+		t.Errorf("2 * P != P + P")
+	}
+	q.negate(a.PublicKey().point())
+	q.add(&q, a.PublicKey().point())
+	if q.isIdentity() != 1 {
+		t.Errorf("P - P is not the identity")
+	}
+}
+
+func TestScalarInvert(t *testing.T) {
+	var x, inv, prod scalar
+	x.setBytesReduced(decodeHex("c90fdaa22168c234c4c6628b80dc1cd129024e088a67cc74020bbea63b14e5c9"))
+	inv.invert(&x)
+	prod.mul(&x, &inv)
+	if prod != (scalar{1}) {
+		t.Errorf("x * 1/x = %x, want 1", prod.bytes())
+	}
+}
@@ -0,0 +1,466 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha3
+
+// This file implements the cSHAKE customizable extendable-output functions
+// and the KMAC, TupleHash and ParallelHash functions derived from them, as
+// defined in NIST SP 800-185 [1].
+//
+// [1] https://doi.org/10.6028/NIST.SP.800-185
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"runtime"
+	"sync"
+)
+
+const (
+	// dsbyteCShake holds the "00" domain separation bits of cSHAKE,
+	// followed by the first bit of the padding. See the comment in the
+	// state struct.
+	dsbyteCShake = 0x04
+
+	rate128 = 168
+	rate256 = 136
+)
+
+// cshakeState is a cSHAKE sponge that absorbed its function-name and
+// customization strings.
+type cshakeState struct {
+	ShakeHash
+
+	// initChunk is bytepad(encode_string(N) || encode_string(S), rate). It
+	// is kept so that Reset can absorb it again.
+	initChunk []byte
+}
+
+// NewCShake128 creates a new cSHAKE128 variable-output-length ShakeHash.
+// N is the function-name string, reserved by NIST for functions defined on
+// top of cSHAKE, and S is a customization string chosen by the caller to
+// separate the outputs of different applications. When both are empty, the
+// result is equivalent to NewShake128. Its generic security strength is 128
+// bits against all attacks if at least 32 bytes of its output are used.
+func NewCShake128(N, S []byte) ShakeHash {
+	if len(N) == 0 && len(S) == 0 {
+		return NewShake128()
+	}
+	return newCShake(N, S, rate128)
+}
+
+// NewCShake256 creates a new cSHAKE256 variable-output-length ShakeHash.
+// N and S are the function-name and customization strings, as described for
+// NewCShake128. When both are empty, the result is equivalent to
+// NewShake256. Its generic security strength is 256 bits against all attacks
+// if at least 64 bytes of its output are used.
+func NewCShake256(N, S []byte) ShakeHash {
+	if len(N) == 0 && len(S) == 0 {
+		return NewShake256()
+	}
+	return newCShake(N, S, rate256)
+}
+
+func newCShake(N, S []byte, rate int) ShakeHash {
+	c := &cshakeState{initChunk: bytepad(append(encodeString(N), encodeString(S)...), rate)}
+	if c.ShakeHash = newCShakeAsm(rate); c.ShakeHash == nil {
+		c.ShakeHash = &state{rate: rate, dsbyte: dsbyteCShake}
+	}
+	c.ShakeHash.Write(c.initChunk)
+	return c
+}
+
+// Reset resets the ShakeHash to its state after absorbing N and S.
+func (c *cshakeState) Reset() {
+	c.ShakeHash.Reset()
+	c.ShakeHash.Write(c.initChunk)
+}
+
+// Clone returns a copy of the ShakeHash in its current state.
+func (c *cshakeState) Clone() ShakeHash {
+	return &cshakeState{ShakeHash: c.ShakeHash.Clone(), initChunk: c.initChunk}
+}
+
+const (
+	// cshakeMagic and kmacMagic identify the marshaled states of cSHAKE and
+	// KMAC. They are followed by a digest of the parameters of the
+	// instance, and then by the marshaled state of the underlying sponge.
+	cshakeMagic = "sha3\x02"
+	kmacMagic   = "sha3\x03"
+
+	paramsDigestSize = 32
+)
+
+// paramsDigest returns a digest of the strings that an instance absorbed
+// before any input, and of its output length, so that a marshaled state is
+// only restored into an instance with the same parameters.
+func paramsDigest(outputLen int, chunks ...[]byte) []byte {
+	d := &state{rate: rate256, dsbyte: 0x1f}
+	for _, c := range chunks {
+		d.Write(encodeString(c))
+	}
+	d.Write(rightEncode(uint64(outputLen) * 8))
+	out := make([]byte, paramsDigestSize)
+	d.Read(out)
+	return out
+}
+
+// marshalWithParams returns magic, followed by params and by the marshaled
+// state of the sponge d.
+func marshalWithParams(magic string, params []byte, d ShakeHash) ([]byte, error) {
+	s, err := d.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 0, len(magic)+len(params)+len(s))
+	b = append(b, magic...)
+	b = append(b, params...)
+	return append(b, s...), nil
+}
+
+// unmarshalWithParams restores into d a state returned by marshalWithParams
+// with the same magic and params.
+func unmarshalWithParams(magic string, params []byte, d ShakeHash, b []byte) error {
+	if len(b) < len(magic) || string(b[:len(magic)]) != magic {
+		return errors.New("sha3: invalid hash state identifier")
+	}
+	b = b[len(magic):]
+	if len(b) < len(params) {
+		return errors.New("sha3: invalid hash state size")
+	}
+	if !bytes.Equal(b[:len(params)], params) {
+		return errors.New("sha3: hash state was produced with different parameters")
+	}
+	return d.(encoding.BinaryUnmarshaler).UnmarshalBinary(b[len(params):])
+}
+
+// MarshalBinary returns the state of the sponge. It can only be restored
+// into an instance with the same function-name and customization strings.
+func (c *cshakeState) MarshalBinary() ([]byte, error) {
+	return marshalWithParams(cshakeMagic, paramsDigest(0, c.initChunk), c.ShakeHash)
+}
+
+// UnmarshalBinary restores a sponge state returned by MarshalBinary.
+func (c *cshakeState) UnmarshalBinary(b []byte) error {
+	return unmarshalWithParams(cshakeMagic, paramsDigest(0, c.initChunk), c.ShakeHash, b)
+}
+
+// kmac is a KMAC instance with a fixed output length.
+type kmac struct {
+	ShakeHash
+	rate      int
+	outputLen int
+
+	// keyChunk is bytepad(encode_string(K), rate). It is kept so that
+	// Reset can absorb it again.
+	keyChunk []byte
+}
+
+// NewKMAC128 returns a new KMAC128 hash.Hash computing the MAC of its input
+// under key, with an output of size bytes and the customization string S.
+// The key should be at least 16 bytes long to achieve the full 128-bit
+// security strength. Unlike other MACs, KMAC binds the output length to the
+// tag, so that tags of different lengths are unrelated.
+func NewKMAC128(key []byte, size int, S []byte) hash.Hash {
+	return newKMAC(key, size, S, rate128)
+}
+
+// NewKMAC256 returns a new KMAC256 hash.Hash computing the MAC of its input
+// under key, with an output of size bytes and the customization string S.
+// The key should be at least 32 bytes long to achieve the full 256-bit
+// security strength.
+func NewKMAC256(key []byte, size int, S []byte) hash.Hash {
+	return newKMAC(key, size, S, rate256)
+}
+
+func newKMAC(key []byte, size int, S []byte, rate int) *kmac {
+	if size <= 0 {
+		panic("sha3: KMAC output size must be positive")
+	}
+	k := &kmac{
+		ShakeHash: newCShake([]byte("KMAC"), S, rate),
+		rate:      rate,
+		outputLen: size,
+		keyChunk:  bytepad(encodeString(key), rate),
+	}
+	k.ShakeHash.Write(k.keyChunk)
+	return k
+}
+
+// Sum appends the MAC of the data written so far to b and returns the
+// resulting slice. It does not change the underlying hash state.
+func (k *kmac) Sum(b []byte) []byte {
+	dup := k.ShakeHash.Clone()
+	dup.Write(rightEncode(uint64(k.outputLen) * 8))
+	out := make([]byte, k.outputLen)
+	dup.Read(out)
+	return append(b, out...)
+}
+
+// Reset resets the hash to its state after absorbing the key.
+func (k *kmac) Reset() {
+	k.ShakeHash.Reset()
+	k.ShakeHash.Write(k.keyChunk)
+}
+
+// Size returns the output size of the MAC in bytes.
+func (k *kmac) Size() int { return k.outputLen }
+
+// ChunkSize returns the rate of the sponge underlying the MAC.
+func (k *kmac) ChunkSize() int { return k.rate }
+
+// MarshalBinary returns the state of the MAC. The state includes the
+// absorbed key and must be protected like it. It can only be restored into
+// an instance with the same key, output size and customization string.
+func (k *kmac) MarshalBinary() ([]byte, error) {
+	c := k.ShakeHash.(*cshakeState)
+	return marshalWithParams(kmacMagic, paramsDigest(k.outputLen, c.initChunk, k.keyChunk), c.ShakeHash)
+}
+
+// UnmarshalBinary restores a MAC state returned by MarshalBinary.
+func (k *kmac) UnmarshalBinary(b []byte) error {
+	c := k.ShakeHash.(*cshakeState)
+	return unmarshalWithParams(kmacMagic, paramsDigest(k.outputLen, c.initChunk, k.keyChunk), c.ShakeHash, b)
+}
+
+// kmacXOF is a KMAC instance with arbitrary-length output.
+type kmacXOF struct {
+	ShakeHash
+	keyChunk  []byte
+	squeezing bool
+}
+
+// NewKMACXOF128 returns a new ShakeHash computing KMACXOF128 of its input
+// under key, with the customization string S. The output of KMACXOF128 does
+// not depend on the number of bytes read.
+func NewKMACXOF128(key, S []byte) ShakeHash {
+	return newKMACXOF(key, S, rate128)
+}
+
+// NewKMACXOF256 returns a new ShakeHash computing KMACXOF256 of its input
+// under key, with the customization string S. The output of KMACXOF256 does
+// not depend on the number of bytes read.
+func NewKMACXOF256(key, S []byte) ShakeHash {
+	return newKMACXOF(key, S, rate256)
+}
+
+func newKMACXOF(key, S []byte, rate int) *kmacXOF {
+	k := &kmacXOF{
+		ShakeHash: newCShake([]byte("KMAC"), S, rate),
+		keyChunk:  bytepad(encodeString(key), rate),
+	}
+	k.ShakeHash.Write(k.keyChunk)
+	return k
+}
+
+// Read squeezes an arbitrary number of bytes from the MAC.
+func (k *kmacXOF) Read(out []byte) (int, error) {
+	if !k.squeezing {
+		k.ShakeHash.Write(rightEncode(0))
+		k.squeezing = true
+	}
+	return k.ShakeHash.Read(out)
+}
+
+// Clone returns a copy of the ShakeHash in its current state.
+func (k *kmacXOF) Clone() ShakeHash {
+	return &kmacXOF{ShakeHash: k.ShakeHash.Clone(), keyChunk: k.keyChunk, squeezing: k.squeezing}
+}
+
+// Reset resets the ShakeHash to its state after absorbing the key.
+func (k *kmacXOF) Reset() {
+	k.ShakeHash.Reset()
+	k.ShakeHash.Write(k.keyChunk)
+	k.squeezing = false
+}
+
+// MarshalBinary returns the state of the MAC. The state includes the
+// absorbed key and must be protected like it. It can only be restored into
+// an instance with the same key and customization string.
+func (k *kmacXOF) MarshalBinary() ([]byte, error) {
+	c := k.ShakeHash.(*cshakeState)
+	return marshalWithParams(kmacMagic, paramsDigest(0, c.initChunk, k.keyChunk), c.ShakeHash)
+}
+
+// UnmarshalBinary restores a MAC state returned by MarshalBinary.
+func (k *kmacXOF) UnmarshalBinary(b []byte) error {
+	c := k.ShakeHash.(*cshakeState)
+	if err := unmarshalWithParams(kmacMagic, paramsDigest(0, c.initChunk, k.keyChunk), c.ShakeHash, b); err != nil {
+		return err
+	}
+	k.squeezing = spongeDirection(b[len(kmacMagic)+paramsDigestSize+directionOffset]) == spongeSqueezing
+	return nil
+}
+
+// TupleHash128 writes the TupleHash128 digest of tuple with the
+// customization string S into hash. The digest depends on the length of
+// hash. Unlike hashing the concatenation of the elements, TupleHash
+// distinguishes between tuples such as ("abc", "d") and ("ab", "cd").
+func TupleHash128(hash []byte, tuple [][]byte, S []byte) {
+	tupleHash(hash, tuple, S, rate128, uint64(len(hash))*8)
+}
+
+// TupleHash256 writes the TupleHash256 digest of tuple with the
+// customization string S into hash. The digest depends on the length of
+// hash.
+func TupleHash256(hash []byte, tuple [][]byte, S []byte) {
+	tupleHash(hash, tuple, S, rate256, uint64(len(hash))*8)
+}
+
+// TupleHashXOF128 writes an arbitrary-length TupleHashXOF128 digest of tuple
+// with the customization string S into hash. Shorter digests are prefixes of
+// longer ones.
+func TupleHashXOF128(hash []byte, tuple [][]byte, S []byte) {
+	tupleHash(hash, tuple, S, rate128, 0)
+}
+
+// TupleHashXOF256 writes an arbitrary-length TupleHashXOF256 digest of tuple
+// with the customization string S into hash. Shorter digests are prefixes of
+// longer ones.
+func TupleHashXOF256(hash []byte, tuple [][]byte, S []byte) {
+	tupleHash(hash, tuple, S, rate256, 0)
+}
+
+func tupleHash(hash []byte, tuple [][]byte, S []byte, rate int, outputBits uint64) {
+	h := newCShake([]byte("TupleHash"), S, rate)
+	for _, x := range tuple {
+		h.Write(leftEncode(uint64(len(x)) * 8))
+		h.Write(x)
+	}
+	h.Write(rightEncode(outputBits))
+	h.Read(hash)
+}
+
+// ParallelHash128 writes the ParallelHash128 digest of data, split into
+// chunks of chunkSize bytes, with the customization string S into hash. The
+// digest depends on the length of hash and on chunkSize. The chunks are
+// hashed concurrently on up to GOMAXPROCS goroutines.
+func ParallelHash128(hash, data []byte, chunkSize int, S []byte) {
+	parallelHash(hash, data, chunkSize, S, rate128, uint64(len(hash))*8)
+}
+
+// ParallelHash256 writes the ParallelHash256 digest of data, split into
+// chunks of chunkSize bytes, with the customization string S into hash. The
+// digest depends on the length of hash and on chunkSize.
+func ParallelHash256(hash, data []byte, chunkSize int, S []byte) {
+	parallelHash(hash, data, chunkSize, S, rate256, uint64(len(hash))*8)
+}
+
+// ParallelHashXOF128 writes an arbitrary-length ParallelHashXOF128 digest of
+// data, split into chunks of chunkSize bytes, with the customization string
+// S into hash. Shorter digests are prefixes of longer ones.
+func ParallelHashXOF128(hash, data []byte, chunkSize int, S []byte) {
+	parallelHash(hash, data, chunkSize, S, rate128, 0)
+}
+
+// ParallelHashXOF256 writes an arbitrary-length ParallelHashXOF256 digest of
+// data, split into chunks of chunkSize bytes, with the customization string
+// S into hash. Shorter digests are prefixes of longer ones.
+func ParallelHashXOF256(hash, data []byte, chunkSize int, S []byte) {
+	parallelHash(hash, data, chunkSize, S, rate256, 0)
+}
+
+// minParallelChunks is the number of chunks from which ParallelHash starts
+// additional goroutines.
+const minParallelChunks = 4
+
+func parallelHash(hash, data []byte, chunkSize int, S []byte, rate int, outputBits uint64) {
+	if chunkSize <= 0 {
+		panic("sha3: ParallelHash chunk size must be positive")
+	}
+	newShake, leafSize := NewShake128, 32
+	if rate == rate256 {
+		newShake, leafSize = NewShake256, 64
+	}
+
+	n := (len(data) + chunkSize - 1) / chunkSize
+	leaves := make([]byte, n*leafSize)
+	hashLeaves := func(lo, hi int) {
+		d := newShake()
+		for i := lo; i < hi; i++ {
+			end := (i + 1) * chunkSize
+			if end > len(data) {
+				end = len(data)
+			}
+			d.Reset()
+			d.Write(data[i*chunkSize : end])
+			d.Read(leaves[i*leafSize : (i+1)*leafSize])
+		}
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n/minParallelChunks {
+		workers = n / minParallelChunks
+	}
+	if workers < 2 {
+		hashLeaves(0, n)
+	} else {
+		var wg sync.WaitGroup
+		step := (n + workers - 1) / workers
+		for lo := 0; lo < n; lo += step {
+			hi := lo + step
+			if hi > n {
+				hi = n
+			}
+			wg.Add(1)
+			go func(lo, hi int) {
+				defer wg.Done()
+				hashLeaves(lo, hi)
+			}(lo, hi)
+		}
+		wg.Wait()
+	}
+
+	h := newCShake([]byte("ParallelHash"), S, rate)
+	h.Write(leftEncode(uint64(chunkSize)))
+	h.Write(leaves)
+	h.Write(rightEncode(uint64(n)))
+	h.Write(rightEncode(outputBits))
+	h.Read(hash)
+}
+
+// leftEncode returns the left_encode(x) encoding of SP 800-185: the
+// big-endian bytes of x with leading zeros removed, prefixed by their number.
+func leftEncode(x uint64) []byte {
+	var b [9]byte
+	binary.BigEndian.PutUint64(b[1:], x)
+	i := 1
+	for i < 8 && b[i] == 0 {
+		i++
+	}
+	b[i-1] = byte(9 - i)
+	return append([]byte(nil), b[i-1:]...)
+}
+
+// rightEncode returns the right_encode(x) encoding of SP 800-185: the
+// big-endian bytes of x with leading zeros removed, followed by their number.
+func rightEncode(x uint64) []byte {
+	var b [9]byte
+	binary.BigEndian.PutUint64(b[:8], x)
+	i := 0
+	for i < 7 && b[i] == 0 {
+		i++
+	}
+	b[8] = byte(8 - i)
+	return append([]byte(nil), b[i:]...)
+}
+
+// encodeString returns encode_string(s), which is s prefixed by its bit
+// length encoded with leftEncode.
+func encodeString(s []byte) []byte {
+	return append(leftEncode(uint64(len(s))*8), s...)
+}
+
+// bytepad prefixes x with leftEncode(w) and pads it with zeros to a
+// multiple of w bytes.
+func bytepad(x []byte, w int) []byte {
+	b := append(leftEncode(uint64(w)), x...)
+	if padding := len(b) % w; padding != 0 {
+		b = append(b, make([]byte, w-padding)...)
+	}
+	return b
+}
@@ -0,0 +1,291 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha3
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/hex"
+	"io"
+	"runtime"
+	"testing"
+)
+
+// Test vectors from the NIST SP 800-185 example values at
+// https://csrc.nist.gov/projects/cryptographic-standards-and-guidelines/example-values
+
+func TestCShakeSamples(t *testing.T) {
+	tests := []struct {
+		newHash func(N, S []byte) ShakeHash
+		data    []byte
+		S       string
+		want    string
+	}{
+		{
+			NewCShake128, sequentialBytes(4), "Email Signature",
+			"c1c36925b6409a04f1b504fcbca9d82b4017277cb5ed2b2065fc1d3814d5aaf5",
+		},
+		{
+			NewCShake128, sequentialBytes(200), "Email Signature",
+			"c5221d50e4f822d96a2e8881a961420f294b7b24fe3d2094baed2c6524cc166b",
+		},
+		{
+			NewCShake256, sequentialBytes(4), "Email Signature",
+			"d008828e2b80ac9d2218ffee1d070c48b8e4c87bff32c9699d5b6896eee0edd1" +
+				"64020e2be0560858d9c00c037e34a96937c561a74c412bb4c746469527281c8c",
+		},
+		{
+			NewCShake256, sequentialBytes(200), "Email Signature",
+			"07dc27b11e51fbac75bc7b3c1d983e8b4b85fb1defaf218912ac864302730917" +
+				"27f42b17ed1df63e8ec118f04b23633c1dfb1574c8fb55cb45da8e25afb092bb",
+		},
+	}
+	testUnalignedAndGeneric(t, func(impl string) {
+		for i, tt := range tests {
+			h := tt.newHash(nil, []byte(tt.S))
+			h.Write(tt.data)
+			got := make([]byte, len(tt.want)/2)
+			h.Read(got)
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("%s: #%d: got %x, want %s", impl, i, got, tt.want)
+			}
+
+			// Reset must restore the customization.
+			h.Reset()
+			h.Write(tt.data)
+			h.Read(got)
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("%s: #%d: after Reset got %x, want %s", impl, i, got, tt.want)
+			}
+		}
+	})
+}
+
+func TestCShakeEmptyCustomization(t *testing.T) {
+	for _, newHash := range []func() ShakeHash{
+		func() ShakeHash { return NewCShake128(nil, nil) },
+		func() ShakeHash { return NewCShake256(nil, nil) },
+	} {
+		h := newHash()
+		if _, ok := h.(*cshakeState); ok {
+			t.Errorf("cSHAKE with empty N and S is not plain SHAKE")
+		}
+	}
+}
+
+func TestCShakeClone(t *testing.T) {
+	h := NewCShake256([]byte("N"), []byte("S"))
+	h.Write([]byte(testString))
+	c := h.Clone()
+	want, got := make([]byte, 64), make([]byte, 64)
+	h.Read(want)
+	c.Read(got)
+	if !bytes.Equal(got, want) {
+		t.Errorf("clone output %x, want %x", got, want)
+	}
+	c.Reset()
+	c.Write([]byte(testString))
+	c.Read(got)
+	if !bytes.Equal(got, want) {
+		t.Errorf("output of reset clone %x, want %x", got, want)
+	}
+}
+
+func kmacKey() []byte {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(0x40 + i)
+	}
+	return key
+}
+
+func TestKMACSamples(t *testing.T) {
+	tests := []struct {
+		size int
+		data []byte
+		S    string
+		want string
+	}{
+		{
+			32, sequentialBytes(4), "",
+			"e5780b0d3ea6f7d3a429c5706aa43a00fadbd7d49628839e3187243f456ee14e",
+		},
+		{
+			32, sequentialBytes(4), "My Tagged Application",
+			"3b1fba963cd8b0b59e8c1a6d71888b7143651af8ba0a7070c0979e2811324aa5",
+		},
+		{
+			32, sequentialBytes(200), "My Tagged Application",
+			"1f5b4e6cca02209e0dcb5ca635b89a15e271ecc760071dfd805faa38f9729230",
+		},
+		{
+			64, sequentialBytes(4), "My Tagged Application",
+			"20c570c31346f703c9ac36c61c03cb64c3970d0cfc787e9b79599d273a68d2f7" +
+				"f69d4cc3de9d104a351689f27cf6f5951f0103f33f4f24871024d9c27773a8dd",
+		},
+	}
+	for i, tt := range tests {
+		newKMAC := NewKMAC128
+		if tt.size == 64 {
+			newKMAC = NewKMAC256
+		}
+		h := newKMAC(kmacKey(), tt.size, []byte(tt.S))
+		h.Write(tt.data)
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.want {
+			t.Errorf("#%d: got %s, want %s", i, got, tt.want)
+		}
+		h.Reset()
+		h.Write(tt.data)
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.want {
+			t.Errorf("#%d: after Reset got %s, want %s", i, got, tt.want)
+		}
+	}
+}
+
+func TestKMACXOF(t *testing.T) {
+	h := NewKMACXOF128(kmacKey(), nil)
+	h.Write(sequentialBytes(4))
+	c := h.Clone()
+	short, long := make([]byte, 32), make([]byte, 64)
+	h.Read(short)
+	c.Read(long)
+	if !bytes.Equal(short, long[:32]) {
+		t.Errorf("KMACXOF output is not a prefix of longer output")
+	}
+
+	// KMACXOF and KMAC of the same length must differ, as the output
+	// length is encoded into the latter.
+	fixed := NewKMAC128(kmacKey(), 32, nil)
+	fixed.Write(sequentialBytes(4))
+	if bytes.Equal(fixed.Sum(nil), short) {
+		t.Errorf("KMACXOF output equals KMAC output")
+	}
+}
+
+// TestUnmarshalParamsMismatch checks that the state of a cSHAKE or KMAC
+// instance cannot be restored into an instance with different parameters.
+func TestUnmarshalParamsMismatch(t *testing.T) {
+	key1, key2 := []byte("key one"), []byte("key two")
+	for _, tt := range []struct {
+		name     string
+		from, to func() interface{}
+	}{
+		{"cSHAKE128 S", func() interface{} { return NewCShake128([]byte("N"), []byte("S1")) }, func() interface{} { return NewCShake128([]byte("N"), []byte("S2")) }},
+		{"cSHAKE128 N", func() interface{} { return NewCShake128([]byte("N1"), []byte("S")) }, func() interface{} { return NewCShake128([]byte("N2"), []byte("S")) }},
+		{"cSHAKE256 to SHAKE256", func() interface{} { return NewCShake256([]byte("N"), nil) }, func() interface{} { return NewShake256() }},
+		{"SHAKE256 to cSHAKE256", func() interface{} { return NewShake256() }, func() interface{} { return NewCShake256([]byte("N"), nil) }},
+		{"cSHAKE128 to KMACXOF128", func() interface{} { return NewCShake128([]byte("KMAC"), nil) }, func() interface{} { return NewKMACXOF128(key1, nil) }},
+		{"KMAC128 key", func() interface{} { return NewKMAC128(key1, 32, nil) }, func() interface{} { return NewKMAC128(key2, 32, nil) }},
+		{"KMAC128 size", func() interface{} { return NewKMAC128(key1, 32, nil) }, func() interface{} { return NewKMAC128(key1, 64, nil) }},
+		{"KMAC128 S", func() interface{} { return NewKMAC128(key1, 32, []byte("S1")) }, func() interface{} { return NewKMAC128(key1, 32, []byte("S2")) }},
+		{"KMAC256 to KMACXOF256", func() interface{} { return NewKMAC256(key1, 32, nil) }, func() interface{} { return NewKMACXOF256(key1, nil) }},
+		{"KMACXOF256 key", func() interface{} { return NewKMACXOF256(key1, nil) }, func() interface{} { return NewKMACXOF256(key2, nil) }},
+	} {
+		from := tt.from()
+		from.(io.Writer).Write([]byte(testString))
+		state, err := from.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: could not marshal: %v", tt.name, err)
+		}
+		if err := tt.from().(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+			t.Errorf("%s: could not unmarshal into an identical instance: %v", tt.name, err)
+		}
+		if err := tt.to().(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err == nil {
+			t.Errorf("%s: state restored into an instance with different parameters", tt.name)
+		}
+	}
+}
+
+func TestTupleHashSamples(t *testing.T) {
+	tuple := [][]byte{sequentialBytes(3), {0x10, 0x11, 0x12, 0x13, 0x14, 0x15}}
+	tests := []struct {
+		S    string
+		want string
+	}{
+		{"", "c5d8786c1afb9b82111ab34b65b2c0048fa64e6d48e263264ce1707d3ffc8ed1"},
+		{"My Tuple App", "75cdb20ff4db1154e841d758e24160c54bae86eb8c13e7f5f40eb35588e96dfb"},
+	}
+	for i, tt := range tests {
+		got := make([]byte, 32)
+		TupleHash128(got, tuple, []byte(tt.S))
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("#%d: got %x, want %s", i, got, tt.want)
+		}
+	}
+
+	// The split between the elements of a tuple must matter.
+	a, b := make([]byte, 32), make([]byte, 32)
+	TupleHash256(a, [][]byte{[]byte("abc"), []byte("d")}, nil)
+	TupleHash256(b, [][]byte{[]byte("ab"), []byte("cd")}, nil)
+	if bytes.Equal(a, b) {
+		t.Errorf("TupleHash256 does not separate tuple elements")
+	}
+}
+
+func TestParallelHashSamples(t *testing.T) {
+	data := decodeHex("000102030405060710111213141516172021222324252627")
+	tests := []struct {
+		S    string
+		want string
+	}{
+		{"", "ba8dc1d1d979331d3f813603c67f72609ab5e44b94a0b8f9af46514454a2b4f5"},
+		{"Parallel Data", "fc484dcb3f84dceedc353438151bee58157d6efed0445a81f165e495795b7206"},
+	}
+	for i, tt := range tests {
+		got := make([]byte, 32)
+		ParallelHash128(got, data, 8, []byte(tt.S))
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("#%d: got %x, want %s", i, got, tt.want)
+		}
+	}
+}
+
+func TestParallelHashConcurrency(t *testing.T) {
+	data := sequentialBytes(100000)
+	want := make([]byte, 64)
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	ParallelHashXOF256(want, data, 1000, nil)
+
+	runtime.GOMAXPROCS(8)
+	got := make([]byte, 64)
+	ParallelHashXOF256(got, data, 1000, nil)
+	if !bytes.Equal(got, want) {
+		t.Errorf("concurrent ParallelHash output %x, want %x", got, want)
+	}
+}
+
+func TestEncodings(t *testing.T) {
+	tests := []struct {
+		x           uint64
+		left, right string
+	}{
+		{0, "0100", "0001"},
+		{1, "0101", "0101"},
+		{255, "01ff", "ff01"},
+		{256, "020100", "010002"},
+		{1<<64 - 1, "08ffffffffffffffff", "ffffffffffffffff08"},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(leftEncode(tt.x)); got != tt.left {
+			t.Errorf("leftEncode(%d) = %s, want %s", tt.x, got, tt.left)
+		}
+		if got := hex.EncodeToString(rightEncode(tt.x)); got != tt.right {
+			t.Errorf("rightEncode(%d) = %s, want %s", tt.x, got, tt.right)
+		}
+	}
+}
+
+func BenchmarkKMAC128_MTU(b *testing.B) {
+	benchmarkHash(b, NewKMAC128(kmacKey(), 32, nil), 1350, 1)
+}
+
+func BenchmarkParallelHash128_1MiB(b *testing.B) {
+	data := sequentialBytes(1 << 20)
+	out := make([]byte, 32)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		ParallelHash128(out, data, 8192, nil)
+	}
+}
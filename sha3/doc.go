@@ -3,7 +3,9 @@
 // license that can be found in the LICENSE file.
 
 // Package sha3 implements the SHA-3 fixed-output-length hash functions and
-// the SHAKE variable-output-length hash functions defined by FIPS-202.
+// the SHAKE variable-output-length hash functions defined by FIPS-202, as well
+// as the cSHAKE, KMAC, TupleHash and ParallelHash functions derived from them
+// in NIST SP 800-185.
 //
 // Both types of hash function use the "sponge" construction and the Keccak
 // permutation. For a detailed specification see http://keccak.noekeon.org/
@@ -15,9 +17,12 @@
 // bytes of output. The SHAKE instances are faster than the SHA3 instances;
 // the latter have to allocate memory to conform to the hash.Hash interface.
 //
-// If you need a secret-key MAC (message authentication code), prepend the
-// secret key to the input, hash with SHAKE256 and read at least 32 bytes of
-// output.
+// If you need a secret-key MAC (message authentication code), use KMAC256
+// with a key of at least 32 bytes. Alternatively, prepend the secret key to
+// the input, hash with SHAKE256 and read at least 32 bytes of output.
+//
+// If you need to separate the hashes computed by different applications or
+// protocols, use cSHAKE with a customization string naming the application.
 //
 //
 // Security strengths
@@ -51,6 +56,19 @@
 // that the security strength of a sponge instance is equal to (1600 - bitrate) / 2.
 //
 //
+// Checkpointing
+//
+// The hashes and ShakeHash instances returned by this package implement
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler, so that a
+// long-running computation can be saved and resumed later, possibly on a
+// different platform. The marshaled state starts with a version tag and can
+// only be restored into an instance of the same function with the same
+// parameters: the function-name and customization strings of cSHAKE, and the
+// key, customization string and output size of KMAC. The state of a keyed
+// function such as KMAC includes the key. On IBM Z, SHAKE instances cannot
+// be marshaled once output has been read from them.
+//
+//
 // Recommendations
 //
 // The SHAKE functions are recommended for most new uses. They can produce
@@ -4,6 +4,11 @@
 
 package sha3
 
+import (
+	"encoding/binary"
+	"errors"
+)
+
 // spongeDirection indicates the direction bytes are flowing through the sponge.
 type spongeDirection int
 
@@ -68,12 +73,80 @@ func (d *state) clone() *state {
 	if ret.state == spongeAbsorbing {
 		ret.buf = ret.storage[:len(ret.buf)]
 	} else {
-		ret.buf = ret.storage[d.rate-cap(d.buf) : d.rate]
+		// While squeezing, buf holds the unread tail of the output
+		// chunk, which always ends at the rate.
+		ret.buf = ret.storage[d.rate-len(d.buf) : d.rate]
 	}
 
 	return &ret
 }
 
+const (
+	// magic identifies a marshaled sponge state. Its last byte is the
+	// version of the format, which is followed by the rate, the output
+	// length, the domain separation byte, the sponge direction, the number
+	// of buffered bytes, the 25 lanes of the permutation state and the
+	// buffer storage.
+	magic         = "sha3\x01"
+	marshaledSize = len(magic) + 1 + 2 + 1 + 1 + 1 + 25*8 + maxRate
+
+	// directionOffset is the offset of the sponge direction in a
+	// marshaled state.
+	directionOffset = len(magic) + 1 + 2 + 1
+)
+
+// MarshalBinary returns the state of the sponge in a format that can be
+// restored with UnmarshalBinary, including by a different implementation of
+// the same function.
+func (d *state) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, marshaledSize)
+	b = append(b, magic...)
+	b = append(b, byte(d.rate), byte(d.outputLen>>8), byte(d.outputLen), d.dsbyte, byte(d.state), byte(len(d.buf)))
+	for _, lane := range d.a {
+		var l [8]byte
+		binary.LittleEndian.PutUint64(l[:], lane)
+		b = append(b, l[:]...)
+	}
+	return append(b, d.storage[:]...), nil
+}
+
+// UnmarshalBinary restores a sponge state returned by MarshalBinary. The
+// state must have been produced by the same function as d.
+func (d *state) UnmarshalBinary(b []byte) error {
+	if len(b) < len(magic) || string(b[:len(magic)]) != magic {
+		return errors.New("sha3: invalid hash state identifier")
+	}
+	if len(b) != marshaledSize {
+		return errors.New("sha3: invalid hash state size")
+	}
+	b = b[len(magic):]
+	rate, outputLen, dsbyte := int(b[0]), int(b[1])<<8|int(b[2]), b[3]
+	if rate != d.rate || outputLen != d.outputLen || dsbyte != d.dsbyte {
+		return errors.New("sha3: hash state was produced by a different function")
+	}
+	direction, buffered := spongeDirection(b[4]), int(b[5])
+	switch {
+	case direction == spongeAbsorbing && buffered < rate:
+	case direction == spongeSqueezing && buffered > 0 && buffered <= rate:
+	default:
+		return errors.New("sha3: invalid hash state")
+	}
+	b = b[6:]
+
+	for i := range d.a {
+		d.a[i] = binary.LittleEndian.Uint64(b)
+		b = b[8:]
+	}
+	copy(d.storage[:], b)
+	d.state = direction
+	if direction == spongeAbsorbing {
+		d.buf = d.storage[:buffered]
+	} else {
+		d.buf = d.storage[rate-buffered : rate]
+	}
+	return nil
+}
+
 // permute applies the KeccakF-1600 permutation. It handles
 // any input-output buffering.
 func (d *state) permute() {
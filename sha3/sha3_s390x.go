@@ -11,6 +11,8 @@ package sha3
 // instructions to compute SHA-3 and SHAKE hashes on IBM Z.
 
 import (
+	"encoding/binary"
+	"errors"
 	"hash"
 )
 
@@ -54,6 +56,14 @@ type asmState struct {
 	outputLen int             // output length if fixed, 0 if not
 	function  code            // KIMD/KLMD function code
 	state     spongeDirection // whether the sponge is absorbing or squeezing
+
+	// dsbyte is the domain separation byte for sponges, such as cSHAKE,
+	// whose padding KLMD does not implement. It is zero otherwise.
+	dsbyte byte
+	// squeezer is the generic sponge that output is read from once a
+	// sponge with a non-zero dsbyte has been padded, or once a squeezing
+	// state has been unmarshaled.
+	squeezer *state
 }
 
 func newAsmState(function code) *asmState {
@@ -88,6 +98,9 @@ func newAsmState(function code) *asmState {
 func (s *asmState) clone() *asmState {
 	c := *s
 	c.buf = c.storage[:len(s.buf):cap(s.buf)]
+	if s.squeezer != nil {
+		c.squeezer = s.squeezer.clone()
+	}
 	return &c
 }
 
@@ -144,6 +157,13 @@ func (s *asmState) Write(b []byte) (int, error) {
 
 // Read squeezes an arbitrary number of bytes from the sponge.
 func (s *asmState) Read(out []byte) (n int, err error) {
+	if s.squeezer == nil && s.dsbyte != 0 {
+		s.padCustom()
+	}
+	if s.squeezer != nil {
+		return s.squeezer.Read(out)
+	}
+
 	n = len(out)
 
 	// need to pad if we were absorbing
@@ -191,6 +211,77 @@ func (s *asmState) Read(out []byte) (n int, err error) {
 	return
 }
 
+// padCustom hands the sponge over to the generic implementation, which
+// applies the padding for s.dsbyte and squeezes the output.
+func (s *asmState) padCustom() {
+	d := s.toGeneric()
+	d.padAndPermute(d.dsbyte)
+	s.buf = s.buf[:0]
+	s.state = spongeSqueezing
+	s.squeezer = d
+}
+
+// toGeneric returns a generic sponge in the same state as s, which must be
+// absorbing. Complete chunks of buffered input are absorbed with KIMD.
+func (s *asmState) toGeneric() *state {
+	a := s.a
+	full := len(s.buf) - len(s.buf)%s.rate
+	kimd(s.function, &a, s.buf[:full])
+
+	d := &state{rate: s.rate, outputLen: s.outputLen, dsbyte: s.genericDSByte()}
+	for i := range d.a {
+		d.a[i] = binary.LittleEndian.Uint64(a[8*i:])
+	}
+	d.buf = append(d.storage[:0], s.buf[full:]...)
+	return d
+}
+
+// genericDSByte returns the domain separation byte the generic
+// implementation uses for the function of s.
+func (s *asmState) genericDSByte() byte {
+	switch {
+	case s.dsbyte != 0:
+		return s.dsbyte
+	case s.function == shake_128 || s.function == shake_256:
+		return 0x1f
+	default:
+		return 0x06
+	}
+}
+
+// MarshalBinary returns the state of the sponge in the format used by the
+// generic implementation. The state of a SHA-3 or SHAKE sponge cannot be
+// marshaled once KLMD started squeezing it.
+func (s *asmState) MarshalBinary() ([]byte, error) {
+	if s.squeezer != nil {
+		return s.squeezer.MarshalBinary()
+	}
+	if s.state != spongeAbsorbing {
+		return nil, errors.New("sha3: cannot marshal the state of a sponge squeezed by KLMD")
+	}
+	return s.toGeneric().MarshalBinary()
+}
+
+// UnmarshalBinary restores a sponge state returned by MarshalBinary. A
+// sponge restored while squeezing is read with the generic implementation.
+func (s *asmState) UnmarshalBinary(b []byte) error {
+	d := &state{rate: s.rate, outputLen: s.outputLen, dsbyte: s.genericDSByte()}
+	if err := d.UnmarshalBinary(b); err != nil {
+		return err
+	}
+	s.Reset()
+	if d.state == spongeSqueezing {
+		s.state = spongeSqueezing
+		s.squeezer = d
+		return nil
+	}
+	for i, lane := range d.a {
+		binary.LittleEndian.PutUint64(s.a[8*i:], lane)
+	}
+	s.copyIntoBuf(d.buf)
+	return nil
+}
+
 // Sum appends the current hash to b and returns the resulting slice.
 // It does not change the underlying hash state.
 func (s *asmState) Sum(b []byte) []byte {
@@ -214,6 +305,7 @@ func (s *asmState) Reset() {
 	}
 	s.resetBuf()
 	s.state = spongeAbsorbing
+	s.squeezer = nil
 }
 
 // Size returns the number of bytes Sum will return.
@@ -287,3 +379,23 @@ func newShake256Asm() ShakeHash {
 	}
 	return nil
 }
+
+// newCShakeAsm returns an assembly implementation of the cSHAKE sponge with
+// the given rate if available, otherwise it returns nil. Input is absorbed
+// with KIMD, while padding and output use the generic code.
+func newCShakeAsm(rate int) ShakeHash {
+	if !hasAsm {
+		return nil
+	}
+	var s *asmState
+	switch rate {
+	case rate128:
+		s = newAsmState(shake_128)
+	case rate256:
+		s = newAsmState(shake_256)
+	default:
+		return nil
+	}
+	s.dsbyte = dsbyteCShake
+	return s
+}
@@ -13,6 +13,7 @@ package sha3
 import (
 	"bytes"
 	"compress/flate"
+	"encoding"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -240,6 +241,112 @@ func TestSqueezing(t *testing.T) {
 	})
 }
 
+// TestMarshal checks that a hash restored from the marshaled state of a
+// partially written hash produces the same output.
+func TestMarshal(t *testing.T) {
+	testUnalignedAndGeneric(t, func(impl string) {
+		input := sequentialBytes(400)
+		for alg, df := range testDigests {
+			for _, i := range []int{0, 1, 71, 72, 73, 135, 136, 137, 200, 400} {
+				h, h2 := df(), df()
+				h.Write(input[:i/2])
+				halfstate, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+				if err != nil {
+					t.Fatalf("%s (%s), len(input)=%d: could not marshal: %v", alg, impl, i, err)
+				}
+				if err := h2.(encoding.BinaryUnmarshaler).UnmarshalBinary(halfstate); err != nil {
+					t.Fatalf("%s (%s), len(input)=%d: could not unmarshal: %v", alg, impl, i, err)
+				}
+				h.Write(input[i/2 : i])
+				h2.Write(input[i/2 : i])
+				if sum, sum2 := h.Sum(nil), h2.Sum(nil); !bytes.Equal(sum, sum2) {
+					t.Fatalf("%s (%s), len(input)=%d: results do not match; sum = %x, sum2 = %x", alg, impl, i, sum, sum2)
+				}
+			}
+		}
+	})
+}
+
+// TestMarshalSqueezing checks that the state of a ShakeHash can be marshaled
+// and restored while output is being read from it.
+func TestMarshalSqueezing(t *testing.T) {
+	testShakes := map[string]func() ShakeHash{
+		"SHAKE128":   NewShake128,
+		"SHAKE256":   NewShake256,
+		"cSHAKE128":  func() ShakeHash { return NewCShake128([]byte("N"), []byte("S")) },
+		"cSHAKE256":  func() ShakeHash { return NewCShake256([]byte("N"), []byte("S")) },
+		"KMACXOF128": func() ShakeHash { return NewKMACXOF128([]byte("key"), nil) },
+		"KMACXOF256": func() ShakeHash { return NewKMACXOF256([]byte("key"), nil) },
+	}
+	for alg, newShakeHash := range testShakes {
+		want := make([]byte, 500)
+		h := newShakeHash()
+		h.Write([]byte(testString))
+		h.Read(want)
+
+		for _, split := range []int{0, 1, 135, 136, 137, 168, 300} {
+			h, h2 := newShakeHash(), newShakeHash()
+			h.Write([]byte(testString))
+			got := make([]byte, len(want))
+			h.Read(got[:split])
+			state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				t.Fatalf("%s, split=%d: could not marshal: %v", alg, split, err)
+			}
+			if err := h2.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+				t.Fatalf("%s, split=%d: could not unmarshal: %v", alg, split, err)
+			}
+			h2.Read(got[split:])
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s, split=%d: output of restored hash does not match", alg, split)
+			}
+		}
+	}
+}
+
+// TestUnmarshalMismatch checks that a state cannot be restored into a hash
+// of a different function.
+func TestUnmarshalMismatch(t *testing.T) {
+	state, err := New256().(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for alg, df := range testDigests {
+		if alg == "SHA3-256" {
+			continue
+		}
+		if err := df().(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err == nil {
+			t.Errorf("%s accepted the state of SHA3-256", alg)
+		}
+	}
+	if err := New256().(encoding.BinaryUnmarshaler).UnmarshalBinary(state[:len(state)-1]); err == nil {
+		t.Errorf("truncated state accepted")
+	}
+	state[len(magic)-1]++
+	if err := New256().(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err == nil {
+		t.Errorf("state with unknown version accepted")
+	}
+}
+
+// TestCloneSqueezing checks that a clone of a ShakeHash taken while output
+// is being read from it continues the same output.
+func TestCloneSqueezing(t *testing.T) {
+	for functionName, newShakeHash := range testShakes {
+		d := newShakeHash()
+		d.Write([]byte(testString))
+		want := make([]byte, 400)
+		d.Read(want[:30])
+		c := d.Clone()
+		d.Read(want[30:])
+
+		got := make([]byte, 370)
+		c.Read(got)
+		if !bytes.Equal(got, want[30:]) {
+			t.Errorf("%s: output of clone does not match", functionName)
+		}
+	}
+}
+
 // sequentialBytes produces a buffer of size consecutive bytes 0x00, 0x01, ..., used for testing.
 func sequentialBytes(size int) []byte {
 	result := make([]byte, size)
@@ -17,6 +17,7 @@ import (
 	"github.com/benchlab/bench-crypto/subtle"
 
 	"golang.org/x/github.com/benchlab/bench-crypto/curve25519"
+	"golang.org/x/github.com/benchlab/bench-crypto/x448"
 )
 
 const (
@@ -26,6 +27,7 @@ const (
 	kexAlgoECDH384          = "ecdh-sha2-nistp384"
 	kexAlgoECDH521          = "ecdh-sha2-nistp521"
 	kexAlgoCurve25519SHA256 = "curve25519-sha256@libssh.org"
+	kexAlgoCurve448SHA512   = "curve448-sha512"
 )
 
 // kexResult captures the outcome of a key exchange.
@@ -404,6 +406,7 @@ func init() {
 	kexAlgoMap[kexAlgoECDH384] = &ecdh{elliptic.P384()}
 	kexAlgoMap[kexAlgoECDH256] = &ecdh{elliptic.P256()}
 	kexAlgoMap[kexAlgoCurve25519SHA256] = &curve25519sha256{}
+	kexAlgoMap[kexAlgoCurve448SHA512] = &curve448sha512{}
 }
 
 // curve25519sha256 implements the curve25519-sha256@libssh.org key
@@ -540,3 +543,137 @@ func (kex *curve25519sha256) Server(c packetConn, rand io.Reader, magics *handsh
 		Hash:      crypto.SHA256,
 	}, nil
 }
+
+// curve448sha512 implements the curve448-sha512 key agreement protocol, as
+// described in RFC 8731. It is the curve25519-sha256 protocol with X448 and
+// SHA-512.
+type curve448sha512 struct{}
+
+type curve448KeyPair struct {
+	priv [56]byte
+	pub  [56]byte
+}
+
+func (kp *curve448KeyPair) generate(rand io.Reader) error {
+	if _, err := io.ReadFull(rand, kp.priv[:]); err != nil {
+		return err
+	}
+	x448.ScalarBaseMult(&kp.pub, &kp.priv)
+	return nil
+}
+
+// curve448Zeros is an array of 56 zero bytes to compare against in order to
+// reject curve448 points with the wrong order.
+var curve448Zeros [56]byte
+
+func (kex *curve448sha512) Client(c packetConn, rand io.Reader, magics *handshakeMagics) (*kexResult, error) {
+	var kp curve448KeyPair
+	if err := kp.generate(rand); err != nil {
+		return nil, err
+	}
+	if err := c.writePacket(Marshal(&kexECDHInitMsg{kp.pub[:]})); err != nil {
+		return nil, err
+	}
+
+	packet, err := c.readPacket()
+	if err != nil {
+		return nil, err
+	}
+
+	var reply kexECDHReplyMsg
+	if err = Unmarshal(packet, &reply); err != nil {
+		return nil, err
+	}
+	if len(reply.EphemeralPubKey) != 56 {
+		return nil, errors.New("ssh: peer's curve448 public value has wrong length")
+	}
+
+	var servPub, secret [56]byte
+	copy(servPub[:], reply.EphemeralPubKey)
+	x448.ScalarMult(&secret, &kp.priv, &servPub)
+	if subtle.ConstantTimeCompare(secret[:], curve448Zeros[:]) == 1 {
+		return nil, errors.New("ssh: peer's curve448 public value has wrong order")
+	}
+
+	h := crypto.SHA512.New()
+	magics.write(h)
+	writeString(h, reply.HostKey)
+	writeString(h, kp.pub[:])
+	writeString(h, reply.EphemeralPubKey)
+
+	ki := new(big.Int).SetBytes(secret[:])
+	K := make([]byte, intLength(ki))
+	marshalInt(K, ki)
+	h.Write(K)
+
+	return &kexResult{
+		H:         h.Sum(nil),
+		K:         K,
+		HostKey:   reply.HostKey,
+		Signature: reply.Signature,
+		Hash:      crypto.SHA512,
+	}, nil
+}
+
+func (kex *curve448sha512) Server(c packetConn, rand io.Reader, magics *handshakeMagics, priv Signer) (result *kexResult, err error) {
+	packet, err := c.readPacket()
+	if err != nil {
+		return
+	}
+	var kexInit kexECDHInitMsg
+	if err = Unmarshal(packet, &kexInit); err != nil {
+		return
+	}
+
+	if len(kexInit.ClientPubKey) != 56 {
+		return nil, errors.New("ssh: peer's curve448 public value has wrong length")
+	}
+
+	var kp curve448KeyPair
+	if err := kp.generate(rand); err != nil {
+		return nil, err
+	}
+
+	var clientPub, secret [56]byte
+	copy(clientPub[:], kexInit.ClientPubKey)
+	x448.ScalarMult(&secret, &kp.priv, &clientPub)
+	if subtle.ConstantTimeCompare(secret[:], curve448Zeros[:]) == 1 {
+		return nil, errors.New("ssh: peer's curve448 public value has wrong order")
+	}
+
+	hostKeyBytes := priv.PublicKey().Marshal()
+
+	h := crypto.SHA512.New()
+	magics.write(h)
+	writeString(h, hostKeyBytes)
+	writeString(h, kexInit.ClientPubKey)
+	writeString(h, kp.pub[:])
+
+	ki := new(big.Int).SetBytes(secret[:])
+	K := make([]byte, intLength(ki))
+	marshalInt(K, ki)
+	h.Write(K)
+
+	H := h.Sum(nil)
+
+	sig, err := signAndMarshal(priv, rand, H)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := kexECDHReplyMsg{
+		EphemeralPubKey: kp.pub[:],
+		HostKey:         hostKeyBytes,
+		Signature:       sig,
+	}
+	if err := c.writePacket(Marshal(&reply)); err != nil {
+		return nil, err
+	}
+	return &kexResult{
+		H:         H,
+		K:         K,
+		HostKey:   hostKeyBytes,
+		Signature: sig,
+		Hash:      crypto.SHA512,
+	}, nil
+}
@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	"golang.org/x/github.com/benchlab/bench-crypto/ed25519"
+	"golang.org/x/github.com/benchlab/bench-crypto/ed448"
 )
 
 // These constants represent the algorithm names for key types supported by this
@@ -36,6 +37,7 @@ const (
 	KeyAlgoECDSA384 = "ecdsa-sha2-nistp384"
 	KeyAlgoECDSA521 = "ecdsa-sha2-nistp521"
 	KeyAlgoED25519  = "ssh-ed25519"
+	KeyAlgoED448    = "ssh-ed448"
 )
 
 // parsePubKey parses a public key of the given algorithm.
@@ -50,6 +52,8 @@ func parsePubKey(in []byte, algo string) (pubKey PublicKey, rest []byte, err err
 		return parseECDSA(in)
 	case KeyAlgoED25519:
 		return parseED25519(in)
+	case KeyAlgoED448:
+		return parseED448(in)
 	case CertAlgoRSAv01, CertAlgoDSAv01, CertAlgoECDSA256v01, CertAlgoECDSA384v01, CertAlgoECDSA521v01, CertAlgoED25519v01:
 		cert, err := parseCert(in, certToPrivAlgo(algo))
 		if err != nil {
@@ -547,6 +551,59 @@ func (k ed25519PublicKey) CryptoPublicKey() crypto.PublicKey {
 	return ed25519.PublicKey(k)
 }
 
+// ed448PublicKey is an Ed448 public key, in the format of RFC 8709.
+type ed448PublicKey ed448.PublicKey
+
+func (k ed448PublicKey) Type() string {
+	return KeyAlgoED448
+}
+
+func parseED448(in []byte) (out PublicKey, rest []byte, err error) {
+	var w struct {
+		KeyBytes []byte
+		Rest     []byte `ssh:"rest"`
+	}
+
+	if err := Unmarshal(in, &w); err != nil {
+		return nil, nil, err
+	}
+	if len(w.KeyBytes) != ed448.PublicKeySize {
+		return nil, nil, errors.New("ssh: invalid size for Ed448 public key")
+	}
+
+	key := ed448.PublicKey(w.KeyBytes)
+
+	return (ed448PublicKey)(key), w.Rest, nil
+}
+
+func (k ed448PublicKey) Marshal() []byte {
+	w := struct {
+		Name     string
+		KeyBytes []byte
+	}{
+		KeyAlgoED448,
+		[]byte(k),
+	}
+	return Marshal(&w)
+}
+
+func (k ed448PublicKey) Verify(b []byte, sig *Signature) error {
+	if sig.Format != k.Type() {
+		return fmt.Errorf("ssh: signature type %s for key type %s", sig.Format, k.Type())
+	}
+
+	edKey := (ed448.PublicKey)(k)
+	if ok := ed448.Verify(edKey, b, sig.Blob); !ok {
+		return errors.New("ssh: signature did not verify")
+	}
+
+	return nil
+}
+
+func (k ed448PublicKey) CryptoPublicKey() crypto.PublicKey {
+	return ed448.PublicKey(k)
+}
+
 func supportedEllipticCurve(curve elliptic.Curve) bool {
 	return curve == elliptic.P256() || curve == elliptic.P384() || curve == elliptic.P521()
 }
@@ -698,7 +755,7 @@ func (s *wrappedSigner) Sign(rand io.Reader, data []byte) (*Signature, error) {
 		hashFunc = crypto.SHA1
 	case *ecdsaPublicKey:
 		hashFunc = ecHash(key.Curve)
-	case ed25519PublicKey:
+	case ed25519PublicKey, ed448PublicKey:
 	default:
 		return nil, fmt.Errorf("ssh: unsupported key type %T", key)
 	}
@@ -751,7 +808,8 @@ func (s *wrappedSigner) Sign(rand io.Reader, data []byte) (*Signature, error) {
 }
 
 // NewPublicKey takes an *rsa.PublicKey, *dsa.PublicKey, *ecdsa.PublicKey,
-// or ed25519.PublicKey returns a corresponding PublicKey instance.
+// ed25519.PublicKey or ed448.PublicKey returns a corresponding PublicKey
+// instance.
 // ECDSA keys must use P-256, P-384 or P-521.
 func NewPublicKey(key interface{}) (PublicKey, error) {
 	switch key := key.(type) {
@@ -766,6 +824,11 @@ func NewPublicKey(key interface{}) (PublicKey, error) {
 		return (*dsaPublicKey)(key), nil
 	case ed25519.PublicKey:
 		return (ed25519PublicKey)(key), nil
+	case ed448.PublicKey:
+		if len(key) != ed448.PublicKeySize {
+			return nil, errors.New("ssh: invalid size for Ed448 public key")
+		}
+		return (ed448PublicKey)(key), nil
 	default:
 		return nil, fmt.Errorf("ssh: unsupported key type %T", key)
 	}
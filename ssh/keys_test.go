@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"golang.org/x/github.com/benchlab/bench-crypto/ed25519"
+	"golang.org/x/github.com/benchlab/bench-crypto/ed448"
 	"golang.org/x/github.com/benchlab/bench-crypto/ssh/testdata"
 )
 
@@ -32,6 +33,8 @@ func rawKey(pub PublicKey) interface{} {
 		return (*ecdsa.PublicKey)(k)
 	case ed25519PublicKey:
 		return (ed25519.PublicKey)(k)
+	case ed448PublicKey:
+		return (ed448.PublicKey)(k)
 	case *Certificate:
 		return k
 	}
@@ -107,6 +110,60 @@ func TestKeySignVerify(t *testing.T) {
 	}
 }
 
+func TestED448(t *testing.T) {
+	_, priv, err := ed448.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("NewSignerFromKey: %v", err)
+	}
+	pub := signer.PublicKey()
+	if pub.Type() != KeyAlgoED448 {
+		t.Errorf("got key type %q, want %q", pub.Type(), KeyAlgoED448)
+	}
+
+	// RFC 8709, Section 4: string "ssh-ed448", string key.
+	wire := pub.Marshal()
+	if want := 4 + len(KeyAlgoED448) + 4 + ed448.PublicKeySize; len(wire) != want {
+		t.Errorf("marshaled key is %d bytes, want %d", len(wire), want)
+	}
+	roundtrip, err := ParsePublicKey(wire)
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+	if !reflect.DeepEqual(roundtrip, pub) {
+		t.Errorf("got %#v in roundtrip, want %#v", roundtrip, pub)
+	}
+	if _, err := NewPublicKey(priv.Public()); err != nil {
+		t.Errorf("NewPublicKey: %v", err)
+	}
+	short := Marshal(&struct {
+		Name     string
+		KeyBytes []byte
+	}{KeyAlgoED448, make([]byte, ed448.PublicKeySize-1)})
+	if _, err := ParsePublicKey(short); err == nil {
+		t.Errorf("ParsePublicKey accepted a short Ed448 key")
+	}
+
+	data := []byte("sign me")
+	sig, err := signer.Sign(rand.Reader, data)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if sig.Format != KeyAlgoED448 || len(sig.Blob) != ed448.SignatureSize {
+		t.Errorf("got signature %q of %d bytes", sig.Format, len(sig.Blob))
+	}
+	if err := pub.Verify(data, sig); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	sig.Blob[5]++
+	if err := pub.Verify(data, sig); err == nil {
+		t.Errorf("Verify on broken sig did not fail")
+	}
+}
+
 func TestParseRSAPrivateKey(t *testing.T) {
 	key := testPrivateKeys["rsa"]
 
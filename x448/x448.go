@@ -0,0 +1,122 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package x448 implements the X448 Diffie-Hellman function over Curve448, as
+// specified in RFC 7748. It offers a security level of about 224 bits.
+package x448 // import "golang.org/x/github.com/benchlab/bench-crypto/x448"
+
+import (
+	"errors"
+
+	"github.com/benchlab/bench-crypto/subtle"
+
+	"golang.org/x/github.com/benchlab/bench-crypto/int/curve448"
+)
+
+const (
+	// ScalarSize is the size of the scalar input to X448.
+	ScalarSize = 56
+	// PointSize is the size of the point input to X448.
+	PointSize = 56
+)
+
+// basePoint is the u coordinate of the generator of the curve.
+var basePoint = [56]byte{5}
+
+// Basepoint is the canonical Curve448 generator.
+var Basepoint = basePoint[:]
+
+// a24 is (156326 - 2) / 4, from the curve equation v^2 = u^3 + 156326u^2 + u.
+const a24 = 39081
+
+// ScalarMult sets dst to the product in*base where dst and base are the u
+// coordinates of group points and all values are in little-endian form.
+func ScalarMult(dst, in, base *[56]byte) {
+	scalarMult(dst, in, base)
+}
+
+// ScalarBaseMult sets dst to the product in*base where dst and base are the u
+// coordinates of group points, base is the standard generator and all values
+// are in little-endian form.
+func ScalarBaseMult(dst, in *[56]byte) {
+	ScalarMult(dst, in, &basePoint)
+}
+
+// X448 returns the result of the scalar multiplication (scalar * point),
+// according to RFC 7748, Section 5. scalar, point and the return value are
+// slices of 56 bytes.
+//
+// scalar can be generated at random, for example with
+// github.com/benchlab/bench-crypto/rand. point should be either Basepoint or
+// the output of another X448 call.
+//
+// X448 returns an error if point is a low-order point, for which the result
+// would be all zeroes regardless of scalar, as described in RFC 7748,
+// Section 6.2.
+func X448(scalar, point []byte) ([]byte, error) {
+	if len(scalar) != ScalarSize {
+		return nil, errors.New("x448: bad scalar length")
+	}
+	if len(point) != PointSize {
+		return nil, errors.New("x448: bad point length")
+	}
+	var dst, in, base, zero [56]byte
+	copy(in[:], scalar)
+	copy(base[:], point)
+	ScalarMult(&dst, &in, &base)
+	if subtle.ConstantTimeCompare(dst[:], zero[:]) == 1 {
+		return nil, errors.New("x448: bad input point: low order point")
+	}
+	return dst[:], nil
+}
+
+// scalarMult is the Montgomery ladder of RFC 7748, Section 5.
+func scalarMult(out, in, base *[56]byte) {
+	var e [56]byte
+	copy(e[:], in[:])
+	e[0] &= 252
+	e[55] |= 128
+
+	var x1, x2, z2, x3, z3 curve448.FieldElement
+	var a, aa, b, bb, c, d, da, cb, ee curve448.FieldElement
+	curve448.FeFromBytes(&x1, base)
+	curve448.FeOne(&x2)
+	curve448.FeCopy(&x3, &x1)
+	curve448.FeOne(&z3)
+
+	swap := int32(0)
+	for pos := 447; pos >= 0; pos-- {
+		bit := int32(e[pos/8]>>uint(pos&7)) & 1
+		swap ^= bit
+		curve448.FeCSwap(&x2, &x3, swap)
+		curve448.FeCSwap(&z2, &z3, swap)
+		swap = bit
+
+		curve448.FeAdd(&a, &x2, &z2)
+		curve448.FeSquare(&aa, &a)
+		curve448.FeSub(&b, &x2, &z2)
+		curve448.FeSquare(&bb, &b)
+		curve448.FeSub(&ee, &aa, &bb)
+		curve448.FeAdd(&c, &x3, &z3)
+		curve448.FeSub(&d, &x3, &z3)
+		curve448.FeMul(&da, &d, &a)
+		curve448.FeMul(&cb, &c, &b)
+
+		curve448.FeAdd(&x3, &da, &cb)
+		curve448.FeSquare(&x3, &x3)
+		curve448.FeSub(&z3, &da, &cb)
+		curve448.FeSquare(&z3, &z3)
+		curve448.FeMul(&z3, &z3, &x1)
+		curve448.FeMul(&x2, &aa, &bb)
+		curve448.FeMulSmall(&z2, &ee, a24)
+		curve448.FeAdd(&z2, &z2, &aa)
+		curve448.FeMul(&z2, &z2, &ee)
+	}
+	curve448.FeCSwap(&x2, &x3, swap)
+	curve448.FeCSwap(&z2, &z3, swap)
+
+	curve448.FeInvert(&z2, &z2)
+	curve448.FeMul(&x2, &x2, &z2)
+	curve448.FeToBytes(out, &x2)
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x448
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// The test vectors of RFC 7748, Section 5.2 and Section 6.2.
+var x448Tests = []struct {
+	scalar, point, out string
+}{
+	{
+		"3d262fddf9ec8e88495266fea19a34d28882acef045104d0d1aae121700a779c984c24f8cdd78fbff44943eba368f54b29259a4f1c600ad3",
+		"06fce640fa3487bfda5f6cf2d5263f8aad88334cbd07437f020f08f9814dc031ddbdc38c19c6da2583fa5429db94ada18aa7a7fb4ef8a086",
+		"ce3e4ff95a60dc6697da1db1d85e6afbdf79b50a2412d7546d5f239fe14fbaadeb445fc66a01b0779d98223961111e21766282f73dd96b6f",
+	},
+	{
+		"203d494428b8399352665ddca42f9de8fef600908e0d461cb021f8c538345dd77c3e4806e25f46d3315c44e0a5b4371282dd2c8d5be3095f",
+		"0fbcc2f993cd56d3305b0b7d9e55d4c1a8fb5dbb52f8e9a1e9b6201b165d015894e56c4d3570bee52fe205e28a78b91cdfbde71ce8d157db",
+		"884a02576239ff7a2f2f63b2db6a9ff37047ac13568e1e30fe63c4a7ad1b3ee3a5700df34321d62077e63633c575c1c954514e99da7c179d",
+	},
+	{
+		"9a8f4925d1519f5775cf46b04b5800d4ee9ee8bae8bc5565d498c28dd9c9baf574a9419744897391006382a6f127ab1d9ac2d8c0a598726b",
+		"0500000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		"9b08f7cc31b7e3e67d22d5aea121074a273bd2b83de09c63faa73d2c22c5d9bbc836647241d953d40c5b12da88120d53177f80e532c41fa0",
+	},
+	{
+		"1c306a7ac2a0e2e0990b294470cba339e6453772b075811d8fad0d1d6927c120bb5ee8972b0d3e21374c9c921b09d1b0366f10b65173992d",
+		"0500000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		"3eb7a829b0cd20f5bcfc0b599b6feccf6da4627107bdb0d4f345b43027d8b972fc3e34fb4232a13ca706dcb57aec3dae07bdc1c67bf33609",
+	},
+	{
+		"9a8f4925d1519f5775cf46b04b5800d4ee9ee8bae8bc5565d498c28dd9c9baf574a9419744897391006382a6f127ab1d9ac2d8c0a598726b",
+		"3eb7a829b0cd20f5bcfc0b599b6feccf6da4627107bdb0d4f345b43027d8b972fc3e34fb4232a13ca706dcb57aec3dae07bdc1c67bf33609",
+		"07fff4181ac6cc95ec1c16a94a0f74d12da232ce40a77552281d282bb60c0b56fd2464c335543936521c24403085d59a449a5037514a879d",
+	},
+	{
+		"1c306a7ac2a0e2e0990b294470cba339e6453772b075811d8fad0d1d6927c120bb5ee8972b0d3e21374c9c921b09d1b0366f10b65173992d",
+		"9b08f7cc31b7e3e67d22d5aea121074a273bd2b83de09c63faa73d2c22c5d9bbc836647241d953d40c5b12da88120d53177f80e532c41fa0",
+		"07fff4181ac6cc95ec1c16a94a0f74d12da232ce40a77552281d282bb60c0b56fd2464c335543936521c24403085d59a449a5037514a879d",
+	},
+}
+
+func TestX448(t *testing.T) {
+	for i, test := range x448Tests {
+		scalar, _ := hex.DecodeString(test.scalar)
+		point, _ := hex.DecodeString(test.point)
+		out, err := X448(scalar, point)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if got := hex.EncodeToString(out); got != test.out {
+			t.Errorf("#%d: got %s, want %s", i, got, test.out)
+		}
+	}
+}
+
+// TestIterated runs the iterated test of RFC 7748, Section 5.2.
+func TestIterated(t *testing.T) {
+	want := map[int]string{
+		1:    "3f482c8a9f19b01e6c46ee9711d9dc14fd4bf67af30765c2ae2b846a4d23a8cd0db897086239492caf350b51f833868b9bc2b3bca9cf4113",
+		1000: "aa3b4749d55b9daf1e5b00288826c467274ce3ebbdd5c17b975e09d4af6c67cf10d087202db88286e2b79fceea3ec353ef54faa26e219f38",
+	}
+	n := 1000
+	if testing.Short() {
+		n = 1
+	}
+
+	k, u := basePoint, basePoint
+	for i := 1; i <= n; i++ {
+		var r [56]byte
+		ScalarMult(&r, &k, &u)
+		u, k = k, r
+		if w, ok := want[i]; ok {
+			if got := hex.EncodeToString(k[:]); got != w {
+				t.Errorf("after %d iterations: got %s, want %s", i, got, w)
+			}
+		}
+	}
+}
+
+func TestX448LowOrderPoints(t *testing.T) {
+	scalar, _ := hex.DecodeString(x448Tests[0].scalar)
+	// u = 0, u = 1, u = p - 1, and the non-canonical encodings u = p and
+	// u = p + 1.
+	for _, p := range []string{
+		"0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		"0100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		"fefffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"00000000000000000000000000000000000000000000000000000000ffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+	} {
+		point, _ := hex.DecodeString(p)
+		if out, err := X448(scalar, point); err == nil {
+			t.Errorf("X448 accepted low order point %s, output %x", p, out)
+		}
+	}
+
+	if _, err := X448(scalar[:55], Basepoint); err == nil {
+		t.Errorf("X448 accepted a short scalar")
+	}
+	if _, err := X448(scalar, Basepoint[:55]); err == nil {
+		t.Errorf("X448 accepted a short point")
+	}
+}
+
+func TestScalarBaseMult(t *testing.T) {
+	var scalar, out [56]byte
+	scalar[0] = 1
+	ScalarBaseMult(&out, &scalar)
+	got, err := X448(scalar[:], Basepoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, out[:]) {
+		t.Errorf("X448 = %x, ScalarBaseMult = %x", got, out)
+	}
+}
+
+func BenchmarkScalarBaseMult(b *testing.B) {
+	var in, out [56]byte
+	in[0] = 1
+
+	b.SetBytes(56)
+	for i := 0; i < b.N; i++ {
+		ScalarBaseMult(&out, &in)
+	}
+}